@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// DefaultDeliveriesAtlantisURL is the Atlantis server the deliveries command
+// talks to by default.
+const DefaultDeliveriesAtlantisURL = "http://localhost:4141"
+
+// DeliveriesCmd lists and replays the webhook deliveries recorded by an
+// Atlantis server using its admin API.
+type DeliveriesCmd struct {
+	// Viper must be a different instance than the server command's since both
+	// commands have an --atlantis-url flag.
+	Viper *viper.Viper
+	// Out is where responses are printed. Defaults to stdout.
+	Out io.Writer
+}
+
+// Init returns the runnable cobra command.
+func (d *DeliveriesCmd) Init() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "deliveries",
+		Short: "List and replay the webhook deliveries recorded by an Atlantis server",
+		Long: `List and replay the webhook deliveries recorded by an Atlantis server.
+The server must be started with --` + AdminTokenFlag + `.`,
+	}
+	list := &cobra.Command{
+		Use:           "list",
+		Short:         "List the recorded deliveries, newest first",
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return d.withErrPrint(d.request("GET", "/admin/deliveries"))
+		},
+	}
+	replay := &cobra.Command{
+		Use:           "replay ID",
+		Short:         "Replay the delivery with ID as if the server had just received it",
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return d.withErrPrint(d.request("POST", "/admin/deliveries/"+url.PathEscape(args[0])+"/replay"))
+		},
+	}
+	c.AddCommand(list, replay)
+
+	// Like the server command, flags can also be set with ATLANTIS_ env vars.
+	d.Viper.SetEnvPrefix("ATLANTIS")
+	d.Viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	d.Viper.AutomaticEnv()
+	c.PersistentFlags().String(AtlantisURLFlag, "", fmt.Sprintf("URL of the Atlantis server. (default %q)", DefaultDeliveriesAtlantisURL))
+	c.PersistentFlags().String(AdminTokenFlag, "", "The server's admin token. Should be specified via the ATLANTIS_ADMIN_TOKEN environment variable.")
+	d.Viper.BindPFlag(AtlantisURLFlag, c.PersistentFlags().Lookup(AtlantisURLFlag)) // nolint: errcheck
+	d.Viper.BindPFlag(AdminTokenFlag, c.PersistentFlags().Lookup(AdminTokenFlag))   // nolint: errcheck
+	return c
+}
+
+// request makes the admin API request and prints the response.
+func (d *DeliveriesCmd) request(method string, path string) error {
+	token := d.Viper.GetString(AdminTokenFlag)
+	if token == "" {
+		return fmt.Errorf("--%s must be set", AdminTokenFlag)
+	}
+	atlantisURL := d.Viper.GetString(AtlantisURLFlag)
+	if atlantisURL == "" {
+		atlantisURL = DefaultDeliveriesAtlantisURL
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(atlantisURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading response")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	out := d.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintln(out, strings.TrimSpace(string(body)))
+	return nil
+}
+
+// withErrPrint prints err in red to stderr, if it's not nil, and returns it.
+func (d *DeliveriesCmd) withErrPrint(err error) error {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\033[31mError: %s\033[39m\n\n", err.Error())
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package cmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudposse/atlantis/cmd"
+	. "github.com/cloudposse/atlantis/testing"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestDeliveries_NoToken(t *testing.T) {
+	c := setupDeliveries(&bytes.Buffer{}, "list", "--atlantis-url", "http://localhost")
+	ErrEquals(t, "--admin-token must be set", c.Execute())
+}
+
+func TestDeliveries_List(t *testing.T) {
+	var path, auth string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `[{"id": "1"}]`)
+	}))
+	defer s.Close()
+
+	out := &bytes.Buffer{}
+	c := setupDeliveries(out, "list", "--atlantis-url", s.URL+"/", "--admin-token", "token")
+	Ok(t, c.Execute())
+	Equals(t, "GET /admin/deliveries", path)
+	Equals(t, "Bearer token", auth)
+	Equals(t, "[{\"id\": \"1\"}]\n", out.String())
+}
+
+func TestDeliveries_Replay(t *testing.T) {
+	var path string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		fmt.Fprintln(w, "Processing...")
+	}))
+	defer s.Close()
+
+	out := &bytes.Buffer{}
+	c := setupDeliveries(out, "replay", "abc-123", "--atlantis-url", s.URL, "--admin-token", "token")
+	Ok(t, c.Execute())
+	Equals(t, "POST /admin/deliveries/abc-123/replay", path)
+	Equals(t, "Processing...\n", out.String())
+}
+
+func TestDeliveries_ErrorResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintln(w, "Invalid admin token")
+	}))
+	defer s.Close()
+
+	c := setupDeliveries(&bytes.Buffer{}, "list", "--atlantis-url", s.URL, "--admin-token", "wrong")
+	ErrEquals(t, "401 Unauthorized: Invalid admin token", c.Execute())
+}
+
+// setupDeliveries returns the deliveries command set up to run with args.
+func setupDeliveries(out *bytes.Buffer, args ...string) *cobra.Command {
+	d := &cmd.DeliveriesCmd{Viper: viper.New(), Out: out}
+	c := d.Init()
+	c.SetArgs(args)
+	return c
+}
@@ -19,9 +19,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/cloudposse/atlantis/server"
+	"github.com/cloudposse/atlantis/server/events/terraform"
+	"github.com/cloudposse/atlantis/server/events/vcs/azuredevops"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketcloud"
+	"github.com/cloudposse/atlantis/server/events/vcs/retry"
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -34,53 +38,114 @@ import (
 // 3. Add your flag's description etc. to the stringFlags, intFlags, or boolFlags slices.
 const (
 	// Flag names.
-	AllowForkPRsFlag           = "allow-fork-prs"
-	AllowRepoConfigFlag        = "allow-repo-config"
-	AtlantisURLFlag            = "atlantis-url"
-	BitbucketBaseURLFlag       = "bitbucket-base-url"
-	BitbucketTokenFlag         = "bitbucket-token"
-	BitbucketUserFlag          = "bitbucket-user"
-	BitbucketWebhookSecretFlag = "bitbucket-webhook-secret"
-	ConfigFlag                 = "config"
-	DataDirFlag                = "data-dir"
-	GHHostnameFlag             = "gh-hostname"
-	GHTeamWhitelistFlag        = "gh-team-whitelist"
-	GHTokenFlag                = "gh-token"
-	GHUserFlag                 = "gh-user"
-	GHWebhookSecretFlag        = "gh-webhook-secret" // nolint: gosec
-	GitlabHostnameFlag         = "gitlab-hostname"
-	GitlabTokenFlag            = "gitlab-token"
-	GitlabUserFlag             = "gitlab-user"
-	GitlabWebhookSecretFlag    = "gitlab-webhook-secret" // nolint: gosec
-	LogLevelFlag               = "log-level"
-	PortFlag                   = "port"
-	RepoConfigFlag             = "repo-config"
-	RepoWhitelistFlag          = "repo-whitelist"
-	RequireApprovalFlag        = "require-approval"
-	SSLCertFileFlag            = "ssl-cert-file"
-	SSLKeyFileFlag             = "ssl-key-file"
-	WakeWordFlag               = "wake-word"
+	AdminTokenFlag                 = "admin-token" // nolint: gosec
+	AllowForkPRsFlag               = "allow-fork-prs"
+	AllowRepoConfigFlag            = "allow-repo-config"
+	AtlantisURLFlag                = "atlantis-url"
+	AutomergeFlag                  = "automerge"
+	AzureDevopsHostnameFlag        = "azuredevops-hostname"
+	AzureDevopsTokenFlag           = "azuredevops-token"
+	AzureDevopsUserFlag            = "azuredevops-user"
+	AzureDevopsWebhookPasswordFlag = "azuredevops-webhook-password" // nolint: gosec
+	AzureDevopsWebhookUserFlag     = "azuredevops-webhook-user"
+	BitbucketBaseURLFlag           = "bitbucket-base-url"
+	BitbucketTokenFlag             = "bitbucket-token"
+	BitbucketUserFlag              = "bitbucket-user"
+	BitbucketWebhookSecretFlag     = "bitbucket-webhook-secret"
+	CommentModeFlag                = "comment-mode"
+	ConfigFlag                     = "config"
+	DataDirFlag                    = "data-dir"
+	GHAppIDFlag                    = "gh-app-id"
+	GHAppKeyFileFlag               = "gh-app-key-file"
+	GHChecksFlag                   = "gh-checks"
+	GHHostnameFlag                 = "gh-hostname"
+	GHTeamWhitelistFlag            = "gh-team-whitelist"
+	GHTokenFlag                    = "gh-token"
+	GHUserFlag                     = "gh-user"
+	GHWebhookSecretFlag            = "gh-webhook-secret" // nolint: gosec
+	GiteaBaseURLFlag               = "gitea-base-url"
+	GiteaTokenFlag                 = "gitea-token"
+	GiteaUserFlag                  = "gitea-user"
+	GiteaWebhookSecretFlag         = "gitea-webhook-secret" // nolint: gosec
+	GitlabHostnameFlag             = "gitlab-hostname"
+	GitlabTokenFlag                = "gitlab-token"
+	GitlabUserFlag                 = "gitlab-user"
+	GitlabWebhookSecretFlag        = "gitlab-webhook-secret" // nolint: gosec
+	LogLevelFlag                   = "log-level"
+	PolicyApproversFlag            = "policy-approvers"
+	PortFlag                       = "port"
+	RepoConfigFlag                 = "repo-config"
+	RepoWhitelistFlag              = "repo-whitelist"
+	RequireApprovalFlag            = "require-approval"
+	SSLCertFileFlag                = "ssl-cert-file"
+	SSLKeyFileFlag                 = "ssl-key-file"
+	TFDownloadURLFlag              = "tf-download-url"
+	VCSCacheModifiedFilesTTLFlag   = "vcs-cache-modified-files-ttl"
+	VCSCachePullTTLFlag            = "vcs-cache-pull-ttl"
+	VCSCacheTeamsTTLFlag           = "vcs-cache-teams-ttl"
+	VCSDeliveryDedupWindowFlag     = "vcs-delivery-dedup-window"
+	VCSDeliveryRetentionFlag       = "vcs-delivery-retention"
+	VCSMaxRetriesFlag              = "vcs-max-retries"
+	WakeWordFlag                   = "wake-word"
 
 	// Flag defaults.
-	DefaultBitbucketBaseURL = bitbucketcloud.BaseURL
-	DefaultDataDir          = "~/.atlantis"
-	DefaultGHHostname       = "github.com"
-	DefaultGHTeamWhitelist  = "*:*"
-	DefaultGitlabHostname   = "gitlab.com"
-	DefaultLogLevel         = "info"
-	DefaultPort             = 4141
-	DefaultRepoConfig       = "atlantis.yaml"
-	DefaultWakeWord         = "atlantis"
+	DefaultAzureDevopsHostname      = azuredevops.DefaultHostname
+	DefaultBitbucketBaseURL         = bitbucketcloud.BaseURL
+	DefaultCommentMode              = "new"
+	DefaultDataDir                  = "~/.atlantis"
+	DefaultGHHostname               = "github.com"
+	DefaultGHTeamWhitelist          = "*:*"
+	DefaultGiteaBaseURL             = "https://gitea.com"
+	DefaultGitlabHostname           = "gitlab.com"
+	DefaultLogLevel                 = "info"
+	DefaultPort                     = 4141
+	DefaultRepoConfig               = "atlantis.yaml"
+	DefaultTFDownloadURL            = terraform.DefaultDownloadURL
+	DefaultVCSCacheModifiedFilesTTL = "1h"
+	DefaultVCSCachePullTTL          = "1m"
+	DefaultVCSCacheTeamsTTL         = "5m"
+	DefaultVCSDeliveryDedupWindow   = "10m"
+	DefaultVCSDeliveryRetention     = "0"
+	DefaultVCSMaxRetries            = retry.DefaultMaxRetries
+	DefaultWakeWord                 = "atlantis"
 )
 
 const redTermStart = "\033[31m"
 const redTermEnd = "\033[39m"
 
 var stringFlags = []stringFlag{
+	{
+		name: AdminTokenFlag,
+		description: "Token required to use the admin API, ex. to list and replay webhook deliveries." +
+			" The admin API is disabled if not set. Should be specified via the ATLANTIS_ADMIN_TOKEN environment variable.",
+	},
 	{
 		name:        AtlantisURLFlag,
 		description: "URL that Atlantis can be reached at. Defaults to http://$(hostname):$port where $port is from --" + PortFlag + ".",
 	},
+	{
+		name:         AzureDevopsHostnameFlag,
+		description:  "Hostname of your Azure DevOps Server installation. If using dev.azure.com, no need to set. Can include a scheme, ex. 'http://azuredevops.corp:8080'.",
+		defaultValue: DefaultAzureDevopsHostname,
+	},
+	{
+		name:        AzureDevopsUserFlag,
+		description: "Azure DevOps username of API user.",
+	},
+	{
+		name:        AzureDevopsTokenFlag,
+		description: "Azure DevOps personal access token of API user. Can also be specified via the ATLANTIS_AZUREDEVOPS_TOKEN environment variable.",
+	},
+	{
+		name: AzureDevopsWebhookUserFlag,
+		description: "Basic auth username Azure DevOps service hooks must send." +
+			" SECURITY WARNING: If not specified, Atlantis won't be able to validate that the incoming webhook call came from Azure DevOps. " +
+			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions.",
+	},
+	{
+		name:        AzureDevopsWebhookPasswordFlag,
+		description: "Basic auth password Azure DevOps service hooks must send. Should be specified via the ATLANTIS_AZUREDEVOPS_WEBHOOK_PASSWORD environment variable.",
+	},
 	{
 		name:        BitbucketUserFlag,
 		description: "Bitbucket username of API user.",
@@ -104,8 +169,16 @@ var stringFlags = []stringFlag{
 			"Should be specified via the ATLANTIS_BITBUCKET_WEBHOOK_SECRET environment variable.",
 	},
 	{
-		name:        ConfigFlag,
-		description: "Path to config file. All flags can be set in a YAML config file instead.",
+		name: CommentModeFlag,
+		description: "What to do with Atlantis's previous comment for the same command and projects when commenting back on a pull request." +
+			" One of 'new' (leave it and make a new comment), 'update' (edit it to have the new output) or 'hide' (make a new comment and hide it)." +
+			" Hiding is only supported by GitHub and Azure DevOps.",
+		defaultValue: DefaultCommentMode,
+	},
+	{
+		name: ConfigFlag,
+		description: "Path to config file. All flags can be set in a YAML config file instead." +
+			" Additional VCS hosts can only be set in the config file, under vcs-hosts, and must be github, gitlab, bitbucket-server or gitea hosts.",
 	},
 	{
 		name:         DataDirFlag,
@@ -125,12 +198,18 @@ var stringFlags = []stringFlag{
 			"give the 'ops' team the permissions to execute the 'apply' command, " +
 			"give the 'admin' team the permissions to execute the 'destroy' command, " +
 			"and allow the 'devops' team to perform any operation. If this argument is not provided, the default value (*:*) will be used and the default behavior will be to not check permissions " +
-			"and to allow users from any team to perform any operation.",
+			"and to allow users from any team to perform any operation. " +
+			"The teams are also matched against GitLab groups by full path, ex. org/devops, Bitbucket Cloud workspace groups, Bitbucket Server repo permissions, ex. REPO_WRITE, " +
+			"Gitea organization teams and Azure DevOps project teams.",
 		defaultValue: DefaultGHTeamWhitelist,
 	},
+	{
+		name:        GHAppKeyFileFlag,
+		description: "Path to the private key file of the GitHub App Atlantis authenticates as. Must be set with --" + GHAppIDFlag + ".",
+	},
 	{
 		name:        GHUserFlag,
-		description: "GitHub username of API user.",
+		description: "GitHub username of API user. If running as a GitHub App, optionally set to the app's bot username so it can be mentioned in comments.",
 	},
 	{
 		name:        GHTokenFlag,
@@ -143,6 +222,27 @@ var stringFlags = []stringFlag{
 			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
 			"Should be specified via the ATLANTIS_GH_WEBHOOK_SECRET environment variable.",
 	},
+	{
+		name: GiteaBaseURLFlag,
+		description: "Base URL of your Gitea installation." +
+			" Must include scheme, ex. 'https://gitea.corp.com' or 'http://gitea.corp:3000'. If using gitea.com, no need to set.",
+		defaultValue: DefaultGiteaBaseURL,
+	},
+	{
+		name:        GiteaUserFlag,
+		description: "Gitea username of API user.",
+	},
+	{
+		name:        GiteaTokenFlag,
+		description: "Gitea access token of API user. Can also be specified via the ATLANTIS_GITEA_TOKEN environment variable.",
+	},
+	{
+		name: GiteaWebhookSecretFlag,
+		description: "Optional secret used to validate Gitea webhooks." +
+			" SECURITY WARNING: If not specified, Atlantis won't be able to validate that the incoming webhook call came from Gitea. " +
+			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
+			"Should be specified via the ATLANTIS_GITEA_WEBHOOK_SECRET environment variable.",
+	},
 	{
 		name:         GitlabHostnameFlag,
 		description:  "Hostname of your GitLab Enterprise installation. If using gitlab.com, no need to set.",
@@ -168,6 +268,13 @@ var stringFlags = []stringFlag{
 		description:  "Log level. Either debug, info, warn, or error.",
 		defaultValue: DefaultLogLevel,
 	},
+	{
+		name: PolicyApproversFlag,
+		description: "Comma separated list of the teams whose members can approve plans that failed their policy checks with 'atlantis approve_policies'." +
+			" Plans that failed can't be applied or destroyed until they're approved. " +
+			"Teams are matched the same way as --" + GHTeamWhitelistFlag + ". Policy sets are configured with policy-sets in the config file." +
+			" Rego policy sets are evaluated with the opa binary, which must be in $PATH or the server won't start.",
+	},
 	{
 		name: RepoConfigFlag,
 		description: "Optional path to the Atlantis YAML config file contained in each repo that this server should use. " +
@@ -189,6 +296,43 @@ var stringFlags = []stringFlag{
 		name:        SSLKeyFileFlag,
 		description: fmt.Sprintf("File containing x509 private key matching --%s.", SSLCertFileFlag),
 	},
+	{
+		name: TFDownloadURLFlag,
+		description: "Base URL to download terraform versions from when a project's terraform_version isn't installed." +
+			" Must have the same layout as https://releases.hashicorp.com, ex. an internal mirror. Downloads are verified against" +
+			" the release's SHA256SUMS and cached in the data dir.",
+		defaultValue: DefaultTFDownloadURL,
+	},
+	{
+		name: VCSCacheModifiedFilesTTLFlag,
+		description: "How long to cache the files modified by a pull request, ex. 30m. They're cached by the pull request's head commit" +
+			" so new commits are always picked up. Set to 0 to disable caching.",
+		defaultValue: DefaultVCSCacheModifiedFilesTTL,
+	},
+	{
+		name: VCSCachePullTTLFlag,
+		description: "How long to cache pull requests fetched from the VCS host, ex. 30s. The cache is invalidated when we receive a pull request webhook." +
+			" Set to 0 to disable caching.",
+		defaultValue: DefaultVCSCachePullTTL,
+	},
+	{
+		name: VCSCacheTeamsTTLFlag,
+		description: "How long to cache the teams or groups a user is in, ex. 10m. Team membership is looked up for every comment when using --" + GHTeamWhitelistFlag + "." +
+			" Set to 0 to disable caching.",
+		defaultValue: DefaultVCSCacheTeamsTTL,
+	},
+	{
+		name: VCSDeliveryDedupWindowFlag,
+		description: "How long after a webhook delivery is received that deliveries from the same VCS host with the same id are ignored, ex. 5m." +
+			" VCS hosts can redeliver a webhook when they time out waiting for a response. Set to 0 to handle every delivery.",
+		defaultValue: DefaultVCSDeliveryDedupWindow,
+	},
+	{
+		name: VCSDeliveryRetentionFlag,
+		description: "How long to store the payloads of webhook deliveries in the data dir so duplicates can be ignored and deliveries can be replayed, ex. 24h." +
+			" Must be at least --" + VCSDeliveryDedupWindowFlag + ". Deliveries aren't stored by default.",
+		defaultValue: DefaultVCSDeliveryRetention,
+	},
 	{
 		name: WakeWordFlag,
 		description: "Wake word for this server to listen to. Default is 'atlantis'. " +
@@ -202,6 +346,12 @@ var boolFlags = []boolFlag{
 		description:  "Allow Atlantis to run on pull requests from forks. A security issue for public repos.",
 		defaultValue: false,
 	},
+	{
+		name: AutomergeFlag,
+		description: "Automatically merge pull requests once all their plans have been successfully applied." +
+			" Repos can also enable this by setting automerge: true in their atlantis.yaml.",
+		defaultValue: false,
+	},
 	{
 		name: AllowRepoConfigFlag,
 		description: "Allow repositories to use atlantis repo config YAML files to customize the commands Atlantis runs." +
@@ -209,6 +359,12 @@ var boolFlags = []boolFlag{
 			" on the Atlantis server.",
 		defaultValue: false,
 	},
+	{
+		name: GHChecksFlag,
+		description: "Create a GitHub check run for each project with its plan or apply output, in addition to the \"Atlantis\" commit status." +
+			" Branch protection can then require specific projects. Requires --" + GHAppIDFlag + " since only GitHub Apps can use the Checks API.",
+		defaultValue: false,
+	},
 	{
 		name:         RequireApprovalFlag,
 		description:  "Require pull requests to be \"Approved\" before allowing the apply command to be run.",
@@ -216,11 +372,22 @@ var boolFlags = []boolFlag{
 	},
 }
 var intFlags = []intFlag{
+	{
+		name: GHAppIDFlag,
+		description: "ID of the GitHub App Atlantis authenticates as instead of a user and token." +
+			" Installation tokens are created for each organization or user the app is installed on.",
+	},
 	{
 		name:         PortFlag,
 		description:  "Port to bind to.",
 		defaultValue: DefaultPort,
 	},
+	{
+		name: VCSMaxRetriesFlag,
+		description: "Number of times to retry VCS API calls that fail because of server errors or rate limits." +
+			" Rate limited calls are retried after the time the VCS host asks us to wait. Set to -1 to disable retries.",
+		defaultValue: DefaultVCSMaxRetries,
+	},
 }
 
 type stringFlag struct {
@@ -376,6 +543,9 @@ func (s *ServerCmd) run() error {
 }
 
 func (s *ServerCmd) setDefaults(c *server.UserConfig) {
+	if c.CommentMode == "" {
+		c.CommentMode = DefaultCommentMode
+	}
 	if c.DataDir == "" {
 		c.DataDir = DefaultDataDir
 	}
@@ -388,6 +558,12 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig) {
 	if c.BitbucketBaseURL == "" {
 		c.BitbucketBaseURL = DefaultBitbucketBaseURL
 	}
+	if c.AzureDevopsHostname == "" {
+		c.AzureDevopsHostname = DefaultAzureDevopsHostname
+	}
+	if c.GiteaBaseURL == "" {
+		c.GiteaBaseURL = DefaultGiteaBaseURL
+	}
 	if c.LogLevel == "" {
 		c.LogLevel = DefaultLogLevel
 	}
@@ -403,6 +579,29 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig) {
 	if c.WakeWord == "" {
 		c.WakeWord = DefaultWakeWord
 	}
+	if c.TFDownloadURL == "" {
+		c.TFDownloadURL = DefaultTFDownloadURL
+	}
+	if c.VCSCacheModifiedFilesTTL == "" {
+		c.VCSCacheModifiedFilesTTL = DefaultVCSCacheModifiedFilesTTL
+	}
+	if c.VCSCachePullTTL == "" {
+		c.VCSCachePullTTL = DefaultVCSCachePullTTL
+	}
+	if c.VCSCacheTeamsTTL == "" {
+		c.VCSCacheTeamsTTL = DefaultVCSCacheTeamsTTL
+	}
+	if c.VCSDeliveryDedupWindow == "" {
+		c.VCSDeliveryDedupWindow = DefaultVCSDeliveryDedupWindow
+	}
+	if c.VCSDeliveryRetention == "" {
+		c.VCSDeliveryRetention = DefaultVCSDeliveryRetention
+	}
+	if c.VCSMaxRetries == 0 {
+		c.VCSMaxRetries = DefaultVCSMaxRetries
+	} else if c.VCSMaxRetries < 0 {
+		c.VCSMaxRetries = 0
+	}
 }
 
 func (s *ServerCmd) validate(userConfig server.UserConfig) error {
@@ -411,24 +610,45 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return errors.New("invalid log level: not one of debug, info, warn, error")
 	}
 
+	commentMode := userConfig.CommentMode
+	if commentMode != "new" && commentMode != "update" && commentMode != "hide" {
+		return fmt.Errorf("invalid --%s: not one of new, update, hide", CommentModeFlag)
+	}
+
 	if (userConfig.SSLKeyFile == "") != (userConfig.SSLCertFile == "") {
 		return fmt.Errorf("--%s and --%s are both required for ssl", SSLKeyFileFlag, SSLCertFileFlag)
 	}
 
 	// The following combinations are valid.
-	// 1. github user and token set
+	// 1. github user and token set, or github app id and key file set
 	// 2. gitlab user and token set
 	// 3. bitbucket user and token set
-	// 4. any combination of the above
-	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag)
-	if ((userConfig.GithubUser == "") != (userConfig.GithubToken == "")) || ((userConfig.GitlabUser == "") != (userConfig.GitlabToken == "")) || ((userConfig.BitbucketUser == "") != (userConfig.BitbucketToken == "")) {
+	// 4. gitea user and token set
+	// 5. azure devops user and token set
+	// 6. any combination of the above
+	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GHAppIDFlag, GHAppKeyFileFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag, GiteaUserFlag, GiteaTokenFlag, AzureDevopsUserFlag, AzureDevopsTokenFlag)
+	if (userConfig.GithubAppID == 0) != (userConfig.GithubAppKeyFile == "") {
+		return vcsErr
+	}
+	if userConfig.GithubAppID != 0 && userConfig.GithubToken != "" {
+		return fmt.Errorf("--%s and --%s cannot both be set", GHTokenFlag, GHAppIDFlag)
+	}
+	// When running as a GitHub App the user is optional and only used for
+	// mentions.
+	if ((userConfig.GithubUser == "") != (userConfig.GithubToken == "") && userConfig.GithubAppID == 0) || ((userConfig.GitlabUser == "") != (userConfig.GitlabToken == "")) || ((userConfig.BitbucketUser == "") != (userConfig.BitbucketToken == "")) || ((userConfig.GiteaUser == "") != (userConfig.GiteaToken == "")) || ((userConfig.AzureDevopsUser == "") != (userConfig.AzureDevopsToken == "")) {
 		return vcsErr
 	}
 	// At this point, we know that there can't be a single user/token without
 	// its partner, but we haven't checked if any user/token is set at all.
-	if userConfig.GithubUser == "" && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" {
+	if userConfig.GithubUser == "" && userConfig.GithubAppID == 0 && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" && userConfig.GiteaUser == "" && userConfig.AzureDevopsUser == "" && len(userConfig.VCSHosts) == 0 {
 		return vcsErr
 	}
+	if userConfig.GithubChecks && userConfig.GithubAppID == 0 {
+		return fmt.Errorf("--%s requires --%s to be set", GHChecksFlag, GHAppIDFlag)
+	}
+	if (userConfig.AzureDevopsWebhookUser == "") != (userConfig.AzureDevopsWebhookPassword == "") {
+		return fmt.Errorf("--%s and --%s must both be set", AzureDevopsWebhookUserFlag, AzureDevopsWebhookPasswordFlag)
+	}
 
 	if userConfig.RepoWhitelist == "" {
 		return fmt.Errorf("--%s must be set for security purposes", RepoWhitelistFlag)
@@ -449,6 +669,53 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return fmt.Errorf("--%s must have http:// or https://, got %q", BitbucketBaseURLFlag, userConfig.BitbucketBaseURL)
 	}
 
+	parsed, err = url.Parse(userConfig.GiteaBaseURL)
+	if err != nil {
+		return fmt.Errorf("error parsing --%s flag value %q: %s", GiteaBaseURLFlag, userConfig.GiteaBaseURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("--%s must have http:// or https://, got %q", GiteaBaseURLFlag, userConfig.GiteaBaseURL)
+	}
+
+	parsed, err = url.Parse(userConfig.TFDownloadURL)
+	if err != nil {
+		return fmt.Errorf("error parsing --%s flag value %q: %s", TFDownloadURLFlag, userConfig.TFDownloadURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("--%s must have http:// or https://, got %q", TFDownloadURLFlag, userConfig.TFDownloadURL)
+	}
+
+	if err := s.validateVCSHosts(userConfig.VCSHosts); err != nil {
+		return err
+	}
+
+	ttls := []struct {
+		flag  string
+		value string
+	}{
+		{VCSCacheModifiedFilesTTLFlag, userConfig.VCSCacheModifiedFilesTTL},
+		{VCSCachePullTTLFlag, userConfig.VCSCachePullTTL},
+		{VCSCacheTeamsTTLFlag, userConfig.VCSCacheTeamsTTL},
+		{VCSDeliveryDedupWindowFlag, userConfig.VCSDeliveryDedupWindow},
+		{VCSDeliveryRetentionFlag, userConfig.VCSDeliveryRetention},
+	}
+	durations := make(map[string]time.Duration)
+	for _, ttl := range ttls {
+		d, err := time.ParseDuration(ttl.value)
+		if err != nil {
+			return fmt.Errorf("error parsing --%s flag value %q: %s", ttl.flag, ttl.value, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("--%s cannot be negative, got %q", ttl.flag, ttl.value)
+		}
+		durations[ttl.flag] = d
+	}
+	// Deliveries must be stored for at least the dedup window to be
+	// recognized as duplicates.
+	if retention := durations[VCSDeliveryRetentionFlag]; retention != 0 && retention < durations[VCSDeliveryDedupWindowFlag] {
+		return fmt.Errorf("--%s cannot be less than --%s", VCSDeliveryRetentionFlag, VCSDeliveryDedupWindowFlag)
+	}
+
 	// Cannot accept custom repo config if we know repo configs are disabled
 	if (userConfig.RepoConfig != DefaultRepoConfig) && (!userConfig.AllowRepoConfig) {
 		return fmt.Errorf("custom --%s cannot be specified if --%s is false", RepoConfigFlag, AllowRepoConfigFlag)
@@ -457,6 +724,35 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 	return nil
 }
 
+// validateVCSHosts validates the additional VCS hosts set in the config file.
+func (s *ServerCmd) validateVCSHosts(hosts []server.VCSHostConfig) error {
+	hostnames := make(map[string]bool)
+	for i, host := range hosts {
+		supported := false
+		for _, t := range server.VCSHostConfigTypes {
+			supported = supported || host.Type == t
+		}
+		if !supported {
+			return fmt.Errorf("invalid vcs-hosts[%d] type %q: additional VCS hosts must be one of %s", i, host.Type, strings.Join(server.VCSHostConfigTypes, ", "))
+		}
+		parsed, err := url.Parse(host.URL)
+		if err != nil {
+			return fmt.Errorf("error parsing vcs-hosts[%d] url %q: %s", i, host.URL, err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+			return fmt.Errorf("vcs-hosts[%d] url must have http:// or https:// and a hostname, got %q", i, host.URL)
+		}
+		if host.User == "" || host.Token == "" {
+			return fmt.Errorf("vcs-hosts[%d] user and token must be set", i)
+		}
+		if hostnames[parsed.Hostname()] {
+			return fmt.Errorf("vcs-hosts[%d] hostname %s is used by another vcs host", i, parsed.Hostname())
+		}
+		hostnames[parsed.Hostname()] = true
+	}
+	return nil
+}
+
 // setAtlantisURL sets the externally accessible URL for atlantis.
 func (s *ServerCmd) setAtlantisURL(userConfig *server.UserConfig) error {
 	if userConfig.AtlantisURL == "" {
@@ -493,26 +789,42 @@ func (s *ServerCmd) setDataDir(userConfig *server.UserConfig) error {
 	return nil
 }
 
-// trimAtSymbolFromUsers trims @ from the front of the github and gitlab usernames
+// trimAtSymbolFromUsers trims @ from the front of the VCS usernames
 func (s *ServerCmd) trimAtSymbolFromUsers(userConfig *server.UserConfig) {
 	userConfig.GithubUser = strings.TrimPrefix(userConfig.GithubUser, "@")
 	userConfig.GitlabUser = strings.TrimPrefix(userConfig.GitlabUser, "@")
 	userConfig.BitbucketUser = strings.TrimPrefix(userConfig.BitbucketUser, "@")
+	userConfig.GiteaUser = strings.TrimPrefix(userConfig.GiteaUser, "@")
+	userConfig.AzureDevopsUser = strings.TrimPrefix(userConfig.AzureDevopsUser, "@")
+	for i := range userConfig.VCSHosts {
+		userConfig.VCSHosts[i].User = strings.TrimPrefix(userConfig.VCSHosts[i].User, "@")
+	}
 }
 
 func (s *ServerCmd) securityWarnings(userConfig *server.UserConfig) {
-	if userConfig.GithubUser != "" && userConfig.GithubWebhookSecret == "" && !s.SilenceOutput {
+	if (userConfig.GithubUser != "" || userConfig.GithubAppID != 0) && userConfig.GithubWebhookSecret == "" && !s.SilenceOutput {
 		fmt.Fprintf(os.Stderr, "%s[WARN] No GitHub webhook secret set. This could allow attackers to spoof requests from GitHub.%s\n", redTermStart, redTermEnd)
 	}
 	if userConfig.GitlabUser != "" && userConfig.GitlabWebhookSecret == "" && !s.SilenceOutput {
 		fmt.Fprintf(os.Stderr, "%s[WARN] No GitLab webhook secret set. This could allow attackers to spoof requests from GitLab.%s\n", redTermStart, redTermEnd)
 	}
+	if userConfig.GiteaUser != "" && userConfig.GiteaWebhookSecret == "" && !s.SilenceOutput {
+		fmt.Fprintf(os.Stderr, "%s[WARN] No Gitea webhook secret set. This could allow attackers to spoof requests from Gitea.%s\n", redTermStart, redTermEnd)
+	}
+	if userConfig.AzureDevopsUser != "" && userConfig.AzureDevopsWebhookUser == "" && !s.SilenceOutput {
+		fmt.Fprintf(os.Stderr, "%s[WARN] No Azure DevOps webhook user and password set. This could allow attackers to spoof requests from Azure DevOps.%s\n", redTermStart, redTermEnd)
+	}
 	if userConfig.BitbucketUser != "" && userConfig.BitbucketBaseURL != DefaultBitbucketBaseURL && userConfig.BitbucketWebhookSecret == "" && !s.SilenceOutput {
 		fmt.Fprintf(os.Stderr, "%s[WARN] No Bitbucket webhook secret set. This could allow attackers to spoof requests from Bitbucket.%s\n", redTermStart, redTermEnd)
 	}
 	if userConfig.BitbucketUser != "" && userConfig.BitbucketBaseURL == DefaultBitbucketBaseURL && !s.SilenceOutput {
 		fmt.Fprintf(os.Stderr, "%s[WARN] Bitbucket Cloud does not support webhook secrets. This could allow attackers to spoof requests from Bitbucket. Ensure you are whitelisting Bitbucket IPs.%s\n", redTermStart, redTermEnd)
 	}
+	for _, host := range userConfig.VCSHosts {
+		if host.WebhookSecret == "" && !s.SilenceOutput {
+			fmt.Fprintf(os.Stderr, "%s[WARN] No webhook secret set for %s. This could allow attackers to spoof requests from it.%s\n", redTermStart, host.URL, redTermEnd)
+		}
+	}
 }
 
 // withErrPrint prints out any errors to a terminal in red.
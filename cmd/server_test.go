@@ -127,6 +127,13 @@ func TestExecute_ValidateLogLevel(t *testing.T) {
 	Equals(t, "invalid log level: not one of debug, info, warn, error", err.Error())
 }
 
+func TestExecute_ValidateCommentMode(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		cmd.CommentModeFlag: "invalid",
+	})
+	ErrEquals(t, "invalid --comment-mode: not one of new, update, hide", c.Execute())
+}
+
 func TestExecute_ValidateSSLConfig(t *testing.T) {
 	expErr := "--ssl-key-file and --ssl-cert-file are both required for ssl"
 	cases := []struct {
@@ -176,7 +183,7 @@ func TestExecute_ValidateSSLConfig(t *testing.T) {
 }
 
 func TestExecute_ValidateVCSConfig(t *testing.T) {
-	expErr := "--gh-user/--gh-token or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token must be set"
+	expErr := "--gh-user/--gh-token or --gh-app-id/--gh-app-key-file or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --gitea-user/--gitea-token or --azuredevops-user/--azuredevops-token must be set"
 	cases := []struct {
 		description string
 		flags       map[string]interface{}
@@ -208,6 +215,48 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just github app id set",
+			map[string]interface{}{
+				cmd.GHAppIDFlag: 1,
+			},
+			true,
+		},
+		{
+			"just github app key file set",
+			map[string]interface{}{
+				cmd.GHAppKeyFileFlag: "key.pem",
+			},
+			true,
+		},
+		{
+			"just gitea token set",
+			map[string]interface{}{
+				cmd.GiteaTokenFlag: "token",
+			},
+			true,
+		},
+		{
+			"just gitea user set",
+			map[string]interface{}{
+				cmd.GiteaUserFlag: "user",
+			},
+			true,
+		},
+		{
+			"just azure devops token set",
+			map[string]interface{}{
+				cmd.AzureDevopsTokenFlag: "token",
+			},
+			true,
+		},
+		{
+			"just azure devops user set",
+			map[string]interface{}{
+				cmd.AzureDevopsUserFlag: "user",
+			},
+			true,
+		},
 		{
 			"just github user set",
 			map[string]interface{}{
@@ -277,15 +326,52 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"github app id and key file set and should be successful",
+			map[string]interface{}{
+				cmd.GHAppIDFlag:      1,
+				cmd.GHAppKeyFileFlag: "key.pem",
+			},
+			false,
+		},
+		{
+			"github app and github user set and should be successful",
+			map[string]interface{}{
+				cmd.GHAppIDFlag:      1,
+				cmd.GHAppKeyFileFlag: "key.pem",
+				cmd.GHUserFlag:       "atlantis[bot]",
+			},
+			false,
+		},
+		{
+			"gitea user and gitea token set and should be successful",
+			map[string]interface{}{
+				cmd.GiteaUserFlag:  "user",
+				cmd.GiteaTokenFlag: "token",
+			},
+			false,
+		},
+		{
+			"azure devops user and azure devops token set and should be successful",
+			map[string]interface{}{
+				cmd.AzureDevopsUserFlag:  "user",
+				cmd.AzureDevopsTokenFlag: "token",
+			},
+			false,
+		},
 		{
 			"all set should be successful",
 			map[string]interface{}{
-				cmd.GHUserFlag:         "user",
-				cmd.GHTokenFlag:        "token",
-				cmd.GitlabUserFlag:     "user",
-				cmd.GitlabTokenFlag:    "token",
-				cmd.BitbucketUserFlag:  "user",
-				cmd.BitbucketTokenFlag: "token",
+				cmd.GHUserFlag:           "user",
+				cmd.GHTokenFlag:          "token",
+				cmd.GitlabUserFlag:       "user",
+				cmd.GitlabTokenFlag:      "token",
+				cmd.BitbucketUserFlag:    "user",
+				cmd.BitbucketTokenFlag:   "token",
+				cmd.GiteaUserFlag:        "user",
+				cmd.GiteaTokenFlag:       "token",
+				cmd.AzureDevopsUserFlag:  "user",
+				cmd.AzureDevopsTokenFlag: "token",
 			},
 			false,
 		},
@@ -324,8 +410,11 @@ func TestExecute_Defaults(t *testing.T) {
 	hostname, err := os.Hostname()
 	Ok(t, err)
 	Equals(t, "http://"+hostname+":4141", passedConfig.AtlantisURL)
+	Equals(t, "", passedConfig.AdminToken)
 	Equals(t, false, passedConfig.AllowForkPRs)
 	Equals(t, false, passedConfig.AllowRepoConfig)
+	Equals(t, false, passedConfig.Automerge)
+	Equals(t, "new", passedConfig.CommentMode)
 
 	// Get our home dir since that's what gets defaulted to
 	dataDir, err := homedir.Expand("~/.atlantis")
@@ -344,8 +433,17 @@ func TestExecute_Defaults(t *testing.T) {
 	Equals(t, "bitbucket-token", passedConfig.BitbucketToken)
 	Equals(t, "bitbucket-user", passedConfig.BitbucketUser)
 	Equals(t, "", passedConfig.BitbucketWebhookSecret)
+	Equals(t, "https://gitea.com", passedConfig.GiteaBaseURL)
+	Equals(t, "dev.azure.com", passedConfig.AzureDevopsHostname)
 	Equals(t, "info", passedConfig.LogLevel)
 	Equals(t, 4141, passedConfig.Port)
+	Equals(t, "https://releases.hashicorp.com", passedConfig.TFDownloadURL)
+	Equals(t, "1h", passedConfig.VCSCacheModifiedFilesTTL)
+	Equals(t, "1m", passedConfig.VCSCachePullTTL)
+	Equals(t, "5m", passedConfig.VCSCacheTeamsTTL)
+	Equals(t, "10m", passedConfig.VCSDeliveryDedupWindow)
+	Equals(t, "0", passedConfig.VCSDeliveryRetention)
+	Equals(t, 3, passedConfig.VCSMaxRetries)
 	Equals(t, false, passedConfig.RequireApproval)
 	Equals(t, "", passedConfig.SSLCertFile)
 	Equals(t, "", passedConfig.SSLKeyFile)
@@ -421,59 +519,244 @@ func TestExecute_BitbucketUser(t *testing.T) {
 	Equals(t, "user", passedConfig.BitbucketUser)
 }
 
+func TestExecute_GithubApp(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHAppIDFlag:       123,
+		cmd.GHAppKeyFileFlag:  "key.pem",
+		cmd.RepoWhitelistFlag: "*",
+	})
+	err := c.Execute()
+	Ok(t, err)
+
+	Equals(t, 123, passedConfig.GithubAppID)
+	Equals(t, "key.pem", passedConfig.GithubAppKeyFile)
+}
+
+func TestExecute_GithubAppAndToken(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHAppIDFlag:       123,
+		cmd.GHAppKeyFileFlag:  "key.pem",
+		cmd.GHTokenFlag:       "token",
+		cmd.RepoWhitelistFlag: "*",
+	})
+	ErrEquals(t, "--gh-token and --gh-app-id cannot both be set", c.Execute())
+}
+
+func TestExecute_VCSMaxRetriesDisabled(t *testing.T) {
+	t.Log("Setting --vcs-max-retries to -1 should disable retries.")
+	c := setup(map[string]interface{}{
+		cmd.GHUserFlag:        "user",
+		cmd.GHTokenFlag:       "token",
+		cmd.RepoWhitelistFlag: "*",
+		cmd.VCSMaxRetriesFlag: -1,
+	})
+	err := c.Execute()
+	Ok(t, err)
+	Equals(t, 0, passedConfig.VCSMaxRetries)
+}
+
+func TestExecute_InvalidVCSCacheTTL(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHUserFlag:           "user",
+		cmd.GHTokenFlag:          "token",
+		cmd.RepoWhitelistFlag:    "*",
+		cmd.VCSCacheTeamsTTLFlag: "5",
+	})
+	ErrContains(t, "error parsing --vcs-cache-teams-ttl flag value \"5\"", c.Execute())
+}
+
+func TestExecute_NegativeVCSCacheTTL(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHUserFlag:          "user",
+		cmd.GHTokenFlag:         "token",
+		cmd.RepoWhitelistFlag:   "*",
+		cmd.VCSCachePullTTLFlag: "-1m",
+	})
+	ErrEquals(t, "--vcs-cache-pull-ttl cannot be negative, got \"-1m\"", c.Execute())
+}
+
+func TestExecute_VCSDeliveryRetentionLessThanDedupWindow(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHUserFlag:                 "user",
+		cmd.GHTokenFlag:                "token",
+		cmd.RepoWhitelistFlag:          "*",
+		cmd.VCSDeliveryDedupWindowFlag: "1h",
+		cmd.VCSDeliveryRetentionFlag:   "30m",
+	})
+	ErrEquals(t, "--vcs-delivery-retention cannot be less than --vcs-delivery-dedup-window", c.Execute())
+}
+
+func TestExecute_GithubChecksWithoutApp(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHUserFlag:        "user",
+		cmd.GHTokenFlag:       "token",
+		cmd.GHChecksFlag:      true,
+		cmd.RepoWhitelistFlag: "*",
+	})
+	ErrEquals(t, "--gh-checks requires --gh-app-id to be set", c.Execute())
+}
+
+func TestExecute_GithubChecks(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHAppIDFlag:       123,
+		cmd.GHAppKeyFileFlag:  "key.pem",
+		cmd.GHChecksFlag:      true,
+		cmd.RepoWhitelistFlag: "*",
+	})
+	Ok(t, c.Execute())
+	Equals(t, true, passedConfig.GithubChecks)
+}
+
+func TestExecute_GiteaUser(t *testing.T) {
+	t.Log("Should remove the @ from the gitea username if it's passed.")
+	c := setup(map[string]interface{}{
+		cmd.GiteaUserFlag:     "@user",
+		cmd.GiteaTokenFlag:    "token",
+		cmd.RepoWhitelistFlag: "*",
+	})
+	err := c.Execute()
+	Ok(t, err)
+
+	Equals(t, "user", passedConfig.GiteaUser)
+}
+
+func TestExecute_GiteaBaseURLScheme(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GiteaUserFlag:     "user",
+		cmd.GiteaTokenFlag:    "token",
+		cmd.RepoWhitelistFlag: "*",
+		cmd.GiteaBaseURLFlag:  "gitea.corp.com",
+	})
+	ErrEquals(t, "--gitea-base-url must have http:// or https://, got \"gitea.corp.com\"", c.Execute())
+}
+
+func TestExecute_TFDownloadURLScheme(t *testing.T) {
+	c := setup(map[string]interface{}{
+		cmd.GHUserFlag:        "user",
+		cmd.GHTokenFlag:       "token",
+		cmd.RepoWhitelistFlag: "*",
+		cmd.TFDownloadURLFlag: "terraform-mirror.corp",
+	})
+	ErrEquals(t, "--tf-download-url must have http:// or https://, got \"terraform-mirror.corp\"", c.Execute())
+}
+
+func TestExecute_AzureDevopsUser(t *testing.T) {
+	t.Log("Should remove the @ from the azure devops username if it's passed.")
+	c := setup(map[string]interface{}{
+		cmd.AzureDevopsUserFlag:  "@user",
+		cmd.AzureDevopsTokenFlag: "token",
+		cmd.RepoWhitelistFlag:    "*",
+	})
+	err := c.Execute()
+	Ok(t, err)
+
+	Equals(t, "user", passedConfig.AzureDevopsUser)
+}
+
+func TestExecute_AzureDevopsWebhookAuth(t *testing.T) {
+	t.Log("Should error if only one of the azure devops webhook user and password is set.")
+	c := setup(map[string]interface{}{
+		cmd.AzureDevopsUserFlag:        "user",
+		cmd.AzureDevopsTokenFlag:       "token",
+		cmd.AzureDevopsWebhookUserFlag: "webhook-user",
+		cmd.RepoWhitelistFlag:          "*",
+	})
+	ErrEquals(t, "--azuredevops-webhook-user and --azuredevops-webhook-password must both be set", c.Execute())
+}
+
 func TestExecute_Flags(t *testing.T) {
 	t.Log("Should use all flags that are set.")
 	c := setup(map[string]interface{}{
-		cmd.AtlantisURLFlag:            "url",
-		cmd.AllowForkPRsFlag:           true,
-		cmd.AllowRepoConfigFlag:        true,
-		cmd.BitbucketBaseURLFlag:       "https://bitbucket-base-url.com",
-		cmd.BitbucketTokenFlag:         "bitbucket-token",
-		cmd.BitbucketUserFlag:          "bitbucket-user",
-		cmd.BitbucketWebhookSecretFlag: "bitbucket-secret",
-		cmd.DataDirFlag:                "/path",
-		cmd.GHHostnameFlag:             "ghhostname",
-		cmd.GHTokenFlag:                "token",
-		cmd.GHUserFlag:                 "user",
-		cmd.GHWebhookSecretFlag:        "secret",
-		cmd.GitlabHostnameFlag:         "gitlab-hostname",
-		cmd.GitlabTokenFlag:            "gitlab-token",
-		cmd.GitlabUserFlag:             "gitlab-user",
-		cmd.GitlabWebhookSecretFlag:    "gitlab-secret",
-		cmd.LogLevelFlag:               "debug",
-		cmd.PortFlag:                   8181,
-		cmd.RepoConfigFlag:             "atlantis.yaml",
-		cmd.RepoWhitelistFlag:          "github.com/runatlantis/atlantis",
-		cmd.RequireApprovalFlag:        true,
-		cmd.SSLCertFileFlag:            "cert-file",
-		cmd.SSLKeyFileFlag:             "key-file",
+		cmd.AdminTokenFlag:                 "admin-token",
+		cmd.AtlantisURLFlag:                "url",
+		cmd.AllowForkPRsFlag:               true,
+		cmd.AllowRepoConfigFlag:            true,
+		cmd.AutomergeFlag:                  true,
+		cmd.AzureDevopsHostnameFlag:        "azuredevops-hostname",
+		cmd.AzureDevopsTokenFlag:           "azuredevops-token",
+		cmd.AzureDevopsUserFlag:            "azuredevops-user",
+		cmd.AzureDevopsWebhookPasswordFlag: "azuredevops-webhook-password",
+		cmd.AzureDevopsWebhookUserFlag:     "azuredevops-webhook-user",
+		cmd.BitbucketBaseURLFlag:           "https://bitbucket-base-url.com",
+		cmd.BitbucketTokenFlag:             "bitbucket-token",
+		cmd.BitbucketUserFlag:              "bitbucket-user",
+		cmd.BitbucketWebhookSecretFlag:     "bitbucket-secret",
+		cmd.CommentModeFlag:                "hide",
+		cmd.DataDirFlag:                    "/path",
+		cmd.GHHostnameFlag:                 "ghhostname",
+		cmd.GHTokenFlag:                    "token",
+		cmd.GHUserFlag:                     "user",
+		cmd.GHWebhookSecretFlag:            "secret",
+		cmd.GiteaBaseURLFlag:               "https://gitea-base-url.com",
+		cmd.GiteaTokenFlag:                 "gitea-token",
+		cmd.GiteaUserFlag:                  "gitea-user",
+		cmd.GiteaWebhookSecretFlag:         "gitea-secret",
+		cmd.GitlabHostnameFlag:             "gitlab-hostname",
+		cmd.GitlabTokenFlag:                "gitlab-token",
+		cmd.GitlabUserFlag:                 "gitlab-user",
+		cmd.GitlabWebhookSecretFlag:        "gitlab-secret",
+		cmd.LogLevelFlag:                   "debug",
+		cmd.PolicyApproversFlag:            "security,ops",
+		cmd.PortFlag:                       8181,
+		cmd.RepoConfigFlag:                 "atlantis.yaml",
+		cmd.RepoWhitelistFlag:              "github.com/runatlantis/atlantis",
+		cmd.RequireApprovalFlag:            true,
+		cmd.SSLCertFileFlag:                "cert-file",
+		cmd.SSLKeyFileFlag:                 "key-file",
+		cmd.TFDownloadURLFlag:              "https://terraform-mirror.corp",
+		cmd.VCSCacheModifiedFilesTTLFlag:   "2h",
+		cmd.VCSCachePullTTLFlag:            "0",
+		cmd.VCSCacheTeamsTTLFlag:           "10m",
+		cmd.VCSDeliveryDedupWindowFlag:     "1m",
+		cmd.VCSDeliveryRetentionFlag:       "0",
+		cmd.VCSMaxRetriesFlag:              5,
 	})
 	err := c.Execute()
 	Ok(t, err)
 
+	Equals(t, "admin-token", passedConfig.AdminToken)
 	Equals(t, "url", passedConfig.AtlantisURL)
 	Equals(t, true, passedConfig.AllowForkPRs)
 	Equals(t, true, passedConfig.AllowRepoConfig)
+	Equals(t, true, passedConfig.Automerge)
+	Equals(t, "azuredevops-hostname", passedConfig.AzureDevopsHostname)
+	Equals(t, "azuredevops-token", passedConfig.AzureDevopsToken)
+	Equals(t, "azuredevops-user", passedConfig.AzureDevopsUser)
+	Equals(t, "azuredevops-webhook-password", passedConfig.AzureDevopsWebhookPassword)
+	Equals(t, "azuredevops-webhook-user", passedConfig.AzureDevopsWebhookUser)
 	Equals(t, "https://bitbucket-base-url.com", passedConfig.BitbucketBaseURL)
 	Equals(t, "bitbucket-token", passedConfig.BitbucketToken)
 	Equals(t, "bitbucket-user", passedConfig.BitbucketUser)
 	Equals(t, "bitbucket-secret", passedConfig.BitbucketWebhookSecret)
+	Equals(t, "hide", passedConfig.CommentMode)
 	Equals(t, "/path", passedConfig.DataDir)
 	Equals(t, "ghhostname", passedConfig.GithubHostname)
 	Equals(t, "token", passedConfig.GithubToken)
 	Equals(t, "user", passedConfig.GithubUser)
 	Equals(t, "secret", passedConfig.GithubWebhookSecret)
+	Equals(t, "https://gitea-base-url.com", passedConfig.GiteaBaseURL)
+	Equals(t, "gitea-token", passedConfig.GiteaToken)
+	Equals(t, "gitea-user", passedConfig.GiteaUser)
+	Equals(t, "gitea-secret", passedConfig.GiteaWebhookSecret)
 	Equals(t, "gitlab-hostname", passedConfig.GitlabHostname)
 	Equals(t, "gitlab-token", passedConfig.GitlabToken)
 	Equals(t, "gitlab-user", passedConfig.GitlabUser)
 	Equals(t, "gitlab-secret", passedConfig.GitlabWebhookSecret)
 	Equals(t, "debug", passedConfig.LogLevel)
+	Equals(t, "security,ops", passedConfig.PolicyApprovers)
 	Equals(t, 8181, passedConfig.Port)
 	Equals(t, "atlantis.yaml", passedConfig.RepoConfig)
 	Equals(t, "github.com/runatlantis/atlantis", passedConfig.RepoWhitelist)
 	Equals(t, true, passedConfig.RequireApproval)
 	Equals(t, "cert-file", passedConfig.SSLCertFile)
 	Equals(t, "key-file", passedConfig.SSLKeyFile)
+	Equals(t, "https://terraform-mirror.corp", passedConfig.TFDownloadURL)
+	Equals(t, "2h", passedConfig.VCSCacheModifiedFilesTTL)
+	Equals(t, "0", passedConfig.VCSCachePullTTL)
+	Equals(t, "10m", passedConfig.VCSCacheTeamsTTL)
+	Equals(t, "1m", passedConfig.VCSDeliveryDedupWindow)
+	Equals(t, "0", passedConfig.VCSDeliveryRetention)
+	Equals(t, 5, passedConfig.VCSMaxRetries)
 }
 
 func TestExecute_ConfigFile(t *testing.T) {
@@ -535,6 +818,171 @@ ssl-key-file: key-file
 	Equals(t, "key-file", passedConfig.SSLKeyFile)
 }
 
+func TestExecute_VCSHostsConfigFile(t *testing.T) {
+	t.Log("Should parse additional VCS hosts from the config file.")
+	tmpFile := tempFile(t, `---
+repo-whitelist: "*"
+vcs-hosts:
+- type: github
+  url: https://github.example.com
+  user: "@ghe-user"
+  token: ghe-token
+  webhook-secret: ghe-secret
+- type: gitlab
+  url: https://gitlab.example.com
+  user: gitlab-user
+  token: gitlab-token
+`)
+	defer os.Remove(tmpFile) // nolint: errcheck
+	c := setup(map[string]interface{}{
+		cmd.ConfigFlag: tmpFile,
+	})
+
+	Ok(t, c.Execute())
+	Equals(t, []server.VCSHostConfig{
+		{
+			Type:          "github",
+			URL:           "https://github.example.com",
+			User:          "ghe-user",
+			Token:         "ghe-token",
+			WebhookSecret: "ghe-secret",
+		},
+		{
+			Type:  "gitlab",
+			URL:   "https://gitlab.example.com",
+			User:  "gitlab-user",
+			Token: "gitlab-token",
+		},
+	}, passedConfig.VCSHosts)
+}
+
+func TestExecute_SMTPConfigFile(t *testing.T) {
+	t.Log("Should parse the SMTP config and email webhooks from the config file.")
+	tmpFile := tempFile(t, `---
+repo-whitelist: "*"
+smtp:
+  host: smtp.example.com
+  port: 2525
+  username: user
+  password: pass
+  from: atlantis@example.com
+  starttls: opportunistic
+webhooks:
+- event: apply
+  workspace-regex: .*
+  kind: email
+  to: [alice@example.com, bob@example.com]
+`)
+	defer os.Remove(tmpFile) // nolint: errcheck
+	c := setup(map[string]interface{}{
+		cmd.ConfigFlag:  tmpFile,
+		cmd.GHUserFlag:  "user",
+		cmd.GHTokenFlag: "token",
+	})
+
+	Ok(t, c.Execute())
+	Equals(t, server.SMTPConfig{
+		Host:     "smtp.example.com",
+		Port:     2525,
+		Username: "user",
+		Password: "pass",
+		From:     "atlantis@example.com",
+		StartTLS: "opportunistic",
+	}, passedConfig.SMTP)
+	Equals(t, []string{"alice@example.com", "bob@example.com"}, passedConfig.Webhooks[0].To)
+}
+
+func TestExecute_PolicySetsConfigFile(t *testing.T) {
+	t.Log("Should parse policy sets from the config file.")
+	tmpFile := tempFile(t, `---
+repo-whitelist: "*"
+policy-approvers: security
+policy-sets:
+- name: s3
+  engine: rego
+  path: /policies/s3
+- name: instance-types
+  engine: exec
+  command: ./check-instance-types.sh
+`)
+	defer os.Remove(tmpFile) // nolint: errcheck
+	c := setup(map[string]interface{}{
+		cmd.ConfigFlag:  tmpFile,
+		cmd.GHUserFlag:  "user",
+		cmd.GHTokenFlag: "token",
+	})
+
+	Ok(t, c.Execute())
+	Equals(t, "security", passedConfig.PolicyApprovers)
+	Equals(t, []server.PolicySetConfig{
+		{Name: "s3", Engine: "rego", Path: "/policies/s3"},
+		{Name: "instance-types", Engine: "exec", Command: "./check-instance-types.sh"},
+	}, passedConfig.PolicySets)
+}
+
+func TestExecute_ValidateVCSHosts(t *testing.T) {
+	cases := []struct {
+		description string
+		hosts       []server.VCSHostConfig
+		expErr      string
+	}{
+		{
+			"valid",
+			[]server.VCSHostConfig{
+				{Type: "github", URL: "https://github.example.com", User: "user", Token: "token"},
+				{Type: "bitbucket-server", URL: "http://bitbucket.example.com/context", User: "user", Token: "token"},
+			},
+			"",
+		},
+		{
+			"invalid type",
+			[]server.VCSHostConfig{{Type: "bitbucket", URL: "https://bitbucket.example.com", User: "user", Token: "token"}},
+			"invalid vcs-hosts[0] type \"bitbucket\": additional VCS hosts must be one of github, gitlab, bitbucket-server, gitea",
+		},
+		{
+			"azure devops",
+			[]server.VCSHostConfig{{Type: "azure-devops", URL: "https://dev.azure.com", User: "user", Token: "token"}},
+			"invalid vcs-hosts[0] type \"azure-devops\": additional VCS hosts must be one of github, gitlab, bitbucket-server, gitea",
+		},
+		{
+			"no scheme",
+			[]server.VCSHostConfig{{Type: "gitea", URL: "gitea.example.com", User: "user", Token: "token"}},
+			"vcs-hosts[0] url must have http:// or https:// and a hostname, got \"gitea.example.com\"",
+		},
+		{
+			"no token",
+			[]server.VCSHostConfig{{Type: "gitlab", URL: "https://gitlab.example.com", User: "user"}},
+			"vcs-hosts[0] user and token must be set",
+		},
+		{
+			"duplicate hostname",
+			[]server.VCSHostConfig{
+				{Type: "github", URL: "https://example.com", User: "user", Token: "token"},
+				{Type: "gitlab", URL: "https://example.com:8080", User: "user", Token: "token"},
+			},
+			"vcs-hosts[1] hostname example.com is used by another vcs host",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			vipr := viper.New()
+			vipr.Set(cmd.RepoWhitelistFlag, "*")
+			vipr.Set("vcs-hosts", c.hosts)
+			serverCmd := &cmd.ServerCmd{
+				ServerCreator: &ServerCreatorMock{},
+				Viper:         vipr,
+				SilenceOutput: true,
+			}
+			err := serverCmd.Init().Execute()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
+
 func TestExecute_EnvironmentOverride(t *testing.T) {
 	t.Log("Environment variables should override config file flags.")
 	tmpFile := tempFile(t, `---
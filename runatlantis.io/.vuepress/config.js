@@ -44,6 +44,7 @@ module.exports = {
                 'apply-requirements',
                 'locking',
                 'autoplanning',
+                'automerging',
                 ['atlantis-yaml-reference', 'atlantis.yaml Reference'],
                 'upgrading-atlantis-yaml-to-version-2',
                 'security',
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/gorilla/mux"
+)
+
+// AdminController handles the admin API used to list and replay webhook
+// deliveries. Requests must send the admin token as a bearer token.
+type AdminController struct {
+	// Token is the admin token. If empty, the admin API is disabled.
+	Token            string
+	Deliveries       DeliveryStore
+	EventsController *EventsController
+	Logger           *logging.SimpleLogger
+}
+
+// DeliverySummary is how a delivery is listed by the admin API. It doesn't
+// include the delivery's headers or body.
+type DeliverySummary struct {
+	ID         string    `json:"id"`
+	HostType   string    `json:"host_type"`
+	Hostname   string    `json:"hostname,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// ListDeliveries is the GET /admin/deliveries route. It responds with the
+// stored deliveries as JSON, newest first.
+func (a *AdminController) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !a.authorized(w, r) {
+		return
+	}
+	deliveries, err := a.Deliveries.List()
+	if err != nil {
+		a.respond(w, logging.Error, http.StatusInternalServerError, "Failed listing deliveries: %s", err)
+		return
+	}
+	summaries := []DeliverySummary{}
+	for _, d := range deliveries {
+		summaries = append(summaries, DeliverySummary{
+			ID:         d.ID,
+			HostType:   d.HostType,
+			Hostname:   d.Hostname,
+			ReceivedAt: d.ReceivedAt,
+		})
+	}
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		a.respond(w, logging.Error, http.StatusInternalServerError, "Error creating deliveries json response: %s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data) // nolint: errcheck
+}
+
+// ReplayDelivery is the POST /admin/deliveries/{id}/replay route. It handles
+// the stored delivery again as if it had just been received and responds
+// with the events controller's response.
+func (a *AdminController) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	if !a.authorized(w, r) {
+		return
+	}
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		a.respond(w, logging.Warn, http.StatusBadRequest, "No delivery id in request")
+		return
+	}
+	d, found, err := a.Deliveries.Get(id)
+	if err != nil {
+		a.respond(w, logging.Error, http.StatusInternalServerError, "Failed getting delivery: %s", err)
+		return
+	}
+	if !found {
+		a.respond(w, logging.Info, http.StatusNotFound, "No delivery found at id %q", id)
+		return
+	}
+
+	// The delivery was validated when it was received and its credentials
+	// weren't stored, so the replay isn't validated again. It's marked as a
+	// replay so it isn't ignored as a duplicate.
+	replay, err := http.NewRequest("POST", "/events", ioutil.NopCloser(bytes.NewReader(d.Body)))
+	if err != nil {
+		a.respond(w, logging.Error, http.StatusInternalServerError, "Failed creating request: %s", err)
+		return
+	}
+	replay.Header = d.Header
+	replay = replay.WithContext(context.WithValue(replay.Context(), replayKey{}, true))
+	a.Logger.Info("replaying delivery %q", id)
+	resp := httptest.NewRecorder()
+	events := a.EventsController.withoutValidation()
+	if d.Hostname != "" {
+		replay = mux.SetURLVars(replay, map[string]string{"hostname": d.Hostname})
+		events.PostHost(resp, replay)
+	} else {
+		events.Post(resp, replay)
+	}
+	w.WriteHeader(resp.Code)
+	w.Write(resp.Body.Bytes()) // nolint: errcheck
+}
+
+// authorized returns true if r has the admin token and deliveries are
+// recorded. Otherwise it responds and returns false.
+func (a *AdminController) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if a.Token == "" {
+		a.respond(w, logging.Debug, http.StatusNotFound, "Admin API is disabled since no admin token is configured")
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) != 1 {
+		a.respond(w, logging.Warn, http.StatusUnauthorized, "Invalid admin token")
+		return false
+	}
+	if a.Deliveries == nil {
+		a.respond(w, logging.Debug, http.StatusNotFound, "Deliveries aren't recorded since --vcs-delivery-retention is 0")
+		return false
+	}
+	return true
+}
+
+// respond is a helper function to respond and log the response. lvl is the log
+// level to log at, code is the HTTP response code.
+func (a *AdminController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
+	response := fmt.Sprintf(format, args...)
+	a.Logger.Log(lvl, "%s", response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
@@ -0,0 +1,149 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudposse/atlantis/server"
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+	"github.com/gorilla/mux"
+)
+
+func TestListDeliveries_NoToken(t *testing.T) {
+	t.Log("If no admin token is configured the admin API is disabled")
+	ac := server.AdminController{
+		Logger: logging.NewNoopLogger(),
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	ac.ListDeliveries(w, req)
+	responseContains(t, w, http.StatusNotFound, "Admin API is disabled since no admin token is configured")
+}
+
+func TestListDeliveries_InvalidToken(t *testing.T) {
+	t.Log("If the request doesn't have the admin token a 401 is returned")
+	ac, cleanup := setupAdmin(t)
+	defer cleanup()
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set("Authorization", "Bearer wrong")
+	w := httptest.NewRecorder()
+	ac.ListDeliveries(w, req)
+	responseContains(t, w, http.StatusUnauthorized, "Invalid admin token")
+}
+
+func TestListDeliveries_NotRecorded(t *testing.T) {
+	t.Log("If deliveries aren't recorded a 404 is returned")
+	ac, cleanup := setupAdmin(t)
+	defer cleanup()
+	ac.Deliveries = nil
+	w := httptest.NewRecorder()
+	ac.ListDeliveries(w, adminRequest())
+	responseContains(t, w, http.StatusNotFound, "Deliveries aren't recorded")
+}
+
+func TestListDeliveries(t *testing.T) {
+	t.Log("The stored deliveries are listed without their headers or bodies")
+	ac, cleanup := setupAdmin(t)
+	defer cleanup()
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err := ac.Deliveries.Record(server.Delivery{ID: "1", HostType: "Gitea", ReceivedAt: now, Body: []byte("body")})
+	Ok(t, err)
+	_, err = ac.Deliveries.Record(server.Delivery{ID: "2", HostType: "Gitea", Hostname: "gitea.example.com", ReceivedAt: now.Add(time.Second)})
+	Ok(t, err)
+
+	w := httptest.NewRecorder()
+	ac.ListDeliveries(w, adminRequest())
+	Equals(t, http.StatusOK, w.Code)
+	Assert(t, !bytes.Contains(w.Body.Bytes(), []byte("body")), "exp body not to be listed, got %s", w.Body.String())
+	var summaries []server.DeliverySummary
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &summaries))
+	Equals(t, []server.DeliverySummary{
+		{ID: "2", HostType: "Gitea", Hostname: "gitea.example.com", ReceivedAt: now.Add(time.Second)},
+		{ID: "1", HostType: "Gitea", ReceivedAt: now},
+	}, summaries)
+}
+
+func TestReplayDelivery_NotFound(t *testing.T) {
+	t.Log("If there's no delivery at the id a 404 is returned")
+	ac, cleanup := setupAdmin(t)
+	defer cleanup()
+	req := mux.SetURLVars(adminRequest(), map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	ac.ReplayDelivery(w, req)
+	responseContains(t, w, http.StatusNotFound, "No delivery found at id \"1\"")
+}
+
+func TestReplayDelivery(t *testing.T) {
+	t.Log("A replayed delivery is handled again even though it's a duplicate and its signature wasn't stored")
+	ac, cleanup := setupAdmin(t)
+	defer cleanup()
+	ac.EventsController.GiteaWebhookSecret = []byte("secret")
+	_, err := ac.Deliveries.Record(server.Delivery{
+		ID:         "1",
+		HostType:   "Gitea",
+		ReceivedAt: time.Now(),
+		Header:     http.Header{"X-Gitea-Event": []string{"push"}, "X-Gitea-Delivery": []string{"1"}},
+		Body:       []byte(`{}`),
+	})
+	Ok(t, err)
+
+	req := mux.SetURLVars(adminRequest(), map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	ac.ReplayDelivery(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring unsupported event type push X-Gitea-Delivery=1")
+}
+
+func TestReplayDelivery_AdditionalHost(t *testing.T) {
+	t.Log("A delivery sent to an additional VCS host's endpoint is replayed to it")
+	ac, cleanup := setupAdmin(t)
+	defer cleanup()
+	ac.EventsController.SupportedVCSHosts = nil
+	ac.EventsController.VCSHosts = map[string]server.VCSHostWebhooks{
+		"gitea.example.com": {Type: models.Gitea, WebhookSecret: []byte("secret")},
+	}
+	_, err := ac.Deliveries.Record(server.Delivery{
+		ID:         "1",
+		HostType:   "Gitea",
+		Hostname:   "gitea.example.com",
+		ReceivedAt: time.Now(),
+		Header:     http.Header{"X-Gitea-Event": []string{"push"}, "X-Gitea-Delivery": []string{"1"}},
+		Body:       []byte(`{}`),
+	})
+	Ok(t, err)
+
+	req := mux.SetURLVars(adminRequest(), map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	ac.ReplayDelivery(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring unsupported event type push X-Gitea-Delivery=1")
+}
+
+// setupAdmin returns an admin controller whose deliveries are stored in a
+// temp dir and whose events controller supports Gitea.
+func setupAdmin(t *testing.T) (server.AdminController, func()) {
+	tmp, cleanup := TempDir(t)
+	deliveries := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Hour, Retention: 2 * time.Hour}
+	return server.AdminController{
+		Token:      "token",
+		Deliveries: deliveries,
+		EventsController: &server.EventsController{
+			Logger:            logging.NewNoopLogger(),
+			SupportedVCSHosts: []models.VCSHostType{models.Gitea},
+			Deliveries:        deliveries,
+		},
+		Logger: logging.NewNoopLogger(),
+	}, cleanup
+}
+
+// adminRequest returns a request with the admin token set up by setupAdmin.
+func adminRequest() *http.Request {
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set("Authorization", "Bearer token")
+	return req
+}
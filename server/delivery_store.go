@@ -0,0 +1,210 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// deliveriesDir is the directory in the data dir that we store deliveries in.
+const deliveriesDir = "deliveries"
+
+// Delivery is a webhook request received from a VCS host.
+type Delivery struct {
+	// ID is the delivery id the VCS host sent, ex. the X-Github-Delivery
+	// header.
+	ID string `json:"id"`
+	// HostType is the type of VCS host that sent the webhook, ex. GitHub.
+	HostType string `json:"host_type"`
+	// Hostname is the additional VCS host whose endpoint the webhook was
+	// sent to. It's empty for webhooks sent to /events.
+	Hostname   string    `json:"hostname,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+	// Header and Body are the request's headers and raw body. They're used
+	// to replay the delivery.
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// DeliveryStore records the webhooks we receive so that duplicate deliveries
+// can be ignored and deliveries can be replayed.
+type DeliveryStore interface {
+	// Record stores d. If a delivery with the same id from the same host was
+	// recorded within the dedup window, it returns false and doesn't store d.
+	Record(d Delivery) (bool, error)
+	// Forget deletes the recorded delivery with d's id from d's host so that
+	// it's no longer a duplicate, ex. because handling it failed and the host
+	// will retry it.
+	Forget(d Delivery) error
+	// List returns the stored deliveries, newest first.
+	List() ([]Delivery, error)
+	// Get returns the newest delivery with id. found is false if there isn't
+	// one.
+	Get(id string) (d Delivery, found bool, err error)
+}
+
+// FileDeliveryStore implements DeliveryStore by storing each delivery as a
+// JSON file under DataDir.
+type FileDeliveryStore struct {
+	DataDir string
+	// DedupWindow is how long after a delivery is recorded that deliveries
+	// with the same id are ignored.
+	DedupWindow time.Duration
+	// Retention is how long deliveries are stored for. Older deliveries are
+	// deleted when new ones are recorded.
+	Retention time.Duration
+	// PruneInterval is how often older deliveries are looked for. If 0, they're
+	// looked for every time a delivery is recorded.
+	PruneInterval time.Duration
+	mutex         sync.Mutex
+	lastPruned    time.Time
+}
+
+// Record stores d unless it's a duplicate.
+func (f *FileDeliveryStore) Record(d Delivery) (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.prune(); err != nil {
+		return false, err
+	}
+
+	prev, found, err := f.read(f.path(d))
+	if err != nil {
+		return false, err
+	}
+	if found && d.ReceivedAt.Sub(prev.ReceivedAt) < f.DedupWindow {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(f.DataDir, deliveriesDir), 0700); err != nil {
+		return false, errors.Wrap(err, "creating deliveries dir")
+	}
+	contents, err := json.Marshal(d)
+	if err != nil {
+		return false, errors.Wrap(err, "json encoding")
+	}
+	if err := ioutil.WriteFile(f.path(d), contents, 0600); err != nil {
+		return false, errors.Wrap(err, "writing delivery")
+	}
+	return true, nil
+}
+
+// Forget deletes the recorded delivery with d's id from d's host.
+func (f *FileDeliveryStore) Forget(d Delivery) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := os.Remove(f.path(d)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "deleting delivery")
+	}
+	return nil
+}
+
+// List returns the stored deliveries.
+func (f *FileDeliveryStore) List() ([]Delivery, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.list()
+}
+
+// Get returns the newest delivery with id. Ids are only unique per host so
+// deliveries from different hosts can have the same id, but hosts use
+// UUIDs so in practice they don't.
+func (f *FileDeliveryStore) Get(id string) (Delivery, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	deliveries, err := f.list()
+	if err != nil {
+		return Delivery{}, false, err
+	}
+	for _, d := range deliveries {
+		if d.ID == id {
+			return d, true, nil
+		}
+	}
+	return Delivery{}, false, nil
+}
+
+// list returns the stored deliveries, newest first.
+func (f *FileDeliveryStore) list() ([]Delivery, error) {
+	files, err := ioutil.ReadDir(filepath.Join(f.DataDir, deliveriesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "listing deliveries")
+	}
+	var deliveries []Delivery
+	for _, file := range files {
+		d, found, err := f.read(filepath.Join(f.DataDir, deliveriesDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ReceivedAt.After(deliveries[j].ReceivedAt)
+	})
+	return deliveries, nil
+}
+
+// prune deletes the deliveries that are older than Retention unless it
+// already did within PruneInterval.
+func (f *FileDeliveryStore) prune() error {
+	if time.Since(f.lastPruned) < f.PruneInterval {
+		return nil
+	}
+	f.lastPruned = time.Now()
+	dir := filepath.Join(f.DataDir, deliveriesDir)
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "listing deliveries")
+	}
+	for _, file := range files {
+		if time.Since(file.ModTime()) > f.Retention {
+			if err := os.Remove(filepath.Join(dir, file.Name())); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "deleting delivery")
+			}
+		}
+	}
+	return nil
+}
+
+// read returns the delivery stored at path.
+func (f *FileDeliveryStore) read(path string) (Delivery, bool, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Delivery{}, false, nil
+	}
+	if err != nil {
+		return Delivery{}, false, errors.Wrap(err, "reading delivery")
+	}
+	var d Delivery
+	if err := json.Unmarshal(contents, &d); err != nil {
+		return Delivery{}, false, errors.Wrap(err, "parsing delivery")
+	}
+	return d, true, nil
+}
+
+// path returns the path of the delivery with d's id from d's host. Ids are
+// only unique per host so the host is part of the key. It's named after a
+// hash of the key since ids come from webhook headers and can't be trusted.
+func (f *FileDeliveryStore) path(d Delivery) string {
+	// The fields are JSON encoded so that they can't run into each other.
+	key, _ := json.Marshal([]string{d.HostType, d.Hostname, d.ID})
+	sum := sha256.Sum256(key)
+	return filepath.Join(f.DataDir, deliveriesDir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,183 @@
+package server_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudposse/atlantis/server"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestFileDeliveryStore_RecordGet(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Minute, Retention: time.Hour}
+
+	// Getting before anything is recorded should return nothing.
+	_, found, err := store.Get("1")
+	Ok(t, err)
+	Equals(t, false, found)
+
+	d := server.Delivery{
+		ID:         "1",
+		HostType:   "Github",
+		ReceivedAt: time.Now().UTC().Truncate(time.Second),
+		Header:     http.Header{"X-Github-Delivery": []string{"1"}},
+		Body:       []byte(`{"action": "opened"}`),
+	}
+	recorded, err := store.Record(d)
+	Ok(t, err)
+	Equals(t, true, recorded)
+
+	actual, found, err := store.Get("1")
+	Ok(t, err)
+	Equals(t, true, found)
+	Equals(t, d, actual)
+}
+
+func TestFileDeliveryStore_RecordDuplicate(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Minute, Retention: time.Hour}
+	now := time.Now()
+
+	recorded, err := store.Record(server.Delivery{ID: "1", ReceivedAt: now})
+	Ok(t, err)
+	Equals(t, true, recorded)
+
+	// The same id within the dedup window is a duplicate.
+	recorded, err = store.Record(server.Delivery{ID: "1", ReceivedAt: now.Add(30 * time.Second)})
+	Ok(t, err)
+	Equals(t, false, recorded)
+
+	// Other ids aren't.
+	recorded, err = store.Record(server.Delivery{ID: "2", ReceivedAt: now.Add(30 * time.Second)})
+	Ok(t, err)
+	Equals(t, true, recorded)
+
+	// Once the dedup window has passed it's recorded again.
+	recorded, err = store.Record(server.Delivery{ID: "1", ReceivedAt: now.Add(2 * time.Minute)})
+	Ok(t, err)
+	Equals(t, true, recorded)
+}
+
+func TestFileDeliveryStore_RecordOtherHost(t *testing.T) {
+	t.Log("deliveries with the same id from different hosts aren't duplicates")
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Minute, Retention: time.Hour}
+	now := time.Now()
+
+	recorded, err := store.Record(server.Delivery{ID: "1", HostType: "Github", ReceivedAt: now})
+	Ok(t, err)
+	Equals(t, true, recorded)
+	recorded, err = store.Record(server.Delivery{ID: "1", HostType: "Gitlab", ReceivedAt: now})
+	Ok(t, err)
+	Equals(t, true, recorded)
+	recorded, err = store.Record(server.Delivery{ID: "1", HostType: "Github", Hostname: "github.example.com", ReceivedAt: now})
+	Ok(t, err)
+	Equals(t, true, recorded)
+	recorded, err = store.Record(server.Delivery{ID: "1", HostType: "Github", ReceivedAt: now})
+	Ok(t, err)
+	Equals(t, false, recorded)
+
+	deliveries, err := store.List()
+	Ok(t, err)
+	Equals(t, 3, len(deliveries))
+}
+
+func TestFileDeliveryStore_Forget(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Minute, Retention: time.Hour}
+	now := time.Now()
+	d := server.Delivery{ID: "1", HostType: "Github", ReceivedAt: now}
+
+	// Forgetting a delivery that wasn't recorded is fine.
+	Ok(t, store.Forget(d))
+
+	_, err := store.Record(d)
+	Ok(t, err)
+	Ok(t, store.Forget(d))
+	_, found, err := store.Get("1")
+	Ok(t, err)
+	Equals(t, false, found)
+
+	// Once forgotten it's no longer a duplicate.
+	recorded, err := store.Record(server.Delivery{ID: "1", HostType: "Github", ReceivedAt: now.Add(time.Second)})
+	Ok(t, err)
+	Equals(t, true, recorded)
+}
+
+func TestFileDeliveryStore_List(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Minute, Retention: time.Hour}
+
+	// Listing before anything is recorded should return nothing.
+	deliveries, err := store.List()
+	Ok(t, err)
+	Equals(t, 0, len(deliveries))
+
+	now := time.Now()
+	_, err = store.Record(server.Delivery{ID: "1", ReceivedAt: now})
+	Ok(t, err)
+	_, err = store.Record(server.Delivery{ID: "2", ReceivedAt: now.Add(time.Second)})
+	Ok(t, err)
+
+	deliveries, err = store.List()
+	Ok(t, err)
+	Equals(t, 2, len(deliveries))
+	Equals(t, "2", deliveries[0].ID)
+	Equals(t, "1", deliveries[1].ID)
+}
+
+func TestFileDeliveryStore_Retention(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Minute, Retention: time.Hour}
+
+	_, err := store.Record(server.Delivery{ID: "1", ReceivedAt: time.Now()})
+	Ok(t, err)
+
+	// Make the delivery's file older than the retention.
+	files, err := filepath.Glob(filepath.Join(tmp, "deliveries", "*.json"))
+	Ok(t, err)
+	Equals(t, 1, len(files))
+	old := time.Now().Add(-2 * time.Hour)
+	Ok(t, os.Chtimes(files[0], old, old))
+
+	// It should be deleted when the next delivery is recorded.
+	_, err = store.Record(server.Delivery{ID: "2", ReceivedAt: time.Now()})
+	Ok(t, err)
+	_, found, err := store.Get("1")
+	Ok(t, err)
+	Equals(t, false, found)
+	_, found, err = store.Get("2")
+	Ok(t, err)
+	Equals(t, true, found)
+}
+
+func TestFileDeliveryStore_PruneInterval(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Minute, Retention: time.Hour, PruneInterval: time.Hour}
+
+	_, err := store.Record(server.Delivery{ID: "1", ReceivedAt: time.Now()})
+	Ok(t, err)
+	files, err := filepath.Glob(filepath.Join(tmp, "deliveries", "*.json"))
+	Ok(t, err)
+	Equals(t, 1, len(files))
+	old := time.Now().Add(-2 * time.Hour)
+	Ok(t, os.Chtimes(files[0], old, old))
+
+	// Old deliveries were just looked for so it shouldn't be deleted yet.
+	_, err = store.Record(server.Delivery{ID: "2", ReceivedAt: time.Now()})
+	Ok(t, err)
+	_, found, err := store.Get("1")
+	Ok(t, err)
+	Equals(t, true, found)
+}
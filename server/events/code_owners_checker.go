@@ -0,0 +1,256 @@
+package events
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/pkg/errors"
+)
+
+// codeOwnersPaths are the paths, relative to the repo root, that we look for
+// the CODEOWNERS file at, in order. GitHub uses the first three and GitLab
+// also supports .gitlab/.
+var codeOwnersPaths = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_code_owners_checker.go CodeOwnersChecker
+
+// CodeOwnersChecker checks whether a project's code owners approved a pull
+// request.
+type CodeOwnersChecker interface {
+	// CheckApproved returns why the project in ctx hasn't been approved by
+	// one of its code owners, or an empty string if it has. repoDir is the
+	// root of the repo's working dir, where we look for the CODEOWNERS file.
+	CheckApproved(ctx models.ProjectCommandContext, repoDir string) (failure string, err error)
+}
+
+// DefaultCodeOwnersChecker implements CodeOwnersChecker using the pull
+// request's reviews.
+type DefaultCodeOwnersChecker struct {
+	VCSClient vcs.ClientProxy
+}
+
+// CheckApproved finds the owners of the project's dir in the CODEOWNERS file
+// and checks if one of them, or a member of one of the teams that own it,
+// approved the pull request. The pull request's author can't approve it.
+// Since CODEOWNERS is read from the pull request's branch, we won't trust it
+// if the pull request modifies any of the CODEOWNERS files. Otherwise deleting
+// one could make another one with different owners be used.
+func (d *DefaultCodeOwnersChecker) CheckApproved(ctx models.ProjectCommandContext, repoDir string) (string, error) {
+	codeOwnersPath, owners, err := d.findOwners(repoDir, ctx.RepoRelDir)
+	if err != nil {
+		return "", err
+	}
+	if codeOwnersPath == "" {
+		return "no CODEOWNERS file was found", nil
+	}
+	if len(owners) == 0 {
+		return fmt.Sprintf("no code owners are defined for it in %s", codeOwnersPath), nil
+	}
+
+	modifiedFiles, err := d.VCSClient.GetModifiedFiles(ctx.BaseRepo, ctx.Pull)
+	if err != nil {
+		return "", errors.Wrap(err, "getting modified files")
+	}
+	for _, f := range modifiedFiles {
+		for _, p := range codeOwnersPaths {
+			if f == p {
+				return fmt.Sprintf("the pull request modifies %s so its code owners can't be trusted", f), nil
+			}
+		}
+	}
+
+	approvers, err := d.VCSClient.GetApprovers(ctx.BaseRepo, ctx.Pull)
+	if err != nil {
+		return "", errors.Wrap(err, "getting pull request approvers")
+	}
+	for _, approver := range approvers {
+		if strings.EqualFold(approver, ctx.Pull.Author) {
+			continue
+		}
+		approved, err := d.isOwner(ctx, approver, owners)
+		if err != nil {
+			return "", err
+		}
+		if approved {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("none of its code owners (%s) have approved it", strings.Join(owners, ", ")), nil
+}
+
+// findOwners returns the path of the CODEOWNERS file relative to repoDir and
+// the owners it defines for dir. The path is empty if there is no CODEOWNERS
+// file.
+func (d *DefaultCodeOwnersChecker) findOwners(repoDir string, dir string) (string, []string, error) {
+	for _, p := range codeOwnersPaths {
+		f, err := os.Open(filepath.Join(repoDir, p)) // nolint: gosec
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "opening %s", p)
+		}
+		defer f.Close() // nolint: errcheck
+		codeOwners, err := ParseCodeOwners(f)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "parsing %s", p)
+		}
+		return p, codeOwners.Owners(dir), nil
+	}
+	return "", nil, nil
+}
+
+// isOwner returns true if user is one of owners or is in one of the teams
+// that are owners.
+func (d *DefaultCodeOwnersChecker) isOwner(ctx models.ProjectCommandContext, user string, owners []string) (bool, error) {
+	var teamOwners []string
+	for _, o := range owners {
+		o = strings.TrimPrefix(o, "@")
+		if strings.Contains(o, "/") {
+			teamOwners = append(teamOwners, o)
+			continue
+		}
+		// Owners can also be emails which is what Azure DevOps uses as
+		// usernames.
+		if strings.EqualFold(o, user) {
+			return true, nil
+		}
+	}
+	if len(teamOwners) == 0 {
+		return false, nil
+	}
+
+	teams, err := d.VCSClient.GetTeamNamesForUser(ctx.BaseRepo, models.User{Username: user})
+	if err != nil {
+		return false, errors.Wrapf(err, "getting teams of %s", user)
+	}
+	for _, owner := range teamOwners {
+		for _, team := range teams {
+			if teamMatchesOwner(team, owner) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// teamMatchesOwner returns true if team is the team owner, ex. org/infra.
+// GitLab returns the group's full path, ex. org/infra, but GitHub returns the
+// team's name, ex. "Infra Team", whereas CODEOWNERS uses its slug, ex.
+// org/infra-team.
+func teamMatchesOwner(team string, owner string) bool {
+	if strings.EqualFold(team, owner) {
+		return true
+	}
+	slug := owner[strings.Index(owner, "/")+1:]
+	return strings.EqualFold(team, slug) || strings.EqualFold(strings.Replace(team, " ", "-", -1), slug)
+}
+
+// CodeOwners is a parsed CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ParseCodeOwners parses a CODEOWNERS file. Each line is a pattern followed by
+// its owners, ex. "/envs/prod/ @alice @org/infra". Comments and GitLab
+// section headers are ignored.
+func ParseCodeOwners(r io.Reader) (CodeOwners, error) {
+	var codeOwners CodeOwners
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		pattern, err := codeOwnersPatternRegex(fields[0])
+		if err != nil {
+			return CodeOwners{}, errors.Wrapf(err, "invalid pattern %q", fields[0])
+		}
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{
+			pattern: pattern,
+			owners:  fields[1:],
+		})
+	}
+	return codeOwners, scanner.Err()
+}
+
+// Owners returns the owners of dir, relative to the repo root. Like in
+// .gitignore, a pattern matches dir if it matches dir or one of its parents
+// and the last matching pattern wins. Patterns that only match files, ex.
+// *.tf, never match a dir.
+func (c CodeOwners) Owners(dir string) []string {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	if dir == "." {
+		dir = ""
+	}
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(dir) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersPatternRegex converts a CODEOWNERS pattern into a regex that
+// matches the paths of the dirs it matches.
+func codeOwnersPatternRegex(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// Everything under a dir is the same as the dir since we only match dirs.
+	pattern = strings.TrimSuffix(pattern, "/**")
+	if pattern == "" || pattern == "**" {
+		return regexp.Compile("^.*$")
+	}
+	// A pattern with a / in the middle is relative to the repo root.
+	if strings.Contains(pattern, "/") {
+		anchored = true
+	}
+	if strings.HasPrefix(pattern, "**/") {
+		pattern = strings.TrimPrefix(pattern, "**/")
+		anchored = false
+	}
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	expr.WriteString("(/.*)?$")
+	return regexp.Compile(expr.String())
+}
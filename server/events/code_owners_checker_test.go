@@ -0,0 +1,200 @@
+package events_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events"
+	"github.com/cloudposse/atlantis/server/events/models"
+	vcsmocks "github.com/cloudposse/atlantis/server/events/vcs/mocks"
+	. "github.com/cloudposse/atlantis/testing"
+	. "github.com/petergtz/pegomock"
+)
+
+func TestCodeOwners_Owners(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners(strings.NewReader(`
+# Comment.
+*                  @default
+*.tf               @tf-files
+docs/              @docs # trailing comment
+/envs/             @envs-owner
+/envs/prod/        @alice @org/infra
+modules/*/aws      @aws
+**/staging         @staging
+/envs/dev/**       @dev
+[Section]
+/envs/prod/db      dba@example.com
+`))
+	Ok(t, err)
+
+	cases := []struct {
+		dir    string
+		owners []string
+	}{
+		{".", []string{"@default"}},
+		{"docs", []string{"@docs"}},
+		{"nested/docs", []string{"@docs"}},
+		{"envs", []string{"@envs-owner"}},
+		{"envs/qa", []string{"@envs-owner"}},
+		{"envs/prod", []string{"@alice", "@org/infra"}},
+		{"envs/prod/vpc", []string{"@alice", "@org/infra"}},
+		{"envs/prod/db", []string{"dba@example.com"}},
+		{"envs/production", []string{"@envs-owner"}},
+		{"other/envs/prod", []string{"@default"}},
+		{"modules/vpc/aws", []string{"@aws"}},
+		{"nested/modules/vpc/aws", []string{"@default"}},
+		{"envs/staging", []string{"@staging"}},
+		{"a/b/staging/c", []string{"@staging"}},
+		{"envs/dev", []string{"@dev"}},
+		{"envs/dev/vpc", []string{"@dev"}},
+	}
+	for _, c := range cases {
+		t.Run(c.dir, func(t *testing.T) {
+			Equals(t, c.owners, codeOwners.Owners(c.dir))
+		})
+	}
+}
+
+func TestCodeOwners_OwnersNoMatch(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners(strings.NewReader("/envs/prod/ @alice\n*.tf @tf-files\n"))
+	Ok(t, err)
+	Assert(t, codeOwners.Owners("envs/dev") == nil, "expected no owners")
+	Assert(t, codeOwners.Owners(".") == nil, "expected no owners")
+}
+
+func TestCodeOwners_OwnersRootPattern(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners(strings.NewReader("/ @root\n"))
+	Ok(t, err)
+	Equals(t, []string{"@root"}, codeOwners.Owners("."))
+	Equals(t, []string{"@root"}, codeOwners.Owners("envs/prod"))
+}
+
+func TestDefaultCodeOwnersChecker_CheckApproved(t *testing.T) {
+	ctx := models.ProjectCommandContext{
+		RepoRelDir: "envs/prod",
+		Pull:       models.PullRequest{Num: 1, Author: "author"},
+	}
+	cases := []struct {
+		description string
+		codeOwners  string
+		approvers   []string
+		teams       map[string][]string
+		modified    []string
+		expFailure  string
+	}{
+		{
+			description: "no codeowners file",
+			expFailure:  "no CODEOWNERS file was found",
+		},
+		{
+			description: "no owners for dir",
+			codeOwners:  "/envs/dev/ @alice\n",
+			approvers:   []string{"alice"},
+			expFailure:  "no code owners are defined for it in .github/CODEOWNERS",
+		},
+		{
+			description: "user owner approved",
+			codeOwners:  "/envs/prod/ @Alice\n",
+			approvers:   []string{"bob", "alice"},
+		},
+		{
+			description: "email owner approved",
+			codeOwners:  "/envs/prod/ alice@example.com\n",
+			approvers:   []string{"Alice@example.com"},
+		},
+		{
+			description: "no owner approved",
+			codeOwners:  "/envs/prod/ @alice @org/infra\n",
+			approvers:   []string{"bob"},
+			teams:       map[string][]string{"bob": {"Frontend"}},
+			expFailure:  "none of its code owners (@alice, @org/infra) have approved it",
+		},
+		{
+			description: "author can't approve",
+			codeOwners:  "/envs/prod/ @author\n",
+			approvers:   []string{"author"},
+			expFailure:  "none of its code owners (@author) have approved it",
+		},
+		{
+			description: "team owner approved by team slug",
+			codeOwners:  "/envs/prod/ @org/infra-team\n",
+			approvers:   []string{"bob"},
+			teams:       map[string][]string{"bob": {"Infra Team"}},
+		},
+		{
+			description: "team owner approved by full path",
+			codeOwners:  "/envs/prod/ @org/infra\n",
+			approvers:   []string{"bob"},
+			teams:       map[string][]string{"bob": {"org/infra"}},
+		},
+		{
+			description: "pull request modifies codeowners",
+			codeOwners:  "/envs/prod/ @alice\n",
+			approvers:   []string{"alice"},
+			modified:    []string{"envs/prod/main.tf", ".github/CODEOWNERS"},
+			expFailure:  "the pull request modifies .github/CODEOWNERS so its code owners can't be trusted",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			if c.codeOwners != "" {
+				Ok(t, os.MkdirAll(filepath.Join(repoDir, ".github"), 0700))
+				Ok(t, ioutil.WriteFile(filepath.Join(repoDir, ".github", "CODEOWNERS"), []byte(c.codeOwners), 0600))
+			}
+			vcsClient := vcsmocks.NewMockClientProxy()
+			When(vcsClient.GetModifiedFiles(ctx.BaseRepo, ctx.Pull)).ThenReturn(c.modified, nil)
+			When(vcsClient.GetApprovers(ctx.BaseRepo, ctx.Pull)).ThenReturn(c.approvers, nil)
+			for user, teams := range c.teams {
+				When(vcsClient.GetTeamNamesForUser(ctx.BaseRepo, models.User{Username: user})).ThenReturn(teams, nil)
+			}
+
+			checker := &events.DefaultCodeOwnersChecker{VCSClient: vcsClient}
+			failure, err := checker.CheckApproved(ctx, repoDir)
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+		})
+	}
+}
+
+// The first CODEOWNERS file found is used.
+func TestDefaultCodeOwnersChecker_CheckApprovedRootCodeOwners(t *testing.T) {
+	RegisterMockTestingT(t)
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	Ok(t, ioutil.WriteFile(filepath.Join(repoDir, "CODEOWNERS"), []byte("* @alice\n"), 0600))
+	Ok(t, os.MkdirAll(filepath.Join(repoDir, "docs"), 0700))
+	Ok(t, ioutil.WriteFile(filepath.Join(repoDir, "docs", "CODEOWNERS"), []byte("* @bob\n"), 0600))
+	ctx := models.ProjectCommandContext{RepoRelDir: "."}
+	vcsClient := vcsmocks.NewMockClientProxy()
+	When(vcsClient.GetApprovers(ctx.BaseRepo, ctx.Pull)).ThenReturn([]string{"bob"}, nil)
+
+	checker := &events.DefaultCodeOwnersChecker{VCSClient: vcsClient}
+	failure, err := checker.CheckApproved(ctx, repoDir)
+	Ok(t, err)
+	Equals(t, "none of its code owners (@alice) have approved it", failure)
+}
+
+// If the pull request deletes a CODEOWNERS file, the one that's used instead
+// can't be trusted either.
+func TestDefaultCodeOwnersChecker_CheckApprovedDeletedCodeOwners(t *testing.T) {
+	RegisterMockTestingT(t)
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	// .github/CODEOWNERS was deleted so only the root one is left.
+	Ok(t, ioutil.WriteFile(filepath.Join(repoDir, "CODEOWNERS"), []byte("* @alice\n"), 0600))
+	ctx := models.ProjectCommandContext{RepoRelDir: "."}
+	vcsClient := vcsmocks.NewMockClientProxy()
+	When(vcsClient.GetModifiedFiles(ctx.BaseRepo, ctx.Pull)).ThenReturn([]string{".github/CODEOWNERS"}, nil)
+	When(vcsClient.GetApprovers(ctx.BaseRepo, ctx.Pull)).ThenReturn([]string{"alice"}, nil)
+
+	checker := &events.DefaultCodeOwnersChecker{VCSClient: vcsClient}
+	failure, err := checker.CheckApproved(ctx, repoDir)
+	Ok(t, err)
+	Equals(t, "the pull request modifies .github/CODEOWNERS so its code owners can't be trusted", failure)
+}
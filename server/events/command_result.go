@@ -13,9 +13,25 @@
 
 package events
 
+import "github.com/cloudposse/atlantis/server/events/models"
+
 // CommandResult is the result of running a Command.
 type CommandResult struct {
 	Error          error
 	Failure        string
 	ProjectResults []ProjectResult
 }
+
+// HasErrors returns true if the command or any of its projects errored or
+// failed.
+func (c CommandResult) HasErrors() bool {
+	if c.Error != nil || c.Failure != "" {
+		return true
+	}
+	for _, r := range c.ProjectResults {
+		if r.Status() != models.SuccessCommitStatus {
+			return true
+		}
+	}
+	return false
+}
@@ -15,9 +15,15 @@ package events
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
+	"github.com/cloudposse/atlantis/server/events/vcs/gitea"
+	"github.com/cloudposse/atlantis/server/events/webhooks"
 	"github.com/cloudposse/atlantis/server/logging"
 	"github.com/cloudposse/atlantis/server/recovery"
 	"github.com/google/go-github/github"
@@ -52,11 +58,20 @@ type GitlabMergeRequestGetter interface {
 	GetMergeRequest(repoFullName string, pullNum int) (*gitlab.MergeRequest, error)
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_gitea_pull_getter.go GiteaPullGetter
+
+// GiteaPullGetter makes API calls to get pull requests.
+type GiteaPullGetter interface {
+	// GetPullRequest gets the pull request with id pullNum for the repo.
+	GetPullRequest(repo models.Repo, pullNum int) (*gitea.PullRequest, error)
+}
+
 // DefaultCommandRunner is the first step when processing a comment command.
 type DefaultCommandRunner struct {
 	VCSClient                vcs.ClientProxy
 	GithubPullGetter         GithubPullGetter
 	GitlabMergeRequestGetter GitlabMergeRequestGetter
+	GiteaPullGetter          GiteaPullGetter
 	CommitStatusUpdater      CommitStatusUpdater
 	EventParser              EventParsing
 	MarkdownRenderer         *MarkdownRenderer
@@ -69,8 +84,72 @@ type DefaultCommandRunner struct {
 	AllowForkPRsFlag      string
 	ProjectCommandBuilder ProjectCommandBuilder
 	ProjectCommandRunner  ProjectCommandRunner
+	// CommentOutputStore and OutputURLGenerator are used to store the output
+	// of commands whose comments would be split into more than
+	// maxSplitComments comments. If nil, we always split.
+	CommentOutputStore CommentOutputStore
+	OutputURLGenerator OutputURLGenerator
+	// GlobalAutomerge is true if pull requests should be merged once all
+	// their plans have been applied, even if their atlantis.yaml doesn't set
+	// automerge.
+	GlobalAutomerge   bool
+	WorkingDir        WorkingDir
+	PendingPlanFinder PendingPlanFinder
+	// CommentMode controls what happens to our previous comment for the same
+	// command and projects when we comment back. PullCommentStore tracks the
+	// ids of those comments. If it's nil, we always make new comments.
+	CommentMode      CommentMode
+	PullCommentStore PullCommentStore
+	// VCSHosts maps from the hostname of each additional VCS host to what's
+	// used for its repos instead of EventParser and the pull getters above.
+	VCSHosts map[string]VCSHost
+	// Webhooks sends the autoplan webhooks. If it's nil, they aren't sent.
+	Webhooks WebhooksSender
+	// PolicyChecksEnabled is true if successful plans should be checked
+	// against the policy sets.
+	PolicyChecksEnabled bool
 }
 
+// VCSHost is what's used to get the pull requests of an additional VCS host
+// of the same type as another host, ex. GitHub Enterprise and github.com.
+// Only the getter for the host's type needs to be set.
+type VCSHost struct {
+	EventParser              EventParsing
+	GithubPullGetter         GithubPullGetter
+	GitlabMergeRequestGetter GitlabMergeRequestGetter
+	GiteaPullGetter          GiteaPullGetter
+}
+
+// CommentMode is what we do with our previous comment for the same command
+// and projects when we comment back on a pull request.
+type CommentMode string
+
+const (
+	// NewCommentMode leaves previous comments alone and makes a new comment.
+	NewCommentMode CommentMode = "new"
+	// UpdateCommentMode edits the previous comment to have the new output.
+	UpdateCommentMode CommentMode = "update"
+	// HideCommentMode makes a new comment and hides the previous one if the
+	// VCS host supports it.
+	HideCommentMode CommentMode = "hide"
+)
+
+// outdatedComment replaces the parts of a previous comment that are no longer
+// needed because the updated comment is shorter.
+const outdatedComment = "_This comment is outdated. See the comment above._"
+
+// maxSplitComments is the most comments we'll split a comment into. Past
+// that, the full output is stored on Atlantis and we comment with a truncated
+// version that links to it.
+const maxSplitComments = 5
+
+// automergeComment is the comment we post before automerging.
+const automergeComment = "Automatically merging because all plans have been successfully applied."
+
+// deletedPlansComment is the comment we post after deleting the plans of a
+// pull request that will be automerged because one of them failed.
+const deletedPlansComment = "Automerge is enabled so all plans were deleted because at least one of them failed. Fix the failures and run `atlantis plan` again."
+
 // RunAutoplanCommand runs plan when a pull request is opened or updated.
 func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) {
 	log := c.buildLogger(baseRepo.FullName, pull.Num)
@@ -91,6 +170,13 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 
 	projectCmds, err := c.ProjectCommandBuilder.BuildAutoplanCommands(ctx)
 	if err != nil {
+		c.sendWebhook(ctx, webhooks.CommandFailure{
+			Repo:    baseRepo,
+			Pull:    pull,
+			User:    user,
+			Command: "autoplan",
+			Error:   err.Error(),
+		})
 		c.updatePull(ctx, AutoplanCommand{}, CommandResult{Error: err})
 		return
 	}
@@ -99,11 +185,52 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 		if err := c.CommitStatusUpdater.Update(baseRepo, pull, models.SuccessCommitStatus, PlanCommand); err != nil {
 			ctx.Log.Warn("unable to update commit status: %s", err)
 		}
+		if err := c.CommitStatusUpdater.CleanupStaleProjects(ctx, CommandResult{}); err != nil {
+			ctx.Log.Warn("unable to clean up stale commit statuses: %s", err)
+		}
 		return
 	}
 
+	var projects []webhooks.AutoplanProject
+	for _, cmd := range projectCmds {
+		projects = append(projects, webhooks.AutoplanProject{Dir: cmd.RepoRelDir, Workspace: cmd.Workspace})
+	}
+	c.sendWebhook(ctx, webhooks.AutoplanStart{
+		Repo:     baseRepo,
+		Pull:     pull,
+		User:     user,
+		Projects: projects,
+	})
+	startedAt := time.Now()
 	results := c.runProjectCmds(projectCmds, PlanCommand)
-	c.updatePull(ctx, AutoplanCommand{}, CommandResult{ProjectResults: results})
+	res := CommandResult{ProjectResults: results}
+	failed := 0
+	for _, r := range results {
+		if r.Status() != models.SuccessCommitStatus {
+			failed++
+		}
+	}
+	c.sendWebhook(ctx, webhooks.AutoplanFinish{
+		Repo:           baseRepo,
+		Pull:           pull,
+		User:           user,
+		Success:        failed == 0,
+		Projects:       len(results),
+		FailedProjects: failed,
+		StartedAt:      startedAt,
+		FinishedAt:     time.Now(),
+	})
+	c.updatePull(ctx, AutoplanCommand{}, res)
+	if c.automergeEnabled(projectCmds) && res.HasErrors() {
+		c.deletePlans(ctx)
+	} else {
+		c.runPolicyChecks(ctx, false, projectCmds, results)
+	}
+	// Autoplan covers every modified project so any other project we've
+	// published a status for is no longer affected.
+	if err := c.CommitStatusUpdater.CleanupStaleProjects(ctx, res); err != nil {
+		ctx.Log.Warn("unable to clean up stale commit statuses: %s", err)
+	}
 }
 
 // RunCommentCommand executes the command.
@@ -122,10 +249,12 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 	var pull models.PullRequest
 	switch baseRepo.VCSHost.Type {
 	case models.Github:
-		pull, headRepo, err = c.getGithubData(baseRepo, pullNum)
+		pull, headRepo, err = c.getGithubData(c.vcsHost(baseRepo), baseRepo, pullNum)
 	case models.Gitlab:
-		pull, err = c.getGitlabData(baseRepo, pullNum)
-	case models.BitbucketCloud, models.BitbucketServer:
+		pull, err = c.getGitlabData(c.vcsHost(baseRepo), baseRepo, pullNum)
+	case models.Gitea:
+		pull, headRepo, err = c.getGiteaData(c.vcsHost(baseRepo), baseRepo, pullNum)
+	case models.BitbucketCloud, models.BitbucketServer, models.AzureDevops:
 		if maybePull == nil {
 			err = errors.New("pull request should not be nil–this is a bug")
 		}
@@ -160,6 +289,9 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		projectCmds, err = c.ProjectCommandBuilder.BuildApplyCommands(ctx, cmd)
 	case DestroyCommand:
 		projectCmds, err = c.ProjectCommandBuilder.BuildDestroyCommands(ctx, cmd)
+	case ApprovePoliciesCommand:
+		// Policies are approved for the same plans that would be applied.
+		projectCmds, err = c.ProjectCommandBuilder.BuildApplyCommands(ctx, cmd)
 	default:
 		ctx.Log.Err("failed to determine desired command, neither plan nor apply")
 		return
@@ -169,11 +301,98 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		return
 	}
 	results := c.runProjectCmds(projectCmds, cmd.Name)
-	c.updatePull(
-		ctx,
-		cmd,
-		CommandResult{
-			ProjectResults: results})
+	res := CommandResult{ProjectResults: results}
+	c.updatePull(ctx, cmd, res)
+	if cmd.Name == PlanCommand {
+		if c.automergeEnabled(projectCmds) && res.HasErrors() {
+			c.deletePlans(ctx)
+		} else {
+			c.runPolicyChecks(ctx, cmd.Verbose, projectCmds, results)
+		}
+	}
+	if cmd.Name == ApplyCommand && c.automergeEnabled(projectCmds) && !res.HasErrors() {
+		c.automerge(ctx)
+	}
+}
+
+// automergeEnabled returns true if pull requests should be merged once all
+// their plans have been applied, either because it's enabled for every repo
+// or in the repo's atlantis.yaml.
+func (c *DefaultCommandRunner) automergeEnabled(projectCmds []models.ProjectCommandContext) bool {
+	if c.GlobalAutomerge {
+		return true
+	}
+	// Every project is built from the same atlantis.yaml.
+	return len(projectCmds) > 0 && projectCmds[0].GlobalConfig != nil && projectCmds[0].GlobalConfig.Automerge
+}
+
+// automerge merges the pull request if there are no plans left to apply.
+func (c *DefaultCommandRunner) automerge(ctx *CommandContext) {
+	pullDir, err := c.WorkingDir.GetPullDir(ctx.BaseRepo, ctx.Pull)
+	if err != nil {
+		ctx.Log.Err("failed to get pull dir, not automerging: %s", err)
+		return
+	}
+	plans, err := c.PendingPlanFinder.Find(pullDir)
+	if err != nil {
+		ctx.Log.Err("failed to find pending plans, not automerging: %s", err)
+		return
+	}
+	if len(plans) > 0 {
+		ctx.Log.Info("not automerging because there are %d plans that haven't been applied", len(plans))
+		return
+	}
+
+	if err := c.VCSClient.CreateComment(ctx.BaseRepo, ctx.Pull.Num, automergeComment); err != nil {
+		ctx.Log.Err("failed to comment about automerge: %s", err)
+	}
+	ctx.Log.Info("automerging pull request")
+	if err := c.VCSClient.MergePull(ctx.BaseRepo, ctx.Pull); err != nil {
+		ctx.Log.Err("automerging failed: %s", err)
+		failureComment := fmt.Sprintf("Automerging failed:\n```\n%s\n```", err)
+		if commentErr := c.VCSClient.CreateComment(ctx.BaseRepo, ctx.Pull.Num, failureComment); commentErr != nil {
+			ctx.Log.Err("failed to comment about automerge failing: %s", commentErr)
+		}
+	}
+}
+
+// deletePlans deletes the pull request's plans after a plan failed so that
+// the plans that succeeded can't be applied and automerged without the
+// failed ones.
+func (c *DefaultCommandRunner) deletePlans(ctx *CommandContext) {
+	pullDir, err := c.WorkingDir.GetPullDir(ctx.BaseRepo, ctx.Pull)
+	if err != nil {
+		ctx.Log.Err("failed to get pull dir: %s", err)
+		return
+	}
+	if err := c.PendingPlanFinder.DeletePlans(pullDir); err != nil {
+		ctx.Log.Err("failed to delete plans: %s", err)
+		return
+	}
+	ctx.Log.Info("deleted all plans because automerge is enabled and a plan failed")
+	if err := c.VCSClient.CreateComment(ctx.BaseRepo, ctx.Pull.Num, deletedPlansComment); err != nil {
+		ctx.Log.Err("failed to comment about deleted plans: %s", err)
+	}
+}
+
+// runPolicyChecks checks the plans in planResults that succeeded against the
+// policy sets and comments with the results. projectCmds are the commands
+// the plans were run for, in the same order.
+func (c *DefaultCommandRunner) runPolicyChecks(ctx *CommandContext, verbose bool, projectCmds []models.ProjectCommandContext, planResults []ProjectResult) {
+	if !c.PolicyChecksEnabled {
+		return
+	}
+	var checkCmds []models.ProjectCommandContext
+	for i, r := range planResults {
+		if r.PlanSuccess != nil {
+			checkCmds = append(checkCmds, projectCmds[i])
+		}
+	}
+	if len(checkCmds) == 0 {
+		return
+	}
+	results := c.runProjectCmds(checkCmds, PolicyCheckCommand)
+	c.updatePull(ctx, &CommentCommand{Name: PolicyCheckCommand, Verbose: verbose}, CommandResult{ProjectResults: results})
 }
 
 func (c *DefaultCommandRunner) runProjectCmds(cmds []models.ProjectCommandContext, cmdName CommandName) []ProjectResult {
@@ -187,39 +406,72 @@ func (c *DefaultCommandRunner) runProjectCmds(cmds []models.ProjectCommandContex
 			res = c.ProjectCommandRunner.Apply(pCmd)
 		case DestroyCommand:
 			res = c.ProjectCommandRunner.Destroy(pCmd)
+		case PolicyCheckCommand:
+			res = c.ProjectCommandRunner.PolicyCheck(pCmd)
+		case ApprovePoliciesCommand:
+			res = c.ProjectCommandRunner.ApprovePolicies(pCmd)
 		}
 		results = append(results, res)
 	}
 	return results
 }
 
-func (c *DefaultCommandRunner) getGithubData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
-	if c.GithubPullGetter == nil {
+// vcsHost returns what to use to get the pull requests of baseRepo's VCS
+// host.
+func (c *DefaultCommandRunner) vcsHost(baseRepo models.Repo) VCSHost {
+	if host, ok := c.VCSHosts[baseRepo.VCSHost.Hostname]; ok {
+		return host
+	}
+	return VCSHost{
+		EventParser:              c.EventParser,
+		GithubPullGetter:         c.GithubPullGetter,
+		GitlabMergeRequestGetter: c.GitlabMergeRequestGetter,
+		GiteaPullGetter:          c.GiteaPullGetter,
+	}
+}
+
+func (c *DefaultCommandRunner) getGithubData(host VCSHost, baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	if host.GithubPullGetter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support GitHub")
 	}
-	ghPull, err := c.GithubPullGetter.GetPullRequest(baseRepo, pullNum)
+	ghPull, err := host.GithubPullGetter.GetPullRequest(baseRepo, pullNum)
 	if err != nil {
 		return models.PullRequest{}, models.Repo{}, errors.Wrap(err, "making pull request API call to GitHub")
 	}
-	pull, _, headRepo, err := c.EventParser.ParseGithubPull(ghPull)
+	pull, _, headRepo, err := host.EventParser.ParseGithubPull(ghPull)
 	if err != nil {
 		return pull, headRepo, errors.Wrap(err, "extracting required fields from comment data")
 	}
 	return pull, headRepo, nil
 }
 
-func (c *DefaultCommandRunner) getGitlabData(baseRepo models.Repo, pullNum int) (models.PullRequest, error) {
-	if c.GitlabMergeRequestGetter == nil {
+func (c *DefaultCommandRunner) getGitlabData(host VCSHost, baseRepo models.Repo, pullNum int) (models.PullRequest, error) {
+	if host.GitlabMergeRequestGetter == nil {
 		return models.PullRequest{}, errors.New("Atlantis not configured to support GitLab")
 	}
-	mr, err := c.GitlabMergeRequestGetter.GetMergeRequest(baseRepo.FullName, pullNum)
+	mr, err := host.GitlabMergeRequestGetter.GetMergeRequest(baseRepo.FullName, pullNum)
 	if err != nil {
 		return models.PullRequest{}, errors.Wrap(err, "making merge request API call to GitLab")
 	}
-	pull := c.EventParser.ParseGitlabMergeRequest(mr, baseRepo)
+	pull := host.EventParser.ParseGitlabMergeRequest(mr, baseRepo)
 	return pull, nil
 }
 
+func (c *DefaultCommandRunner) getGiteaData(host VCSHost, baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	if host.GiteaPullGetter == nil {
+		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support Gitea")
+	}
+	giteaPull, err := host.GiteaPullGetter.GetPullRequest(baseRepo, pullNum)
+	if err != nil {
+		return models.PullRequest{}, models.Repo{}, errors.Wrap(err, "making pull request API call to Gitea")
+	}
+	pull, _, headRepo, err := host.EventParser.ParseGiteaPull(giteaPull)
+	if err != nil {
+		return pull, headRepo, errors.Wrap(err, "extracting required fields from comment data")
+	}
+	return pull, headRepo, nil
+}
+
 func (c *DefaultCommandRunner) buildLogger(repoFullName string, pullNum int) *logging.SimpleLogger {
 	src := fmt.Sprintf("%s#%d", repoFullName, pullNum)
 	return logging.NewSimpleLogger(src, c.Logger.Underlying(), true, c.Logger.GetLevel())
@@ -257,11 +509,135 @@ func (c *DefaultCommandRunner) updatePull(ctx *CommandContext, command PullComma
 		ctx.Log.Warn("unable to update commit status: %s", err)
 	}
 	comment := c.MarkdownRenderer.Render(res, command.CommandName(), ctx.Log.History.String(), command.IsVerbose(), ctx.BaseRepo.VCSHost.Type)
-	if err := c.VCSClient.CreateComment(ctx.BaseRepo, ctx.Pull.Num, comment); err != nil {
+	comment = c.truncateComment(ctx, comment)
+	if err := c.comment(ctx, commentKey(command.CommandName(), res), comment); err != nil {
 		ctx.Log.Err("unable to comment: %s", err)
 	}
 }
 
+// comment comments on the pull request, updating or hiding our previous
+// comment for key depending on CommentMode.
+func (c *DefaultCommandRunner) comment(ctx *CommandContext, key string, comment string) error {
+	if c.PullCommentStore == nil || c.CommentMode == "" || c.CommentMode == NewCommentMode {
+		return c.VCSClient.CreateComment(ctx.BaseRepo, ctx.Pull.Num, comment)
+	}
+
+	prevIDs, err := c.PullCommentStore.Get(ctx.BaseRepo, ctx.Pull.Num, key)
+	if err != nil {
+		ctx.Log.Warn("unable to get previous comment ids, commenting without them: %s", err)
+	}
+
+	var ids []string
+	if c.CommentMode == UpdateCommentMode && len(prevIDs) > 0 {
+		ids, err = c.updateComment(ctx, prevIDs, comment)
+		// The previous comment may have been deleted so rather than losing
+		// the output we make a new comment, which we'll update from now on.
+		if err != nil {
+			ctx.Log.Warn("unable to update previous comment, making a new comment instead: %s", err)
+			ids, err = c.VCSClient.CreateCommentWithIDs(ctx.BaseRepo, ctx.Pull.Num, comment)
+		}
+	} else {
+		ids, err = c.VCSClient.CreateCommentWithIDs(ctx.BaseRepo, ctx.Pull.Num, comment)
+		if err == nil && c.CommentMode == HideCommentMode {
+			c.hideComments(ctx, prevIDs)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.PullCommentStore.Set(ctx.BaseRepo, ctx.Pull.Num, key, ids); err != nil {
+		ctx.Log.Warn("unable to store comment ids: %s", err)
+	}
+	return nil
+}
+
+// updateComment replaces the comments with prevIDs by comment and returns the
+// ids of the comments it's now made up of. Parts of comment that don't have a
+// previous comment to update are made as new comments and previous comments
+// that are no longer needed are marked as outdated.
+func (c *DefaultCommandRunner) updateComment(ctx *CommandContext, prevIDs []string, comment string) ([]string, error) {
+	parts, err := common.SplitComment(comment, vcs.MaxCommentLength(ctx.BaseRepo.VCSHost.Type), common.SepEnd, common.SepStart)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for i, part := range parts {
+		if i < len(prevIDs) {
+			if err := c.VCSClient.UpdateComment(ctx.BaseRepo, ctx.Pull.Num, prevIDs[i], part); err != nil {
+				return nil, err
+			}
+			ids = append(ids, prevIDs[i])
+			continue
+		}
+		created, err := c.VCSClient.CreateCommentWithIDs(ctx.BaseRepo, ctx.Pull.Num, part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, created...)
+	}
+	for i := len(parts); i < len(prevIDs); i++ {
+		id := prevIDs[i]
+		if err := c.VCSClient.UpdateComment(ctx.BaseRepo, ctx.Pull.Num, id, outdatedComment); err != nil {
+			ctx.Log.Warn("unable to mark comment %s as outdated: %s", id, err)
+		}
+	}
+	return ids, nil
+}
+
+// hideComments hides the comments with ids, logging if they can't be hidden.
+func (c *DefaultCommandRunner) hideComments(ctx *CommandContext, ids []string) {
+	for _, id := range ids {
+		err := c.VCSClient.HideComment(ctx.BaseRepo, ctx.Pull.Num, id)
+		if err == common.ErrHideCommentNotSupported {
+			ctx.Log.Warn("unable to hide previous comments: %s", err)
+			return
+		}
+		if err != nil {
+			ctx.Log.Warn("unable to hide comment %s: %s", id, err)
+		}
+	}
+}
+
+// commentKey identifies the comments made for a command on a set of projects
+// so that the next comment for the same command and projects can replace them.
+// Autoplan and plan share the same key.
+func commentKey(cmdName CommandName, res CommandResult) string {
+	var projects []string
+	for _, p := range res.ProjectResults {
+		projects = append(projects, fmt.Sprintf("%s/%s", p.RepoRelDir, p.Workspace))
+	}
+	sort.Strings(projects)
+	return fmt.Sprintf("%s:%s", cmdName, strings.Join(projects, ","))
+}
+
+// truncateComment returns comment unchanged if it can be split into at most
+// maxSplitComments comments. Otherwise it stores the full comment and returns
+// a truncated version that links to it.
+func (c *DefaultCommandRunner) truncateComment(ctx *CommandContext, comment string) string {
+	if c.CommentOutputStore == nil || c.OutputURLGenerator == nil {
+		return comment
+	}
+	maxLength := vcs.MaxCommentLength(ctx.BaseRepo.VCSHost.Type)
+	if len(comment) <= maxLength*maxSplitComments {
+		return comment
+	}
+	id, err := c.CommentOutputStore.Save(ctx.BaseRepo, ctx.Pull.Num, comment)
+	if err != nil {
+		ctx.Log.Warn("unable to store output, commenting with the full output instead: %s", err)
+		return comment
+	}
+	suffix := fmt.Sprintf("\n\n**Warning**: Output length greater than max comment size. The full output is available at %s", c.OutputURLGenerator.GenerateOutputURL(id))
+	return common.TruncateComment(comment, maxLength, suffix)
+}
+
+// sendWebhook sends the webhooks for event if webhooks are configured.
+func (c *DefaultCommandRunner) sendWebhook(ctx *CommandContext, event webhooks.Event) {
+	if c.Webhooks == nil {
+		return
+	}
+	c.Webhooks.Send(ctx.Log, event) // nolint: errcheck
+}
+
 // logPanics logs and creates a comment on the pull request for panics.
 func (c *DefaultCommandRunner) logPanics(ctx *CommandContext) {
 	if err := recover(); err != nil {
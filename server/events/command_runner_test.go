@@ -26,6 +26,8 @@ import (
 	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/models/fixtures"
 	vcsmocks "github.com/cloudposse/atlantis/server/events/vcs/mocks"
+	"github.com/cloudposse/atlantis/server/events/webhooks"
+	"github.com/cloudposse/atlantis/server/events/yaml/valid"
 	logmocks "github.com/cloudposse/atlantis/server/logging/mocks"
 	. "github.com/cloudposse/atlantis/testing"
 	"github.com/google/go-github/github"
@@ -37,6 +39,7 @@ var eventParsing *mocks.MockEventParsing
 var ghStatus *mocks.MockCommitStatusUpdater
 var githubGetter *mocks.MockGithubPullGetter
 var gitlabGetter *mocks.MockGitlabMergeRequestGetter
+var giteaGetter *mocks.MockGiteaPullGetter
 var ch events.DefaultCommandRunner
 var logBytes *bytes.Buffer
 
@@ -48,6 +51,7 @@ func setup(t *testing.T) *vcsmocks.MockClientProxy {
 	vcsClient := vcsmocks.NewMockClientProxy()
 	githubGetter = mocks.NewMockGithubPullGetter()
 	gitlabGetter = mocks.NewMockGitlabMergeRequestGetter()
+	giteaGetter = mocks.NewMockGiteaPullGetter()
 	logger := logmocks.NewMockSimpleLogging()
 	logBytes = new(bytes.Buffer)
 	projectCommandRunner := mocks.NewMockProjectCommandRunner()
@@ -59,6 +63,7 @@ func setup(t *testing.T) *vcsmocks.MockClientProxy {
 		MarkdownRenderer:         &events.MarkdownRenderer{},
 		GithubPullGetter:         githubGetter,
 		GitlabMergeRequestGetter: gitlabGetter,
+		GiteaPullGetter:          giteaGetter,
 		Logger:                   logger,
 		AllowForkPRs:             false,
 		AllowForkPRsFlag:         "allow-fork-prs-flag",
@@ -95,6 +100,22 @@ func TestRunCommentCommand_NoGitlabMergeGetter(t *testing.T) {
 	Equals(t, "[ERROR] runatlantis/atlantis#1: Atlantis not configured to support GitLab\n", logBytes.String())
 }
 
+func TestRunCommentCommand_NoGiteaPullGetter(t *testing.T) {
+	t.Log("if DefaultCommandRunner was constructed with a nil GiteaPullGetter an error should be logged")
+	setup(t)
+	ch.GiteaPullGetter = nil
+	ch.RunCommentCommand(fixtures.GiteaRepo, nil, nil, fixtures.User, 1, nil)
+	Equals(t, "[ERROR] runatlantis/atlantis#1: Atlantis not configured to support Gitea\n", logBytes.String())
+}
+
+func TestRunCommentCommand_GiteaPullErr(t *testing.T) {
+	t.Log("if getting the gitea pull request fails an error should be logged")
+	setup(t)
+	When(giteaGetter.GetPullRequest(fixtures.GiteaRepo, fixtures.Pull.Num)).ThenReturn(nil, errors.New("err"))
+	ch.RunCommentCommand(fixtures.GiteaRepo, nil, nil, fixtures.User, fixtures.Pull.Num, nil)
+	Equals(t, "[ERROR] runatlantis/atlantis#1: Making pull request API call to Gitea: err\n", logBytes.String())
+}
+
 func TestRunCommentCommand_GithubPullErr(t *testing.T) {
 	t.Log("if getting the github pull request fails an error should be logged")
 	setup(t)
@@ -111,6 +132,21 @@ func TestRunCommentCommand_GitlabMergeRequestErr(t *testing.T) {
 	Equals(t, "[ERROR] runatlantis/atlantis#1: Making merge request API call to GitLab: err\n", logBytes.String())
 }
 
+func TestRunCommentCommand_AdditionalVCSHost(t *testing.T) {
+	t.Log("repos on an additional VCS host should use that host's pull getter")
+	setup(t)
+	hostGetter := mocks.NewMockGithubPullGetter()
+	ch.VCSHosts = map[string]events.VCSHost{
+		"github.example.com": {GithubPullGetter: hostGetter, EventParser: mocks.NewMockEventParsing()},
+	}
+	repo := fixtures.GithubRepo
+	repo.VCSHost.Hostname = "github.example.com"
+	When(hostGetter.GetPullRequest(repo, fixtures.Pull.Num)).ThenReturn(nil, errors.New("err"))
+	ch.RunCommentCommand(repo, &repo, nil, fixtures.User, fixtures.Pull.Num, nil)
+	Equals(t, "[ERROR] runatlantis/atlantis#1: Making pull request API call to GitHub: err\n", logBytes.String())
+	githubGetter.VerifyWasCalled(Never()).GetPullRequest(matchers.AnyModelsRepo(), AnyInt())
+}
+
 func TestRunCommentCommand_GithubPullParseErr(t *testing.T) {
 	t.Log("if parsing the returned github pull request fails an error should be logged")
 	setup(t)
@@ -154,3 +190,314 @@ func TestRunCommentCommand_ClosedPull(t *testing.T) {
 	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, nil, fixtures.User, fixtures.Pull.Num, nil)
 	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, "Atlantis commands can't be run on closed pull requests")
 }
+
+func TestRunAutoplanCommand_TruncatesLongComments(t *testing.T) {
+	t.Log("if the comment would be split into too many comments, the full output" +
+		" should be stored and we should comment with a truncated version that links to it")
+	vcsClient := setup(t)
+	store := mocks.NewMockCommentOutputStore()
+	urlGenerator := mocks.NewMockOutputURLGenerator()
+	ch.CommentOutputStore = store
+	ch.OutputURLGenerator = urlGenerator
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil, errors.New(strings.Repeat("a", 6*65536)))
+	When(store.Save(matchers.AnyModelsRepo(), AnyInt(), AnyString())).ThenReturn("id", nil)
+	When(urlGenerator.GenerateOutputURL("id")).ThenReturn("https://atlantis/outputs/id")
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	_, _, output := store.VerifyWasCalledOnce().Save(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, strings.Contains(output, strings.Repeat("a", 6*65536)), "expected the full output to be stored")
+	_, _, comment := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, len(comment) <= 65536, "comment was %d characters", len(comment))
+	Assert(t, strings.HasSuffix(comment, "The full output is available at https://atlantis/outputs/id"), "comment didn't link to the output: %q", comment[len(comment)-200:])
+}
+
+func TestRunAutoplanCommand_DoesNotTruncateShortComments(t *testing.T) {
+	t.Log("if the comment can be split into a few comments, it shouldn't be stored")
+	vcsClient := setup(t)
+	store := mocks.NewMockCommentOutputStore()
+	ch.CommentOutputStore = store
+	ch.OutputURLGenerator = mocks.NewMockOutputURLGenerator()
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil, errors.New(strings.Repeat("a", 2*65536)))
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	store.VerifyWasCalled(Never()).Save(matchers.AnyModelsRepo(), AnyInt(), AnyString())
+	_, _, comment := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, strings.Contains(comment, strings.Repeat("a", 2*65536)), "expected the full output in the comment")
+}
+
+func TestRunCommentCommand_Automerge(t *testing.T) {
+	cases := []struct {
+		description     string
+		globalAutomerge bool
+		repoAutomerge   bool
+		applyResult     events.ProjectResult
+		pendingPlans    []events.PendingPlan
+		expMerge        bool
+	}{
+		{
+			description: "automerge disabled",
+			applyResult: events.ProjectResult{ApplySuccess: "success"},
+		},
+		{
+			description:     "automerge enabled by flag",
+			globalAutomerge: true,
+			applyResult:     events.ProjectResult{ApplySuccess: "success"},
+			expMerge:        true,
+		},
+		{
+			description:   "automerge enabled by atlantis.yaml",
+			repoAutomerge: true,
+			applyResult:   events.ProjectResult{ApplySuccess: "success"},
+			expMerge:      true,
+		},
+		{
+			description:     "apply failed",
+			globalAutomerge: true,
+			applyResult:     events.ProjectResult{Error: errors.New("err")},
+		},
+		{
+			description:     "plans left to apply",
+			globalAutomerge: true,
+			applyResult:     events.ProjectResult{ApplySuccess: "success"},
+			pendingPlans:    []events.PendingPlan{{RepoRelDir: "other", Workspace: "default"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			vcsClient := setup(t)
+			workingDir, pendingPlanFinder := setupAutomerge(c.globalAutomerge)
+			projectCmd := models.ProjectCommandContext{
+				GlobalConfig: &valid.Config{Automerge: c.repoAutomerge},
+			}
+			When(projectCommandBuilder.BuildApplyCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).ThenReturn([]models.ProjectCommandContext{projectCmd}, nil)
+			When(ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner).Apply(projectCmd)).ThenReturn(c.applyResult)
+			When(workingDir.GetPullDir(bitbucketRepo, fixtures.Pull)).ThenReturn("/pull/dir", nil)
+			When(pendingPlanFinder.Find("/pull/dir")).ThenReturn(c.pendingPlans, nil)
+
+			ch.RunCommentCommand(bitbucketRepo, &bitbucketRepo, &fixtures.Pull, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: events.ApplyCommand})
+			if c.expMerge {
+				vcsClient.VerifyWasCalledOnce().CreateComment(bitbucketRepo, fixtures.Pull.Num, "Automatically merging because all plans have been successfully applied.")
+				vcsClient.VerifyWasCalledOnce().MergePull(bitbucketRepo, fixtures.Pull)
+			} else {
+				vcsClient.VerifyWasCalled(Never()).MergePull(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+			}
+		})
+	}
+}
+
+func TestRunCommentCommand_AutomergeFails(t *testing.T) {
+	t.Log("if automerging fails we should comment with the error")
+	vcsClient := setup(t)
+	workingDir, pendingPlanFinder := setupAutomerge(true)
+	projectCmd := models.ProjectCommandContext{}
+	When(projectCommandBuilder.BuildApplyCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).ThenReturn([]models.ProjectCommandContext{projectCmd}, nil)
+	When(ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner).Apply(projectCmd)).ThenReturn(events.ProjectResult{ApplySuccess: "success"})
+	When(workingDir.GetPullDir(bitbucketRepo, fixtures.Pull)).ThenReturn("/pull/dir", nil)
+	When(pendingPlanFinder.Find("/pull/dir")).ThenReturn(nil, nil)
+	When(vcsClient.MergePull(bitbucketRepo, fixtures.Pull)).ThenReturn(errors.New("not mergeable"))
+
+	ch.RunCommentCommand(bitbucketRepo, &bitbucketRepo, &fixtures.Pull, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: events.ApplyCommand})
+	vcsClient.VerifyWasCalledOnce().CreateComment(bitbucketRepo, fixtures.Pull.Num, "Automerging failed:\n```\nnot mergeable\n```")
+}
+
+func TestRunAutoplanCommand_AutomergeDeletesPlansOnFailure(t *testing.T) {
+	t.Log("if automerge is enabled and a plan fails, all plans should be deleted" +
+		" so the pull request can't be merged without the failed plan being applied")
+	vcsClient := setup(t)
+	workingDir, pendingPlanFinder := setupAutomerge(true)
+	projectCmds := []models.ProjectCommandContext{{RepoRelDir: "a"}, {RepoRelDir: "b"}}
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(projectCmds, nil)
+	When(ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner).Plan(projectCmds[0])).ThenReturn(events.ProjectResult{PlanSuccess: &events.PlanSuccess{}})
+	When(ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner).Plan(projectCmds[1])).ThenReturn(events.ProjectResult{Error: errors.New("err")})
+	When(workingDir.GetPullDir(bitbucketRepo, fixtures.Pull)).ThenReturn("/pull/dir", nil)
+
+	ch.RunAutoplanCommand(bitbucketRepo, bitbucketRepo, fixtures.Pull, fixtures.User)
+	pendingPlanFinder.VerifyWasCalledOnce().DeletePlans("/pull/dir")
+	vcsClient.VerifyWasCalledOnce().CreateComment(bitbucketRepo, fixtures.Pull.Num, "Automerge is enabled so all plans were deleted because at least one of them failed. Fix the failures and run `atlantis plan` again.")
+}
+
+func TestRunAutoplanCommand_PolicyChecks(t *testing.T) {
+	t.Log("if policy checks are enabled, successful plans should be checked")
+	setup(t)
+	ch.PolicyChecksEnabled = true
+	projectCmds := []models.ProjectCommandContext{{RepoRelDir: "a"}, {RepoRelDir: "b"}}
+	runner := ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner)
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(projectCmds, nil)
+	When(runner.Plan(projectCmds[0])).ThenReturn(events.ProjectResult{PlanSuccess: &events.PlanSuccess{}})
+	When(runner.Plan(projectCmds[1])).ThenReturn(events.ProjectResult{Error: errors.New("err")})
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	runner.VerifyWasCalledOnce().PolicyCheck(projectCmds[0])
+	runner.VerifyWasCalled(Never()).PolicyCheck(projectCmds[1])
+}
+
+func TestRunCommentCommand_PolicyChecksDisabled(t *testing.T) {
+	t.Log("if policy checks aren't enabled, plans shouldn't be checked")
+	setup(t)
+	projectCmd := models.ProjectCommandContext{RepoRelDir: "a"}
+	runner := ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner)
+	When(projectCommandBuilder.BuildPlanCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).ThenReturn([]models.ProjectCommandContext{projectCmd}, nil)
+	When(runner.Plan(projectCmd)).ThenReturn(events.ProjectResult{PlanSuccess: &events.PlanSuccess{}})
+
+	ch.RunCommentCommand(bitbucketRepo, &bitbucketRepo, &fixtures.Pull, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: events.PlanCommand})
+	runner.VerifyWasCalledOnce().Plan(projectCmd)
+	runner.VerifyWasCalled(Never()).PolicyCheck(matchers.AnyModelsProjectCommandContext())
+}
+
+func TestRunCommentCommand_ApprovePolicies(t *testing.T) {
+	t.Log("approve_policies should approve the plans that apply would apply")
+	setup(t)
+	projectCmd := models.ProjectCommandContext{RepoRelDir: "a"}
+	runner := ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner)
+	When(projectCommandBuilder.BuildApplyCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).ThenReturn([]models.ProjectCommandContext{projectCmd}, nil)
+	When(runner.ApprovePolicies(projectCmd)).ThenReturn(events.ProjectResult{ApprovePoliciesSuccess: "approved"})
+
+	ch.RunCommentCommand(bitbucketRepo, &bitbucketRepo, &fixtures.Pull, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: events.ApprovePoliciesCommand})
+	runner.VerifyWasCalledOnce().ApprovePolicies(projectCmd)
+	runner.VerifyWasCalled(Never()).Apply(matchers.AnyModelsProjectCommandContext())
+}
+
+func TestRunAutoplanCommand_Webhooks(t *testing.T) {
+	t.Log("autoplan should send a webhook when it starts and when it finishes")
+	setup(t)
+	sender := mocks.NewMockWebhooksSender()
+	ch.Webhooks = sender
+	projectCmds := []models.ProjectCommandContext{{RepoRelDir: "a", Workspace: "default"}, {RepoRelDir: "b", Workspace: "staging"}}
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(projectCmds, nil)
+	When(ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner).Plan(projectCmds[0])).ThenReturn(events.ProjectResult{PlanSuccess: &events.PlanSuccess{}})
+	When(ch.ProjectCommandRunner.(*mocks.MockProjectCommandRunner).Plan(projectCmds[1])).ThenReturn(events.ProjectResult{Error: errors.New("err")})
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	_, sent := sender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, webhooks.AutoplanStart{
+		Repo: fixtures.GithubRepo,
+		Pull: fixtures.Pull,
+		User: fixtures.User,
+		Projects: []webhooks.AutoplanProject{
+			{Dir: "a", Workspace: "default"},
+			{Dir: "b", Workspace: "staging"},
+		},
+	}, sent[0])
+	finish := sent[1].(webhooks.AutoplanFinish)
+	Equals(t, false, finish.Success)
+	Equals(t, 2, finish.Projects)
+	Equals(t, 1, finish.FailedProjects)
+}
+
+func TestRunAutoplanCommand_BuildErrorWebhook(t *testing.T) {
+	t.Log("if autoplan can't build the project commands, a command failure webhook should be sent")
+	setup(t)
+	sender := mocks.NewMockWebhooksSender()
+	ch.Webhooks = sender
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil, errors.New("parsing atlantis.yaml"))
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	sender.VerifyWasCalledOnce().Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.EqWebhooksEvent(webhooks.CommandFailure{
+		Repo:    fixtures.GithubRepo,
+		Pull:    fixtures.Pull,
+		User:    fixtures.User,
+		Command: "autoplan",
+		Error:   "parsing atlantis.yaml",
+	}))
+}
+
+func TestRunAutoplanCommand_UpdateCommentMode(t *testing.T) {
+	t.Log("in update mode the previous comment should be updated, and the parts" +
+		" of it that are no longer needed marked as outdated")
+	vcsClient := setup(t)
+	store, cleanup := setupCommentMode(t, events.UpdateCommentMode)
+	defer cleanup()
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num, "plan:", []string{"1", "2"}))
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil, errors.New("err"))
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	vcsClient.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString())
+	_, _, id, comment := vcsClient.VerifyWasCalled(Times(2)).UpdateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString()).GetAllCapturedArguments()
+	Equals(t, []string{"1", "2"}, id)
+	Assert(t, strings.Contains(comment[0], "err"), "expected the first comment to be updated with the output, got %q", comment[0])
+	Equals(t, "_This comment is outdated. See the comment above._", comment[1])
+	ids, err := store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:")
+	Ok(t, err)
+	Equals(t, []string{"1"}, ids)
+}
+
+func TestRunAutoplanCommand_UpdateCommentModeUpdateFails(t *testing.T) {
+	t.Log("in update mode if the previous comment can't be updated we should" +
+		" make a new comment and update it from then on")
+	vcsClient := setup(t)
+	store, cleanup := setupCommentMode(t, events.UpdateCommentMode)
+	defer cleanup()
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num, "plan:", []string{"1"}))
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil, errors.New("err"))
+	When(vcsClient.UpdateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())).ThenReturn(errors.New("comment not found"))
+	When(vcsClient.CreateCommentWithIDs(matchers.AnyModelsRepo(), AnyInt(), AnyString())).ThenReturn([]string{"3"}, nil)
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	vcsClient.VerifyWasCalledOnce().UpdateComment(matchers.AnyModelsRepo(), AnyInt(), EqString("1"), AnyString())
+	_, _, comment := vcsClient.VerifyWasCalledOnce().CreateCommentWithIDs(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, strings.Contains(comment, "err"), "expected the new comment to have the output, got %q", comment)
+	ids, err := store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:")
+	Ok(t, err)
+	Equals(t, []string{"3"}, ids)
+}
+
+func TestRunAutoplanCommand_UpdateCommentModeNoPrevious(t *testing.T) {
+	t.Log("in update mode we should make a new comment if there isn't a previous one")
+	vcsClient := setup(t)
+	store, cleanup := setupCommentMode(t, events.UpdateCommentMode)
+	defer cleanup()
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil, errors.New("err"))
+	When(vcsClient.CreateCommentWithIDs(matchers.AnyModelsRepo(), AnyInt(), AnyString())).ThenReturn([]string{"3"}, nil)
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	vcsClient.VerifyWasCalled(Never()).UpdateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+	ids, err := store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:")
+	Ok(t, err)
+	Equals(t, []string{"3"}, ids)
+}
+
+func TestRunAutoplanCommand_HideCommentMode(t *testing.T) {
+	t.Log("in hide mode we should make a new comment and hide the previous one")
+	vcsClient := setup(t)
+	store, cleanup := setupCommentMode(t, events.HideCommentMode)
+	defer cleanup()
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num, "plan:", []string{"1"}))
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil, errors.New("err"))
+	When(vcsClient.CreateCommentWithIDs(matchers.AnyModelsRepo(), AnyInt(), AnyString())).ThenReturn([]string{"3"}, nil)
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	vcsClient.VerifyWasCalledOnce().HideComment(fixtures.GithubRepo, fixtures.Pull.Num, "1")
+	ids, err := store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:")
+	Ok(t, err)
+	Equals(t, []string{"3"}, ids)
+}
+
+var bitbucketRepo = models.Repo{
+	FullName: "owner/repo",
+	Owner:    "owner",
+	Name:     "repo",
+	VCSHost: models.VCSHost{
+		Hostname: "bitbucket.org",
+		Type:     models.BitbucketCloud,
+	},
+}
+
+// setupAutomerge sets up ch to automerge, must be called after setup.
+func setupAutomerge(globalAutomerge bool) (*mocks.MockWorkingDir, *mocks.MockPendingPlanFinder) {
+	workingDir := mocks.NewMockWorkingDir()
+	pendingPlanFinder := mocks.NewMockPendingPlanFinder()
+	ch.GlobalAutomerge = globalAutomerge
+	ch.WorkingDir = workingDir
+	ch.PendingPlanFinder = pendingPlanFinder
+	return workingDir, pendingPlanFinder
+}
+
+// setupCommentMode sets up ch to use mode with a real comment store, must be
+// called after setup.
+func setupCommentMode(t *testing.T, mode events.CommentMode) (*events.FilePullCommentStore, func()) {
+	tmpDir, cleanup := TempDir(t)
+	store := &events.FilePullCommentStore{DataDir: tmpDir}
+	ch.CommentMode = mode
+	ch.PullCommentStore = store
+	return store, cleanup
+}
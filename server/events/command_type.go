@@ -13,6 +13,8 @@
 
 package events
 
+import "strings"
+
 // CommandName is which command to run.
 type CommandName int
 
@@ -23,6 +25,12 @@ const (
 	PlanCommand
 	// DestroyCommand is a command to run terraform destroy.
 	DestroyCommand
+	// PolicyCheckCommand is a command to check plans against the policy sets.
+	// It's run after plan rather than by commenting.
+	PolicyCheckCommand
+	// ApprovePoliciesCommand is a command to approve plans that failed their
+	// policy checks so they can be applied.
+	ApprovePoliciesCommand
 	// Adding more? Don't forget to update String() below
 )
 
@@ -35,6 +43,16 @@ func (c CommandName) String() string {
 		return "plan"
 	case DestroyCommand:
 		return "destroy"
+	case PolicyCheckCommand:
+		return "policy_check"
+	case ApprovePoliciesCommand:
+		return "approve_policies"
 	}
 	return ""
 }
+
+// TitleString returns the name of c in title case for comments and commit
+// statuses, ex. "Policy Check".
+func (c CommandName) TitleString() string {
+	return strings.Title(strings.Replace(c.String(), "_", " ", -1))
+}
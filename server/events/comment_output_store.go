@@ -0,0 +1,107 @@
+package events
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/pkg/errors"
+)
+
+// outputsDir is the directory in the data dir that we store outputs in.
+const outputsDir = "outputs"
+
+// outputIDRegex matches the ids generated by FileCommentOutputStore. We
+// validate ids against it so they can't be used to read other files.
+var outputIDRegex = regexp.MustCompile(`^[0-9a-f]{16}-[0-9a-f]{32}$`)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_comment_output_store.go CommentOutputStore
+
+// CommentOutputStore stores the full output of commands whose comments are
+// too long to post, even when split, so it can be viewed on Atlantis instead.
+type CommentOutputStore interface {
+	// Save stores output for the pull request and returns the id it can be
+	// retrieved at.
+	Save(repo models.Repo, pullNum int, output string) (string, error)
+	// Get returns the output stored at id. found is false if there is no
+	// output at id.
+	Get(id string) (output string, found bool, err error)
+	// DeleteForPull deletes all the output stored for the pull request.
+	DeleteForPull(repo models.Repo, pullNum int) error
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_output_url_generator.go OutputURLGenerator
+
+// OutputURLGenerator generates urls to stored outputs.
+type OutputURLGenerator interface {
+	// GenerateOutputURL returns the full URL to the output at id.
+	GenerateOutputURL(id string) string
+}
+
+// FileCommentOutputStore implements CommentOutputStore by storing each output
+// as a file under DataDir.
+type FileCommentOutputStore struct {
+	DataDir string
+}
+
+// Save writes output to a new file. The id is prefixed by a hash of the pull
+// request so we can delete its outputs once it's closed, and suffixed by
+// random characters so the URL can't be guessed.
+func (f *FileCommentOutputStore) Save(repo models.Repo, pullNum int, output string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.Wrap(err, "generating id")
+	}
+	id := fmt.Sprintf("%s-%s", f.pullPrefix(repo, pullNum), hex.EncodeToString(random))
+
+	dir := filepath.Join(f.DataDir, outputsDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", errors.Wrap(err, "creating outputs dir")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, id), []byte(output), 0600); err != nil {
+		return "", errors.Wrap(err, "writing output")
+	}
+	return id, nil
+}
+
+// Get reads the output at id.
+func (f *FileCommentOutputStore) Get(id string) (string, bool, error) {
+	if !outputIDRegex.MatchString(id) {
+		return "", false, nil
+	}
+	output, err := ioutil.ReadFile(filepath.Join(f.DataDir, outputsDir, id))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Wrapf(err, "reading output %q", id)
+	}
+	return string(output), true, nil
+}
+
+// DeleteForPull deletes the files of every output saved for the pull request.
+func (f *FileCommentOutputStore) DeleteForPull(repo models.Repo, pullNum int) error {
+	matches, err := filepath.Glob(filepath.Join(f.DataDir, outputsDir, f.pullPrefix(repo, pullNum)+"-*"))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := os.Remove(m); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "deleting %q", m)
+		}
+	}
+	return nil
+}
+
+// pullPrefix returns the prefix of the ids of the outputs for the pull
+// request. It's hashed so the id doesn't reveal the repo.
+func (f *FileCommentOutputStore) pullPrefix(repo models.Repo, pullNum int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s#%d", repo.VCSHost.Hostname, repo.FullName, pullNum)))
+	return hex.EncodeToString(sum[:])[:16]
+}
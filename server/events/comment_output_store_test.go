@@ -0,0 +1,71 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events"
+	"github.com/cloudposse/atlantis/server/events/models/fixtures"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestFileCommentOutputStore_SaveGet(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := events.FileCommentOutputStore{DataDir: tmp}
+
+	id, err := store.Save(fixtures.GithubRepo, fixtures.Pull.Num, "output")
+	Ok(t, err)
+	output, found, err := store.Get(id)
+	Ok(t, err)
+	Equals(t, true, found)
+	Equals(t, "output", output)
+
+	// Each output should get its own id.
+	id2, err := store.Save(fixtures.GithubRepo, fixtures.Pull.Num, "output2")
+	Ok(t, err)
+	Assert(t, id != id2, "expected different ids")
+}
+
+func TestFileCommentOutputStore_GetNotFound(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := events.FileCommentOutputStore{DataDir: tmp}
+
+	cases := []string{
+		"0123456789abcdef-0123456789abcdef0123456789abcdef",
+		// Invalid ids shouldn't be read.
+		"../outputs",
+		"",
+	}
+	for _, id := range cases {
+		t.Run(id, func(t *testing.T) {
+			_, found, err := store.Get(id)
+			Ok(t, err)
+			Equals(t, false, found)
+		})
+	}
+}
+
+func TestFileCommentOutputStore_DeleteForPull(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := events.FileCommentOutputStore{DataDir: tmp}
+
+	// Deleting before anything is saved should succeed.
+	Ok(t, store.DeleteForPull(fixtures.GithubRepo, fixtures.Pull.Num))
+
+	id, err := store.Save(fixtures.GithubRepo, fixtures.Pull.Num, "output")
+	Ok(t, err)
+	otherID, err := store.Save(fixtures.GithubRepo, fixtures.Pull.Num+1, "other")
+	Ok(t, err)
+
+	Ok(t, store.DeleteForPull(fixtures.GithubRepo, fixtures.Pull.Num))
+	_, found, err := store.Get(id)
+	Ok(t, err)
+	Equals(t, false, found)
+
+	// Outputs for other pulls should remain.
+	_, found, err = store.Get(otherID)
+	Ok(t, err)
+	Equals(t, true, found)
+}
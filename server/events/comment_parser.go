@@ -60,15 +60,20 @@ type CommentBuilder interface {
 	BuildApplyComment(repoRelDir string, workspace string, project string) string
 	// BuildDestroyComment builds a destroy comment for the specified args.
 	BuildDestroyComment(repoRelDir string, workspace string, project string) string
+	// BuildApprovePoliciesComment builds an approve_policies comment for the
+	// specified args.
+	BuildApprovePoliciesComment(repoRelDir string, workspace string, project string) string
 }
 
 // CommentParser implements CommentParsing
 type CommentParser struct {
-	GithubUser  string
-	GithubToken string
-	GitlabUser  string
-	GitlabToken string
-	WakeWord    string
+	GithubUser      string
+	GithubToken     string
+	GitlabUser      string
+	GitlabToken     string
+	GiteaUser       string
+	AzureDevopsUser string
+	WakeWord        string
 }
 
 // CommentParseResult describes the result of parsing a comment as a command.
@@ -88,7 +93,8 @@ type CommentParseResult struct {
 // Valid commands contain:
 // - The initial "executable" name or '@GithubUser'
 //   where GithubUser is the API user Atlantis is running as.
-// - Then a command, either 'plan', 'apply', or 'help'.
+// - Then a command, either 'plan', 'apply', 'destroy', 'approve_policies'
+//   or 'help'.
 // - Then optional flags, then an optional separator '--' followed by optional
 //   extra flags to be appended to the terraform plan/apply command.
 //
@@ -119,8 +125,13 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 	// Atlantis can be invoked using the name of the VCS host user we're
 	// running under. Need to be able to match against that user.
 	vcsUser := e.GithubUser
-	if vcsHost == models.Gitlab {
+	switch vcsHost {
+	case models.Gitlab:
 		vcsUser = e.GitlabUser
+	case models.Gitea:
+		vcsUser = e.GiteaUser
+	case models.AzureDevops:
+		vcsUser = e.AzureDevopsUser
 	}
 	executableNames := []string{e.WakeWord, "@" + vcsUser}
 
@@ -142,8 +153,8 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		return CommentParseResult{CommentResponse: e.GetHelpComment()}
 	}
 
-	// Need to have a plan, apply or destroy at this point.
-	if !e.stringInSlice(command, []string{PlanCommand.String(), ApplyCommand.String(), DestroyCommand.String()}) {
+	// Need to have a plan, apply, destroy or approve_policies at this point.
+	if !e.stringInSlice(command, []string{PlanCommand.String(), ApplyCommand.String(), DestroyCommand.String(), ApprovePoliciesCommand.String()}) {
 		message := fmt.Sprintf("```\nError: unknown command %q.\nRun '%s --help' for usage.\n```", command, e.GetDidYouMeanWakeWordComment())
 		return CommentParseResult{CommentResponse: message}
 	}
@@ -182,6 +193,14 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Destroy the plan for this directory, relative to root of repo, ex. 'child/dir'.")
 		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Destroy the plan for this project. Refers to the name of the project configured in the repos atlantis.yaml file. Cannot be used at same time as workspace or dir flags."))
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case ApprovePoliciesCommand.String():
+		name = ApprovePoliciesCommand
+		flagSet = pflag.NewFlagSet(ApprovePoliciesCommand.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(ioutil.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Approve the policies of the plan for this Terraform workspace.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Approve the policies of the plan for this directory, relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Approve the policies of the plan for this project. Refers to the name of the project configured in the repos atlantis.yaml file. Cannot be used at same time as workspace or dir flags."))
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	default:
 		return CommentParseResult{CommentResponse: fmt.Sprintf("Error: unknown command %q – this is a bug", command)}
 	}
@@ -271,6 +290,13 @@ func (e *CommentParser) BuildDestroyComment(repoRelDir string, workspace string,
 	return fmt.Sprintf("%s %s%s", e.WakeWord, DestroyCommand.String(), flags)
 }
 
+// BuildApprovePoliciesComment builds an approve_policies comment for the
+// specified args.
+func (e *CommentParser) BuildApprovePoliciesComment(repoRelDir string, workspace string, project string) string {
+	flags := e.buildFlags(repoRelDir, workspace, project)
+	return fmt.Sprintf("%s %s%s", e.WakeWord, ApprovePoliciesCommand.String(), flags)
+}
+
 func (e *CommentParser) buildFlags(repoRelDir string, workspace string, project string) string {
 	switch {
 	// If project is specified we can just use its name.
@@ -346,6 +372,9 @@ Examples:
   # destroy the infrastructure for the root directory and staging workspace
   %[1]s destroy -d . -w staging
 
+  # approve the plans that failed their policy checks
+  %[1]s approve_policies
+
 Commands:
   plan     Runs 'terraform plan' for the changes in this pull request.
            To plan a specific project, use the -d, -w and -p flags.
@@ -353,6 +382,10 @@ Commands:
            To only apply a specific plan, use the -d, -w and -p flags.
   destroy  Runs 'terraform destroy' in this pull request.
            To destroy a specific plan, use the -d, -w and -p flags.
+  approve_policies
+           Approves the plans that failed their policy checks so they
+           can be applied. Only policy approvers can run it.
+           To approve a specific plan, use the -d, -w and -p flags.
   help     View help.
 
 Flags:
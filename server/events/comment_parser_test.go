@@ -24,11 +24,13 @@ import (
 )
 
 var commentParser = events.CommentParser{
-	GithubUser:  "github-user",
-	GithubToken: "github-token",
-	GitlabUser:  "gitlab-user",
-	GitlabToken: "gitlab-token",
-	WakeWord:    "atlantis",
+	GithubUser:      "github-user",
+	GithubToken:     "github-token",
+	GitlabUser:      "gitlab-user",
+	GitlabToken:     "gitlab-token",
+	GiteaUser:       "gitea-user",
+	AzureDevopsUser: "azuredevops-user",
+	WakeWord:        "atlantis",
 }
 
 func TestParse_Ignored(t *testing.T) {
@@ -147,6 +149,28 @@ func TestParse_DidYouMeanAtlantis(t *testing.T) {
 	}
 }
 
+func TestParse_VCSUserPerHost(t *testing.T) {
+	cases := []struct {
+		comment string
+		vcsHost models.VCSHostType
+		ignored bool
+	}{
+		{"@github-user plan", models.Github, false},
+		{"@gitlab-user plan", models.Github, true},
+		{"@gitlab-user plan", models.Gitlab, false},
+		{"@gitea-user plan", models.Gitea, false},
+		{"@github-user plan", models.Gitea, true},
+		{"@azuredevops-user plan", models.AzureDevops, false},
+		{"@github-user plan", models.AzureDevops, true},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s %s", c.comment, c.vcsHost), func(t *testing.T) {
+			r := commentParser.Parse(c.comment, c.vcsHost)
+			Equals(t, c.ignored, r.Ignore)
+		})
+	}
+}
+
 func TestParse_InvalidCommand(t *testing.T) {
 	t.Log("given a comment with an invalid atlantis command, should return " +
 		"a warning.")
@@ -538,6 +562,31 @@ func TestParse_Parsing(t *testing.T) {
 	}
 }
 
+func TestParse_ApprovePolicies(t *testing.T) {
+	cases := []struct {
+		comment      string
+		expDir       string
+		expWorkspace string
+		expProject   string
+		expVerbose   bool
+	}{
+		{comment: "atlantis approve_policies"},
+		{comment: "atlantis approve_policies -d dir -w workspace --verbose", expDir: "dir", expWorkspace: "workspace", expVerbose: true},
+		{comment: "atlantis approve_policies -p project", expProject: "project"},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, events.ApprovePoliciesCommand, r.Command.Name)
+			Equals(t, c.expDir, r.Command.RepoRelDir)
+			Equals(t, c.expWorkspace, r.Command.Workspace)
+			Equals(t, c.expProject, r.Command.ProjectName)
+			Equals(t, c.expVerbose, r.Command.Verbose)
+		})
+	}
+}
+
 func TestBuildPlanApplyComment(t *testing.T) {
 	cases := []struct {
 		repoRelDir    string
@@ -615,7 +664,7 @@ func TestBuildPlanApplyComment(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.expPlanFlags, func(t *testing.T) {
-			for _, cmd := range []events.CommandName{events.PlanCommand, events.ApplyCommand} {
+			for _, cmd := range []events.CommandName{events.PlanCommand, events.ApplyCommand, events.ApprovePoliciesCommand} {
 				switch cmd {
 				case events.PlanCommand:
 					actComment := commentParser.BuildPlanComment(c.repoRelDir, c.workspace, c.project, c.commentArgs)
@@ -623,6 +672,9 @@ func TestBuildPlanApplyComment(t *testing.T) {
 				case events.ApplyCommand:
 					actComment := commentParser.BuildApplyComment(c.repoRelDir, c.workspace, c.project)
 					Equals(t, fmt.Sprintf("atlantis apply %s", c.expApplyFlags), actComment)
+				case events.ApprovePoliciesCommand:
+					actComment := commentParser.BuildApprovePoliciesComment(c.repoRelDir, c.workspace, c.project)
+					Equals(t, fmt.Sprintf("atlantis approve_policies %s", c.expApplyFlags), actComment)
 				}
 			}
 		})
@@ -15,10 +15,13 @@ package events
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/pkg/errors"
 )
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_commit_status_updater.go CommitStatusUpdater
@@ -26,25 +29,60 @@ import (
 // CommitStatusUpdater updates the status of a commit with the VCS host. We set
 // the status to signify whether the plan/apply succeeds.
 type CommitStatusUpdater interface {
-	// Update updates the status of the head commit of pull.
+	// Update updates the aggregated status of the head commit of pull.
 	Update(repo models.Repo, pull models.PullRequest, status models.CommitStatus, command CommandName) error
-	// UpdateProjectResult updates the status of the head commit given the
-	// state of response.
+	// UpdateProjectResult updates the aggregated status of the head commit
+	// and the status of each project given the state of response.
 	UpdateProjectResult(ctx *CommandContext, commandName CommandName, res CommandResult) error
+	// CleanupStaleProjects marks the statuses of projects that were
+	// previously published for the pull request but aren't in res as
+	// successful since those projects are no longer affected. It should only
+	// be called with the results of an autoplan since they cover every
+	// modified project.
+	CleanupStaleProjects(ctx *CommandContext, res CommandResult) error
+	// ForgetPull forgets the statuses published for pull. It should be
+	// called once pull is closed.
+	ForgetPull(repo models.Repo, pull models.PullRequest)
 }
 
+// aggregatedStatusSrc is the name of the status that covers every project.
+// It's kept for backwards compatibility with branch protection rules that
+// were set up before we published a status per project.
+const aggregatedStatusSrc = "Atlantis"
+
+// noLongerAffectedDescription is the description of a project's status once
+// the project is no longer modified by the pull request.
+const noLongerAffectedDescription = "No longer affected by this pull request"
+
 // DefaultCommitStatusUpdater implements CommitStatusUpdater.
 type DefaultCommitStatusUpdater struct {
 	Client vcs.ClientProxy
+	// ChecksClient is set if we should create a GitHub check run for each
+	// project instead of a commit status. If nil, commit statuses are used.
+	ChecksClient vcs.GithubChecksClient
+	// ChecksHostname is the hostname of the GitHub host ChecksClient makes
+	// check runs on, ex. github.com. Repos on other GitHub hosts get commit
+	// statuses.
+	ChecksHostname string
+
+	// published maps from each pull request to the status names we've
+	// published for its projects, and from each name to the project it's
+	// for. It's used to clean up the statuses of projects that are no longer
+	// affected. It's only held in memory so statuses published before a
+	// restart won't be cleaned up. Pulls are removed by ForgetPull.
+	published map[string]map[string]string
+	mutex     sync.Mutex
 }
 
-// Update updates the commit status.
+// Update updates the aggregated commit status.
 func (d *DefaultCommitStatusUpdater) Update(repo models.Repo, pull models.PullRequest, status models.CommitStatus, command CommandName) error {
-	description := fmt.Sprintf("%s %s", strings.Title(command.String()), strings.Title(status.String()))
-	return d.Client.UpdateStatus(repo, pull, status, description)
+	return d.Client.UpdateStatus(repo, pull, status, aggregatedStatusSrc, d.description(command, status))
 }
 
-// UpdateProjectResult updates the commit status based on the status of res.
+// UpdateProjectResult updates the aggregated commit status based on the
+// status of res and publishes a status for each project. If every project
+// planned successfully, the descriptions say how many resources will change,
+// ex. "3 projects: +12 ~3 -1".
 func (d *DefaultCommitStatusUpdater) UpdateProjectResult(ctx *CommandContext, commandName CommandName, res CommandResult) error {
 	var status models.CommitStatus
 	if res.Error != nil || res.Failure != "" {
@@ -56,7 +94,228 @@ func (d *DefaultCommitStatusUpdater) UpdateProjectResult(ctx *CommandContext, co
 		}
 		status = d.worstStatus(statuses)
 	}
-	return d.Update(ctx.BaseRepo, ctx.Pull, status, commandName)
+	description := d.description(commandName, status)
+	if status == models.SuccessCommitStatus {
+		changes := d.planChangesDescription(res.ProjectResults)
+		if changes == noChangesDescription {
+			description = changes
+		} else if changes != "" {
+			projects := "projects"
+			if len(res.ProjectResults) == 1 {
+				projects = "project"
+			}
+			description = fmt.Sprintf("%d %s: %s", len(res.ProjectResults), projects, changes)
+		}
+	}
+	if err := d.Client.UpdateStatus(ctx.BaseRepo, ctx.Pull, status, aggregatedStatusSrc, description); err != nil {
+		return err
+	}
+
+	for _, p := range res.ProjectResults {
+		// Approving policies replaces the project's policy check status so
+		// a failed check no longer blocks merging.
+		projectCommandName := commandName
+		if commandName == ApprovePoliciesCommand {
+			projectCommandName = PolicyCheckCommand
+		}
+		src := d.projectStatusSrc(projectCommandName, p.RepoRelDir, p.Workspace)
+		var err error
+		if d.useChecks(ctx.BaseRepo) {
+			err = d.updateCheckRun(ctx, commandName, src, p)
+		} else {
+			description := d.description(commandName, p.Status())
+			if changes := d.planChangesDescription([]ProjectResult{p}); changes != "" {
+				description = changes
+			}
+			if p.ApprovePoliciesSuccess != "" {
+				description = policiesApprovedDescription
+			}
+			err = d.Client.UpdateStatus(ctx.BaseRepo, ctx.Pull, p.Status(), src, description)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "updating status %q", src)
+		}
+		d.recordPublished(ctx, src, d.projectID(p.RepoRelDir, p.Workspace))
+	}
+	return nil
+}
+
+// CleanupStaleProjects marks the statuses of projects that aren't in res as
+// successful.
+func (d *DefaultCommitStatusUpdater) CleanupStaleProjects(ctx *CommandContext, res CommandResult) error {
+	// If the command errored we don't know which projects are affected.
+	if res.Error != nil || res.Failure != "" {
+		return nil
+	}
+	affected := make(map[string]bool)
+	for _, p := range res.ProjectResults {
+		affected[d.projectID(p.RepoRelDir, p.Workspace)] = true
+	}
+
+	for _, src := range d.takeStale(ctx, affected) {
+		var err error
+		if d.useChecks(ctx.BaseRepo) {
+			err = d.ChecksClient.UpdateCheckRun(ctx.BaseRepo, ctx.Pull, src, models.SuccessCommitStatus, noLongerAffectedDescription, noLongerAffectedDescription, "")
+		} else {
+			err = d.Client.UpdateStatus(ctx.BaseRepo, ctx.Pull, models.SuccessCommitStatus, src, noLongerAffectedDescription)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "cleaning up status %q", src)
+		}
+	}
+	return nil
+}
+
+// recordPublished records that we published the status src for project.
+func (d *DefaultCommitStatusUpdater) recordPublished(ctx *CommandContext, src string, project string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.published == nil {
+		d.published = make(map[string]map[string]string)
+	}
+	key := d.pullKey(ctx.BaseRepo, ctx.Pull.Num)
+	if d.published[key] == nil {
+		d.published[key] = make(map[string]string)
+	}
+	d.published[key][src] = project
+}
+
+// takeStale returns the names of the statuses we've published for projects
+// that aren't in affected and forgets about them. The names are sorted so
+// statuses are updated in a consistent order.
+func (d *DefaultCommitStatusUpdater) takeStale(ctx *CommandContext, affected map[string]bool) []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	key := d.pullKey(ctx.BaseRepo, ctx.Pull.Num)
+	var stale []string
+	for src, project := range d.published[key] {
+		if !affected[project] {
+			stale = append(stale, src)
+			delete(d.published[key], src)
+		}
+	}
+	if len(d.published[key]) == 0 {
+		delete(d.published, key)
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// ForgetPull forgets the statuses published for pull so they don't use
+// memory after it's closed.
+func (d *DefaultCommitStatusUpdater) ForgetPull(repo models.Repo, pull models.PullRequest) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.published, d.pullKey(repo, pull.Num))
+}
+
+func (d *DefaultCommitStatusUpdater) pullKey(repo models.Repo, pullNum int) string {
+	return fmt.Sprintf("%s/%s#%d", repo.VCSHost.Hostname, repo.FullName, pullNum)
+}
+
+// projectStatusSrc returns the name of the status for the project, ex.
+// "atlantis/plan: envs/prod (default)".
+func (d *DefaultCommitStatusUpdater) projectStatusSrc(commandName CommandName, repoRelDir string, workspace string) string {
+	return fmt.Sprintf("atlantis/%s: %s", commandName.String(), d.projectID(repoRelDir, workspace))
+}
+
+func (d *DefaultCommitStatusUpdater) projectID(repoRelDir string, workspace string) string {
+	return fmt.Sprintf("%s (%s)", repoRelDir, workspace)
+}
+
+// policiesApprovedDescription is the description of a project's policy check
+// status once its policies have been approved.
+const policiesApprovedDescription = "Policies approved"
+
+// noChangesDescription is the description of a successful plan that won't
+// change any resources.
+const noChangesDescription = "no changes"
+
+// planChangesDescription returns how many resources the plans in results will
+// add, change and destroy, ex. "+12 ~3 -1", or noChangesDescription if they
+// won't change anything. It returns an empty string unless every result is a
+// successful plan whose changes are known.
+func (d *DefaultCommitStatusUpdater) planChangesDescription(results []ProjectResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+	var total models.PlanChanges
+	for _, r := range results {
+		if r.PlanSuccess == nil || r.Error != nil || r.Failure != "" {
+			return ""
+		}
+		changes := r.PlanSuccess.Changes()
+		if changes == nil {
+			return ""
+		}
+		total.Add += changes.Add
+		total.Change += changes.Change
+		total.Destroy += changes.Destroy
+	}
+	if total == (models.PlanChanges{}) {
+		return noChangesDescription
+	}
+	return fmt.Sprintf("+%d ~%d -%d", total.Add, total.Change, total.Destroy)
+}
+
+func (d *DefaultCommitStatusUpdater) description(commandName CommandName, status models.CommitStatus) string {
+	return fmt.Sprintf("%s %s", commandName.TitleString(), strings.Title(status.String()))
+}
+
+// useChecks returns true if we should use GitHub check runs instead of commit
+// statuses for projects in repo.
+func (d *DefaultCommitStatusUpdater) useChecks(repo models.Repo) bool {
+	return d.ChecksClient != nil && repo.VCSHost.Type == models.Github && repo.VCSHost.Hostname == d.ChecksHostname
+}
+
+// updateCheckRun creates or updates the check run called name for the project
+// in res. The check's summary is Terraform's plan summary and its details
+// hold the full output.
+func (d *DefaultCommitStatusUpdater) updateCheckRun(ctx *CommandContext, commandName CommandName, name string, res ProjectResult) error {
+	status := res.Status()
+	title := d.description(commandName, status)
+
+	var summary, text string
+	switch {
+	case res.Error != nil:
+		summary = fmt.Sprintf("**%s Error**", commandName.TitleString())
+		text = fmt.Sprintf("```\n%s\n```", res.Error.Error())
+	case res.Failure != "":
+		summary = fmt.Sprintf("**%s Failed**: %s", commandName.TitleString(), res.Failure)
+	case res.PlanSuccess != nil:
+		summary = res.PlanSuccess.Summary()
+		text = fmt.Sprintf("```diff\n%s\n```", (&MarkdownRenderer{}).fmtDiff(res.PlanSuccess.TerraformOutput))
+		if res.PlanSuccess.ApplyCmd != "" {
+			text += fmt.Sprintf("\n\n* To **apply** this plan, comment:\n  * `%s`", res.PlanSuccess.ApplyCmd)
+		}
+	case res.PolicyCheckSuccess != nil:
+		var failed []string
+		var violations []string
+		for _, r := range res.PolicyCheckSuccess.Results {
+			if r.Passed() {
+				continue
+			}
+			failed = append(failed, r.PolicySet)
+			for _, v := range r.Violations {
+				violations = append(violations, fmt.Sprintf("* **%s**: %s", r.PolicySet, v))
+			}
+		}
+		if len(failed) > 0 {
+			summary = fmt.Sprintf("**Policy Check Failed**: %s", strings.Join(failed, ", "))
+			text = strings.Join(violations, "\n")
+		}
+	case res.ApprovePoliciesSuccess != "":
+		title = policiesApprovedDescription
+		text = res.ApprovePoliciesSuccess
+	case res.ApplySuccess != "":
+		text = fmt.Sprintf("```diff\n%s\n```", res.ApplySuccess)
+	case res.DestroySuccess != "":
+		text = fmt.Sprintf("```diff\n%s\n```", res.DestroySuccess)
+	}
+	if summary == "" {
+		summary = title
+	}
+	return d.ChecksClient.UpdateCheckRun(ctx.BaseRepo, ctx.Pull, name, status, title, summary, text)
 }
 
 func (d *DefaultCommitStatusUpdater) worstStatus(ss []models.CommitStatus) models.CommitStatus {
@@ -20,7 +20,9 @@ import (
 
 	"github.com/cloudposse/atlantis/server/events"
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/policy"
 	"github.com/cloudposse/atlantis/server/events/vcs/mocks"
+	"github.com/cloudposse/atlantis/server/events/vcs/mocks/matchers"
 	. "github.com/cloudposse/atlantis/testing"
 	. "github.com/petergtz/pegomock"
 )
@@ -35,7 +37,7 @@ func TestUpdate(t *testing.T) {
 	s := events.DefaultCommitStatusUpdater{Client: client}
 	err := s.Update(repoModel, pullModel, status, events.PlanCommand)
 	Ok(t, err)
-	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, status, "Plan Success")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, status, "Atlantis", "Plan Success")
 }
 
 func TestUpdateProjectResult_Error(t *testing.T) {
@@ -48,7 +50,7 @@ func TestUpdateProjectResult_Error(t *testing.T) {
 	s := events.DefaultCommitStatusUpdater{Client: client}
 	err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{Error: errors.New("err")})
 	Ok(t, err)
-	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "Plan Failed")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "Atlantis", "Plan Failed")
 }
 
 func TestUpdateProjectResult_Failure(t *testing.T) {
@@ -61,7 +63,7 @@ func TestUpdateProjectResult_Failure(t *testing.T) {
 	s := events.DefaultCommitStatusUpdater{Client: client}
 	err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{Failure: "failure"})
 	Ok(t, err)
-	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "Plan Failed")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "Atlantis", "Plan Failed")
 }
 
 func TestUpdateProjectResult(t *testing.T) {
@@ -131,7 +133,282 @@ func TestUpdateProjectResult(t *testing.T) {
 			s := events.DefaultCommitStatusUpdater{Client: client}
 			err := s.UpdateProjectResult(ctx, events.PlanCommand, resp)
 			Ok(t, err)
-			client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, c.Expected, "Plan "+strings.Title(c.Expected.String()))
+			client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, c.Expected, "Atlantis", "Plan "+strings.Title(c.Expected.String()))
 		})
 	}
 }
+
+func TestUpdateProjectResult_PlanChanges(t *testing.T) {
+	cases := []struct {
+		description  string
+		results      []events.ProjectResult
+		expAggregate string
+		expProjects  []string
+	}{
+		{
+			description: "changes",
+			results: []events.ProjectResult{
+				{RepoRelDir: "a", Workspace: "default", PlanSuccess: &events.PlanSuccess{TerraformOutput: "Plan: 10 to add, 3 to change, 0 to destroy."}},
+				{RepoRelDir: "b", Workspace: "default", PlanSuccess: &events.PlanSuccess{TerraformOutput: "No changes. Infrastructure is up-to-date."}},
+				{RepoRelDir: "c", Workspace: "default", PlanSuccess: &events.PlanSuccess{PlanSummary: &models.PlanSummary{Create: 1, Replace: 1}}},
+			},
+			expAggregate: "3 projects: +12 ~3 -1",
+			expProjects:  []string{"+10 ~3 -0", "no changes", "+2 ~0 -1"},
+		},
+		{
+			description: "one project",
+			results: []events.ProjectResult{
+				{RepoRelDir: "a", Workspace: "default", PlanSuccess: &events.PlanSuccess{TerraformOutput: "Plan: 0 to add, 0 to change, 2 to destroy."}},
+			},
+			expAggregate: "1 project: +0 ~0 -2",
+			expProjects:  []string{"+0 ~0 -2"},
+		},
+		{
+			description: "no changes",
+			results: []events.ProjectResult{
+				{RepoRelDir: "a", Workspace: "default", PlanSuccess: &events.PlanSuccess{TerraformOutput: "No changes. Infrastructure is up-to-date."}},
+				{RepoRelDir: "b", Workspace: "default", PlanSuccess: &events.PlanSuccess{PlanSummary: &models.PlanSummary{}}},
+			},
+			expAggregate: "no changes",
+			expProjects:  []string{"no changes", "no changes"},
+		},
+		{
+			description: "unknown changes",
+			results: []events.ProjectResult{
+				{RepoRelDir: "a", Workspace: "default", PlanSuccess: &events.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}},
+				{RepoRelDir: "b", Workspace: "default", PlanSuccess: &events.PlanSuccess{TerraformOutput: "custom workflow output"}},
+			},
+			expAggregate: "Plan Success",
+			expProjects:  []string{"+1 ~0 -0", "Plan Success"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			ctx := &events.CommandContext{
+				BaseRepo: repoModel,
+				Pull:     pullModel,
+			}
+			client := mocks.NewMockClientProxy()
+			s := events.DefaultCommitStatusUpdater{Client: client}
+			err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{ProjectResults: c.results})
+			Ok(t, err)
+			client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "Atlantis", c.expAggregate)
+			for i, r := range c.results {
+				client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: "+r.RepoRelDir+" (default)", c.expProjects[i])
+			}
+		})
+	}
+}
+
+func TestUpdateProjectResult_PlanChangesFailed(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := &events.CommandContext{
+		BaseRepo: repoModel,
+		Pull:     pullModel,
+	}
+	client := mocks.NewMockClientProxy()
+	s := events.DefaultCommitStatusUpdater{Client: client}
+	err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{RepoRelDir: "a", Workspace: "default", PlanSuccess: &events.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}},
+			{RepoRelDir: "b", Workspace: "default", Error: errors.New("err")},
+		},
+	})
+	Ok(t, err)
+	// If any project fails, the aggregated status shouldn't count changes.
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "Atlantis", "Plan Failed")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: a (default)", "+1 ~0 -0")
+}
+
+func TestUpdateProjectResult_PolicyCheck(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := &events.CommandContext{
+		BaseRepo: repoModel,
+		Pull:     pullModel,
+	}
+	client := mocks.NewMockClientProxy()
+	s := events.DefaultCommitStatusUpdater{Client: client}
+	err := s.UpdateProjectResult(ctx, events.PolicyCheckCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{RepoRelDir: "a", Workspace: "default", PolicyCheckSuccess: &events.PolicyCheckSuccess{
+				Results: []policy.Result{{PolicySet: "s3", Violations: []string{"bucket is public"}}},
+			}},
+		},
+	})
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "Atlantis", "Policy Check Failed")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "atlantis/policy_check: a (default)", "Policy Check Failed")
+
+	// Approving the policies should replace the failed policy check status.
+	err = s.UpdateProjectResult(ctx, events.ApprovePoliciesCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{RepoRelDir: "a", Workspace: "default", ApprovePoliciesSuccess: "approved"},
+		},
+	})
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "Atlantis", "Approve Policies Success")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/policy_check: a (default)", "Policies approved")
+}
+
+func TestUpdateProjectResult_ChecksClient(t *testing.T) {
+	RegisterMockTestingT(t)
+	repo := models.Repo{VCSHost: models.VCSHost{Type: models.Github, Hostname: "github.com"}}
+	ctx := &events.CommandContext{
+		BaseRepo: repo,
+		Pull:     pullModel,
+	}
+	client := mocks.NewMockClientProxy()
+	checksClient := mocks.NewMockGithubChecksClient()
+	s := events.DefaultCommitStatusUpdater{Client: client, ChecksClient: checksClient, ChecksHostname: "github.com"}
+	err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{
+				RepoRelDir: "envs/prod",
+				Workspace:  "default",
+				PlanSuccess: &events.PlanSuccess{
+					TerraformOutput: "  + null_resource.test\n\nPlan: 1 to add, 0 to change, 0 to destroy.",
+					ApplyCmd:        "atlantis apply -d envs/prod",
+				},
+			},
+			{
+				RepoRelDir: ".",
+				Workspace:  "staging",
+				Error:      errors.New("err"),
+			},
+		},
+	})
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(repo, pullModel, models.FailedCommitStatus, "Atlantis", "Plan Failed")
+	client.VerifyWasCalledOnce().UpdateStatus(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnyVcsCommitStatus(), AnyString(), AnyString())
+	checksClient.VerifyWasCalledOnce().UpdateCheckRun(repo, pullModel, "atlantis/plan: envs/prod (default)", models.SuccessCommitStatus, "Plan Success",
+		"Plan: 1 to add, 0 to change, 0 to destroy.",
+		"```diff\n+ null_resource.test\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n```\n\n* To **apply** this plan, comment:\n  * `atlantis apply -d envs/prod`")
+	checksClient.VerifyWasCalledOnce().UpdateCheckRun(repo, pullModel, "atlantis/plan: . (staging)", models.FailedCommitStatus, "Plan Failed",
+		"**Plan Error**",
+		"```\nerr\n```")
+}
+
+// Check runs should only be used for repos on the GitHub host ChecksClient is
+// for.
+func TestUpdateProjectResult_ChecksClientOtherHost(t *testing.T) {
+	cases := []struct {
+		description string
+		host        models.VCSHost
+	}{
+		{"gitlab", models.VCSHost{Type: models.Gitlab, Hostname: "github.com"}},
+		{"other github host", models.VCSHost{Type: models.Github, Hostname: "github.example.com"}},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			repo := models.Repo{VCSHost: c.host}
+			ctx := &events.CommandContext{
+				BaseRepo: repo,
+				Pull:     pullModel,
+			}
+			client := mocks.NewMockClientProxy()
+			checksClient := mocks.NewMockGithubChecksClient()
+			s := events.DefaultCommitStatusUpdater{Client: client, ChecksClient: checksClient, ChecksHostname: "github.com"}
+			err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{
+				ProjectResults: []events.ProjectResult{{RepoRelDir: ".", Workspace: "default"}},
+			})
+			Ok(t, err)
+			client.VerifyWasCalledOnce().UpdateStatus(repo, pullModel, models.SuccessCommitStatus, "Atlantis", "Plan Success")
+			client.VerifyWasCalledOnce().UpdateStatus(repo, pullModel, models.SuccessCommitStatus, "atlantis/plan: . (default)", "Plan Success")
+			checksClient.VerifyWasCalled(Never()).UpdateCheckRun(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString(), matchers.AnyVcsCommitStatus(), AnyString(), AnyString(), AnyString())
+		})
+	}
+}
+
+func TestUpdateProjectResult_ProjectStatuses(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := &events.CommandContext{
+		BaseRepo: repoModel,
+		Pull:     pullModel,
+	}
+	client := mocks.NewMockClientProxy()
+	s := events.DefaultCommitStatusUpdater{Client: client}
+	err := s.UpdateProjectResult(ctx, events.ApplyCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{RepoRelDir: "envs/prod", Workspace: "default", ApplySuccess: "success"},
+			{RepoRelDir: "envs/staging", Workspace: "default", Failure: "failure"},
+		},
+	})
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "Atlantis", "Apply Failed")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/apply: envs/prod (default)", "Apply Success")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.FailedCommitStatus, "atlantis/apply: envs/staging (default)", "Apply Failed")
+}
+
+func TestCleanupStaleProjects(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := &events.CommandContext{
+		BaseRepo: repoModel,
+		Pull:     pullModel,
+	}
+	client := mocks.NewMockClientProxy()
+	s := events.DefaultCommitStatusUpdater{Client: client}
+	err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{RepoRelDir: "envs/prod", Workspace: "default"},
+			{RepoRelDir: "envs/staging", Workspace: "default"},
+		},
+	})
+	Ok(t, err)
+
+	// A later autoplan only affects envs/prod.
+	err = s.CleanupStaleProjects(ctx, events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{RepoRelDir: "envs/prod", Workspace: "default"},
+		},
+	})
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: envs/staging (default)", "No longer affected by this pull request")
+	client.VerifyWasCalled(Never()).UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: envs/prod (default)", "No longer affected by this pull request")
+
+	// The stale status should only be cleaned up once.
+	err = s.CleanupStaleProjects(ctx, events.CommandResult{})
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: envs/staging (default)", "No longer affected by this pull request")
+	client.VerifyWasCalledOnce().UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: envs/prod (default)", "No longer affected by this pull request")
+}
+
+func TestForgetPull(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := &events.CommandContext{
+		BaseRepo: repoModel,
+		Pull:     pullModel,
+	}
+	client := mocks.NewMockClientProxy()
+	s := events.DefaultCommitStatusUpdater{Client: client}
+	err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{{RepoRelDir: ".", Workspace: "default"}},
+	})
+	Ok(t, err)
+
+	// Once the pull is forgotten there's nothing to clean up.
+	s.ForgetPull(repoModel, pullModel)
+	err = s.CleanupStaleProjects(ctx, events.CommandResult{})
+	Ok(t, err)
+	client.VerifyWasCalled(Never()).UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: . (default)", "No longer affected by this pull request")
+}
+
+func TestCleanupStaleProjects_Error(t *testing.T) {
+	RegisterMockTestingT(t)
+	ctx := &events.CommandContext{
+		BaseRepo: repoModel,
+		Pull:     pullModel,
+	}
+	client := mocks.NewMockClientProxy()
+	s := events.DefaultCommitStatusUpdater{Client: client}
+	err := s.UpdateProjectResult(ctx, events.PlanCommand, events.CommandResult{
+		ProjectResults: []events.ProjectResult{{RepoRelDir: ".", Workspace: "default"}},
+	})
+	Ok(t, err)
+
+	// If autoplan errored we don't know which projects are affected.
+	err = s.CleanupStaleProjects(ctx, events.CommandResult{Error: errors.New("err")})
+	Ok(t, err)
+	client.VerifyWasCalled(Never()).UpdateStatus(repoModel, pullModel, models.SuccessCommitStatus, "atlantis/plan: . (default)", "No longer affected by this pull request")
+}
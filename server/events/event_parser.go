@@ -16,12 +16,16 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"path"
 	"strings"
 
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/cloudposse/atlantis/server/events/vcs/azuredevops"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/cloudposse/atlantis/server/events/vcs/gitea"
 	"github.com/google/go-github/github"
 	"github.com/lkysow/go-gitlab"
 	"github.com/pkg/errors"
@@ -234,17 +238,72 @@ type EventParsing interface {
 	// GetBitbucketServerPullEventType returns the type of the pull request
 	// event given the Bitbucket Server header.
 	GetBitbucketServerPullEventType(eventTypeHeader string) models.PullRequestEventType
+
+	// ParseGiteaPullEvent parses Gitea pull request events.
+	// pull is the parsed pull request.
+	// pullEventType is the type of event, for example opened/closed.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	// user is the user that triggered the event.
+	ParseGiteaPullEvent(event *gitea.PullRequestEvent) (
+		pull models.PullRequest, pullEventType models.PullRequestEventType,
+		baseRepo models.Repo, headRepo models.Repo, user models.User, err error)
+
+	// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+	// baseRepo is the repo that the pull request will be merged into.
+	// user is the user that made the comment.
+	// pullNum is the number of the pull request that triggered the webhook.
+	ParseGiteaIssueCommentEvent(event *gitea.IssueCommentEvent) (
+		baseRepo models.Repo, user models.User, pullNum int, err error)
+
+	// ParseGiteaPull parses the response from the Gitea API endpoint (not
+	// from a webhook) that returns a pull request.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	ParseGiteaPull(giteaPull *gitea.PullRequest) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
+
+	// ParseAzureDevopsPullEvent parses a pull request service hook event from
+	// Azure DevOps.
+	// pull is the parsed pull request.
+	// pullEventType is the type of event, for example opened/closed.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	// user is the pull request author.
+	ParseAzureDevopsPullEvent(body []byte) (
+		pull models.PullRequest, pullEventType models.PullRequestEventType,
+		baseRepo models.Repo, headRepo models.Repo, user models.User, err error)
+
+	// ParseAzureDevopsPullCommentEvent parses a pull request comment service
+	// hook event from Azure DevOps.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	// user is the user that commented.
+	// comment is the contents of the comment.
+	ParseAzureDevopsPullCommentEvent(body []byte) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo,
+		user models.User, comment string, err error)
 }
 
 // EventParser parses VCS events.
 type EventParser struct {
-	GithubUser         string
-	GithubToken        string
+	GithubUser  string
+	GithubToken string
+	// GithubCredentials is used instead of GithubUser and GithubToken to
+	// build clone urls if set. It's required when running as a GitHub App
+	// because installation tokens differ per owner and expire.
+	GithubCredentials  vcs.GithubCredentials
 	GitlabUser         string
 	GitlabToken        string
 	BitbucketUser      string
 	BitbucketToken     string
 	BitbucketServerURL string
+	GiteaUser          string
+	GiteaToken         string
+	AzureDevopsUser    string
+	AzureDevopsToken   string
 }
 
 // GetBitbucketCloudPullEventType returns the type of the pull request
@@ -463,7 +522,17 @@ func (e *EventParser) ParseGithubPull(pull *github.PullRequest) (pullModel model
 // returns a repo into the Atlantis model.
 // See EventParsing for return value docs.
 func (e *EventParser) ParseGithubRepo(ghRepo *github.Repository) (models.Repo, error) {
-	return models.NewRepo(models.Github, ghRepo.GetFullName(), ghRepo.GetCloneURL(), e.GithubUser, e.GithubToken)
+	user, token := e.GithubUser, e.GithubToken
+	if e.GithubCredentials != nil {
+		var err error
+		owner, _ := models.SplitRepoFullName(ghRepo.GetFullName())
+		user = e.GithubCredentials.GetUser()
+		token, err = e.GithubCredentials.GetToken(owner)
+		if err != nil {
+			return models.Repo{}, errors.Wrap(err, "getting GitHub token")
+		}
+	}
+	return models.NewRepo(models.Github, ghRepo.GetFullName(), ghRepo.GetCloneURL(), user, token)
 }
 
 // ParseGitlabMergeRequestEvent parses GitLab merge request events.
@@ -660,3 +729,200 @@ func (e *EventParser) ParseBitbucketServerPullEvent(body []byte) (pull models.Pu
 	pull, baseRepo, headRepo, user, err = e.parseCommonBitbucketServerEventData(event.CommonEventData)
 	return
 }
+
+// ParseGiteaPullEvent parses Gitea pull request events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPullEvent(event *gitea.PullRequestEvent) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
+	if err = validator.New().Struct(event); err != nil {
+		err = errors.Wrap(err, "event was missing fields")
+		return
+	}
+	pull, baseRepo, headRepo, err = e.ParseGiteaPull(event.PullRequest)
+	if err != nil {
+		return
+	}
+	switch *event.Action {
+	case gitea.PullOpenedAction, gitea.PullReopenedAction:
+		pullEventType = models.OpenedPullEvent
+	case gitea.PullSynchronizedAction:
+		pullEventType = models.UpdatedPullEvent
+	case gitea.PullClosedAction:
+		pullEventType = models.ClosedPullEvent
+	default:
+		pullEventType = models.OtherPullEvent
+	}
+	user = models.User{Username: *event.Sender.Login}
+	return
+}
+
+// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaIssueCommentEvent(event *gitea.IssueCommentEvent) (baseRepo models.Repo, user models.User, pullNum int, err error) {
+	if err = validator.New().Struct(event); err != nil {
+		err = errors.Wrap(err, "event was missing fields")
+		return
+	}
+	baseRepo, err = e.parseGiteaRepo(event.Repository)
+	if err != nil {
+		return
+	}
+	user = models.User{Username: *event.Sender.Login}
+	pullNum = *event.Issue.Number
+	return
+}
+
+// ParseGiteaPull parses the response from the Gitea API endpoint (not
+// from a webhook) that returns a pull request.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPull(pull *gitea.PullRequest) (pullModel models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	if err = validator.New().Struct(pull); err != nil {
+		err = errors.Wrap(err, "pull request was missing fields")
+		return
+	}
+	baseRepo, err = e.parseGiteaRepo(pull.Base.Repository)
+	if err != nil {
+		return
+	}
+	headRepo, err = e.parseGiteaRepo(pull.Head.Repository)
+	if err != nil {
+		return
+	}
+
+	pullState := models.ClosedPullState
+	if *pull.State == "open" {
+		pullState = models.OpenPullState
+	}
+
+	pullModel = models.PullRequest{
+		Author:     *pull.User.Login,
+		Branch:     *pull.Head.Ref,
+		HeadCommit: *pull.Head.SHA,
+		URL:        *pull.HTMLURL,
+		Num:        *pull.Number,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+	}
+	return
+}
+
+func (e *EventParser) parseGiteaRepo(repo *gitea.Repository) (models.Repo, error) {
+	return models.NewRepo(models.Gitea, *repo.FullName, *repo.CloneURL, e.GiteaUser, e.GiteaToken)
+}
+
+// ParseAzureDevopsPullEvent parses a pull request service hook event from
+// Azure DevOps.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseAzureDevopsPullEvent(body []byte) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
+	var event azuredevops.PullRequestEvent
+	if err = json.Unmarshal(body, &event); err != nil {
+		err = errors.Wrap(err, "parsing json")
+		return
+	}
+	if err = validator.New().Struct(event); err != nil {
+		err = errors.Wrapf(err, "API response %q was missing fields", string(body))
+		return
+	}
+	pull, baseRepo, headRepo, err = e.parseAzureDevopsPull(event.Resource)
+	if err != nil {
+		return
+	}
+
+	switch *event.EventType {
+	case azuredevops.PullCreatedEvent:
+		pullEventType = models.OpenedPullEvent
+	case azuredevops.PullMergedEvent:
+		pullEventType = models.ClosedPullEvent
+	case azuredevops.PullUpdatedEvent:
+		// Azure DevOps sends updated events for everything from new commits
+		// to votes and status changes so we need to look at the pull request
+		// and message to figure out what happened.
+		switch {
+		case pull.State == models.ClosedPullState:
+			pullEventType = models.ClosedPullEvent
+		case event.Message != nil && strings.Contains(event.Message.Text, azuredevops.SourceBranchUpdatedText):
+			pullEventType = models.UpdatedPullEvent
+		default:
+			pullEventType = models.OtherPullEvent
+		}
+	default:
+		pullEventType = models.OtherPullEvent
+	}
+	user = models.User{Username: pull.Author}
+	return
+}
+
+// ParseAzureDevopsPullCommentEvent parses a pull request comment service
+// hook event from Azure DevOps.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseAzureDevopsPullCommentEvent(body []byte) (pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, user models.User, comment string, err error) {
+	var event azuredevops.CommentEvent
+	if err = json.Unmarshal(body, &event); err != nil {
+		err = errors.Wrap(err, "parsing json")
+		return
+	}
+	if err = validator.New().Struct(event); err != nil {
+		err = errors.Wrapf(err, "API response %q was missing fields", string(body))
+		return
+	}
+	pull, baseRepo, headRepo, err = e.parseAzureDevopsPull(event.Resource.PullRequest)
+	if err != nil {
+		return
+	}
+	user = models.User{Username: event.Resource.Comment.Author.UniqueName}
+	comment = *event.Resource.Comment.Content
+	return
+}
+
+func (e *EventParser) parseAzureDevopsPull(pull *azuredevops.PullRequest) (pullModel models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	var pullState models.PullRequestState
+	switch *pull.Status {
+	case azuredevops.PullActiveStatus:
+		pullState = models.OpenPullState
+	case azuredevops.PullCompletedStatus, azuredevops.PullAbandonedStatus:
+		pullState = models.ClosedPullState
+	default:
+		err = fmt.Errorf("unable to determine pull request state from %q–this is a bug", *pull.Status)
+		return
+	}
+
+	baseRepo, err = e.parseAzureDevopsRepo(pull.Repository)
+	if err != nil {
+		return
+	}
+	// If the pull request is from a fork, the source branch lives in the fork.
+	headRepo = baseRepo
+	if pull.ForkSource != nil && pull.ForkSource.Repository != nil {
+		headRepo, err = e.parseAzureDevopsRepo(pull.ForkSource.Repository)
+		if err != nil {
+			return
+		}
+	}
+
+	pullModel = models.PullRequest{
+		Num:        *pull.PullRequestID,
+		HeadCommit: *pull.LastMergeSourceCommit.CommitID,
+		URL:        fmt.Sprintf("%s/pullrequest/%d", baseRepo.SanitizedCloneURL, *pull.PullRequestID),
+		Branch:     strings.TrimPrefix(*pull.SourceRefName, "refs/heads/"),
+		Author:     pull.CreatedBy.UniqueName,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+	}
+	return
+}
+
+// parseAzureDevopsRepo builds the repo from its remote url,
+// ex. https://org@dev.azure.com/org/project/_git/repo. The full name is
+// org/project/repo.
+func (e *EventParser) parseAzureDevopsRepo(repo *azuredevops.Repository) (models.Repo, error) {
+	remoteURL, err := url.Parse(*repo.RemoteURL)
+	if err != nil {
+		return models.Repo{}, errors.Wrapf(err, "parsing remote url %q", *repo.RemoteURL)
+	}
+	gitIdx := strings.LastIndex(remoteURL.Path, "/_git/")
+	if gitIdx == -1 {
+		return models.Repo{}, fmt.Errorf("remote url %q did not contain /_git/", *repo.RemoteURL)
+	}
+	owner := strings.Trim(remoteURL.Path[:gitIdx], "/")
+	fullName := fmt.Sprintf("%s/%s", owner, *repo.Name)
+	return models.NewRepo(models.AzureDevops, fullName, *repo.RemoteURL, e.AzureDevopsUser, e.AzureDevopsToken)
+}
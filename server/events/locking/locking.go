@@ -135,6 +135,12 @@ func (c *Client) GetLock(key string) (*models.ProjectLock, error) {
 }
 
 func (c *Client) key(p models.Project, workspace string) string {
+	return GenerateLockKey(p, workspace)
+}
+
+// GenerateLockKey returns the key of the lock for project p in workspace.
+// It's the id used in the URLs of locks.
+func GenerateLockKey(p models.Project, workspace string) string {
 	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
 }
 
@@ -25,8 +25,10 @@ import (
 )
 
 const (
-	planCommandTitle  = "Plan"
-	applyCommandTitle = "Apply"
+	planCommandTitle            = "Plan"
+	applyCommandTitle           = "Apply"
+	policyCheckCommandTitle     = "Policy Check"
+	approvePoliciesCommandTitle = "Approve Policies"
 	// maxUnwrappedLines is the maximum number of lines the Terraform output
 	// can be before we wrap it in an expandable template.
 	maxUnwrappedLines = 12
@@ -83,7 +85,7 @@ type projectResultTmplData struct {
 // Render formats the data into a markdown string.
 // nolint: interfacer
 func (m *MarkdownRenderer) Render(res CommandResult, cmdName CommandName, log string, verbose bool, vcsHost models.VCSHostType) string {
-	commandStr := strings.Title(cmdName.String())
+	commandStr := cmdName.TitleString()
 	common := CommonData{commandStr, verbose, log}
 	if res.Error != nil {
 		return m.renderTemplate(unwrappedErrWithLogTmpl, ErrData{res.Error.Error(), common})
@@ -137,6 +139,10 @@ func (m *MarkdownRenderer) renderProjectResults(results []ProjectResult, common
 				resultData.Rendered = m.renderTemplate(planSuccessUnwrappedTmpl, *result.PlanSuccess)
 			}
 			numPlanSuccesses++
+		} else if result.PolicyCheckSuccess != nil {
+			resultData.Rendered = m.renderTemplate(policyCheckSuccessTmpl, *result.PolicyCheckSuccess)
+		} else if result.ApprovePoliciesSuccess != "" {
+			resultData.Rendered = result.ApprovePoliciesSuccess
 		} else if result.ApplySuccess != "" {
 			if m.shouldUseWrappedTmpl(vcsHost, result.ApplySuccess) {
 				resultData.Rendered = m.renderTemplate(applyWrappedSuccessTmpl, struct{ Output string }{result.ApplySuccess})
@@ -161,11 +167,11 @@ func (m *MarkdownRenderer) renderProjectResults(results []ProjectResult, common
 		tmpl = singleProjectPlanSuccessTmpl
 	case len(resultsTmplData) == 1 && common.Command == planCommandTitle && numPlanSuccesses == 0:
 		tmpl = singleProjectPlanUnsuccessfulTmpl
-	case len(resultsTmplData) == 1 && common.Command == applyCommandTitle:
+	case len(resultsTmplData) == 1 && (common.Command == applyCommandTitle || common.Command == policyCheckCommandTitle || common.Command == approvePoliciesCommandTitle):
 		tmpl = singleProjectApplyTmpl
 	case common.Command == planCommandTitle:
 		tmpl = multiProjectPlanTmpl
-	case common.Command == applyCommandTitle:
+	case common.Command == applyCommandTitle || common.Command == policyCheckCommandTitle || common.Command == approvePoliciesCommandTitle:
 		tmpl = multiProjectApplyTmpl
 	default:
 		return "no template matched–this is a bug"
@@ -237,18 +243,33 @@ var multiProjectApplyTmpl = template.Must(template.New("").Funcs(sprig.TxtFuncMa
 		"{{$result.Rendered}}\n\n" +
 		"---\n{{end}}" +
 		logTmpl))
-var planSuccessUnwrappedTmpl = template.Must(template.New("").Parse(
-	"```diff\n" +
+var planSuccessUnwrappedTmpl = template.Must(template.New("").Funcs(sprig.TxtFuncMap()).Parse(
+	planSummaryTmpl + planTerraformVersion +
+		"```diff\n" +
 		"{{.TerraformOutput}}\n" +
 		"```\n\n" + planNextSteps))
-var planSuccessWrappedTmpl = template.Must(template.New("").Parse(
-	"<details><summary>Show Output</summary>\n\n" +
+var planSuccessWrappedTmpl = template.Must(template.New("").Funcs(sprig.TxtFuncMap()).Parse(
+	planSummaryTmpl + planTerraformVersion +
+		"<details><summary>Show Output</summary>\n\n" +
 		"```diff\n" +
 		"{{.TerraformOutput}}\n" +
 		"```\n\n" +
 		planNextSteps + "\n" +
 		"</details>"))
 
+// planSummaryTmpl is a table of the resources the plan will change if it was
+// summarized from `terraform show -json`.
+var planSummaryTmpl = "{{with .PlanSummary}}" +
+	"| Action | Count | Resources |\n" +
+	"|--------|-------|-----------|\n" +
+	"{{range $action := list \"create\" \"update\" \"delete\" \"replace\"}}" +
+	"| {{$action}} | {{len ($.PlanSummary.Addresses $action)}} | {{range $i, $address := $.PlanSummary.Addresses $action}}{{if $i}}, {{end}}`{{$address}}`{{end}} |\n" +
+	"{{end}}\n{{end}}"
+
+// planTerraformVersion says which version of terraform was used if it was
+// resolved for the project.
+var planTerraformVersion = "{{if .TerraformVersion}}Ran with Terraform `{{.TerraformVersion}}`.\n\n{{end}}"
+
 // planNextSteps are instructions appended after successful plans as to what
 // to do next.
 var planNextSteps = "* :arrow_forward: To **apply** this plan, comment:\n" +
@@ -257,6 +278,23 @@ var planNextSteps = "* :arrow_forward: To **apply** this plan, comment:\n" +
 	"    * `{{.DestroyCmd}}`\n" +
 	"* :repeat: To **plan** this project again, comment:\n" +
 	"    * `{{.RePlanCmd}}`"
+
+// policyCheckSuccessTmpl lists whether the plan passed each policy set and
+// the violations of the ones it failed.
+var policyCheckSuccessTmpl = template.Must(template.New("").Parse(
+	"{{range .Results}}" +
+		"{{if .Passed}}:white_check_mark:{{else}}:x:{{end}} **{{.PolicySet}}**\n" +
+		"{{range .Violations}}  * {{.}}\n{{end}}" +
+		"{{end}}\n" +
+		"{{if .Passed}}" +
+		"* :arrow_forward: To **apply** this plan, comment:\n" +
+		"    * `{{.ApplyCmd}}`" +
+		"{{else}}" +
+		"* :heavy_check_mark: To **approve** this plan anyway, a policy approver can comment:\n" +
+		"    * `{{.ApprovePoliciesCmd}}`\n" +
+		"* :repeat: Or fix the violations and **plan** again:\n" +
+		"    * `{{.RePlanCmd}}`" +
+		"{{end}}"))
 var applyUnwrappedSuccessTmpl = template.Must(template.New("").Parse(
 	"```diff\n" +
 		"{{.Output}}\n" +
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cloudposse/atlantis/server/events"
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/policy"
 	. "github.com/cloudposse/atlantis/testing"
 )
 
@@ -828,6 +829,180 @@ $$$
 	}
 }
 
+func TestRenderProjectResults_PlanTerraformVersion(t *testing.T) {
+	mr := events.MarkdownRenderer{}
+	rendered := mr.Render(events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{
+				RepoRelDir: ".",
+				Workspace:  "default",
+				PlanSuccess: &events.PlanSuccess{
+					TerraformOutput:  "terraform-output",
+					LockURL:          "lock-url",
+					RePlanCmd:        "atlantis plan -d .",
+					ApplyCmd:         "atlantis apply -d .",
+					DestroyCmd:       "atlantis destroy -d .",
+					TerraformVersion: "0.11.14",
+				},
+			},
+		},
+	}, events.PlanCommand, "log", false, models.Github)
+	exp := `Ran Plan in dir: $.$ workspace: $default$
+
+Ran with Terraform $0.11.14$.
+
+$$$diff
+terraform-output
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+    * $atlantis apply -d .$
+* :put_litter_in_its_place: To **destroy** this plan, comment:
+    * $atlantis destroy -d .$
+* :repeat: To **plan** this project again, comment:
+    * $atlantis plan -d .$
+
+---
+* :fast_forward: To **apply** all unapplied plans from this pull request, comment:
+    * $atlantis apply -d .$
+`
+	expWithBackticks := strings.Replace(exp, "$", "`", -1)
+	Equals(t, expWithBackticks, rendered)
+}
+
+func TestRenderProjectResults_PlanSummary(t *testing.T) {
+	mr := events.MarkdownRenderer{}
+	rendered := mr.Render(events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{
+				RepoRelDir: ".",
+				Workspace:  "default",
+				PlanSuccess: &events.PlanSuccess{
+					TerraformOutput: "terraform-output",
+					LockURL:         "lock-url",
+					RePlanCmd:       "atlantis plan -d .",
+					ApplyCmd:        "atlantis apply -d .",
+					DestroyCmd:      "atlantis destroy -d .",
+					PlanSummary: &models.PlanSummary{
+						Create:  2,
+						Replace: 1,
+						Resources: []models.PlanResourceChange{
+							{Address: "aws_eip.a", Action: models.PlanActionCreate},
+							{Address: "aws_instance.web", Action: models.PlanActionReplace},
+							{Address: "aws_eip.b", Action: models.PlanActionCreate},
+						},
+					},
+				},
+			},
+		},
+	}, events.PlanCommand, "log", false, models.Github)
+	exp := `Ran Plan in dir: $.$ workspace: $default$
+
+| Action | Count | Resources |
+|--------|-------|-----------|
+| create | 2 | $aws_eip.a$, $aws_eip.b$ |
+| update | 0 |  |
+| delete | 0 |  |
+| replace | 1 | $aws_instance.web$ |
+
+$$$diff
+terraform-output
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+    * $atlantis apply -d .$
+* :put_litter_in_its_place: To **destroy** this plan, comment:
+    * $atlantis destroy -d .$
+* :repeat: To **plan** this project again, comment:
+    * $atlantis plan -d .$
+
+---
+* :fast_forward: To **apply** all unapplied plans from this pull request, comment:
+    * $atlantis apply -d .$
+`
+	expWithBackticks := strings.Replace(exp, "$", "`", -1)
+	Equals(t, expWithBackticks, rendered)
+}
+
+func TestRenderProjectResults_PolicyCheck(t *testing.T) {
+	mr := events.MarkdownRenderer{}
+	rendered := mr.Render(events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{
+				RepoRelDir: "passed",
+				Workspace:  "default",
+				PolicyCheckSuccess: &events.PolicyCheckSuccess{
+					Results:            []policy.Result{{PolicySet: "s3"}},
+					ApplyCmd:           "atlantis apply -d passed",
+					RePlanCmd:          "atlantis plan -d passed",
+					ApprovePoliciesCmd: "atlantis approve_policies -d passed",
+				},
+			},
+			{
+				RepoRelDir: "failed",
+				Workspace:  "default",
+				PolicyCheckSuccess: &events.PolicyCheckSuccess{
+					Results: []policy.Result{
+						{PolicySet: "s3"},
+						{PolicySet: "instance-types", Violations: []string{"aws_instance.a is too big", "aws_instance.b is too big"}},
+					},
+					ApplyCmd:           "atlantis apply -d failed",
+					RePlanCmd:          "atlantis plan -d failed",
+					ApprovePoliciesCmd: "atlantis approve_policies -d failed",
+				},
+			},
+		},
+	}, events.PolicyCheckCommand, "log", false, models.Github)
+	exp := `Ran Policy Check for 2 projects:
+1. workspace: $default$ dir: $passed$
+1. workspace: $default$ dir: $failed$
+
+### 1. workspace: $default$ dir: $passed$
+:white_check_mark: **s3**
+
+* :arrow_forward: To **apply** this plan, comment:
+    * $atlantis apply -d passed$
+
+---
+### 2. workspace: $default$ dir: $failed$
+:white_check_mark: **s3**
+:x: **instance-types**
+  * aws_instance.a is too big
+  * aws_instance.b is too big
+
+* :heavy_check_mark: To **approve** this plan anyway, a policy approver can comment:
+    * $atlantis approve_policies -d failed$
+* :repeat: Or fix the violations and **plan** again:
+    * $atlantis plan -d failed$
+
+---
+
+`
+	expWithBackticks := strings.Replace(exp, "$", "`", -1)
+	Equals(t, expWithBackticks, rendered)
+}
+
+func TestRenderProjectResults_ApprovePolicies(t *testing.T) {
+	mr := events.MarkdownRenderer{}
+	rendered := mr.Render(events.CommandResult{
+		ProjectResults: []events.ProjectResult{
+			{
+				RepoRelDir:             ".",
+				Workspace:              "default",
+				ApprovePoliciesSuccess: "Policies approved by @approver. To **apply** this plan, comment:\n* `atlantis apply -d .`",
+			},
+		},
+	}, events.ApprovePoliciesCommand, "log", false, models.Github)
+	exp := `Ran Approve Policies in dir: $.$ workspace: $default$
+
+Policies approved by @approver. To **apply** this plan, comment:
+* $atlantis apply -d .$
+
+`
+	expWithBackticks := strings.Replace(exp, "$", "`", -1)
+	Equals(t, expWithBackticks, rendered)
+}
+
 func TestRenderProjectResults_MultiProjectApplyWrapped(t *testing.T) {
 	mr := events.MarkdownRenderer{}
 	tfOut := strings.Repeat("line\n", 13)
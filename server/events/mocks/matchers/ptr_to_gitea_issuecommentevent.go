@@ -0,0 +1,20 @@
+package matchers
+
+import (
+	"reflect"
+
+	gitea "github.com/cloudposse/atlantis/server/events/vcs/gitea"
+	"github.com/petergtz/pegomock"
+)
+
+func AnyPtrToGiteaIssueCommentEvent() *gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.IssueCommentEvent))(nil)).Elem()))
+	var nullValue *gitea.IssueCommentEvent
+	return nullValue
+}
+
+func EqPtrToGiteaIssueCommentEvent(value *gitea.IssueCommentEvent) *gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.IssueCommentEvent
+	return nullValue
+}
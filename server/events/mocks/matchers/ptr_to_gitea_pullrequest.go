@@ -0,0 +1,20 @@
+package matchers
+
+import (
+	"reflect"
+
+	gitea "github.com/cloudposse/atlantis/server/events/vcs/gitea"
+	"github.com/petergtz/pegomock"
+)
+
+func AnyPtrToGiteaPullRequest() *gitea.PullRequest {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.PullRequest))(nil)).Elem()))
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
+
+func EqPtrToGiteaPullRequest(value *gitea.PullRequest) *gitea.PullRequest {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
@@ -0,0 +1,20 @@
+package matchers
+
+import (
+	"reflect"
+
+	gitea "github.com/cloudposse/atlantis/server/events/vcs/gitea"
+	"github.com/petergtz/pegomock"
+)
+
+func AnyPtrToGiteaPullRequestEvent() *gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.PullRequestEvent))(nil)).Elem()))
+	var nullValue *gitea.PullRequestEvent
+	return nullValue
+}
+
+func EqPtrToGiteaPullRequestEvent(value *gitea.PullRequestEvent) *gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.PullRequestEvent
+	return nullValue
+}
@@ -0,0 +1,20 @@
+package matchers
+
+import (
+	"reflect"
+
+	valid "github.com/cloudposse/atlantis/server/events/yaml/valid"
+	"github.com/petergtz/pegomock"
+)
+
+func AnyPtrToValidProject() *valid.Project {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*valid.Project))(nil)).Elem()))
+	var nullValue *valid.Project
+	return nullValue
+}
+
+func EqPtrToValidProject(value *valid.Project) *valid.Project {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *valid.Project
+	return nullValue
+}
@@ -43,6 +43,23 @@ func (mock *MockCommitStatusUpdater) UpdateProjectResult(ctx *events.CommandCont
 	return ret0
 }
 
+func (mock *MockCommitStatusUpdater) CleanupStaleProjects(ctx *events.CommandContext, res events.CommandResult) error {
+	params := []pegomock.Param{ctx, res}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("CleanupStaleProjects", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockCommitStatusUpdater) ForgetPull(repo models.Repo, pull models.PullRequest) {
+	params := []pegomock.Param{repo, pull}
+	pegomock.GetGenericMockFrom(mock).Invoke("ForgetPull", params, []reflect.Type{})
+}
+
 func (mock *MockCommitStatusUpdater) VerifyWasCalledOnce() *VerifierCommitStatusUpdater {
 	return &VerifierCommitStatusUpdater{mock, pegomock.Times(1), nil}
 }
@@ -134,3 +151,65 @@ func (c *CommitStatusUpdater_UpdateProjectResult_OngoingVerification) GetAllCapt
 	}
 	return
 }
+
+func (verifier *VerifierCommitStatusUpdater) CleanupStaleProjects(ctx *events.CommandContext, res events.CommandResult) *CommitStatusUpdater_CleanupStaleProjects_OngoingVerification {
+	params := []pegomock.Param{ctx, res}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "CleanupStaleProjects", params)
+	return &CommitStatusUpdater_CleanupStaleProjects_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type CommitStatusUpdater_CleanupStaleProjects_OngoingVerification struct {
+	mock              *MockCommitStatusUpdater
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *CommitStatusUpdater_CleanupStaleProjects_OngoingVerification) GetCapturedArguments() (*events.CommandContext, events.CommandResult) {
+	ctx, res := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1], res[len(res)-1]
+}
+
+func (c *CommitStatusUpdater_CleanupStaleProjects_OngoingVerification) GetAllCapturedArguments() (_param0 []*events.CommandContext, _param1 []events.CommandResult) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]*events.CommandContext, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(*events.CommandContext)
+		}
+		_param1 = make([]events.CommandResult, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(events.CommandResult)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierCommitStatusUpdater) ForgetPull(repo models.Repo, pull models.PullRequest) *CommitStatusUpdater_ForgetPull_OngoingVerification {
+	params := []pegomock.Param{repo, pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ForgetPull", params)
+	return &CommitStatusUpdater_ForgetPull_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type CommitStatusUpdater_ForgetPull_OngoingVerification struct {
+	mock              *MockCommitStatusUpdater
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *CommitStatusUpdater_ForgetPull_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
+	repo, pull := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1]
+}
+
+func (c *CommitStatusUpdater_ForgetPull_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
@@ -19,8 +19,8 @@ func NewMockWebhooksSender() *MockWebhooksSender {
 	return &MockWebhooksSender{fail: pegomock.GlobalFailHandler}
 }
 
-func (mock *MockWebhooksSender) Send(log *logging.SimpleLogger, res webhooks.ApplyResult) error {
-	params := []pegomock.Param{log, res}
+func (mock *MockWebhooksSender) Send(log *logging.SimpleLogger, event webhooks.Event) error {
+	params := []pegomock.Param{log, event}
 	result := pegomock.GetGenericMockFrom(mock).Invoke("Send", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
 	var ret0 error
 	if len(result) != 0 {
@@ -49,8 +49,8 @@ type VerifierWebhooksSender struct {
 	inOrderContext         *pegomock.InOrderContext
 }
 
-func (verifier *VerifierWebhooksSender) Send(log *logging.SimpleLogger, res webhooks.ApplyResult) *WebhooksSender_Send_OngoingVerification {
-	params := []pegomock.Param{log, res}
+func (verifier *VerifierWebhooksSender) Send(log *logging.SimpleLogger, event webhooks.Event) *WebhooksSender_Send_OngoingVerification {
+	params := []pegomock.Param{log, event}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Send", params)
 	return &WebhooksSender_Send_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -60,21 +60,21 @@ type WebhooksSender_Send_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *WebhooksSender_Send_OngoingVerification) GetCapturedArguments() (*logging.SimpleLogger, webhooks.ApplyResult) {
-	log, res := c.GetAllCapturedArguments()
-	return log[len(log)-1], res[len(res)-1]
+func (c *WebhooksSender_Send_OngoingVerification) GetCapturedArguments() (*logging.SimpleLogger, webhooks.Event) {
+	log, event := c.GetAllCapturedArguments()
+	return log[len(log)-1], event[len(event)-1]
 }
 
-func (c *WebhooksSender_Send_OngoingVerification) GetAllCapturedArguments() (_param0 []*logging.SimpleLogger, _param1 []webhooks.ApplyResult) {
+func (c *WebhooksSender_Send_OngoingVerification) GetAllCapturedArguments() (_param0 []*logging.SimpleLogger, _param1 []webhooks.Event) {
 	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(params) > 0 {
 		_param0 = make([]*logging.SimpleLogger, len(params[0]))
 		for u, param := range params[0] {
 			_param0[u] = param.(*logging.SimpleLogger)
 		}
-		_param1 = make([]webhooks.ApplyResult, len(params[1]))
+		_param1 = make([]webhooks.Event, len(params[1]))
 		for u, param := range params[1] {
-			_param1[u] = param.(webhooks.ApplyResult)
+			_param1[u] = param.(webhooks.Event)
 		}
 	}
 	return
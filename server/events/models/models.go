@@ -25,6 +25,7 @@ import (
 
 	"github.com/cloudposse/atlantis/server/events/yaml/valid"
 	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
 )
 
@@ -62,7 +63,8 @@ func NewRepo(vcsHostType VCSHostType, repoFullName string, cloneURL string, vcsU
 		return Repo{}, errors.New("cloneURL can't be empty")
 	}
 
-	if !strings.HasSuffix(cloneURL, ".git") {
+	// Azure DevOps clone urls don't end in .git and adding it breaks them.
+	if !strings.HasSuffix(cloneURL, ".git") && vcsHostType != AzureDevops {
 		cloneURL += ".git"
 	}
 
@@ -71,12 +73,26 @@ func NewRepo(vcsHostType VCSHostType, repoFullName string, cloneURL string, vcsU
 		return Repo{}, errors.Wrap(err, "invalid clone url")
 	}
 
+	// Azure DevOps includes the organization as the user in its clone urls,
+	// ex. https://org@dev.azure.com/org/project/_git/repo. We remove it since
+	// we add our own credentials below.
+	if vcsHostType == AzureDevops && cloneURLParsed.User != nil {
+		cloneURLParsed.User = nil
+		cloneURL = cloneURLParsed.String()
+	}
+
 	// Ensure the Clone URL is for the same repo to avoid something malicious.
 	// We skip this check for Bitbucket Server because its format is different
 	// and because the caller in that case actually constructs the clone url
 	// from the repo name and so there's no point checking if they match.
 	if vcsHostType != BitbucketServer {
 		expClonePath := fmt.Sprintf("/%s.git", repoFullName)
+		if vcsHostType == AzureDevops {
+			// Azure DevOps repo full names are org/project/repo but the clone
+			// path is /org/project/_git/repo.
+			owner, repo := SplitRepoFullName(repoFullName)
+			expClonePath = fmt.Sprintf("/%s/_git/%s", owner, repo)
+		}
 		if expClonePath != cloneURLParsed.Path {
 			return Repo{}, fmt.Errorf("expected clone url to have path %q but had %q", expClonePath, cloneURLParsed.Path)
 		}
@@ -98,9 +114,9 @@ func NewRepo(vcsHostType VCSHostType, repoFullName string, cloneURL string, vcsU
 	if owner == "" || repo == "" {
 		return Repo{}, fmt.Errorf("invalid repo format %q, owner %q or repo %q was empty", repoFullName, owner, repo)
 	}
-	// Only GitLab repos can have /'s in their owners. This is for GitLab
-	// subgroups.
-	if strings.Contains(owner, "/") && vcsHostType != Gitlab {
+	// Only GitLab and Azure DevOps repos can have /'s in their owners. This is
+	// for GitLab subgroups and Azure DevOps org/project.
+	if strings.Contains(owner, "/") && vcsHostType != Gitlab && vcsHostType != AzureDevops {
 		return Repo{}, fmt.Errorf("invalid repo format %q, owner %q should not contain any /'s", repoFullName, owner)
 	}
 	if strings.Contains(repo, "/") {
@@ -227,6 +243,66 @@ type Plan struct {
 	LocalPath string
 }
 
+// PlanChanges is how many resources a plan will add, change and destroy.
+type PlanChanges struct {
+	Add     int
+	Change  int
+	Destroy int
+}
+
+// The actions of PlanResourceChange.
+const (
+	PlanActionCreate  = "create"
+	PlanActionUpdate  = "update"
+	PlanActionDelete  = "delete"
+	PlanActionReplace = "replace"
+)
+
+// PlanSummary is a structured summary of a plan built from the output of
+// `terraform show -json`.
+type PlanSummary struct {
+	// Create, Update, Delete and Replace are how many resources the plan will
+	// create, update in-place, delete and replace.
+	Create  int
+	Update  int
+	Delete  int
+	Replace int
+	// Resources are the resources the plan will change, in the order
+	// Terraform lists them. Resources that won't change aren't included.
+	Resources []PlanResourceChange
+}
+
+// PlanResourceChange is a change a plan will make to a resource.
+type PlanResourceChange struct {
+	// Address is the resource's address, ex. aws_instance.web[0].
+	Address string
+	// Action is one of PlanActionCreate, PlanActionUpdate, PlanActionDelete or
+	// PlanActionReplace.
+	Action string
+}
+
+// Addresses returns the addresses of the resources the plan will change with
+// action, ex. PlanActionCreate.
+func (p PlanSummary) Addresses(action string) []string {
+	var addresses []string
+	for _, r := range p.Resources {
+		if r.Action == action {
+			addresses = append(addresses, r.Address)
+		}
+	}
+	return addresses
+}
+
+// Changes returns the summary's counts the way Terraform's one line summary
+// counts them, where a replaced resource is both added and destroyed.
+func (p PlanSummary) Changes() PlanChanges {
+	return PlanChanges{
+		Add:     p.Create + p.Replace,
+		Change:  p.Update,
+		Destroy: p.Delete + p.Replace,
+	}
+}
+
 // NewProject constructs a Project. Use this constructor because it
 // sets Path correctly.
 func NewProject(repoFullName string, path string) Project {
@@ -257,6 +333,8 @@ const (
 	Gitlab
 	BitbucketCloud
 	BitbucketServer
+	Gitea
+	AzureDevops
 )
 
 func (h VCSHostType) String() string {
@@ -269,6 +347,10 @@ func (h VCSHostType) String() string {
 		return "BitbucketCloud"
 	case BitbucketServer:
 		return "BitbucketServer"
+	case Gitea:
+		return "Gitea"
+	case AzureDevops:
+		return "AzureDevops"
 	}
 	return "<missing String() implementation>"
 }
@@ -304,6 +386,22 @@ type ProjectCommandContext struct {
 	// DestroyCmd is the command that users should run to destroy this plan. If
 	// this is an apply then this will be empty.
 	DestroyCmd string
+	// ApprovePoliciesCmd is the command that policy approvers should run to
+	// approve this plan if it fails its policy checks.
+	ApprovePoliciesCmd string
+	// TerraformVersion is the version of terraform resolved for this project
+	// from its terraform_version constraint or its required_version. If nil,
+	// the version in ProjectConfig or the default version is used.
+	TerraformVersion *version.Version
+}
+
+// GetProjectName returns the name of the project from atlantis.yaml or an
+// empty string if it isn't named.
+func (p ProjectCommandContext) GetProjectName() string {
+	if p.ProjectConfig != nil {
+		return p.ProjectConfig.GetName()
+	}
+	return ""
 }
 
 // SplitRepoFullName splits a repo full name up into its owner and repo name
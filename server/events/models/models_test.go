@@ -140,6 +140,29 @@ func TestNewRepo_HTTPSAuth(t *testing.T) {
 	}, repo)
 }
 
+// Azure DevOps clone urls have an org/project/_git/repo path, contain the
+// organization as a user and don't end in .git.
+func TestNewRepo_AzureDevops(t *testing.T) {
+	repo, err := models.NewRepo(models.AzureDevops, "org/project/repo", "https://org@dev.azure.com/org/project/_git/repo", "u", "p")
+	Ok(t, err)
+	Equals(t, models.Repo{
+		VCSHost: models.VCSHost{
+			Hostname: "dev.azure.com",
+			Type:     models.AzureDevops,
+		},
+		SanitizedCloneURL: "https://dev.azure.com/org/project/_git/repo",
+		CloneURL:          "https://u:p@dev.azure.com/org/project/_git/repo",
+		FullName:          "org/project/repo",
+		Owner:             "org/project",
+		Name:              "repo",
+	}, repo)
+}
+
+func TestNewRepo_AzureDevopsWrongRepo(t *testing.T) {
+	_, err := models.NewRepo(models.AzureDevops, "org/project/repo", "https://dev.azure.com/org/project/_git/other", "u", "p")
+	ErrEquals(t, `expected clone url to have path "/org/project/_git/repo" but had "/org/project/_git/other"`, err)
+}
+
 func TestProject_String(t *testing.T) {
 	Equals(t, "repofullname=owner/repo path=my/path", (models.Project{
 		RepoFullName: "owner/repo",
@@ -195,6 +218,14 @@ func TestVCSHostType_ToString(t *testing.T) {
 			models.BitbucketServer,
 			"BitbucketServer",
 		},
+		{
+			models.Gitea,
+			"Gitea",
+		},
+		{
+			models.AzureDevops,
+			"AzureDevops",
+		},
 	}
 
 	for _, c := range cases {
@@ -255,3 +286,20 @@ func TestSplitRepoFullName(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanSummary(t *testing.T) {
+	summary := models.PlanSummary{
+		Create:  1,
+		Update:  1,
+		Replace: 2,
+		Resources: []models.PlanResourceChange{
+			{Address: "aws_instance.web[0]", Action: models.PlanActionReplace},
+			{Address: "aws_instance.web[1]", Action: models.PlanActionReplace},
+			{Address: "aws_security_group.web", Action: models.PlanActionUpdate},
+			{Address: "aws_eip.web", Action: models.PlanActionCreate},
+		},
+	}
+	Equals(t, models.PlanChanges{Add: 3, Change: 1, Destroy: 2}, summary.Changes())
+	Equals(t, []string{"aws_instance.web[0]", "aws_instance.web[1]"}, summary.Addresses(models.PlanActionReplace))
+	Equals(t, []string(nil), summary.Addresses(models.PlanActionDelete))
+}
@@ -2,6 +2,7 @@ package events
 
 import (
 	"io/ioutil"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -9,8 +10,19 @@ import (
 	"github.com/pkg/errors"
 )
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_pending_plan_finder.go PendingPlanFinder
+
 // PendingPlanFinder finds unapplied plans.
-type PendingPlanFinder struct{}
+type PendingPlanFinder interface {
+	// Find finds all pending plans in pullDir.
+	Find(pullDir string) ([]PendingPlan, error)
+	// DeletePlans deletes all pending plans in pullDir.
+	DeletePlans(pullDir string) error
+}
+
+// DefaultPendingPlanFinder implements PendingPlanFinder by looking for plan
+// files that git doesn't track.
+type DefaultPendingPlanFinder struct{}
 
 // PendingPlan is a plan that has not been applied.
 type PendingPlan struct {
@@ -27,12 +39,34 @@ type PendingPlan struct {
 // Find finds all pending plans in pullDir. pullDir should be the working
 // directory where Atlantis will operate on this pull request. It's one level
 // up from where Atlantis clones the repo for each workspace.
-func (p *PendingPlanFinder) Find(pullDir string) ([]PendingPlan, error) {
+func (p *DefaultPendingPlanFinder) Find(pullDir string) ([]PendingPlan, error) {
+	plans, _, err := p.findWithAbsPaths(pullDir)
+	return plans, err
+}
+
+// DeletePlans deletes all the plan files found by Find.
+func (p *DefaultPendingPlanFinder) DeletePlans(pullDir string) error {
+	_, absPaths, err := p.findWithAbsPaths(pullDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range absPaths {
+		if err := os.Remove(path); err != nil {
+			return errors.Wrapf(err, "deleting plan at %s", path)
+		}
+	}
+	return nil
+}
+
+// findWithAbsPaths returns the pending plans and the absolute paths to their
+// plan files.
+func (p *DefaultPendingPlanFinder) findWithAbsPaths(pullDir string) ([]PendingPlan, []string, error) {
 	workspaceDirs, err := ioutil.ReadDir(pullDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var plans []PendingPlan
+	var absPaths []string
 	for _, workspaceDir := range workspaceDirs {
 		workspace := workspaceDir.Name()
 		repoDir := filepath.Join(pullDir, workspace)
@@ -43,7 +77,7 @@ func (p *PendingPlanFinder) Find(pullDir string) ([]PendingPlan, error) {
 		lsCmd.Dir = repoDir
 		lsOut, err := lsCmd.CombinedOutput()
 		if err != nil {
-			return nil, errors.Wrapf(err, "running git ls-files . "+
+			return nil, nil, errors.Wrapf(err, "running git ls-files . "+
 				"--others: %s", string(lsOut))
 		}
 		for _, file := range strings.Split(string(lsOut), "\n") {
@@ -54,8 +88,9 @@ func (p *PendingPlanFinder) Find(pullDir string) ([]PendingPlan, error) {
 					RepoRelDir: repoRelDir,
 					Workspace:  workspace,
 				})
+				absPaths = append(absPaths, filepath.Join(repoDir, file))
 			}
 		}
 	}
-	return plans, nil
+	return plans, absPaths, nil
 }
@@ -1,6 +1,7 @@
 package events_test
 
 import (
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -12,7 +13,7 @@ import (
 
 // If the dir doesn't exist should get an error.
 func TestPendingPlanFinder_FindNoDir(t *testing.T) {
-	pf := &events.PendingPlanFinder{}
+	pf := &events.DefaultPendingPlanFinder{}
 	_, err := pf.Find("/doesntexist")
 	ErrEquals(t, "open /doesntexist: no such file or directory", err)
 }
@@ -140,7 +141,7 @@ func TestPendingPlanFinder_Find(t *testing.T) {
 		},
 	}
 
-	pf := &events.PendingPlanFinder{}
+	pf := &events.DefaultPendingPlanFinder{}
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
 			tmpDir, cleanup := DirStructure(t, c.files)
@@ -186,7 +187,38 @@ func TestPendingPlanFinder_FindPlanCheckedIn(t *testing.T) {
 	runCmd(t, repoDir, "git", "config", "--local", "user.name", "atlantisbot")
 	runCmd(t, repoDir, "git", "commit", "-m", "initial commit")
 
-	pf := &events.PendingPlanFinder{}
+	pf := &events.DefaultPendingPlanFinder{}
+	actPlans, err := pf.Find(tmpDir)
+	Ok(t, err)
+	Equals(t, 0, len(actPlans))
+}
+
+// DeletePlans should delete untracked planfiles but not checked in ones.
+func TestPendingPlanFinder_DeletePlans(t *testing.T) {
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"default": map[string]interface{}{
+			"default.tfplan": nil,
+			"dir1": map[string]interface{}{
+				"default.tfplan": nil,
+			},
+		},
+	})
+	defer cleanup()
+
+	repoDir := filepath.Join(tmpDir, "default")
+	runCmd(t, repoDir, "git", "init")
+	runCmd(t, repoDir, "git", "add", "default.tfplan")
+	runCmd(t, repoDir, "git", "config", "--local", "user.email", "atlantisbot@runatlantis.io")
+	runCmd(t, repoDir, "git", "config", "--local", "user.name", "atlantisbot")
+	runCmd(t, repoDir, "git", "commit", "-m", "initial commit")
+
+	pf := &events.DefaultPendingPlanFinder{}
+	Ok(t, pf.DeletePlans(tmpDir))
+
+	_, err := os.Stat(filepath.Join(repoDir, "dir1", "default.tfplan"))
+	Assert(t, os.IsNotExist(err), "untracked planfile should be deleted")
+	_, err = os.Stat(filepath.Join(repoDir, "default.tfplan"))
+	Ok(t, err)
 	actPlans, err := pf.Find(tmpDir)
 	Ok(t, err)
 	Equals(t, 0, len(actPlans))
@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/cloudposse/atlantis/server/logging"
+)
+
+// Exec runs a command to check plans. The plan JSON is passed on stdin and
+// its path is in the PLAN_JSON environment variable. If the command exits 0
+// the plan passes. If it exits 1 each line it printed to stdout is a
+// violation. Any other exit code is an error.
+type Exec struct {
+	Command string
+}
+
+// Check implements Engine.
+func (e *Exec) Check(log *logging.SimpleLogger, planJSONFile string) ([]string, error) {
+	planJSON, err := os.Open(planJSONFile) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	defer planJSON.Close() // nolint: errcheck
+
+	cmd := exec.Command("sh", "-c", e.Command) // #nosec
+	cmd.Stdin = planJSON
+	cmd.Env = append(os.Environ(), fmt.Sprintf("PLAN_JSON=%s", planJSONFile))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		return nil, nil
+	}
+	if exitStatus(err) != 1 {
+		return nil, fmt.Errorf("running %q: %s: %s%s", e.Command, err, stdout.String(), stderr.String())
+	}
+
+	var violations []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			violations = append(violations, line)
+		}
+	}
+	// The command failed the plan so it must have at least one violation.
+	if len(violations) == 0 {
+		violations = []string{fmt.Sprintf("%q exited 1 without saying why", e.Command)}
+	}
+	log.Debug("%q found violations: %s", e.Command, strings.Join(violations, ", "))
+	return violations, nil
+}
+
+// exitStatus returns the exit status of the command that failed with err, or
+// -1 if it didn't exit, ex. because it couldn't be started.
+func exitStatus(err error) int {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return -1
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok {
+		return -1
+	}
+	return status.ExitStatus()
+}
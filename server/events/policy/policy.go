@@ -0,0 +1,137 @@
+// Package policy checks Terraform plans against policies, ex. that S3
+// buckets aren't public.
+package policy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/pkg/errors"
+)
+
+// RegoEngine evaluates Rego policies. ExecEngine runs a command.
+const (
+	RegoEngine = "rego"
+	ExecEngine = "exec"
+)
+
+// Engine evaluates a set of policies.
+type Engine interface {
+	// Check returns the violations of the plan in planJSONFile, which holds
+	// the output of `terraform show -json`. It returns an error if the
+	// policies couldn't be evaluated.
+	Check(log *logging.SimpleLogger, planJSONFile string) ([]string, error)
+}
+
+// Config configures a policy set.
+type Config struct {
+	// Name identifies the policy set in comments.
+	Name string
+	// Engine is the engine that evaluates the set, either rego or exec.
+	Engine string
+	// Path is the file or directory of .rego files. It only applies to the
+	// rego engine.
+	Path string
+	// Command is the shell command to run. It only applies to the exec
+	// engine.
+	Command string
+}
+
+// Set is a named set of policies.
+type Set struct {
+	Name   string
+	Engine Engine
+}
+
+// Result is the result of checking a plan against a policy set.
+type Result struct {
+	PolicySet  string
+	Violations []string
+}
+
+// Passed returns true if the plan didn't violate any policies.
+func (r Result) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_checker.go Checker
+
+// Checker checks plans against every policy set.
+type Checker interface {
+	// Check returns the result of checking the plan in planJSON, the output
+	// of `terraform show -json`, against each policy set.
+	Check(log *logging.SimpleLogger, planJSON string) ([]Result, error)
+}
+
+// DefaultChecker implements Checker.
+type DefaultChecker struct {
+	Sets []Set
+}
+
+// NewChecker constructs a DefaultChecker for the policy sets in configs. It
+// returns an error if opa isn't in our $PATH and there are rego policy sets.
+func NewChecker(configs []Config) (*DefaultChecker, error) {
+	names := make(map[string]bool)
+	var sets []Set
+	for _, c := range configs {
+		if c.Name == "" {
+			return nil, errors.New("must specify \"name\" for policy sets")
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("policy set %q is defined more than once", c.Name)
+		}
+		names[c.Name] = true
+
+		var engine Engine
+		switch c.Engine {
+		case RegoEngine:
+			if c.Path == "" {
+				return nil, fmt.Errorf("must specify \"path\" for rego policy set %q", c.Name)
+			}
+			rego := &Rego{Path: c.Path}
+			if err := rego.Validate(); err != nil {
+				return nil, errors.Wrapf(err, "rego policy set %q", c.Name)
+			}
+			engine = rego
+		case ExecEngine:
+			if c.Command == "" {
+				return nil, fmt.Errorf("must specify \"command\" for exec policy set %q", c.Name)
+			}
+			engine = &Exec{Command: c.Command}
+		default:
+			return nil, fmt.Errorf("policy set %q has unknown engine %q, must be %s or %s", c.Name, c.Engine, RegoEngine, ExecEngine)
+		}
+		sets = append(sets, Set{Name: c.Name, Engine: engine})
+	}
+	return &DefaultChecker{Sets: sets}, nil
+}
+
+// Check implements Checker. Every set is checked even if an earlier one
+// failed so all the violations are reported at once.
+func (d *DefaultChecker) Check(log *logging.SimpleLogger, planJSON string) ([]Result, error) {
+	f, err := ioutil.TempFile("", "atlantis-plan-*.json")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating plan JSON file")
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+	_, err = f.WriteString(planJSON)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "writing plan JSON file")
+	}
+
+	var results []Result
+	for _, set := range d.Sets {
+		violations, err := set.Engine.Check(log, f.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking policy set %q", set.Name)
+		}
+		log.Info("plan has %d violations of policy set %q", len(violations), set.Name)
+		results = append(results, Result{PolicySet: set.Name, Violations: violations})
+	}
+	return results, nil
+}
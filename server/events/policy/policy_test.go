@@ -0,0 +1,217 @@
+package policy_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/policy"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestNewChecker(t *testing.T) {
+	cases := []struct {
+		description string
+		configs     []policy.Config
+		// noOPA is true if opa shouldn't be in our $PATH.
+		noOPA  bool
+		expErr string
+	}{
+		{
+			description: "rego and exec",
+			configs: []policy.Config{
+				{Name: "s3", Engine: "rego", Path: "/policies/s3"},
+				{Name: "instance-types", Engine: "exec", Command: "./check.sh"},
+			},
+		},
+		{
+			description: "no name",
+			configs:     []policy.Config{{Engine: "rego", Path: "/policies"}},
+			expErr:      "must specify \"name\" for policy sets",
+		},
+		{
+			description: "duplicate name",
+			configs: []policy.Config{
+				{Name: "s3", Engine: "rego", Path: "/policies/s3"},
+				{Name: "s3", Engine: "exec", Command: "./check.sh"},
+			},
+			expErr: "policy set \"s3\" is defined more than once",
+		},
+		{
+			description: "rego without path",
+			configs:     []policy.Config{{Name: "s3", Engine: "rego"}},
+			expErr:      "must specify \"path\" for rego policy set \"s3\"",
+		},
+		{
+			description: "exec without command",
+			configs:     []policy.Config{{Name: "s3", Engine: "exec"}},
+			expErr:      "must specify \"command\" for exec policy set \"s3\"",
+		},
+		{
+			description: "rego without opa",
+			configs:     []policy.Config{{Name: "s3", Engine: "rego", Path: "/policies/s3"}},
+			noOPA:       true,
+			expErr:      "rego policy set \"s3\": opa must be installed to evaluate rego policies: exec: \"opa\": executable file not found in $PATH",
+		},
+		{
+			description: "exec without opa",
+			configs:     []policy.Config{{Name: "instance-types", Engine: "exec", Command: "./check.sh"}},
+			noOPA:       true,
+		},
+		{
+			description: "unknown engine",
+			configs:     []policy.Config{{Name: "s3", Engine: "sentinel"}},
+			expErr:      "policy set \"s3\" has unknown engine \"sentinel\", must be rego or exec",
+		},
+	}
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	opaDir := filepath.Join(tmp, "opa-bin")
+	Ok(t, os.Mkdir(opaDir, 0700))
+	Ok(t, ioutil.WriteFile(filepath.Join(opaDir, "opa"), []byte("#!/bin/sh\n"), 0700)) // nolint: gosec
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path) // nolint: errcheck
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.noOPA {
+				Ok(t, os.Setenv("PATH", tmp))
+			} else {
+				Ok(t, os.Setenv("PATH", opaDir))
+			}
+			checker, err := policy.NewChecker(c.configs)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, len(c.configs), len(checker.Sets))
+		})
+	}
+}
+
+func TestDefaultChecker_Check(t *testing.T) {
+	checker, err := policy.NewChecker([]policy.Config{
+		{Name: "pass", Engine: "exec", Command: "exit 0"},
+		{Name: "fail", Engine: "exec", Command: `grep -q public "$PLAN_JSON" && echo "bucket is public" && exit 1`},
+	})
+	Ok(t, err)
+
+	results, err := checker.Check(logging.NewNoopLogger(), `{"acl": "public-read"}`)
+	Ok(t, err)
+	Equals(t, []policy.Result{
+		{PolicySet: "pass"},
+		{PolicySet: "fail", Violations: []string{"bucket is public"}},
+	}, results)
+	Assert(t, results[0].Passed(), "exp pass to pass")
+	Assert(t, !results[1].Passed(), "exp fail to fail")
+}
+
+func TestDefaultChecker_CheckError(t *testing.T) {
+	checker, err := policy.NewChecker([]policy.Config{
+		{Name: "broken", Engine: "exec", Command: "echo oops; exit 2"},
+	})
+	Ok(t, err)
+
+	_, err = checker.Check(logging.NewNoopLogger(), "{}")
+	ErrEquals(t, "checking policy set \"broken\": running \"echo oops; exit 2\": exit status 2: oops\n", err)
+}
+
+func TestExec_Check(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	planFile := filepath.Join(tmp, "plan.json")
+	Ok(t, ioutil.WriteFile(planFile, []byte(`{"resource_changes": []}`), 0600))
+
+	cases := []struct {
+		description   string
+		command       string
+		expViolations []string
+	}{
+		{
+			description: "pass",
+			command:     "cat > /dev/null",
+		},
+		{
+			description:   "violations",
+			command:       "echo 'too big'; echo; echo 'not tagged'; exit 1",
+			expViolations: []string{"too big", "not tagged"},
+		},
+		{
+			description:   "no message",
+			command:       "exit 1",
+			expViolations: []string{"\"exit 1\" exited 1 without saying why"},
+		},
+		{
+			description:   "reads stdin",
+			command:       `grep -q resource_changes && echo "got plan" && exit 1`,
+			expViolations: []string{"got plan"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			e := &policy.Exec{Command: c.command}
+			violations, err := e.Check(logging.NewNoopLogger(), planFile)
+			Ok(t, err)
+			Equals(t, c.expViolations, violations)
+		})
+	}
+}
+
+func TestRego_Check(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	planFile := filepath.Join(tmp, "plan.json")
+	Ok(t, ioutil.WriteFile(planFile, []byte("{}"), 0600))
+
+	cases := []struct {
+		description   string
+		output        string
+		expViolations []string
+		expErr        string
+	}{
+		{
+			description: "pass",
+			output:      `{"result": [{"expressions": [{"value": [], "text": "data.atlantis.deny"}]}]}`,
+		},
+		{
+			description:   "violations",
+			output:        `{"result": [{"expressions": [{"value": ["aws_s3_bucket.a must not be public", {"address": "aws_instance.b"}]}]}]}`,
+			expViolations: []string{"aws_s3_bucket.a must not be public", `{"address":"aws_instance.b"}`},
+		},
+		{
+			description: "undefined",
+			output:      `{}`,
+			expErr:      "data.atlantis.deny is undefined, policies must be in package atlantis and define deny rules",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			// Our fake opa records its args and prints the output.
+			opa := filepath.Join(tmp, "opa")
+			script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(tmp, "args") + "\necho '" + c.output + "'\n"
+			Ok(t, ioutil.WriteFile(opa, []byte(script), 0700)) // nolint: gosec
+
+			r := &policy.Rego{Path: "/policies", Binary: opa}
+			violations, err := r.Check(logging.NewNoopLogger(), planFile)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expViolations, violations)
+			args, err := ioutil.ReadFile(filepath.Join(tmp, "args"))
+			Ok(t, err)
+			Equals(t, "eval --format json --data /policies --input "+planFile+" data.atlantis.deny\n", string(args))
+		})
+	}
+}
+
+func TestRego_CheckNoOPA(t *testing.T) {
+	r := &policy.Rego{Path: "/policies", Binary: "/does/not/exist/opa"}
+	_, err := r.Check(logging.NewNoopLogger(), "plan.json")
+	ErrContains(t, "opa must be installed to evaluate rego policies", err)
+}
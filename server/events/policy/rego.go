@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/pkg/errors"
+)
+
+// RegoQuery is what Rego policies are evaluated with. Policies must be in
+// package atlantis and define deny rules whose messages are the violations.
+const RegoQuery = "data.atlantis.deny"
+
+// defaultOPABinary is used if Rego.Binary isn't set.
+const defaultOPABinary = "opa"
+
+// Rego evaluates Rego policies with the opa binary.
+type Rego struct {
+	// Path is the .rego file or directory of .rego files to evaluate.
+	Path string
+	// Binary is the path to opa. If empty, opa is looked up in our $PATH.
+	Binary string
+}
+
+// Validate returns an error if opa can't be found so that misconfigured
+// servers fail when they start instead of when plans are checked.
+func (r *Rego) Validate() error {
+	_, err := r.binary()
+	return err
+}
+
+// Check implements Engine.
+func (r *Rego) Check(log *logging.SimpleLogger, planJSONFile string) ([]string, error) {
+	binary, err := r.binary()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(binary, "eval", "--format", "json", "--data", r.Path, "--input", planJSONFile, RegoQuery) // #nosec
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("running opa eval: %s: %s%s", err, out, exitErr.Stderr)
+		}
+		return nil, errors.Wrap(err, "running opa eval")
+	}
+	log.Debug("opa eval output: %s", out)
+
+	var output struct {
+		Result []struct {
+			Expressions []struct {
+				Value []interface{} `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out, &output); err != nil {
+		return nil, errors.Wrap(err, "parsing opa eval output")
+	}
+	// If the query is undefined there are no results. That's most likely
+	// because the policies are in the wrong package so we don't treat it as
+	// passing.
+	if len(output.Result) == 0 || len(output.Result[0].Expressions) == 0 {
+		return nil, fmt.Errorf("%s is undefined, policies must be in package atlantis and define deny rules", RegoQuery)
+	}
+
+	var violations []string
+	for _, v := range output.Result[0].Expressions[0].Value {
+		if s, ok := v.(string); ok {
+			violations = append(violations, s)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "formatting violation")
+		}
+		violations = append(violations, string(b))
+	}
+	return violations, nil
+}
+
+// binary returns the path to opa.
+func (r *Rego) binary() (string, error) {
+	binary := r.Binary
+	if binary == "" {
+		binary = defaultOPABinary
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", errors.Wrap(err, "opa must be installed to evaluate rego policies")
+	}
+	return path, nil
+}
@@ -54,7 +54,7 @@ type DefaultProjectCommandBuilder struct {
 	AllowRepoConfig     bool
 	AllowRepoConfigFlag string
 	RepoConfig          string
-	PendingPlanFinder   *PendingPlanFinder
+	PendingPlanFinder   PendingPlanFinder
 	CommentBuilder      CommentBuilder
 }
 
@@ -135,20 +135,21 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *CommandContext,
 		ctx.Log.Info("automatically determined that there were %d projects modified in this pull request: %s", len(modifiedProjects), modifiedProjects)
 		for _, mp := range modifiedProjects {
 			projCtxs = append(projCtxs, models.ProjectCommandContext{
-				BaseRepo:      ctx.BaseRepo,
-				HeadRepo:      ctx.HeadRepo,
-				Pull:          ctx.Pull,
-				User:          ctx.User,
-				Log:           ctx.Log,
-				RepoRelDir:    mp.Path,
-				ProjectConfig: nil,
-				GlobalConfig:  nil,
-				CommentArgs:   commentFlags,
-				Workspace:     DefaultWorkspace,
-				Verbose:       verbose,
-				RePlanCmd:     p.CommentBuilder.BuildPlanComment(mp.Path, DefaultWorkspace, "", commentFlags),
-				ApplyCmd:      p.CommentBuilder.BuildApplyComment(mp.Path, DefaultWorkspace, ""),
-				DestroyCmd:    p.CommentBuilder.BuildDestroyComment(mp.Path, DefaultWorkspace, ""),
+				BaseRepo:           ctx.BaseRepo,
+				HeadRepo:           ctx.HeadRepo,
+				Pull:               ctx.Pull,
+				User:               ctx.User,
+				Log:                ctx.Log,
+				RepoRelDir:         mp.Path,
+				ProjectConfig:      nil,
+				GlobalConfig:       nil,
+				CommentArgs:        commentFlags,
+				Workspace:          DefaultWorkspace,
+				Verbose:            verbose,
+				RePlanCmd:          p.CommentBuilder.BuildPlanComment(mp.Path, DefaultWorkspace, "", commentFlags),
+				ApplyCmd:           p.CommentBuilder.BuildApplyComment(mp.Path, DefaultWorkspace, ""),
+				DestroyCmd:         p.CommentBuilder.BuildDestroyComment(mp.Path, DefaultWorkspace, ""),
+				ApprovePoliciesCmd: p.CommentBuilder.BuildApprovePoliciesComment(mp.Path, DefaultWorkspace, ""),
 			})
 		}
 	} else {
@@ -165,20 +166,21 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *CommandContext,
 		for i := 0; i < len(matchingProjects); i++ {
 			mp := matchingProjects[i]
 			projCtxs = append(projCtxs, models.ProjectCommandContext{
-				BaseRepo:      ctx.BaseRepo,
-				HeadRepo:      ctx.HeadRepo,
-				Pull:          ctx.Pull,
-				User:          ctx.User,
-				Log:           ctx.Log,
-				CommentArgs:   commentFlags,
-				Workspace:     mp.Workspace,
-				RepoRelDir:    mp.Dir,
-				ProjectConfig: &mp,
-				GlobalConfig:  &config,
-				Verbose:       verbose,
-				RePlanCmd:     p.CommentBuilder.BuildPlanComment(mp.Dir, mp.Workspace, mp.GetName(), commentFlags),
-				ApplyCmd:      p.CommentBuilder.BuildApplyComment(mp.Dir, mp.Workspace, mp.GetName()),
-				DestroyCmd:    p.CommentBuilder.BuildDestroyComment(mp.Dir, mp.Workspace, mp.GetName()),
+				BaseRepo:           ctx.BaseRepo,
+				HeadRepo:           ctx.HeadRepo,
+				Pull:               ctx.Pull,
+				User:               ctx.User,
+				Log:                ctx.Log,
+				CommentArgs:        commentFlags,
+				Workspace:          mp.Workspace,
+				RepoRelDir:         mp.Dir,
+				ProjectConfig:      &mp,
+				GlobalConfig:       &config,
+				Verbose:            verbose,
+				RePlanCmd:          p.CommentBuilder.BuildPlanComment(mp.Dir, mp.Workspace, mp.GetName(), commentFlags),
+				ApplyCmd:           p.CommentBuilder.BuildApplyComment(mp.Dir, mp.Workspace, mp.GetName()),
+				DestroyCmd:         p.CommentBuilder.BuildDestroyComment(mp.Dir, mp.Workspace, mp.GetName()),
+				ApprovePoliciesCmd: p.CommentBuilder.BuildApprovePoliciesComment(mp.Dir, mp.Workspace, mp.GetName()),
 			})
 		}
 	}
@@ -379,19 +381,20 @@ func (p *DefaultProjectCommandBuilder) buildProjectCommandCtx(ctx *CommandContex
 		workspace = projCfg.Workspace
 	}
 	return models.ProjectCommandContext{
-		BaseRepo:      ctx.BaseRepo,
-		HeadRepo:      ctx.HeadRepo,
-		Pull:          ctx.Pull,
-		User:          ctx.User,
-		Log:           ctx.Log,
-		CommentArgs:   commentFlags,
-		Workspace:     workspace,
-		RepoRelDir:    repoRelDir,
-		ProjectConfig: projCfg,
-		GlobalConfig:  globalCfg,
-		RePlanCmd:     p.CommentBuilder.BuildPlanComment(repoRelDir, workspace, projectName, commentFlags),
-		ApplyCmd:      p.CommentBuilder.BuildApplyComment(repoRelDir, workspace, projectName),
-		DestroyCmd:    p.CommentBuilder.BuildDestroyComment(repoRelDir, workspace, projectName),
+		BaseRepo:           ctx.BaseRepo,
+		HeadRepo:           ctx.HeadRepo,
+		Pull:               ctx.Pull,
+		User:               ctx.User,
+		Log:                ctx.Log,
+		CommentArgs:        commentFlags,
+		Workspace:          workspace,
+		RepoRelDir:         repoRelDir,
+		ProjectConfig:      projCfg,
+		GlobalConfig:       globalCfg,
+		RePlanCmd:          p.CommentBuilder.BuildPlanComment(repoRelDir, workspace, projectName, commentFlags),
+		ApplyCmd:           p.CommentBuilder.BuildApplyComment(repoRelDir, workspace, projectName),
+		DestroyCmd:         p.CommentBuilder.BuildDestroyComment(repoRelDir, workspace, projectName),
+		ApprovePoliciesCmd: p.CommentBuilder.BuildApprovePoliciesComment(repoRelDir, workspace, projectName),
 	}, nil
 }
 
@@ -199,7 +199,7 @@ projects:
 				ProjectFinder:       &events.DefaultProjectFinder{},
 				AllowRepoConfig:     true,
 				RepoConfig:          repoConfig,
-				PendingPlanFinder:   &events.PendingPlanFinder{},
+				PendingPlanFinder:   &events.DefaultPendingPlanFinder{},
 				AllowRepoConfigFlag: "allow-repo-config",
 				CommentBuilder:      &events.CommentParser{},
 			}
@@ -772,7 +772,7 @@ func TestDefaultProjectCommandBuilder_BuildMultiApply(t *testing.T) {
 		AllowRepoConfig:     true,
 		AllowRepoConfigFlag: "allow-repo-config",
 		RepoConfig:          repoConfig,
-		PendingPlanFinder:   &events.PendingPlanFinder{},
+		PendingPlanFinder:   &events.DefaultPendingPlanFinder{},
 		CommentBuilder:      &events.CommentParser{},
 	}
 
@@ -14,17 +14,25 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/cloudposse/atlantis/server/events/locking"
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/policy"
 	"github.com/cloudposse/atlantis/server/events/runtime"
 	"github.com/cloudposse/atlantis/server/events/webhooks"
 	"github.com/cloudposse/atlantis/server/events/yaml/raw"
 	"github.com/cloudposse/atlantis/server/events/yaml/valid"
 	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
 )
 
@@ -49,8 +57,8 @@ type StepRunner interface {
 
 // WebhooksSender sends webhook.
 type WebhooksSender interface {
-	// Send sends the webhook.
-	Send(log *logging.SimpleLogger, res webhooks.ApplyResult) error
+	// Send sends the webhooks configured for event.
+	Send(log *logging.SimpleLogger, event webhooks.Event) error
 }
 
 // PlanSuccess is the result of a successful plan.
@@ -65,6 +73,74 @@ type PlanSuccess struct {
 	ApplyCmd string
 	// DestroyCmd is the command that users should run to destroy this plan.
 	DestroyCmd string
+	// TerraformVersion is the version of terraform the plan was run with if
+	// it was resolved from the project's terraform_version or
+	// required_version. It's empty if the default version was used.
+	TerraformVersion string
+	// PlanSummary is the structured summary of the plan from `terraform show
+	// -json`. It's nil if terraform doesn't support it or the plan couldn't
+	// be summarized.
+	PlanSummary *models.PlanSummary
+}
+
+// PolicyCheckSuccess is the result of checking a plan against the policy
+// sets.
+type PolicyCheckSuccess struct {
+	// Results are the results of each policy set.
+	Results []policy.Result
+	// ApplyCmd is the command that users should run to apply this plan.
+	ApplyCmd string
+	// RePlanCmd is the command that users should run to re-plan this project.
+	RePlanCmd string
+	// ApprovePoliciesCmd is the command that policy approvers should run to
+	// approve this plan if it failed.
+	ApprovePoliciesCmd string
+}
+
+// Passed returns true if the plan passed every policy set.
+func (p PolicyCheckSuccess) Passed() bool {
+	for _, r := range p.Results {
+		if !r.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+var planSummaryRegex = regexp.MustCompile(`(?m)^(Plan: \d+ to add, \d+ to change, \d+ to destroy\.|No changes\. .*)$`)
+
+// Summary returns Terraform's one line summary of the plan, ex.
+// "Plan: 1 to add, 0 to change, 0 to destroy.", or an empty string if it
+// can't be found in the output.
+func (p PlanSuccess) Summary() string {
+	return planSummaryRegex.FindString(p.TerraformOutput)
+}
+
+var planChangesRegex = regexp.MustCompile(`(?m)^Plan: (\d+) to add, (\d+) to change, (\d+) to destroy\.`)
+
+// Changes returns how many resources the plan will change, or nil if there's
+// no PlanSummary and Terraform's summary can't be found in the output.
+func (p PlanSuccess) Changes() *models.PlanChanges {
+	if p.PlanSummary != nil {
+		changes := p.PlanSummary.Changes()
+		return &changes
+	}
+	return parsePlanChanges(p.TerraformOutput)
+}
+
+// parsePlanChanges returns how many resources the plan in output will
+// change, or nil if Terraform's summary can't be found.
+func parsePlanChanges(output string) *models.PlanChanges {
+	if m := planChangesRegex.FindStringSubmatch(output); m != nil {
+		add, _ := strconv.Atoi(m[1])
+		change, _ := strconv.Atoi(m[2])
+		destroy, _ := strconv.Atoi(m[3])
+		return &models.PlanChanges{Add: add, Change: change, Destroy: destroy}
+	}
+	if strings.Contains(output, "No changes.") {
+		return &models.PlanChanges{}
+	}
+	return nil
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_command_runner.go ProjectCommandRunner
@@ -78,27 +154,64 @@ type ProjectCommandRunner interface {
 	Apply(ctx models.ProjectCommandContext) ProjectResult
 	// Destroy runs terraform destroy for the project described by ctx.
 	Destroy(ctx models.ProjectCommandContext) ProjectResult
+	// PolicyCheck checks the plan for the project described by ctx against
+	// the policy sets.
+	PolicyCheck(ctx models.ProjectCommandContext) ProjectResult
+	// ApprovePolicies approves the plan for the project described by ctx so
+	// it can be applied even though it failed its policy checks.
+	ApprovePolicies(ctx models.ProjectCommandContext) ProjectResult
 }
 
 // DefaultProjectCommandRunner implements ProjectCommandRunner.
 type DefaultProjectCommandRunner struct {
-	Locker                  ProjectLocker
-	LockURLGenerator        LockURLGenerator
-	InitStepRunner          StepRunner
-	PlanStepRunner          StepRunner
-	ApplyStepRunner         StepRunner
-	DestroyStepRunner       StepRunner
-	RunStepRunner           StepRunner
+	Locker            ProjectLocker
+	LockURLGenerator  LockURLGenerator
+	InitStepRunner    StepRunner
+	PlanStepRunner    StepRunner
+	ApplyStepRunner   StepRunner
+	DestroyStepRunner StepRunner
+	RunStepRunner     StepRunner
+	// ShowStepRunner runs `terraform show -json` on the saved plan to
+	// summarize it. If nil, plans aren't summarized.
+	ShowStepRunner          StepRunner
 	PullApprovedChecker     runtime.PullApprovedChecker
+	PullMergeableChecker    runtime.PullMergeableChecker
+	CodeOwnersChecker       CodeOwnersChecker
 	WorkingDir              WorkingDir
 	Webhooks                WebhooksSender
 	WorkingDirLocker        WorkingDirLocker
 	RequireApprovalOverride bool
+	// TerraformVersionResolver picks the version of terraform to run each
+	// project with. If nil, the version in the project's config or the
+	// default version is used.
+	TerraformVersionResolver TerraformVersionResolver
+	// PolicyChecker checks plans against the policy sets. If nil, plans
+	// aren't checked and applies aren't blocked by policies.
+	PolicyChecker policy.Checker
 }
 
+// policyCheckSuffix is appended to the name of a plan file for the file that
+// records that the plan hasn't passed its policy checks. It's written when
+// the plan is made and only removed once the checks pass or are approved so
+// that plans can't be applied if checking them failed.
+const policyCheckSuffix = ".policy-check"
+
+// terraformVersionSuffix is appended to the name of a plan file for the file
+// that records the version of terraform the plan was made with, so it's
+// applied with the same version even if resolving it again would pick
+// another, ex. because a newer version was released. It's empty if the plan
+// was made with the default version.
+const terraformVersionSuffix = ".terraform-version"
+
+// planChangesSuffix is appended to the name of a plan file for the file that
+// records how many resources the plan will change, so that apply webhooks can
+// include them.
+const planChangesSuffix = ".changes"
+
 // Plan runs terraform plan for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Plan(ctx models.ProjectCommandContext) ProjectResult {
 	planSuccess, failure, err := p.doPlan(ctx)
+	p.sendFailure(ctx, "plan", failure, err)
 	return ProjectResult{
 		PlanSuccess: planSuccess,
 		Error:       err,
@@ -108,9 +221,37 @@ func (p *DefaultProjectCommandRunner) Plan(ctx models.ProjectCommandContext) Pro
 	}
 }
 
+// PolicyCheck checks the plan for the project described by ctx against the
+// policy sets.
+func (p *DefaultProjectCommandRunner) PolicyCheck(ctx models.ProjectCommandContext) ProjectResult {
+	policyCheckSuccess, failure, err := p.doPolicyCheck(ctx)
+	p.sendFailure(ctx, "policy_check", failure, err)
+	return ProjectResult{
+		PolicyCheckSuccess: policyCheckSuccess,
+		Error:              err,
+		Failure:            failure,
+		RepoRelDir:         ctx.RepoRelDir,
+		Workspace:          ctx.Workspace,
+	}
+}
+
+// ApprovePolicies approves the plan for the project described by ctx so it
+// can be applied even though it failed its policy checks.
+func (p *DefaultProjectCommandRunner) ApprovePolicies(ctx models.ProjectCommandContext) ProjectResult {
+	approveOut, failure, err := p.doApprovePolicies(ctx)
+	return ProjectResult{
+		ApprovePoliciesSuccess: approveOut,
+		Error:                  err,
+		Failure:                failure,
+		RepoRelDir:             ctx.RepoRelDir,
+		Workspace:              ctx.Workspace,
+	}
+}
+
 // Apply runs terraform apply for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Apply(ctx models.ProjectCommandContext) ProjectResult {
 	applyOut, failure, err := p.doApply(ctx)
+	p.sendFailure(ctx, "apply", failure, err)
 	return ProjectResult{
 		Failure:      failure,
 		Error:        err,
@@ -123,6 +264,7 @@ func (p *DefaultProjectCommandRunner) Apply(ctx models.ProjectCommandContext) Pr
 // Destroy runs terraform destroy for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Destroy(ctx models.ProjectCommandContext) ProjectResult {
 	destroyOut, failure, err := p.doDestroy(ctx)
+	p.sendFailure(ctx, "destroy", failure, err)
 	return ProjectResult{
 		Failure:        failure,
 		Error:          err,
@@ -142,6 +284,21 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (
 		return nil, lockAttempt.LockFailureReason, nil
 	}
 	ctx.Log.Debug("acquired lock for project")
+	lockURL := p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey)
+	// Planning again doesn't acquire the lock again so it's only sent the
+	// first time. Unlocks are sent by UnlockWebhookLocker.
+	if lockAttempt.LockCreated {
+		p.sendWebhook(ctx, webhooks.LockResult{
+			Workspace: ctx.Workspace,
+			Dir:       ctx.RepoRelDir,
+			Project:   ctx.GetProjectName(),
+			User:      ctx.User,
+			Repo:      ctx.BaseRepo,
+			Pull:      ctx.Pull,
+			Locked:    true,
+			LockURL:   lockURL,
+		})
+	}
 
 	// Acquire internal lock for the directory we're going to operate in.
 	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
@@ -153,12 +310,15 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (
 	// Clone is idempotent so okay to run even if the repo was already cloned.
 	repoDir, cloneErr := p.WorkingDir.Clone(ctx.Log, ctx.BaseRepo, ctx.HeadRepo, ctx.Pull, ctx.Workspace)
 	if cloneErr != nil {
-		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
-			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
-		}
+		p.unlockAfterPlanError(ctx, lockAttempt)
 		return nil, "", cloneErr
 	}
 	projAbsPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	ctx, err = p.resolveTerraformVersion(ctx, projAbsPath)
+	if err != nil {
+		p.unlockAfterPlanError(ctx, lockAttempt)
+		return nil, "", err
+	}
 
 	// Use default stage unless another workflow is defined in config
 	stage := p.defaultPlanStage()
@@ -170,21 +330,53 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (
 			stage = *configuredStage
 		}
 	}
+	startedAt := time.Now()
 	outputs, err := p.runSteps(stage.Steps, ctx, projAbsPath)
+	output := strings.Join(outputs, "\n")
+	planResult := webhooks.PlanResult{
+		Workspace:  ctx.Workspace,
+		Dir:        ctx.RepoRelDir,
+		Project:    ctx.GetProjectName(),
+		User:       ctx.User,
+		Repo:       ctx.BaseRepo,
+		Pull:       ctx.Pull,
+		Success:    err == nil,
+		Output:     output,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	}
 	if err != nil {
-		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
-			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
-		}
-		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+		planResult.Output = fmt.Sprintf("%s\n%s", err, output)
+		p.sendWebhook(ctx, planResult)
+		p.unlockAfterPlanError(ctx, lockAttempt)
+		return nil, "", errors.New(planResult.Output)
 	}
-
-	return &PlanSuccess{
-		LockURL:         p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey),
-		TerraformOutput: strings.Join(outputs, "\n"),
+	planSuccess := &PlanSuccess{
+		LockURL:         lockURL,
+		TerraformOutput: output,
 		RePlanCmd:       ctx.RePlanCmd,
 		ApplyCmd:        ctx.ApplyCmd,
 		DestroyCmd:      ctx.DestroyCmd,
-	}, "", nil
+		PlanSummary:     p.planSummary(ctx, projAbsPath),
+	}
+	if ctx.TerraformVersion != nil {
+		planSuccess.TerraformVersion = ctx.TerraformVersion.String()
+	}
+	if err := p.saveTerraformVersion(ctx, projAbsPath); err != nil {
+		p.unlockAfterPlanError(ctx, lockAttempt)
+		return nil, "", err
+	}
+	if p.PolicyChecker != nil {
+		if err := ioutil.WriteFile(p.policyCheckFile(ctx, projAbsPath), []byte("pending"), 0600); err != nil {
+			p.unlockAfterPlanError(ctx, lockAttempt)
+			return nil, "", errors.Wrap(err, "recording that the plan's policies haven't been checked")
+		}
+	}
+	planResult.Changes = planSuccess.Changes()
+	p.savePlanChanges(ctx, projAbsPath, planResult.Changes)
+	planResult.LockURL = lockURL
+	p.sendWebhook(ctx, planResult)
+	return planSuccess, "", nil
 }
 
 func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx models.ProjectCommandContext, absPath string) ([]string, error) {
@@ -234,17 +426,11 @@ func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext)
 	if p.RequireApprovalOverride {
 		applyRequirements = []string{raw.ApprovedApplyRequirement}
 	}
-	for _, req := range applyRequirements {
-		switch req {
-		case raw.ApprovedApplyRequirement:
-			approved, err := p.PullApprovedChecker.PullIsApproved(ctx.BaseRepo, ctx.Pull) // nolint: vetshadow
-			if err != nil {
-				return "", "", errors.Wrap(err, "checking if pull request was approved")
-			}
-			if !approved {
-				return "", "Pull request must be approved before running apply.", nil
-			}
-		}
+	if failure, err := p.checkRequirements(ctx, repoDir, applyRequirements, "apply"); err != nil || failure != "" {
+		return "", failure, err
+	}
+	if failure, err := p.checkPolicies(ctx, absPath, "apply"); err != nil || failure != "" {
+		return "", failure, err
 	}
 	// Acquire internal lock for the directory we're going to operate in.
 	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
@@ -252,6 +438,10 @@ func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext)
 		return "", "", err
 	}
 	defer unlockFn()
+	ctx, err = p.planTerraformVersion(ctx, absPath)
+	if err != nil {
+		return "", "", err
+	}
 
 	// Use default stage unless another workflow is defined in config
 	stage := p.defaultApplyStage()
@@ -261,18 +451,33 @@ func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext)
 			stage = *configuredStage
 		}
 	}
+	// The changes are read before the steps run since applying deletes the
+	// plan.
+	changes := p.planChanges(ctx, absPath)
+	startedAt := time.Now()
 	outputs, err := p.runSteps(stage.Steps, ctx, absPath)
-	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
-		Workspace: ctx.Workspace,
-		User:      ctx.User,
-		Repo:      ctx.BaseRepo,
-		Pull:      ctx.Pull,
-		Success:   err == nil,
+	output := strings.Join(outputs, "\n")
+	if err != nil {
+		output = fmt.Sprintf("%s\n%s", err, output)
+	}
+	p.sendWebhook(ctx, webhooks.ApplyResult{
+		Workspace:  ctx.Workspace,
+		Dir:        ctx.RepoRelDir,
+		Project:    ctx.GetProjectName(),
+		User:       ctx.User,
+		Repo:       ctx.BaseRepo,
+		Pull:       ctx.Pull,
+		Success:    err == nil,
+		Output:     output,
+		Changes:    changes,
+		LockURL:    p.lockURL(ctx),
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
 	})
 	if err != nil {
-		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+		return "", "", errors.New(output)
 	}
-	return strings.Join(outputs, "\n"), "", nil
+	return output, "", nil
 }
 
 func (p *DefaultProjectCommandRunner) doDestroy(ctx models.ProjectCommandContext) (destroyOut string, failure string, err error) {
@@ -294,17 +499,11 @@ func (p *DefaultProjectCommandRunner) doDestroy(ctx models.ProjectCommandContext
 	if p.RequireApprovalOverride {
 		destroyRequirements = []string{raw.ApprovedDestroyRequirement}
 	}
-	for _, req := range destroyRequirements {
-		switch req {
-		case raw.ApprovedDestroyRequirement:
-			approved, err := p.PullApprovedChecker.PullIsApproved(ctx.BaseRepo, ctx.Pull) // nolint: vetshadow
-			if err != nil {
-				return "", "", errors.Wrap(err, "checking if pull request was approved")
-			}
-			if !approved {
-				return "", "Pull request must be approved before running destroy.", nil
-			}
-		}
+	if failure, err := p.checkRequirements(ctx, repoDir, destroyRequirements, "destroy"); err != nil || failure != "" {
+		return "", failure, err
+	}
+	if failure, err := p.checkPolicies(ctx, absPath, "destroy"); err != nil || failure != "" {
+		return "", failure, err
 	}
 	// Acquire internal lock for the directory we're going to operate in.
 	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
@@ -312,6 +511,10 @@ func (p *DefaultProjectCommandRunner) doDestroy(ctx models.ProjectCommandContext
 		return "", "", err
 	}
 	defer unlockFn()
+	ctx, err = p.planTerraformVersion(ctx, absPath)
+	if err != nil {
+		return "", "", err
+	}
 
 	// Use default stage unless another workflow is defined in config
 	stage := p.defaultDestroyStage()
@@ -321,18 +524,378 @@ func (p *DefaultProjectCommandRunner) doDestroy(ctx models.ProjectCommandContext
 			stage = *configuredStage
 		}
 	}
+	// The changes are read before the steps run since applying deletes the
+	// plan.
+	changes := p.planChanges(ctx, absPath)
+	startedAt := time.Now()
 	outputs, err := p.runSteps(stage.Steps, ctx, absPath)
-	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
+	output := strings.Join(outputs, "\n")
+	if err != nil {
+		output = fmt.Sprintf("%s\n%s", err, output)
+	}
+	p.sendWebhook(ctx, webhooks.ApplyResult{
+		Workspace:  ctx.Workspace,
+		Dir:        ctx.RepoRelDir,
+		Project:    ctx.GetProjectName(),
+		User:       ctx.User,
+		Repo:       ctx.BaseRepo,
+		Pull:       ctx.Pull,
+		Success:    err == nil,
+		Output:     output,
+		Changes:    changes,
+		LockURL:    p.lockURL(ctx),
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	})
+	if err != nil {
+		return "", "", errors.New(output)
+	}
+	return output, "", nil
+}
+
+func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx models.ProjectCommandContext) (*PolicyCheckSuccess, string, error) {
+	if p.PolicyChecker == nil {
+		return nil, "", errors.New("policy checks aren't configured")
+	}
+	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", errors.New("project has not been cloned – did you run plan?")
+		}
+		return nil, "", err
+	}
+	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
+
+	// Acquire internal lock for the directory we're going to operate in.
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlockFn()
+	ctx, err = p.planTerraformVersion(ctx, absPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	showOut, err := p.ShowStepRunner.Run(ctx, nil, absPath)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "showing plan")
+	}
+	if showOut == "" {
+		return nil, "", errors.New("policy checks require Terraform 0.12 or later and a saved plan")
+	}
+	results, err := p.PolicyChecker.Check(ctx.Log, runtime.PlanJSON(showOut))
+	if err != nil {
+		return nil, "", err
+	}
+	success := &PolicyCheckSuccess{
+		Results:            results,
+		ApplyCmd:           ctx.ApplyCmd,
+		RePlanCmd:          ctx.RePlanCmd,
+		ApprovePoliciesCmd: ctx.ApprovePoliciesCmd,
+	}
+	if success.Passed() {
+		if err := os.Remove(p.policyCheckFile(ctx, absPath)); err != nil && !os.IsNotExist(err) {
+			return nil, "", errors.Wrap(err, "recording that the plan passed its policy checks")
+		}
+		return success, "", nil
+	}
+	if err := ioutil.WriteFile(p.policyCheckFile(ctx, absPath), []byte("failed"), 0600); err != nil {
+		return nil, "", errors.Wrap(err, "recording that the plan failed its policy checks")
+	}
+	return success, "", nil
+}
+
+func (p *DefaultProjectCommandRunner) doApprovePolicies(ctx models.ProjectCommandContext) (approveOut string, failure string, err error) {
+	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", errors.New("project has not been cloned – did you run plan?")
+		}
+		return "", "", err
+	}
+	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
+
+	// Acquire internal lock for the directory we're going to operate in.
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlockFn()
+
+	err = os.Remove(p.policyCheckFile(ctx, absPath))
+	if os.IsNotExist(err) {
+		return "This plan didn't fail any policy checks so it didn't need to be approved.", "", nil
+	}
+	if err != nil {
+		return "", "", errors.Wrap(err, "approving policies")
+	}
+	ctx.Log.Info("policies were approved by %s", ctx.User.Username)
+	return fmt.Sprintf("Policies approved by @%s. To **apply** this plan, comment:\n* `%s`", ctx.User.Username, ctx.ApplyCmd), "", nil
+}
+
+// checkPolicies returns a failure if the plan in absPath hasn't passed its
+// policy checks and hasn't been approved. command is the command being run,
+// ex. "apply", since destroys are blocked the same way as applies.
+func (p *DefaultProjectCommandRunner) checkPolicies(ctx models.ProjectCommandContext, absPath string, command string) (failure string, err error) {
+	if p.PolicyChecker == nil {
+		return "", nil
+	}
+	_, err = os.Stat(p.policyCheckFile(ctx, absPath))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "checking if the plan passed its policy checks")
+	}
+	return fmt.Sprintf("Plan must pass its policy checks or be approved by a policy approver with `%s` before running %s.", ctx.ApprovePoliciesCmd, command), nil
+}
+
+// policyCheckFile returns the path of the file that records that the plan in
+// absPath hasn't passed its policy checks.
+func (p *DefaultProjectCommandRunner) policyCheckFile(ctx models.ProjectCommandContext, absPath string) string {
+	return filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectConfig)+policyCheckSuffix)
+}
+
+// planSummary returns the structured summary of the plan saved in absPath,
+// or nil if it can't be built. Failing to summarize the plan doesn't fail the
+// plan since the summary is only informational.
+func (p *DefaultProjectCommandRunner) planSummary(ctx models.ProjectCommandContext, absPath string) *models.PlanSummary {
+	if p.ShowStepRunner == nil {
+		return nil
+	}
+	out, err := p.ShowStepRunner.Run(ctx, nil, absPath)
+	if err != nil {
+		ctx.Log.Warn("unable to show plan so it won't be summarized: %s", err)
+		return nil
+	}
+	if out == "" {
+		return nil
+	}
+	summary, err := runtime.ParsePlanSummary(out)
+	if err != nil {
+		ctx.Log.Warn("unable to summarize plan: %s", err)
+		return nil
+	}
+	return summary
+}
+
+// resolveTerraformVersion returns ctx with the version of terraform to run
+// the project in absPath with.
+func (p *DefaultProjectCommandRunner) resolveTerraformVersion(ctx models.ProjectCommandContext, absPath string) (models.ProjectCommandContext, error) {
+	if p.TerraformVersionResolver == nil {
+		return ctx, nil
+	}
+	v, err := p.TerraformVersionResolver.ResolveVersion(ctx.Log, ctx.ProjectConfig, absPath)
+	if err != nil {
+		return ctx, errors.Wrap(err, "resolving terraform version")
+	}
+	ctx.TerraformVersion = v
+	return ctx, nil
+}
+
+// saveTerraformVersion records the version of terraform in ctx as the version
+// the plan in absPath was made with.
+func (p *DefaultProjectCommandRunner) saveTerraformVersion(ctx models.ProjectCommandContext, absPath string) error {
+	if p.TerraformVersionResolver == nil {
+		return nil
+	}
+	var v string
+	if ctx.TerraformVersion != nil {
+		v = ctx.TerraformVersion.String()
+	}
+	if err := ioutil.WriteFile(p.terraformVersionFile(ctx, absPath), []byte(v), 0600); err != nil {
+		return errors.Wrap(err, "recording the plan's terraform version")
+	}
+	return nil
+}
+
+// planTerraformVersion returns ctx with the version of terraform the plan in
+// absPath was made with. If it wasn't recorded, ex. because the plan was made
+// before versions were recorded, the version is resolved again.
+func (p *DefaultProjectCommandRunner) planTerraformVersion(ctx models.ProjectCommandContext, absPath string) (models.ProjectCommandContext, error) {
+	if p.TerraformVersionResolver == nil {
+		return ctx, nil
+	}
+	contents, err := ioutil.ReadFile(p.terraformVersionFile(ctx, absPath))
+	if os.IsNotExist(err) {
+		return p.resolveTerraformVersion(ctx, absPath)
+	}
+	if err != nil {
+		return ctx, errors.Wrap(err, "reading the plan's terraform version")
+	}
+	ctx.TerraformVersion = nil
+	if v := strings.TrimSpace(string(contents)); v != "" {
+		ctx.TerraformVersion, err = version.NewVersion(v)
+		if err != nil {
+			return ctx, errors.Wrapf(err, "parsing the plan's terraform version %q", v)
+		}
+	}
+	return ctx, nil
+}
+
+// terraformVersionFile returns the path of the file that records the version
+// of terraform the plan in absPath was made with.
+func (p *DefaultProjectCommandRunner) terraformVersionFile(ctx models.ProjectCommandContext, absPath string) string {
+	return filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectConfig)+terraformVersionSuffix)
+}
+
+// savePlanChanges records changes as how many resources the plan in absPath
+// will change. Failing to record them doesn't fail the plan since they're
+// only informational.
+func (p *DefaultProjectCommandRunner) savePlanChanges(ctx models.ProjectCommandContext, absPath string, changes *models.PlanChanges) {
+	path := p.planChangesFile(ctx, absPath)
+	if changes == nil {
+		// Remove the changes of a previous plan so they aren't mistaken for
+		// this plan's.
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			ctx.Log.Warn("unable to remove the previous plan's changes: %s", err)
+		}
+		return
+	}
+	contents, err := json.Marshal(changes)
+	if err == nil {
+		err = ioutil.WriteFile(path, contents, 0600)
+	}
+	if err != nil {
+		ctx.Log.Warn("unable to record the plan's changes: %s", err)
+	}
+}
+
+// planChanges returns how many resources the plan in absPath will change, or
+// nil if they weren't recorded.
+func (p *DefaultProjectCommandRunner) planChanges(ctx models.ProjectCommandContext, absPath string) *models.PlanChanges {
+	contents, err := ioutil.ReadFile(p.planChangesFile(ctx, absPath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	var changes models.PlanChanges
+	if err == nil {
+		err = json.Unmarshal(contents, &changes)
+	}
+	if err != nil {
+		ctx.Log.Warn("unable to read the plan's changes: %s", err)
+		return nil
+	}
+	return &changes
+}
+
+// planChangesFile returns the path of the file that records how many
+// resources the plan in absPath will change.
+func (p *DefaultProjectCommandRunner) planChangesFile(ctx models.ProjectCommandContext, absPath string) string {
+	return filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectConfig)+planChangesSuffix)
+}
+
+// lockURL returns the URL of the project's lock.
+func (p *DefaultProjectCommandRunner) lockURL(ctx models.ProjectCommandContext) string {
+	if p.LockURLGenerator == nil {
+		return ""
+	}
+	project := models.NewProject(ctx.BaseRepo.FullName, ctx.RepoRelDir)
+	return p.LockURLGenerator.GenerateLockURL(locking.GenerateLockKey(project, ctx.Workspace))
+}
+
+// unlockAfterPlanError releases the project's lock since a failed plan can't be
+// applied.
+func (p *DefaultProjectCommandRunner) unlockAfterPlanError(ctx models.ProjectCommandContext, lockAttempt *TryLockResponse) {
+	if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
+		ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
+	}
+}
+
+// sendFailure sends a command failure webhook if cmdName failed for the
+// project.
+func (p *DefaultProjectCommandRunner) sendFailure(ctx models.ProjectCommandContext, cmdName string, failure string, err error) {
+	if err == nil && failure == "" {
+		return
+	}
+	reason := failure
+	if err != nil {
+		reason = err.Error()
+	}
+	p.sendWebhook(ctx, webhooks.CommandFailure{
 		Workspace: ctx.Workspace,
+		Dir:       ctx.RepoRelDir,
+		Project:   ctx.GetProjectName(),
 		User:      ctx.User,
 		Repo:      ctx.BaseRepo,
 		Pull:      ctx.Pull,
-		Success:   err == nil,
+		Command:   cmdName,
+		Error:     reason,
 	})
-	if err != nil {
-		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+}
+
+// sendWebhook sends the webhooks for event. Webhooks are best effort so errors
+// are only logged by the sender.
+func (p *DefaultProjectCommandRunner) sendWebhook(ctx models.ProjectCommandContext, event webhooks.Event) {
+	if p.Webhooks == nil {
+		return
+	}
+	p.Webhooks.Send(ctx.Log, event) // nolint: errcheck
+}
+
+// checkRequirements returns a failure if the pull request doesn't meet the
+// apply or destroy requirements reqs. cmdName is the command being run, ex.
+// apply.
+func (p *DefaultProjectCommandRunner) checkRequirements(ctx models.ProjectCommandContext, repoDir string, reqs []string, cmdName string) (failure string, err error) {
+	for _, req := range reqs {
+		// The requirements are the same for apply and destroy.
+		switch req {
+		case raw.ApprovedApplyRequirement:
+			approved, err := p.PullApprovedChecker.PullIsApproved(ctx.BaseRepo, ctx.Pull) // nolint: vetshadow
+			if err != nil {
+				return "", errors.Wrap(err, "checking if pull request was approved")
+			}
+			if !approved {
+				return fmt.Sprintf("Pull request must be approved before running %s.", cmdName), nil
+			}
+		case raw.MergeableApplyRequirement:
+			mergeable, err := p.PullMergeableChecker.PullIsMergeable(ctx.BaseRepo, ctx.Pull) // nolint: vetshadow
+			if err != nil {
+				return "", errors.Wrap(err, "checking if pull request was mergeable")
+			}
+			if !mergeable {
+				return fmt.Sprintf("Pull request must be mergeable before running %s.", cmdName), nil
+			}
+		case raw.CodeOwnersApprovedApplyRequirement:
+			reason, err := p.CodeOwnersChecker.CheckApproved(ctx, repoDir) // nolint: vetshadow
+			if err != nil {
+				return "", errors.Wrap(err, "checking if code owners approved pull request")
+			}
+			if reason != "" {
+				return fmt.Sprintf("Pull request must be approved by a code owner of %q before running %s but %s.", ctx.RepoRelDir, cmdName, reason), nil
+			}
+		default:
+			required, ok := raw.ParseApprovalsRequirement(req)
+			if !ok {
+				// Requirements are validated when the config is loaded so
+				// this shouldn't happen, but we don't want a requirement we
+				// don't understand to be skipped.
+				return "", fmt.Errorf("unsupported %s requirement %q", cmdName, req)
+			}
+			approvers, err := p.PullApprovedChecker.GetApprovers(ctx.BaseRepo, ctx.Pull) // nolint: vetshadow
+			if err != nil {
+				return "", errors.Wrap(err, "getting pull request approvers")
+			}
+			if n := countApprovers(approvers, ctx.Pull.Author); n < required {
+				return fmt.Sprintf("Pull request must be approved by at least %d reviewers other than its author before running %s, it has %d.", required, cmdName, n), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// countApprovers returns the number of distinct approvers, not counting the
+// pull request's author.
+func countApprovers(approvers []string, author string) int {
+	seen := make(map[string]bool)
+	for _, a := range approvers {
+		a = strings.ToLower(a)
+		if a != strings.ToLower(author) {
+			seen[a] = true
+		}
 	}
-	return strings.Join(outputs, "\n"), "", nil
+	return len(seen)
 }
 
 func (p DefaultProjectCommandRunner) defaultPlanStage() valid.Stage {
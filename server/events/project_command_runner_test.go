@@ -14,7 +14,10 @@
 package events_test
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -22,10 +25,14 @@ import (
 	"github.com/cloudposse/atlantis/server/events/mocks"
 	"github.com/cloudposse/atlantis/server/events/mocks/matchers"
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/policy"
+	policymocks "github.com/cloudposse/atlantis/server/events/policy/mocks"
 	mocks2 "github.com/cloudposse/atlantis/server/events/runtime/mocks"
+	"github.com/cloudposse/atlantis/server/events/webhooks"
 	"github.com/cloudposse/atlantis/server/events/yaml/valid"
 	"github.com/cloudposse/atlantis/server/logging"
 	. "github.com/cloudposse/atlantis/testing"
+	"github.com/hashicorp/go-version"
 	. "github.com/petergtz/pegomock"
 )
 
@@ -229,6 +236,152 @@ func TestDefaultProjectCommandRunner_ApplyNotApproved(t *testing.T) {
 	Equals(t, "Pull request must be approved before running apply.", res.Failure)
 }
 
+func TestDefaultProjectCommandRunner_ApplyNotMergeable(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockMergeable := mocks2.NewMockPullMergeableChecker()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:           mockWorkingDir,
+		PullMergeableChecker: mockMergeable,
+		WorkingDirLocker:     events.NewDefaultWorkingDirLocker(),
+	}
+	ctx := models.ProjectCommandContext{
+		ProjectConfig: &valid.Project{
+			Dir:               ".",
+			ApplyRequirements: []string{"mergeable"},
+		},
+	}
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn("/tmp/mydir", nil)
+	When(mockMergeable.PullIsMergeable(ctx.BaseRepo, ctx.Pull)).ThenReturn(false, nil)
+
+	res := runner.Apply(ctx)
+	Equals(t, "Pull request must be mergeable before running apply.", res.Failure)
+}
+
+func TestDefaultProjectCommandRunner_DestroyNotMergeable(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockMergeable := mocks2.NewMockPullMergeableChecker()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:           mockWorkingDir,
+		PullMergeableChecker: mockMergeable,
+		WorkingDirLocker:     events.NewDefaultWorkingDirLocker(),
+	}
+	ctx := models.ProjectCommandContext{
+		ProjectConfig: &valid.Project{
+			Dir:                 ".",
+			DestroyRequirements: []string{"mergeable"},
+		},
+	}
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn("/tmp/mydir", nil)
+	When(mockMergeable.PullIsMergeable(ctx.BaseRepo, ctx.Pull)).ThenReturn(false, nil)
+
+	res := runner.Destroy(ctx)
+	Equals(t, "Pull request must be mergeable before running destroy.", res.Failure)
+}
+
+func TestDefaultProjectCommandRunner_ApplyCodeOwnersNotApproved(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockCodeOwners := mocks.NewMockCodeOwnersChecker()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:        mockWorkingDir,
+		CodeOwnersChecker: mockCodeOwners,
+		WorkingDirLocker:  events.NewDefaultWorkingDirLocker(),
+	}
+	ctx := models.ProjectCommandContext{
+		RepoRelDir: "envs/prod",
+		ProjectConfig: &valid.Project{
+			Dir:               "envs/prod",
+			ApplyRequirements: []string{"codeowners_approved"},
+		},
+	}
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn("/tmp/mydir", nil)
+	When(mockCodeOwners.CheckApproved(ctx, "/tmp/mydir")).ThenReturn("none of its code owners (@org/infra) have approved it", nil)
+
+	res := runner.Apply(ctx)
+	Equals(t, "Pull request must be approved by a code owner of \"envs/prod\" before running apply but none of its code owners (@org/infra) have approved it.", res.Failure)
+}
+
+func TestDefaultProjectCommandRunner_ApplyApprovalsRequirement(t *testing.T) {
+	cases := []struct {
+		description string
+		approvers   []string
+		expFailure  string
+	}{
+		{
+			description: "enough approvals",
+			approvers:   []string{"alice", "bob"},
+			expFailure:  "",
+		},
+		{
+			description: "author's approval isn't counted",
+			approvers:   []string{"alice", "Author"},
+			expFailure:  "Pull request must be approved by at least 2 reviewers other than its author before running apply, it has 1.",
+		},
+		{
+			description: "approvers are only counted once",
+			approvers:   []string{"alice", "alice"},
+			expFailure:  "Pull request must be approved by at least 2 reviewers other than its author before running apply, it has 1.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockApproved := mocks2.NewMockPullApprovedChecker()
+			mockApply := mocks.NewMockStepRunner()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:          mockWorkingDir,
+				PullApprovedChecker: mockApproved,
+				ApplyStepRunner:     mockApply,
+				Webhooks:            mocks.NewMockWebhooksSender(),
+				WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
+			}
+			ctx := models.ProjectCommandContext{
+				Log:  logging.NewNoopLogger(),
+				Pull: models.PullRequest{Author: "author"},
+				ProjectConfig: &valid.Project{
+					Dir:               ".",
+					ApplyRequirements: []string{"approvals: 2"},
+				},
+			}
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn("/tmp/mydir", nil)
+			When(mockApproved.GetApprovers(ctx.BaseRepo, ctx.Pull)).ThenReturn(c.approvers, nil)
+			When(mockApply.Run(ctx, nil, "/tmp/mydir")).ThenReturn("apply", nil)
+
+			res := runner.Apply(ctx)
+			Equals(t, c.expFailure, res.Failure)
+		})
+	}
+}
+
+// A requirement we don't understand should fail the apply rather than be
+// skipped.
+func TestDefaultProjectCommandRunner_ApplyUnsupportedRequirement(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockApply := mocks.NewMockStepRunner()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:       mockWorkingDir,
+		ApplyStepRunner:  mockApply,
+		Webhooks:         mocks.NewMockWebhooksSender(),
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+	}
+	ctx := models.ProjectCommandContext{
+		Log: logging.NewNoopLogger(),
+		ProjectConfig: &valid.Project{
+			Dir:               ".",
+			ApplyRequirements: []string{"approvals: two"},
+		},
+	}
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn("/tmp/mydir", nil)
+
+	res := runner.Apply(ctx)
+	ErrEquals(t, "unsupported apply requirement \"approvals: two\"", res.Error)
+	mockApply.VerifyWasCalled(Never()).Run(ctx, nil, "/tmp/mydir")
+}
+
 func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 	cases := []struct {
 		description string
@@ -407,6 +560,534 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 	}
 }
 
+func TestDefaultProjectCommandRunner_PlanWebhooks(t *testing.T) {
+	runner, sender, ctx := setupPlanWebhooks(t, "Plan: 1 to add, 0 to change, 2 to destroy.", nil)
+
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	Equals(t, &models.PlanChanges{Add: 1, Change: 0, Destroy: 2}, res.PlanSuccess.Changes())
+
+	_, sent := sender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, webhooks.LockResult{
+		Workspace: "default",
+		Dir:       ".",
+		Locked:    true,
+		LockURL:   "https://lock-key",
+	}, sent[0])
+	plan := sent[1].(webhooks.PlanResult)
+	Equals(t, true, plan.Success)
+	Equals(t, &models.PlanChanges{Add: 1, Change: 0, Destroy: 2}, plan.Changes)
+}
+
+func TestDefaultProjectCommandRunner_PlanAlreadyLockedWebhooks(t *testing.T) {
+	t.Log("planning again with the pull request's lock shouldn't send a lock webhook")
+	runner, sender, ctx := setupPlanWebhooks(t, "Plan: 1 to add, 0 to change, 2 to destroy.", nil)
+	When(runner.Locker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+	}, nil)
+
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	_, sent := sender.VerifyWasCalledOnce().Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, "plan", sent[0].EventType())
+}
+
+func TestDefaultProjectCommandRunner_PlanErrorWebhooks(t *testing.T) {
+	runner, sender, ctx := setupPlanWebhooks(t, "", errors.New("plan failed"))
+
+	res := runner.Plan(ctx)
+	ErrEquals(t, "plan failed\n", res.Error)
+
+	_, sent := sender.VerifyWasCalled(Times(3)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	var types []string
+	for _, e := range sent {
+		types = append(types, e.EventType())
+	}
+	Equals(t, []string{"lock", "plan", "command_failure"}, types)
+	Equals(t, false, sent[1].(webhooks.PlanResult).Success)
+	Assert(t, sent[1].(webhooks.PlanResult).Changes == nil, "exp no changes for a failed plan")
+	Equals(t, webhooks.CommandFailure{
+		Workspace: "default",
+		Dir:       ".",
+		Command:   "plan",
+		Error:     "plan failed\n",
+	}, sent[2])
+}
+
+func TestDefaultProjectCommandRunner_ApplyFailureWebhook(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockApproved := mocks2.NewMockPullApprovedChecker()
+	mockSender := mocks.NewMockWebhooksSender()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:              mockWorkingDir,
+		PullApprovedChecker:     mockApproved,
+		Webhooks:                mockSender,
+		WorkingDirLocker:        events.NewDefaultWorkingDirLocker(),
+		RequireApprovalOverride: true,
+	}
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(), Workspace: "default", RepoRelDir: "."}
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn("/tmp/mydir", nil)
+	When(mockApproved.PullIsApproved(ctx.BaseRepo, ctx.Pull)).ThenReturn(false, nil)
+
+	runner.Apply(ctx)
+	mockSender.VerifyWasCalledOnce().Send(ctx.Log, webhooks.CommandFailure{
+		Workspace: "default",
+		Dir:       ".",
+		Command:   "apply",
+		Error:     "Pull request must be approved before running apply.",
+	})
+}
+
+func TestDefaultProjectCommandRunner_PlanSummary(t *testing.T) {
+	runner, sender, ctx := setupPlanWebhooks(t, "Plan: 1 to add, 0 to change, 0 to destroy.", nil)
+	mockShow := mocks.NewMockStepRunner()
+	runner.ShowStepRunner = mockShow
+	When(mockShow.Run(ctx, nil, "/tmp/mydir")).ThenReturn(`{"resource_changes": [
+		{"address": "null_resource.a", "change": {"actions": ["delete", "create"]}}
+	]}`, nil)
+
+	res := runner.Plan(ctx)
+	Ok(t, res.Error)
+	Equals(t, &models.PlanSummary{
+		Replace:   1,
+		Resources: []models.PlanResourceChange{{Address: "null_resource.a", Action: models.PlanActionReplace}},
+	}, res.PlanSuccess.PlanSummary)
+	// The summary's counts should be used instead of Terraform's output.
+	Equals(t, &models.PlanChanges{Add: 1, Destroy: 1}, res.PlanSuccess.Changes())
+	_, sent := sender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, &models.PlanChanges{Add: 1, Destroy: 1}, sent[1].(webhooks.PlanResult).Changes)
+}
+
+func TestDefaultProjectCommandRunner_PlanSummaryError(t *testing.T) {
+	runner, _, ctx := setupPlanWebhooks(t, "Plan: 1 to add, 0 to change, 0 to destroy.", nil)
+	mockShow := mocks.NewMockStepRunner()
+	runner.ShowStepRunner = mockShow
+	When(mockShow.Run(ctx, nil, "/tmp/mydir")).ThenReturn("", errors.New("show failed"))
+
+	// Failing to summarize the plan shouldn't fail the plan.
+	res := runner.Plan(ctx)
+	Ok(t, res.Error)
+	Assert(t, res.PlanSuccess.PlanSummary == nil, "exp no plan summary")
+	Equals(t, &models.PlanChanges{Add: 1}, res.PlanSuccess.Changes())
+}
+
+func TestDefaultProjectCommandRunner_PlanChanges(t *testing.T) {
+	runner, _, ctx := setupPlanWebhooks(t, "", nil)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	When(runner.WorkingDir.(*mocks.MockWorkingDir).Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(tmp, nil)
+	mockPlan := runner.PlanStepRunner.(*mocks.MockStepRunner)
+	When(mockPlan.Run(ctx, nil, tmp)).
+		ThenReturn("Plan: 1 to add, 2 to change, 3 to destroy.", nil).
+		ThenReturn("no summary", nil)
+
+	res := runner.Plan(ctx)
+	Ok(t, res.Error)
+
+	// The changes should be recorded so they're sent with the apply.
+	recorded, err := ioutil.ReadFile(filepath.Join(tmp, "default.tfplan.changes"))
+	Ok(t, err)
+	Equals(t, `{"Add":1,"Change":2,"Destroy":3}`, string(recorded))
+
+	// Planning again when the changes can't be found should remove them.
+	res = runner.Plan(ctx)
+	Ok(t, res.Error)
+	_, err = os.Stat(filepath.Join(tmp, "default.tfplan.changes"))
+	Assert(t, os.IsNotExist(err), "exp changes to be removed")
+}
+
+func TestDefaultProjectCommandRunner_ApplyChangesWebhook(t *testing.T) {
+	cases := []struct {
+		description string
+		// recorded is the changes recorded with the plan. If empty, none were
+		// recorded.
+		recorded   string
+		expChanges *models.PlanChanges
+	}{
+		{
+			description: "recorded",
+			recorded:    `{"Add":1,"Change":2,"Destroy":3}`,
+			expChanges:  &models.PlanChanges{Add: 1, Change: 2, Destroy: 3},
+		},
+		{
+			description: "not recorded",
+			expChanges:  nil,
+		},
+		{
+			description: "invalid",
+			recorded:    "invalid",
+			expChanges:  nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockApply := mocks.NewMockStepRunner()
+			mockSender := mocks.NewMockWebhooksSender()
+			runner := &events.DefaultProjectCommandRunner{
+				ApplyStepRunner:  mockApply,
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				Webhooks:         mockSender,
+			}
+			ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(), Workspace: "default", RepoRelDir: "."}
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			When(mockApply.Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), AnyString())).ThenReturn("Apply complete!", nil)
+			if c.recorded != "" {
+				Ok(t, ioutil.WriteFile(filepath.Join(tmp, "default.tfplan.changes"), []byte(c.recorded), 0600))
+			}
+
+			res := runner.Apply(ctx)
+			Ok(t, res.Error)
+			_, sent := mockSender.VerifyWasCalledOnce().Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetCapturedArguments()
+			apply := sent.(webhooks.ApplyResult)
+			Equals(t, true, apply.Success)
+			Equals(t, c.expChanges, apply.Changes)
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_PlanTerraformVersion(t *testing.T) {
+	runner, _, ctx := setupPlanWebhooks(t, "", nil)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	When(runner.WorkingDir.(*mocks.MockWorkingDir).Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(tmp, nil)
+	mockResolver := mocks.NewMockTerraformVersionResolver()
+	runner.TerraformVersionResolver = mockResolver
+	tfVersion, _ := version.NewVersion("0.11.14")
+	When(mockResolver.ResolveVersion(ctx.Log, ctx.ProjectConfig, tmp)).ThenReturn(tfVersion, nil)
+
+	res := runner.Plan(ctx)
+	Ok(t, res.Error)
+	Equals(t, "0.11.14", res.PlanSuccess.TerraformVersion)
+
+	// The plan step should run with the resolved version.
+	expCtx := ctx
+	expCtx.TerraformVersion = tfVersion
+	runner.PlanStepRunner.(*mocks.MockStepRunner).VerifyWasCalledOnce().Run(expCtx, nil, tmp)
+
+	// The version should be recorded so the plan is applied with it.
+	recorded, err := ioutil.ReadFile(filepath.Join(tmp, "default.tfplan.terraform-version"))
+	Ok(t, err)
+	Equals(t, "0.11.14", string(recorded))
+}
+
+func TestDefaultProjectCommandRunner_ApplyTerraformVersion(t *testing.T) {
+	cases := []struct {
+		description string
+		// recorded is the version recorded with the plan. If nil, no version
+		// was recorded.
+		recorded   *string
+		expVersion string
+	}{
+		{
+			description: "recorded version",
+			recorded:    String("0.11.14"),
+			expVersion:  "0.11.14",
+		},
+		{
+			description: "recorded default version",
+			recorded:    String(""),
+			expVersion:  "",
+		},
+		{
+			description: "not recorded",
+			recorded:    nil,
+			expVersion:  "0.12.0",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockApply := mocks.NewMockStepRunner()
+			mockResolver := mocks.NewMockTerraformVersionResolver()
+			runner := &events.DefaultProjectCommandRunner{
+				ApplyStepRunner:          mockApply,
+				WorkingDir:               mockWorkingDir,
+				WorkingDirLocker:         events.NewDefaultWorkingDirLocker(),
+				TerraformVersionResolver: mockResolver,
+			}
+			ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(), Workspace: "default", RepoRelDir: "."}
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			resolved, _ := version.NewVersion("0.12.0")
+			When(mockResolver.ResolveVersion(ctx.Log, ctx.ProjectConfig, tmp)).ThenReturn(resolved, nil)
+			if c.recorded != nil {
+				Ok(t, ioutil.WriteFile(filepath.Join(tmp, "default.tfplan.terraform-version"), []byte(*c.recorded), 0600))
+			}
+
+			res := runner.Apply(ctx)
+			Ok(t, res.Error)
+			applyCtx, _, _ := mockApply.VerifyWasCalledOnce().Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), AnyString()).GetCapturedArguments()
+			if c.expVersion == "" {
+				Assert(t, applyCtx.TerraformVersion == nil, "exp default version")
+			} else {
+				Equals(t, c.expVersion, applyCtx.TerraformVersion.String())
+			}
+			if c.recorded != nil {
+				mockResolver.VerifyWasCalled(Never()).ResolveVersion(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyPtrToValidProject(), AnyString())
+			}
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_PlanTerraformVersionError(t *testing.T) {
+	runner, sender, ctx := setupPlanWebhooks(t, "", nil)
+	mockResolver := mocks.NewMockTerraformVersionResolver()
+	runner.TerraformVersionResolver = mockResolver
+	When(mockResolver.ResolveVersion(ctx.Log, ctx.ProjectConfig, "/tmp/mydir")).ThenReturn(nil, errors.New("no match"))
+
+	res := runner.Plan(ctx)
+	ErrEquals(t, "resolving terraform version: no match", res.Error)
+
+	// The unlock webhook is sent by the project locker's locking.Locker.
+	_, sent := sender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	var types []string
+	for _, e := range sent {
+		types = append(types, e.EventType())
+	}
+	Equals(t, []string{"lock", "command_failure"}, types)
+}
+
+func TestDefaultProjectCommandRunner_PlanPolicyCheckPending(t *testing.T) {
+	runner, _, ctx := setupPlanWebhooks(t, "", nil)
+	runner.PolicyChecker = policymocks.NewMockChecker()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	When(runner.WorkingDir.(*mocks.MockWorkingDir).Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(tmp, nil)
+
+	res := runner.Plan(ctx)
+	Ok(t, res.Error)
+	// Until the plan is checked it can't be applied.
+	_, err := os.Stat(filepath.Join(tmp, "default.tfplan.policy-check"))
+	Ok(t, err)
+}
+
+func TestDefaultProjectCommandRunner_PolicyCheck(t *testing.T) {
+	cases := []struct {
+		description string
+		results     []policy.Result
+		expPassed   bool
+	}{
+		{
+			description: "passed",
+			results:     []policy.Result{{PolicySet: "s3"}},
+			expPassed:   true,
+		},
+		{
+			description: "failed",
+			results: []policy.Result{
+				{PolicySet: "s3"},
+				{PolicySet: "instance-types", Violations: []string{"too big"}},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			runner, checker, ctx, tmp, cleanup := setupPolicyCheck(t)
+			defer cleanup()
+			When(checker.Check(ctx.Log, `{"resource_changes": []}`)).ThenReturn(c.results, nil)
+
+			res := runner.PolicyCheck(ctx)
+			Ok(t, res.Error)
+			Equals(t, &events.PolicyCheckSuccess{
+				Results:            c.results,
+				ApplyCmd:           "atlantis apply",
+				RePlanCmd:          "atlantis plan",
+				ApprovePoliciesCmd: "atlantis approve_policies",
+			}, res.PolicyCheckSuccess)
+			Equals(t, c.expPassed, res.Status() == models.SuccessCommitStatus)
+
+			// Only plans that passed can be applied.
+			res = runner.Apply(ctx)
+			if c.expPassed {
+				Equals(t, "", res.Failure)
+			} else {
+				Equals(t, "Plan must pass its policy checks or be approved by a policy approver with `atlantis approve_policies` before running apply.", res.Failure)
+			}
+			_, err := os.Stat(filepath.Join(tmp, "default.tfplan.policy-check"))
+			Equals(t, !c.expPassed, err == nil)
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_DestroyPoliciesNotPassed(t *testing.T) {
+	t.Log("plans that haven't passed their policy checks can't be destroyed either")
+	runner, _, ctx, tmp, cleanup := setupPolicyCheck(t)
+	defer cleanup()
+	mockDestroy := mocks.NewMockStepRunner()
+	runner.DestroyStepRunner = mockDestroy
+
+	res := runner.Destroy(ctx)
+	Ok(t, res.Error)
+	Equals(t, "Plan must pass its policy checks or be approved by a policy approver with `atlantis approve_policies` before running destroy.", res.Failure)
+	mockDestroy.VerifyWasCalled(Never()).Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), AnyString())
+
+	// Once the policies are approved it can be destroyed.
+	Ok(t, runner.ApprovePolicies(ctx).Error)
+	_, err := os.Stat(filepath.Join(tmp, "default.tfplan.policy-check"))
+	Assert(t, os.IsNotExist(err), "exp policy check file to be removed")
+	res = runner.Destroy(ctx)
+	Equals(t, "", res.Failure)
+}
+
+func TestDefaultProjectCommandRunner_PolicyCheckNoPlanJSON(t *testing.T) {
+	runner, _, ctx, tmp, cleanup := setupPolicyCheck(t)
+	defer cleanup()
+	When(runner.ShowStepRunner.Run(ctx, nil, tmp)).ThenReturn("", nil)
+
+	res := runner.PolicyCheck(ctx)
+	ErrEquals(t, "policy checks require Terraform 0.12 or later and a saved plan", res.Error)
+	// The plan still can't be applied.
+	_, err := os.Stat(filepath.Join(tmp, "default.tfplan.policy-check"))
+	Ok(t, err)
+}
+
+func TestDefaultProjectCommandRunner_ApprovePolicies(t *testing.T) {
+	runner, _, ctx, tmp, cleanup := setupPolicyCheck(t)
+	defer cleanup()
+
+	res := runner.ApprovePolicies(ctx)
+	Ok(t, res.Error)
+	Equals(t, "Policies approved by @approver. To **apply** this plan, comment:\n* `atlantis apply`", res.ApprovePoliciesSuccess)
+	_, err := os.Stat(filepath.Join(tmp, "default.tfplan.policy-check"))
+	Assert(t, os.IsNotExist(err), "exp policy check file to be removed")
+
+	res = runner.ApprovePolicies(ctx)
+	Ok(t, res.Error)
+	Equals(t, "This plan didn't fail any policy checks so it didn't need to be approved.", res.ApprovePoliciesSuccess)
+}
+
+// setupPolicyCheck returns a runner whose plan in tmp hasn't been checked
+// against the mock checker yet.
+func setupPolicyCheck(t *testing.T) (*events.DefaultProjectCommandRunner, *policymocks.MockChecker, models.ProjectCommandContext, string, func()) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	Ok(t, ioutil.WriteFile(filepath.Join(tmp, "default.tfplan.policy-check"), []byte("pending"), 0600))
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockShow := mocks.NewMockStepRunner()
+	mockApply := mocks.NewMockStepRunner()
+	mockChecker := policymocks.NewMockChecker()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		ShowStepRunner:   mockShow,
+		ApplyStepRunner:  mockApply,
+		PolicyChecker:    mockChecker,
+	}
+	ctx := models.ProjectCommandContext{
+		Log:                logging.NewNoopLogger(),
+		Workspace:          "default",
+		RepoRelDir:         ".",
+		User:               models.User{Username: "approver"},
+		ApplyCmd:           "atlantis apply",
+		RePlanCmd:          "atlantis plan",
+		ApprovePoliciesCmd: "atlantis approve_policies",
+		ProjectConfig: &valid.Project{
+			Dir:      ".",
+			Workflow: String("myworkflow"),
+		},
+		GlobalConfig: &valid.Config{
+			Workflows: map[string]valid.Workflow{
+				"myworkflow": {
+					Apply: &valid.Stage{Steps: []valid.Step{{StepName: "apply"}}},
+				},
+			},
+		},
+	}
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+	When(mockShow.Run(ctx, nil, tmp)).ThenReturn(`{"resource_changes": []}`, nil)
+	return runner, mockChecker, ctx, tmp, cleanup
+}
+
+// setupPlanWebhooks returns a runner whose plan step returns planOut and
+// planErr and the sender its webhooks are sent with.
+func setupPlanWebhooks(t *testing.T, planOut string, planErr error) (*events.DefaultProjectCommandRunner, *mocks.MockWebhooksSender, models.ProjectCommandContext) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	mockSender := mocks.NewMockWebhooksSender()
+	runner := &events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		PlanStepRunner:   mockPlan,
+		WorkingDir:       mockWorkingDir,
+		Webhooks:         mockSender,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+	}
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn("/tmp/mydir", nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+		LockCreated:  true,
+	}, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:        logging.NewNoopLogger(),
+		Workspace:  "default",
+		RepoRelDir: ".",
+		ProjectConfig: &valid.Project{
+			Dir:      ".",
+			Workflow: String("myworkflow"),
+		},
+		GlobalConfig: &valid.Config{
+			Workflows: map[string]valid.Workflow{
+				"myworkflow": {
+					Plan: &valid.Stage{Steps: []valid.Step{{StepName: "plan"}}},
+				},
+			},
+		},
+	}
+	When(mockPlan.Run(ctx, nil, "/tmp/mydir")).ThenReturn(planOut, planErr)
+	return runner, mockSender, ctx
+}
+
 type mockURLGenerator struct{}
 
 func (m mockURLGenerator) GenerateLockURL(lockID string) string {
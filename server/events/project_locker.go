@@ -53,6 +53,9 @@ type TryLockResponse struct {
 	UnlockFn func() error
 	// LockKey is the key for the lock if the lock was acquired.
 	LockKey string
+	// LockCreated is true if the lock was created by this call. It's false if
+	// the pull request already held the lock.
+	LockCreated bool
 }
 
 // TryLock implements ProjectLocker.TryLock.
@@ -77,6 +80,7 @@ func (p *DefaultProjectLocker) TryLock(log *logging.SimpleLogger, pull models.Pu
 			_, err := p.Locker.Unlock(lockAttempt.LockKey)
 			return err
 		},
-		LockKey: lockAttempt.LockKey,
+		LockKey:     lockAttempt.LockKey,
+		LockCreated: lockAttempt.LockAcquired,
 	}, nil
 }
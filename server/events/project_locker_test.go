@@ -84,6 +84,7 @@ func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 	res, err := locker.TryLock(logging.NewNoopLogger(), expPull, expUser, expWorkspace, expProject)
 	Ok(t, err)
 	Equals(t, true, res.LockAcquired)
+	Equals(t, false, res.LockCreated)
 
 	// UnlockFn should work.
 	mockLocker.VerifyWasCalled(Never()).Unlock(lockKey)
@@ -120,6 +121,7 @@ func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 	res, err := locker.TryLock(logging.NewNoopLogger(), expPull, expUser, expWorkspace, expProject)
 	Ok(t, err)
 	Equals(t, true, res.LockAcquired)
+	Equals(t, true, res.LockCreated)
 
 	// UnlockFn should work.
 	mockLocker.VerifyWasCalled(Never()).Unlock(lockKey)
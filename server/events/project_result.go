@@ -26,6 +26,10 @@ type ProjectResult struct {
 	PlanSuccess    *PlanSuccess
 	ApplySuccess   string
 	DestroySuccess string
+	// PolicyCheckSuccess is set if the policy checks ran, even if the plan
+	// failed them.
+	PolicyCheckSuccess     *PolicyCheckSuccess
+	ApprovePoliciesSuccess string
 }
 
 // Status returns the vcs commit status of this project result.
@@ -36,5 +40,8 @@ func (p ProjectResult) Status() models.CommitStatus {
 	if p.Failure != "" {
 		return models.FailedCommitStatus
 	}
+	if p.PolicyCheckSuccess != nil && !p.PolicyCheckSuccess.Passed() {
+		return models.FailedCommitStatus
+	}
 	return models.SuccessCommitStatus
 }
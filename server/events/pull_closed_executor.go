@@ -41,6 +41,15 @@ type PullClosedExecutor struct {
 	Locker     locking.Locker
 	VCSClient  vcs.ClientProxy
 	WorkingDir WorkingDir
+	// CommentOutputStore is optional. If set, the outputs stored for the
+	// pull request are deleted.
+	CommentOutputStore CommentOutputStore
+	// PullCommentStore is optional. If set, the comment ids stored for the
+	// pull request are deleted.
+	PullCommentStore PullCommentStore
+	// CommitStatusUpdater is optional. If set, it forgets the statuses it
+	// published for the pull request.
+	CommitStatusUpdater CommitStatusUpdater
 }
 
 type templatedProject struct {
@@ -59,6 +68,22 @@ func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullReque
 		return errors.Wrap(err, "cleaning workspace")
 	}
 
+	if p.CommentOutputStore != nil {
+		if err := p.CommentOutputStore.DeleteForPull(repo, pull.Num); err != nil {
+			return errors.Wrap(err, "cleaning up stored outputs")
+		}
+	}
+
+	if p.PullCommentStore != nil {
+		if err := p.PullCommentStore.DeleteForPull(repo, pull.Num); err != nil {
+			return errors.Wrap(err, "cleaning up stored comment ids")
+		}
+	}
+
+	if p.CommitStatusUpdater != nil {
+		p.CommitStatusUpdater.ForgetPull(repo, pull)
+	}
+
 	// Finally, delete locks. We do this last because when someone
 	// unlocks a project, right now we don't actually delete the plan
 	// so we might have plans laying around but no locks.
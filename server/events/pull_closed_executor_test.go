@@ -41,6 +41,70 @@ func TestCleanUpPullWorkspaceErr(t *testing.T) {
 	Equals(t, "cleaning workspace: err", actualErr.Error())
 }
 
+func TestCleanUpPullDeletesOutputs(t *testing.T) {
+	t.Log("when there's a comment output store, the pull request's outputs are deleted")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	store := mocks.NewMockCommentOutputStore()
+	pce := events.PullClosedExecutor{
+		Locker:             l,
+		WorkingDir:         w,
+		CommentOutputStore: store,
+	}
+	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(nil, nil)
+	err := pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
+	Ok(t, err)
+	store.VerifyWasCalledOnce().DeleteForPull(fixtures.GithubRepo, fixtures.Pull.Num)
+}
+
+func TestCleanUpPullDeleteOutputsErr(t *testing.T) {
+	t.Log("when deleting the outputs returns an error, we return it")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	store := mocks.NewMockCommentOutputStore()
+	pce := events.PullClosedExecutor{
+		WorkingDir:         w,
+		CommentOutputStore: store,
+	}
+	When(store.DeleteForPull(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(errors.New("err"))
+	actualErr := pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
+	Equals(t, "cleaning up stored outputs: err", actualErr.Error())
+}
+
+func TestCleanUpPullDeletesCommentIDs(t *testing.T) {
+	t.Log("when there's a pull comment store, the pull request's comment ids are deleted")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	store := mocks.NewMockPullCommentStore()
+	pce := events.PullClosedExecutor{
+		Locker:           l,
+		WorkingDir:       w,
+		PullCommentStore: store,
+	}
+	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(nil, nil)
+	err := pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
+	Ok(t, err)
+	store.VerifyWasCalledOnce().DeleteForPull(fixtures.GithubRepo, fixtures.Pull.Num)
+}
+
+func TestCleanUpPullForgetsStatuses(t *testing.T) {
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	updater := mocks.NewMockCommitStatusUpdater()
+	pce := events.PullClosedExecutor{
+		Locker:              l,
+		WorkingDir:          w,
+		CommitStatusUpdater: updater,
+	}
+	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(nil, nil)
+	err := pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
+	Ok(t, err)
+	updater.VerifyWasCalledOnce().ForgetPull(fixtures.GithubRepo, fixtures.Pull)
+}
+
 func TestCleanUpPullUnlockErr(t *testing.T) {
 	t.Log("when locker.UnlockByPull returns an error, we return it")
 	RegisterMockTestingT(t)
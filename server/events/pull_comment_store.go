@@ -0,0 +1,107 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/pkg/errors"
+)
+
+// commentsDir is the directory in the data dir that we store comment ids in.
+const commentsDir = "comments"
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_pull_comment_store.go PullCommentStore
+
+// PullCommentStore tracks the ids of the comments Atlantis made on each pull
+// request so they can be updated or hidden by later comments.
+type PullCommentStore interface {
+	// Get returns the ids of the comments last made for key on the pull
+	// request, or nil if there are none.
+	Get(repo models.Repo, pullNum int, key string) ([]string, error)
+	// Set stores the ids of the comments made for key on the pull request.
+	Set(repo models.Repo, pullNum int, key string, ids []string) error
+	// DeleteForPull deletes the ids stored for the pull request.
+	DeleteForPull(repo models.Repo, pullNum int) error
+}
+
+// FilePullCommentStore implements PullCommentStore by storing the ids of each
+// pull request's comments as a JSON file under DataDir.
+type FilePullCommentStore struct {
+	DataDir string
+	mutex   sync.Mutex
+}
+
+// Get returns the ids stored for key.
+func (f *FilePullCommentStore) Get(repo models.Repo, pullNum int, key string) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	comments, err := f.read(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	return comments[key], nil
+}
+
+// Set stores ids for key, replacing the ids stored before.
+func (f *FilePullCommentStore) Set(repo models.Repo, pullNum int, key string, ids []string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	comments, err := f.read(repo, pullNum)
+	if err != nil {
+		return err
+	}
+	comments[key] = ids
+
+	dir := filepath.Join(f.DataDir, commentsDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "creating comments dir")
+	}
+	contents, err := json.Marshal(comments)
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	if err := ioutil.WriteFile(f.path(repo, pullNum), contents, 0600); err != nil {
+		return errors.Wrap(err, "writing comment ids")
+	}
+	return nil
+}
+
+// DeleteForPull deletes the pull request's file.
+func (f *FilePullCommentStore) DeleteForPull(repo models.Repo, pullNum int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := os.Remove(f.path(repo, pullNum)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "deleting comment ids")
+	}
+	return nil
+}
+
+// read returns the ids stored for each key of the pull request.
+func (f *FilePullCommentStore) read(repo models.Repo, pullNum int) (map[string][]string, error) {
+	comments := make(map[string][]string)
+	contents, err := ioutil.ReadFile(f.path(repo, pullNum))
+	if os.IsNotExist(err) {
+		return comments, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading comment ids")
+	}
+	if err := json.Unmarshal(contents, &comments); err != nil {
+		return nil, errors.Wrap(err, "parsing comment ids")
+	}
+	return comments, nil
+}
+
+// path returns the path of the pull request's file. It's named after a hash
+// of the pull request so it doesn't need escaping.
+func (f *FilePullCommentStore) path(repo models.Repo, pullNum int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s#%d", repo.VCSHost.Hostname, repo.FullName, pullNum)))
+	return filepath.Join(f.DataDir, commentsDir, hex.EncodeToString(sum[:])+".json")
+}
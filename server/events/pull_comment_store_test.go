@@ -0,0 +1,60 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events"
+	"github.com/cloudposse/atlantis/server/events/models/fixtures"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestFilePullCommentStore_SetGet(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &events.FilePullCommentStore{DataDir: tmp}
+
+	// Getting before anything is set should return nothing.
+	ids, err := store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:.")
+	Ok(t, err)
+	Equals(t, 0, len(ids))
+
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num, "plan:.", []string{"1", "2"}))
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num, "apply:.", []string{"3"}))
+	ids, err = store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:.")
+	Ok(t, err)
+	Equals(t, []string{"1", "2"}, ids)
+
+	// Setting again should replace the ids.
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num, "plan:.", []string{"4"}))
+	ids, err = store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:.")
+	Ok(t, err)
+	Equals(t, []string{"4"}, ids)
+	ids, err = store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "apply:.")
+	Ok(t, err)
+	Equals(t, []string{"3"}, ids)
+
+	// Other pull requests shouldn't be affected.
+	ids, err = store.Get(fixtures.GithubRepo, fixtures.Pull.Num+1, "plan:.")
+	Ok(t, err)
+	Equals(t, 0, len(ids))
+}
+
+func TestFilePullCommentStore_DeleteForPull(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	store := &events.FilePullCommentStore{DataDir: tmp}
+
+	// Deleting before anything is set should succeed.
+	Ok(t, store.DeleteForPull(fixtures.GithubRepo, fixtures.Pull.Num))
+
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num, "plan:.", []string{"1"}))
+	Ok(t, store.Set(fixtures.GithubRepo, fixtures.Pull.Num+1, "plan:.", []string{"2"}))
+	Ok(t, store.DeleteForPull(fixtures.GithubRepo, fixtures.Pull.Num))
+
+	ids, err := store.Get(fixtures.GithubRepo, fixtures.Pull.Num, "plan:.")
+	Ok(t, err)
+	Equals(t, 0, len(ids))
+	ids, err = store.Get(fixtures.GithubRepo, fixtures.Pull.Num+1, "plan:.")
+	Ok(t, err)
+	Equals(t, []string{"2"}, ids)
+}
@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 
 	"github.com/cloudposse/atlantis/server/events/models"
-	"github.com/hashicorp/go-version"
 )
 
 // ApplyStepRunner runs `terraform apply`.
@@ -24,14 +23,16 @@ func (a *ApplyStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []stri
 	// NOTE: we need to quote the plan path because Bitbucket Server can
 	// have spaces in its repo owner names which is part of the path.
 	tfApplyCmd := append(append(append([]string{"apply", "-input=false", "-no-color"}, extraArgs...), ctx.CommentArgs...), fmt.Sprintf("%q", planPath))
-	var tfVersion *version.Version
-	if ctx.ProjectConfig != nil && ctx.ProjectConfig.TerraformVersion != nil {
-		tfVersion = ctx.ProjectConfig.TerraformVersion
-	}
+	tfVersion := GetTerraformVersion(ctx, nil)
 	out, tfErr := a.TerraformExecutor.RunCommandWithVersion(ctx.Log, path, tfApplyCmd, tfVersion, ctx.Workspace)
 
 	if tfErr == nil {
-		ctx.Log.Info("apply successful")
+		ctx.Log.Info("apply successful, deleting planfile")
+		// Delete the plan so it's no longer pending and so it can't be
+		// applied again.
+		if removeErr := os.Remove(planPath); removeErr != nil {
+			ctx.Log.Warn("failed to delete planfile after successful apply: %s", removeErr)
+		}
 	}
 	return out, tfErr
 }
@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 
 	"github.com/cloudposse/atlantis/server/events/models"
-	"github.com/hashicorp/go-version"
 )
 
 // DestroyStepRunner runs `terraform destroy`.
@@ -24,10 +23,7 @@ func (a *DestroyStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []st
 	// NOTE: we need to quote the plan path because Bitbucket Server can
 	// have spaces in its repo owner names which is part of the path.
 	tfDestroyCmd := append(append(append([]string{"destroy", "-input=false", "-no-color", "-auto-approve"}, extraArgs...), ctx.CommentArgs...), fmt.Sprintf("%q", planPath))
-	var tfVersion *version.Version
-	if ctx.ProjectConfig != nil && ctx.ProjectConfig.TerraformVersion != nil {
-		tfVersion = ctx.ProjectConfig.TerraformVersion
-	}
+	tfVersion := GetTerraformVersion(ctx, nil)
 	out, tfErr := a.TerraformExecutor.RunCommandWithVersion(ctx.Log, path, tfDestroyCmd, tfVersion, ctx.Workspace)
 
 	if tfErr == nil {
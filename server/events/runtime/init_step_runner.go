@@ -12,10 +12,7 @@ type InitStepRunner struct {
 }
 
 func (i *InitStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string) (string, error) {
-	tfVersion := i.DefaultTFVersion
-	if ctx.ProjectConfig != nil && ctx.ProjectConfig.TerraformVersion != nil {
-		tfVersion = ctx.ProjectConfig.TerraformVersion
-	}
+	tfVersion := GetTerraformVersion(ctx, i.DefaultTFVersion)
 	terraformInitCmd := append([]string{"init", "-input=false", "-no-color"}, extraArgs...)
 
 	// If we're running < 0.9 we have to use `terraform get` instead of `init`.
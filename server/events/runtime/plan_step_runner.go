@@ -18,10 +18,7 @@ type PlanStepRunner struct {
 }
 
 func (p *PlanStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string) (string, error) {
-	tfVersion := p.DefaultTFVersion
-	if ctx.ProjectConfig != nil && ctx.ProjectConfig.TerraformVersion != nil {
-		tfVersion = ctx.ProjectConfig.TerraformVersion
-	}
+	tfVersion := GetTerraformVersion(ctx, p.DefaultTFVersion)
 
 	// We only need to switch workspaces in version 0.9.*. In older versions,
 	// there is no such thing as a workspace so we don't need to do anything.
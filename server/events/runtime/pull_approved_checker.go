@@ -8,4 +8,5 @@ import (
 
 type PullApprovedChecker interface {
 	PullIsApproved(baseRepo models.Repo, pull models.PullRequest) (bool, error)
+	GetApprovers(baseRepo models.Repo, pull models.PullRequest) ([]string, error)
 }
@@ -24,10 +24,7 @@ func (r *RunStepRunner) Run(ctx models.ProjectCommandContext, command []string,
 
 	cmd := exec.Command("sh", "-c", strings.Join(command, " ")) // #nosec
 	cmd.Dir = path
-	tfVersion := r.DefaultTFVersion.String()
-	if ctx.ProjectConfig != nil && ctx.ProjectConfig.TerraformVersion != nil {
-		tfVersion = ctx.ProjectConfig.TerraformVersion.String()
-	}
+	tfVersion := GetTerraformVersion(ctx, r.DefaultTFVersion).String()
 	baseEnvVars := os.Environ()
 	customEnvVars := map[string]string{
 		"WORKSPACE":                  ctx.Workspace,
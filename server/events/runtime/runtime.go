@@ -5,6 +5,7 @@ package runtime
 import (
 	"fmt"
 
+	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/yaml/valid"
 	"github.com/cloudposse/atlantis/server/logging"
 	"github.com/hashicorp/go-version"
@@ -31,3 +32,16 @@ func GetPlanFilename(workspace string, maybeCfg *valid.Project) string {
 	}
 	return fmt.Sprintf("%s-%s.tfplan", *maybeCfg.Name, workspace)
 }
+
+// GetTerraformVersion returns the version of terraform to run the project in
+// ctx with. It's the version resolved for the project, the version in its
+// config or defaultVersion, in that order.
+func GetTerraformVersion(ctx models.ProjectCommandContext, defaultVersion *version.Version) *version.Version {
+	if ctx.TerraformVersion != nil {
+		return ctx.TerraformVersion
+	}
+	if ctx.ProjectConfig != nil && ctx.ProjectConfig.TerraformVersion != nil {
+		return ctx.ProjectConfig.TerraformVersion
+	}
+	return defaultVersion
+}
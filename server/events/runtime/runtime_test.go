@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/runtime"
 	"github.com/cloudposse/atlantis/server/events/yaml/valid"
 	. "github.com/cloudposse/atlantis/testing"
+	"github.com/hashicorp/go-version"
 )
 
 func TestGetPlanFilename(t *testing.T) {
@@ -42,3 +44,18 @@ func TestGetPlanFilename(t *testing.T) {
 }
 
 func String(v string) *string { return &v }
+
+func TestGetTerraformVersion(t *testing.T) {
+	defaultVersion, _ := version.NewVersion("0.11.0")
+	configVersion, _ := version.NewVersion("0.11.1")
+	resolvedVersion, _ := version.NewVersion("0.11.2")
+
+	Equals(t, defaultVersion, runtime.GetTerraformVersion(models.ProjectCommandContext{}, defaultVersion))
+	Equals(t, configVersion, runtime.GetTerraformVersion(models.ProjectCommandContext{
+		ProjectConfig: &valid.Project{TerraformVersion: configVersion},
+	}, defaultVersion))
+	Equals(t, resolvedVersion, runtime.GetTerraformVersion(models.ProjectCommandContext{
+		ProjectConfig:    &valid.Project{TerraformVersion: configVersion},
+		TerraformVersion: resolvedVersion,
+	}, defaultVersion))
+}
@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+)
+
+// ShowStepRunner runs `terraform show -json` on the plan saved by the plan
+// step so it can be summarized.
+type ShowStepRunner struct {
+	TerraformExecutor TerraformExec
+	DefaultTFVersion  *version.Version
+}
+
+// Run returns the plan as JSON. It returns an empty string if the version of
+// terraform doesn't support `show -json`, which was added in 0.12, or if
+// there's no saved plan, ex. because a custom workflow didn't save one.
+func (s *ShowStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string) (string, error) {
+	tfVersion := GetTerraformVersion(ctx, s.DefaultTFVersion)
+	if tfVersion == nil || MustConstraint("< 0.12.0").Check(tfVersion) {
+		return "", nil
+	}
+	planFile := filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectConfig))
+	if _, err := os.Stat(planFile); err != nil {
+		return "", nil
+	}
+	// NOTE: we need to quote the plan filename because Bitbucket Server can
+	// have spaces in its repo owner names.
+	showCmd := append([]string{"show", "-json", "-no-color"}, extraArgs...)
+	showCmd = append(showCmd, fmt.Sprintf("%q", planFile))
+	return s.TerraformExecutor.RunCommandWithVersion(ctx.Log, filepath.Clean(path), showCmd, tfVersion, ctx.Workspace)
+}
+
+// PlanJSON returns the plan JSON in showOutput, the output of `terraform show
+// -json`. Terraform can print warnings before the JSON since we capture
+// stderr too.
+func PlanJSON(showOutput string) string {
+	if i := strings.Index(showOutput, "{"); i > 0 {
+		return showOutput[i:]
+	}
+	return showOutput
+}
+
+// ParsePlanSummary builds a summary of the plan in showJSON, the output of
+// `terraform show -json`.
+func ParsePlanSummary(showJSON string) (*models.PlanSummary, error) {
+	showJSON = PlanJSON(showJSON)
+	var plan struct {
+		ResourceChanges []struct {
+			Address string `json:"address"`
+			Change  struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(showJSON), &plan); err != nil {
+		return nil, errors.Wrap(err, "parsing plan JSON")
+	}
+
+	summary := &models.PlanSummary{}
+	for _, rc := range plan.ResourceChanges {
+		var action string
+		switch strings.Join(rc.Change.Actions, ",") {
+		case "create":
+			action = models.PlanActionCreate
+			summary.Create++
+		case "update":
+			action = models.PlanActionUpdate
+			summary.Update++
+		case "delete":
+			action = models.PlanActionDelete
+			summary.Delete++
+		case "delete,create", "create,delete":
+			action = models.PlanActionReplace
+			summary.Replace++
+		default:
+			// no-op and read don't change anything.
+			continue
+		}
+		summary.Resources = append(summary.Resources, models.PlanResourceChange{
+			Address: rc.Address,
+			Action:  action,
+		})
+	}
+	return summary, nil
+}
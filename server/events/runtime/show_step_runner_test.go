@@ -0,0 +1,103 @@
+package runtime_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/mocks/matchers"
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/runtime"
+	"github.com/cloudposse/atlantis/server/events/terraform/mocks"
+	matchers2 "github.com/cloudposse/atlantis/server/events/terraform/mocks/matchers"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock"
+)
+
+func TestShowStepRunner_Run(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	Ok(t, ioutil.WriteFile(filepath.Join(tmp, "default.tfplan"), nil, 0600))
+	terraform := mocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("0.12.0")
+	s := runtime.ShowStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+	}
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(), Workspace: "default"}
+	When(terraform.RunCommandWithVersion(matchers.AnyPtrToLoggingSimpleLogger(), AnyString(), AnyStringSlice(), matchers2.AnyPtrToGoVersionVersion(), AnyString())).
+		ThenReturn("{}", nil)
+
+	out, err := s.Run(ctx, nil, tmp)
+	Ok(t, err)
+	Equals(t, "{}", out)
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(ctx.Log, tmp, []string{"show", "-json", "-no-color", fmt.Sprintf("%q", filepath.Join(tmp, "default.tfplan"))}, tfVersion, "default")
+}
+
+func TestShowStepRunner_RunUnsupported(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	Ok(t, ioutil.WriteFile(filepath.Join(tmp, "default.tfplan"), nil, 0600))
+	terraform := mocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("0.11.14")
+	s := runtime.ShowStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+	}
+
+	t.Log("terraform < 0.12 doesn't support show -json")
+	out, err := s.Run(models.ProjectCommandContext{Log: logging.NewNoopLogger(), Workspace: "default"}, nil, tmp)
+	Ok(t, err)
+	Equals(t, "", out)
+
+	t.Log("there's nothing to show if the plan wasn't saved")
+	s.DefaultTFVersion, _ = version.NewVersion("0.12.0")
+	out, err = s.Run(models.ProjectCommandContext{Log: logging.NewNoopLogger(), Workspace: "staging"}, nil, tmp)
+	Ok(t, err)
+	Equals(t, "", out)
+	terraform.VerifyWasCalled(Never()).RunCommandWithVersion(matchers.AnyPtrToLoggingSimpleLogger(), AnyString(), AnyStringSlice(), matchers2.AnyPtrToGoVersionVersion(), AnyString())
+}
+
+func TestParsePlanSummary(t *testing.T) {
+	summary, err := runtime.ParsePlanSummary(`
+Warning: something deprecated
+
+{
+  "format_version": "0.1",
+  "resource_changes": [
+    {"address": "aws_instance.web[0]", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_instance.web[1]", "change": {"actions": ["create", "delete"]}},
+    {"address": "aws_security_group.web", "change": {"actions": ["update"]}},
+    {"address": "aws_eip.web", "change": {"actions": ["create"]}},
+    {"address": "aws_eip.old", "change": {"actions": ["delete"]}},
+    {"address": "aws_vpc.main", "change": {"actions": ["no-op"]}},
+    {"address": "data.aws_ami.ubuntu", "change": {"actions": ["read"]}}
+  ]
+}`)
+	Ok(t, err)
+	Equals(t, &models.PlanSummary{
+		Create:  1,
+		Update:  1,
+		Delete:  1,
+		Replace: 2,
+		Resources: []models.PlanResourceChange{
+			{Address: "aws_instance.web[0]", Action: models.PlanActionReplace},
+			{Address: "aws_instance.web[1]", Action: models.PlanActionReplace},
+			{Address: "aws_security_group.web", Action: models.PlanActionUpdate},
+			{Address: "aws_eip.web", Action: models.PlanActionCreate},
+			{Address: "aws_eip.old", Action: models.PlanActionDelete},
+		},
+	}, summary)
+
+	summary, err = runtime.ParsePlanSummary(`{"format_version": "0.1"}`)
+	Ok(t, err)
+	Equals(t, &models.PlanSummary{}, summary)
+
+	_, err = runtime.ParsePlanSummary("Error: plan file is invalid")
+	ErrContains(t, "parsing plan JSON", err)
+}
@@ -0,0 +1,245 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+)
+
+// DefaultDownloadURL is the releases mirror terraform is downloaded from by
+// default.
+const DefaultDownloadURL = "https://releases.hashicorp.com"
+
+const terraformBinDirName = "bin"
+
+// versionsTTL is how long the versions available from the mirror are cached.
+const versionsTTL = time.Hour
+
+// Downloader downloads terraform binaries from a releases mirror into the data
+// dir. A mirror must have the same layout as releases.hashicorp.com, ex.
+// {url}/terraform/0.12.0/terraform_0.12.0_linux_amd64.zip and
+// {url}/terraform/0.12.0/terraform_0.12.0_SHA256SUMS.
+type Downloader struct {
+	// URL is the releases mirror, ex. https://releases.hashicorp.com.
+	URL string
+	// BinDir is where downloaded binaries are cached. Each version is stored
+	// as terraform{version} so it has the same name it would have on $PATH.
+	BinDir     string
+	HTTPClient *http.Client
+	// OS and Arch are the platform to download, ex. linux and amd64.
+	OS   string
+	Arch string
+
+	// mutex guards versionLocks.
+	mutex sync.Mutex
+	// versionLocks makes sure each version is only downloaded once even if
+	// multiple projects need it at the same time.
+	versionLocks map[string]*sync.Mutex
+	// versions are the versions available from the mirror as of versionsAt.
+	// They're guarded by mutex.
+	versions   []*version.Version
+	versionsAt time.Time
+}
+
+// NewDownloader returns a Downloader that downloads from url into the bin
+// directory of dataDir.
+func NewDownloader(url string, dataDir string) (*Downloader, error) {
+	binDir := filepath.Join(dataDir, terraformBinDirName)
+	if err := os.MkdirAll(binDir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "unable to create terraform bin directory at %q", binDir)
+	}
+	return &Downloader{
+		URL:        strings.TrimSuffix(url, "/"),
+		BinDir:     binDir,
+		HTTPClient: &http.Client{Timeout: 5 * time.Minute},
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}, nil
+}
+
+// EnsureVersion returns the path to the terraform binary for v, downloading it
+// if it isn't already cached.
+func (d *Downloader) EnsureVersion(log *logging.SimpleLogger, v *version.Version) (string, error) {
+	binPath := filepath.Join(d.BinDir, fmt.Sprintf("terraform%s", v.String()))
+	lock := d.versionLock(v.String())
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := os.Stat(binPath); err == nil {
+		return binPath, nil
+	}
+	log.Info("downloading terraform %s from %s", v.String(), d.URL)
+	if err := d.download(v, binPath); err != nil {
+		return "", errors.Wrapf(err, "downloading terraform %s", v.String())
+	}
+	log.Info("downloaded terraform %s to %q", v.String(), binPath)
+	return binPath, nil
+}
+
+// ListVersions returns the versions that have been downloaded and the
+// versions that can be downloaded from the mirror's index.json. Pre-releases
+// are skipped.
+func (d *Downloader) ListVersions() ([]*version.Version, error) {
+	versions, err := d.cachedVersions()
+	if err != nil {
+		return nil, err
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.versions != nil && time.Since(d.versionsAt) < versionsTTL {
+		return append(versions, d.versions...), nil
+	}
+
+	body, err := d.get(fmt.Sprintf("%s/terraform/index.json", d.URL))
+	if err != nil {
+		return versions, err
+	}
+	var index struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err := json.Unmarshal(body, &index); err != nil {
+		return versions, errors.Wrap(err, "parsing index.json")
+	}
+	d.versions = []*version.Version{}
+	for s := range index.Versions {
+		v, err := version.NewVersion(s)
+		if err != nil || v.Prerelease() != "" {
+			continue
+		}
+		d.versions = append(d.versions, v)
+	}
+	d.versionsAt = time.Now()
+	return append(versions, d.versions...), nil
+}
+
+// cachedVersions returns the versions that have already been downloaded.
+func (d *Downloader) cachedVersions() ([]*version.Version, error) {
+	files, err := ioutil.ReadDir(d.BinDir)
+	if err != nil {
+		return nil, err
+	}
+	var versions []*version.Version
+	for _, f := range files {
+		if v := binaryVersion(f.Name()); v != nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (d *Downloader) versionLock(v string) *sync.Mutex {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.versionLocks == nil {
+		d.versionLocks = make(map[string]*sync.Mutex)
+	}
+	if _, ok := d.versionLocks[v]; !ok {
+		d.versionLocks[v] = &sync.Mutex{}
+	}
+	return d.versionLocks[v]
+}
+
+// download downloads v, verifies its checksum and extracts the binary to
+// binPath.
+func (d *Downloader) download(v *version.Version, binPath string) error {
+	base := fmt.Sprintf("%s/terraform/%s", d.URL, v.String())
+	zipName := fmt.Sprintf("terraform_%s_%s_%s.zip", v.String(), d.OS, d.Arch)
+
+	sums, err := d.get(fmt.Sprintf("%s/terraform_%s_SHA256SUMS", base, v.String()))
+	if err != nil {
+		return err
+	}
+	expSum, err := findChecksum(sums, zipName)
+	if err != nil {
+		return err
+	}
+	archive, err := d.get(fmt.Sprintf("%s/%s", base, zipName))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(archive)
+	if actSum := hex.EncodeToString(sum[:]); actSum != expSum {
+		return fmt.Errorf("checksum of %s was %s but SHA256SUMS has %s", zipName, actSum, expSum)
+	}
+	return extractTerraform(archive, binPath)
+}
+
+func (d *Downloader) get(url string) ([]byte, error) {
+	resp, err := d.HTTPClient.Get(url) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting %s returned %d", url, resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// findChecksum returns the checksum of filename from the contents of a
+// SHA256SUMS file.
+func findChecksum(sums []byte, filename string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == filename {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s in SHA256SUMS", filename)
+}
+
+// extractTerraform writes the terraform binary in archive to binPath. It's
+// written to a temp file first and then renamed so other processes never see
+// a partially written binary.
+func extractTerraform(archive []byte, binPath string) error {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return errors.Wrap(err, "reading zip")
+	}
+	for _, f := range r.File {
+		if f.Name != "terraform" && f.Name != "terraform.exe" {
+			continue
+		}
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer src.Close() // nolint: errcheck
+
+		tmp, err := ioutil.TempFile(filepath.Dir(binPath), filepath.Base(binPath)+".tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name()) // nolint: errcheck
+		if _, err := io.Copy(tmp, src); err != nil {
+			tmp.Close() // nolint: errcheck
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		if err := os.Chmod(tmp.Name(), 0700); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), binPath)
+	}
+	return errors.New("zip doesn't contain a terraform binary")
+}
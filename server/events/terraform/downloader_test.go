@@ -0,0 +1,213 @@
+package terraform_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/terraform"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+	"github.com/hashicorp/go-version"
+)
+
+// mirror is a local releases mirror that serves a single version of terraform.
+type mirror struct {
+	server *httptest.Server
+	mutex  sync.Mutex
+	// requests counts the requests for each path.
+	requests map[string]int
+}
+
+// newMirror starts a mirror serving version v whose terraform binary is a
+// shell script with contents script. If sum is set, it's used in SHA256SUMS
+// instead of the zip's real checksum.
+func newMirror(t *testing.T, v string, script string, sum string) *mirror {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("terraform")
+	Ok(t, err)
+	_, err = w.Write([]byte(script))
+	Ok(t, err)
+	Ok(t, zw.Close())
+	archive := buf.Bytes()
+
+	zipName := fmt.Sprintf("terraform_%s_%s_%s.zip", v, runtime.GOOS, runtime.GOARCH)
+	if sum == "" {
+		sum = fmt.Sprintf("%x", sha256.Sum256(archive))
+	}
+	sums := fmt.Sprintf("%x  terraform_%s_other_arch.zip\n%s  %s\n", sha256.Sum256(nil), v, sum, zipName)
+
+	m := &mirror{requests: make(map[string]int)}
+	m.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mutex.Lock()
+		m.requests[r.URL.Path]++
+		m.mutex.Unlock()
+		switch r.URL.Path {
+		case fmt.Sprintf("/terraform/%s/%s", v, zipName):
+			w.Write(archive) // nolint: errcheck
+		case fmt.Sprintf("/terraform/%s/terraform_%s_SHA256SUMS", v, v):
+			w.Write([]byte(sums)) // nolint: errcheck
+		case "/terraform/index.json":
+			fmt.Fprintf(w, `{"name": "terraform", "versions": {"%s": {}, "0.12.0-beta1": {}}}`, v)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return m
+}
+
+func (m *mirror) zipRequests(v string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.requests[fmt.Sprintf("/terraform/%s/terraform_%s_%s_%s.zip", v, v, runtime.GOOS, runtime.GOARCH)]
+}
+
+func TestDownloader_EnsureVersion(t *testing.T) {
+	m := newMirror(t, "0.11.10", "#!/bin/sh\necho 0.11.10\n", "")
+	defer m.server.Close()
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	d, err := terraform.NewDownloader(m.server.URL+"/", dataDir)
+	Ok(t, err)
+
+	binPath, err := d.EnsureVersion(logging.NewNoopLogger(), version.Must(version.NewVersion("0.11.10")))
+	Ok(t, err)
+	Equals(t, filepath.Join(dataDir, "bin", "terraform0.11.10"), binPath)
+	contents, err := ioutil.ReadFile(binPath)
+	Ok(t, err)
+	Equals(t, "#!/bin/sh\necho 0.11.10\n", string(contents))
+	info, err := os.Stat(binPath)
+	Ok(t, err)
+	Equals(t, os.FileMode(0700), info.Mode().Perm())
+
+	// The second time it should be cached.
+	_, err = d.EnsureVersion(logging.NewNoopLogger(), version.Must(version.NewVersion("0.11.10")))
+	Ok(t, err)
+	Equals(t, 1, m.zipRequests("0.11.10"))
+}
+
+func TestDownloader_EnsureVersionConcurrent(t *testing.T) {
+	m := newMirror(t, "0.11.10", "#!/bin/sh\necho 0.11.10\n", "")
+	defer m.server.Close()
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	d, err := terraform.NewDownloader(m.server.URL, dataDir)
+	Ok(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = d.EnsureVersion(logging.NewNoopLogger(), version.Must(version.NewVersion("0.11.10")))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		Ok(t, err)
+	}
+	Equals(t, 1, m.zipRequests("0.11.10"))
+}
+
+func TestDownloader_EnsureVersionChecksumMismatch(t *testing.T) {
+	badSum := fmt.Sprintf("%x", sha256.Sum256([]byte("not the zip")))
+	m := newMirror(t, "0.11.10", "#!/bin/sh\n", badSum)
+	defer m.server.Close()
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	d, err := terraform.NewDownloader(m.server.URL, dataDir)
+	Ok(t, err)
+
+	_, err = d.EnsureVersion(logging.NewNoopLogger(), version.Must(version.NewVersion("0.11.10")))
+	ErrContains(t, "but SHA256SUMS has "+badSum, err)
+	_, err = os.Stat(filepath.Join(dataDir, "bin", "terraform0.11.10"))
+	Assert(t, os.IsNotExist(err), "exp binary to not be written")
+}
+
+func TestDownloader_EnsureVersionNotFound(t *testing.T) {
+	m := newMirror(t, "0.11.10", "#!/bin/sh\n", "")
+	defer m.server.Close()
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	d, err := terraform.NewDownloader(m.server.URL, dataDir)
+	Ok(t, err)
+
+	_, err = d.EnsureVersion(logging.NewNoopLogger(), version.Must(version.NewVersion("0.11.11")))
+	ErrEquals(t, fmt.Sprintf("downloading terraform 0.11.11: getting %s/terraform/0.11.11/terraform_0.11.11_SHA256SUMS returned 404", m.server.URL), err)
+}
+
+func TestDefaultClient_RunCommandWithVersionDownloads(t *testing.T) {
+	defer fakeTerraformPath(t)()
+
+	m := newMirror(t, "0.11.11", "#!/bin/sh\necho \"downloaded $@\"\n", "")
+	defer m.server.Close()
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	c, err := terraform.NewClient(dataDir, m.server.URL)
+	Ok(t, err)
+	Equals(t, "0.11.10", c.Version().String())
+
+	out, err := c.RunCommandWithVersion(logging.NewNoopLogger(), dataDir, []string{"plan"}, version.Must(version.NewVersion("0.11.11")), "default")
+	Ok(t, err)
+	Equals(t, "downloaded plan\n", out)
+
+	// Without a download URL, missing versions fail.
+	c, err = terraform.NewClient(dataDir, "")
+	Ok(t, err)
+	_, err = c.RunCommandWithVersion(logging.NewNoopLogger(), dataDir, []string{"plan"}, version.Must(version.NewVersion("0.11.12")), "default")
+	Assert(t, err != nil, "exp error running missing version")
+}
+
+func TestDefaultClient_ListVersions(t *testing.T) {
+	defer fakeTerraformPath(t, "0.10.8")()
+	m := newMirror(t, "0.11.11", "#!/bin/sh\n", "")
+	defer m.server.Close()
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	c, err := terraform.NewClient(dataDir, m.server.URL)
+	Ok(t, err)
+
+	var versions []string
+	for _, v := range c.ListVersions(logging.NewNoopLogger()) {
+		versions = append(versions, v.String())
+	}
+	Equals(t, []string{"0.10.8", "0.11.10", "0.11.11"}, versions)
+
+	// The mirror's versions should be cached.
+	c.ListVersions(logging.NewNoopLogger())
+	Equals(t, 1, m.requests["/terraform/index.json"])
+
+	// If the mirror can't be reached, only installed versions are listed.
+	m.server.Close()
+	c, err = terraform.NewClient(dataDir, m.server.URL)
+	Ok(t, err)
+	Equals(t, 2, len(c.ListVersions(logging.NewNoopLogger())))
+}
+
+// fakeTerraformPath puts a fake terraform 0.11.10 on our $PATH, along with
+// binaries named terraform{version} for each of versions. It returns a func
+// that restores $PATH.
+func fakeTerraformPath(t *testing.T, versions ...string) func() {
+	pathDir, cleanup := TempDir(t)
+	Ok(t, ioutil.WriteFile(filepath.Join(pathDir, "terraform"), []byte("#!/bin/sh\necho 'Terraform v0.11.10'\n"), 0700)) // nolint: gosec
+	for _, v := range versions {
+		Ok(t, ioutil.WriteFile(filepath.Join(pathDir, "terraform"+v), []byte("#!/bin/sh\n"), 0700)) // nolint: gosec
+	}
+	origPath := os.Getenv("PATH")
+	Ok(t, os.Setenv("PATH", pathDir+string(os.PathListSeparator)+origPath))
+	return func() {
+		os.Setenv("PATH", origPath) // nolint: errcheck
+		cleanup()
+	}
+}
@@ -63,6 +63,18 @@ func (mock *MockClient) Init(log *logging.SimpleLogger, path string, workspace s
 	return ret0, ret1
 }
 
+func (mock *MockClient) ListVersions(log *logging.SimpleLogger) []*go_version.Version {
+	params := []pegomock.Param{log}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("ListVersions", params, []reflect.Type{reflect.TypeOf((*[]*go_version.Version)(nil)).Elem()})
+	var ret0 []*go_version.Version
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]*go_version.Version)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockClient) VerifyWasCalledOnce() *VerifierClient {
 	return &VerifierClient{mock, pegomock.Times(1), nil}
 }
@@ -183,3 +195,30 @@ func (c *Client_Init_OngoingVerification) GetAllCapturedArguments() (_param0 []*
 	}
 	return
 }
+
+func (verifier *VerifierClient) ListVersions(log *logging.SimpleLogger) *Client_ListVersions_OngoingVerification {
+	params := []pegomock.Param{log}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListVersions", params)
+	return &Client_ListVersions_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type Client_ListVersions_OngoingVerification struct {
+	mock              *MockClient
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *Client_ListVersions_OngoingVerification) GetCapturedArguments() *logging.SimpleLogger {
+	log := c.GetAllCapturedArguments()
+	return log[len(log)-1]
+}
+
+func (c *Client_ListVersions_OngoingVerification) GetAllCapturedArguments() (_param0 []*logging.SimpleLogger) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]*logging.SimpleLogger, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(*logging.SimpleLogger)
+		}
+	}
+	return
+}
@@ -16,10 +16,12 @@ package terraform
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/cloudposse/atlantis/server/logging"
@@ -32,11 +34,15 @@ import (
 type Client interface {
 	Version() *version.Version
 	RunCommandWithVersion(log *logging.SimpleLogger, path string, args []string, v *version.Version, workspace string) (string, error)
+	ListVersions(log *logging.SimpleLogger) []*version.Version
 }
 
 type DefaultClient struct {
 	defaultVersion          *version.Version
 	terraformPluginCacheDir string
+	// downloader downloads versions of terraform that aren't on our $PATH.
+	// If nil, they must be installed.
+	downloader *Downloader
 }
 
 const terraformPluginCacheDirName = "plugin-cache"
@@ -44,7 +50,10 @@ const terraformPluginCacheDirName = "plugin-cache"
 // zeroPointNine constrains the version to be 0.9.*
 var versionRegex = regexp.MustCompile("Terraform v(.*)\n")
 
-func NewClient(dataDir string) (*DefaultClient, error) {
+// NewClient returns a client for the terraform executable in our $PATH.
+// Other versions of terraform that aren't in our $PATH are downloaded from
+// downloadURL into dataDir. If downloadURL is empty, they're never downloaded.
+func NewClient(dataDir string, downloadURL string) (*DefaultClient, error) {
 	_, err := exec.LookPath("terraform")
 	if err != nil {
 		return nil, errors.New("terraform not found in $PATH. \n\nDownload terraform from https://www.terraform.io/downloads.html")
@@ -71,9 +80,18 @@ func NewClient(dataDir string) (*DefaultClient, error) {
 		return nil, errors.Wrapf(err, "unable to create terraform plugin cache directory at %q", terraformPluginCacheDirName)
 	}
 
+	var downloader *Downloader
+	if downloadURL != "" {
+		downloader, err = NewDownloader(downloadURL, dataDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &DefaultClient{
 		defaultVersion:          v,
 		terraformPluginCacheDir: cacheDir,
+		downloader:              downloader,
 	}, nil
 }
 
@@ -82,9 +100,62 @@ func (c *DefaultClient) Version() *version.Version {
 	return c.defaultVersion
 }
 
+// ListVersions returns the versions of terraform we can run, sorted from
+// oldest to newest. They're the default version, the versions on our $PATH
+// named terraform{version} and the versions that have been or can be
+// downloaded. If the downloadable versions can't be listed, only the installed
+// versions are returned.
+func (c *DefaultClient) ListVersions(log *logging.SimpleLogger) []*version.Version {
+	versions := []*version.Version{c.defaultVersion}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if v := binaryVersion(f.Name()); v != nil {
+				versions = append(versions, v)
+			}
+		}
+	}
+	if c.downloader != nil {
+		downloadable, err := c.downloader.ListVersions()
+		if err != nil {
+			log.Warn("unable to list terraform versions available from %s: %s", c.downloader.URL, err)
+		}
+		versions = append(versions, downloadable...)
+	}
+
+	// Remove duplicates, ex. a version that's installed and downloadable.
+	seen := make(map[string]bool)
+	var unique []*version.Version
+	for _, v := range versions {
+		if !seen[v.String()] {
+			seen[v.String()] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Sort(version.Collection(unique))
+	return unique
+}
+
+// binaryVersion returns the version of a terraform binary named
+// terraform{version}, or nil if name isn't one.
+func binaryVersion(name string) *version.Version {
+	if !strings.HasPrefix(name, "terraform") || name == "terraform" {
+		return nil
+	}
+	v, err := version.NewVersion(strings.TrimPrefix(name, "terraform"))
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
 // RunCommandWithVersion executes the provided version of terraform with
 // the provided args in path. v is the version of terraform executable to use.
-// If v is nil, will use the default version.
+// If v is nil, will use the default version. If the terraform{v} executable
+// isn't in our $PATH, it's downloaded.
 // Workspace is the terraform workspace to run in. We won't switch workspaces
 // but will set the TERRAFORM_WORKSPACE environment variable.
 func (c *DefaultClient) RunCommandWithVersion(log *logging.SimpleLogger, path string, args []string, v *version.Version, workspace string) (string, error) {
@@ -94,6 +165,13 @@ func (c *DefaultClient) RunCommandWithVersion(log *logging.SimpleLogger, path st
 	if v != nil && !v.Equal(c.defaultVersion) {
 		tfExecutable = fmt.Sprintf("%s%s", tfExecutable, v.String())
 		tfVersionStr = v.String()
+		if _, err := exec.LookPath(tfExecutable); err != nil && c.downloader != nil {
+			binPath, err := c.downloader.EnsureVersion(log, v)
+			if err != nil {
+				return "", err
+			}
+			tfExecutable = binPath
+		}
 	}
 
 	// We add custom variables so that if `extra_args` is specified with env
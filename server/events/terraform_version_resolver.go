@@ -0,0 +1,114 @@
+package events
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cloudposse/atlantis/server/events/terraform"
+	"github.com/cloudposse/atlantis/server/events/yaml/valid"
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl"
+	"github.com/pkg/errors"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_terraform_version_resolver.go TerraformVersionResolver
+
+// TerraformVersionResolver picks the version of terraform to run a project
+// with.
+type TerraformVersionResolver interface {
+	// ResolveVersion returns the version of terraform to run the project in
+	// absPath with. It returns nil if the default version should be used.
+	ResolveVersion(log *logging.SimpleLogger, projectConfig *valid.Project, absPath string) (*version.Version, error)
+}
+
+// DefaultTerraformVersionResolver implements TerraformVersionResolver. If the
+// project's terraform_version is an exact version, that version is used. If
+// it's a constraint, or the project doesn't set terraform_version but its .tf
+// files have a required_version, the newest version matching the constraint
+// that's installed or downloadable is used.
+type DefaultTerraformVersionResolver struct {
+	TerraformClient terraform.Client
+}
+
+// ResolveVersion implements TerraformVersionResolver.
+func (d *DefaultTerraformVersionResolver) ResolveVersion(log *logging.SimpleLogger, projectConfig *valid.Project, absPath string) (*version.Version, error) {
+	if projectConfig != nil && projectConfig.TerraformVersion != nil {
+		return projectConfig.TerraformVersion, nil
+	}
+
+	source := "terraform_version"
+	var constraints version.Constraints
+	if projectConfig != nil && projectConfig.TerraformVersionConstraints != nil {
+		constraints = projectConfig.TerraformVersionConstraints
+	} else {
+		source = "required_version"
+		var err error
+		constraints, err = requiredVersion(log, absPath)
+		if err != nil {
+			return nil, err
+		}
+		if constraints == nil {
+			return nil, nil
+		}
+	}
+
+	versions := d.TerraformClient.ListVersions(log)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if constraints.Check(versions[i]) {
+			log.Info("using terraform %s since it's the newest version matching %s %q", versions[i], source, constraints)
+			return versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no installed or downloadable version of terraform matches %s %q", source, constraints)
+}
+
+// requiredVersionRegex finds required_version in a terraform block. It's only
+// used for files our HCL parser can't parse, ex. ones using Terraform 0.12
+// syntax, and it misses required_version if it comes after a nested block.
+var requiredVersionRegex = regexp.MustCompile(`(?m)^\s*terraform\s*\{[^}]*?\brequired_version\s*=\s*"([^"]*)"`)
+
+// requiredVersion returns the constraints of the required_version settings in
+// the terraform blocks of the .tf files in absPath, or nil if there aren't
+// any. Like Terraform, if there are multiple they must all be met.
+func requiredVersion(log *logging.SimpleLogger, absPath string) (version.Constraints, error) {
+	files, err := filepath.Glob(filepath.Join(absPath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	var required []string
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file) // nolint: gosec
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", filepath.Base(file))
+		}
+		var config struct {
+			Terraform []struct {
+				RequiredVersion string `hcl:"required_version"`
+			} `hcl:"terraform"`
+		}
+		if err := hcl.Decode(&config, string(contents)); err != nil {
+			log.Debug("unable to parse %s so searching it for required_version instead: %s", filepath.Base(file), err)
+			for _, match := range requiredVersionRegex.FindAllStringSubmatch(string(contents), -1) {
+				required = append(required, match[1])
+			}
+			continue
+		}
+		for _, block := range config.Terraform {
+			if block.RequiredVersion != "" {
+				required = append(required, block.RequiredVersion)
+			}
+		}
+	}
+	if len(required) == 0 {
+		return nil, nil
+	}
+	constraints, err := version.NewConstraint(strings.Join(required, ","))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing required_version %q", strings.Join(required, ", "))
+	}
+	return constraints, nil
+}
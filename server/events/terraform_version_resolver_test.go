@@ -0,0 +1,120 @@
+package events_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events"
+	tfmocks "github.com/cloudposse/atlantis/server/events/terraform/mocks"
+	"github.com/cloudposse/atlantis/server/events/yaml/valid"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock"
+)
+
+func TestDefaultTerraformVersionResolver_ResolveVersion(t *testing.T) {
+	cases := []struct {
+		description string
+		config      *valid.Project
+		files       map[string]string
+		expVersion  string
+		expErr      string
+	}{
+		{
+			description: "no config or required_version",
+			files:       map[string]string{"main.tf": `resource "null_resource" "a" {}`},
+		},
+		{
+			description: "exact version in config",
+			config:      &valid.Project{TerraformVersion: version.Must(version.NewVersion("0.10.0"))},
+			files:       map[string]string{"main.tf": `terraform { required_version = "~> 0.11.0" }`},
+			expVersion:  "0.10.0",
+		},
+		{
+			description: "constraint in config",
+			config:      &valid.Project{TerraformVersionConstraints: mustConstraint("~> 0.11.8")},
+			files:       map[string]string{"main.tf": `terraform { required_version = ">= 0.12" }`},
+			expVersion:  "0.11.14",
+		},
+		{
+			description: "required_version",
+			config:      &valid.Project{},
+			files: map[string]string{"versions.tf": `
+terraform {
+  required_version = "< 0.11.10"
+  backend "s3" {}
+}`},
+			expVersion: "0.11.8",
+		},
+		{
+			description: "required_version in multiple files",
+			files: map[string]string{
+				"main.tf":     `terraform { required_version = ">= 0.11.8" }`,
+				"versions.tf": `terraform { required_version = "< 0.11.14" }`,
+			},
+			expVersion: "0.11.8",
+		},
+		{
+			description: "required_version with 0.12 syntax",
+			files: map[string]string{"main.tf": `
+terraform {
+  required_version = ">= 0.12"
+}
+
+locals {
+  names = [for s in var.names : upper(s)]
+}`},
+			expVersion: "0.12.2",
+		},
+		{
+			description: "no matching version",
+			files:       map[string]string{"main.tf": `terraform { required_version = "~> 0.9.0" }`},
+			expErr:      "no installed or downloadable version of terraform matches required_version \"~> 0.9.0\"",
+		},
+		{
+			description: "invalid required_version",
+			files:       map[string]string{"main.tf": `terraform { required_version = "latest" }`},
+			expErr:      "parsing required_version \"latest\": Malformed constraint: latest",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			for name, contents := range c.files {
+				Ok(t, ioutil.WriteFile(filepath.Join(tmp, name), []byte(contents), 0600))
+			}
+			log := logging.NewNoopLogger()
+			client := tfmocks.NewMockClient()
+			When(client.ListVersions(log)).ThenReturn([]*version.Version{
+				version.Must(version.NewVersion("0.11.8")),
+				version.Must(version.NewVersion("0.11.14")),
+				version.Must(version.NewVersion("0.12.2")),
+			})
+			r := &events.DefaultTerraformVersionResolver{TerraformClient: client}
+
+			v, err := r.ResolveVersion(log, c.config, tmp)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			if c.expVersion == "" {
+				Assert(t, v == nil, "exp nil version but got %s", v)
+				return
+			}
+			Equals(t, c.expVersion, v.String())
+		})
+	}
+}
+
+func mustConstraint(constraint string) version.Constraints {
+	c, err := version.NewConstraint(constraint)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
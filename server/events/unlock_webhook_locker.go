@@ -0,0 +1,51 @@
+package events
+
+import (
+	"github.com/cloudposse/atlantis/server/events/locking"
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/webhooks"
+	"github.com/cloudposse/atlantis/server/logging"
+)
+
+// UnlockWebhookLocker is a locking.Locker that sends an unlock webhook for
+// each lock it releases, whether it's released because a plan failed, from
+// the UI or because its pull request was closed.
+type UnlockWebhookLocker struct {
+	locking.Locker
+	Webhooks WebhooksSender
+	Logger   *logging.SimpleLogger
+}
+
+// Unlock implements locking.Locker.Unlock.
+func (u *UnlockWebhookLocker) Unlock(key string) (*models.ProjectLock, error) {
+	lock, err := u.Locker.Unlock(key)
+	if err == nil && lock != nil {
+		u.sendUnlock(*lock)
+	}
+	return lock, err
+}
+
+// UnlockByPull implements locking.Locker.UnlockByPull.
+func (u *UnlockWebhookLocker) UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error) {
+	locks, err := u.Locker.UnlockByPull(repoFullName, pullNum)
+	if err != nil {
+		return locks, err
+	}
+	for _, lock := range locks {
+		u.sendUnlock(lock)
+	}
+	return locks, nil
+}
+
+// sendUnlock sends the unlock webhook for lock. Webhooks are best effort so
+// errors are only logged by the sender.
+func (u *UnlockWebhookLocker) sendUnlock(lock models.ProjectLock) {
+	u.Webhooks.Send(u.Logger, webhooks.LockResult{ // nolint: errcheck
+		Workspace: lock.Workspace,
+		Dir:       lock.Project.Path,
+		User:      lock.User,
+		Repo:      lock.Pull.BaseRepo,
+		Pull:      lock.Pull,
+		Locked:    false,
+	})
+}
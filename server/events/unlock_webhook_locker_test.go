@@ -0,0 +1,82 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events"
+	lockmocks "github.com/cloudposse/atlantis/server/events/locking/mocks"
+	"github.com/cloudposse/atlantis/server/events/mocks"
+	"github.com/cloudposse/atlantis/server/events/mocks/matchers"
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/webhooks"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+	. "github.com/petergtz/pegomock"
+)
+
+var unlockedLock = models.ProjectLock{
+	Project:   models.Project{RepoFullName: "owner/repo", Path: "dir"},
+	Workspace: "default",
+	User:      models.User{Username: "user"},
+	Pull:      models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+}
+
+var unlockedResult = webhooks.LockResult{
+	Workspace: "default",
+	Dir:       "dir",
+	User:      models.User{Username: "user"},
+	Repo:      models.Repo{FullName: "owner/repo"},
+	Pull:      models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+	Locked:    false,
+}
+
+func TestUnlockWebhookLocker_Unlock(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockLocker := lockmocks.NewMockLocker()
+	mockSender := mocks.NewMockWebhooksSender()
+	logger := logging.NewNoopLogger()
+	locker := events.UnlockWebhookLocker{Locker: mockLocker, Webhooks: mockSender, Logger: logger}
+	When(mockLocker.Unlock("key")).ThenReturn(&unlockedLock, nil)
+
+	lock, err := locker.Unlock("key")
+	Ok(t, err)
+	Equals(t, &unlockedLock, lock)
+	mockSender.VerifyWasCalledOnce().Send(logger, unlockedResult)
+}
+
+func TestUnlockWebhookLocker_UnlockNoLock(t *testing.T) {
+	t.Log("no webhook should be sent if there wasn't a lock or unlocking failed")
+	RegisterMockTestingT(t)
+	mockLocker := lockmocks.NewMockLocker()
+	mockSender := mocks.NewMockWebhooksSender()
+	locker := events.UnlockWebhookLocker{Locker: mockLocker, Webhooks: mockSender, Logger: logging.NewNoopLogger()}
+	When(mockLocker.Unlock("none")).ThenReturn(nil, nil)
+	When(mockLocker.Unlock("err")).ThenReturn(nil, errors.New("err"))
+
+	_, err := locker.Unlock("none")
+	Ok(t, err)
+	_, err = locker.Unlock("err")
+	ErrEquals(t, "err", err)
+	mockSender.VerifyWasCalled(Never()).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent())
+}
+
+func TestUnlockWebhookLocker_UnlockByPull(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockLocker := lockmocks.NewMockLocker()
+	mockSender := mocks.NewMockWebhooksSender()
+	logger := logging.NewNoopLogger()
+	locker := events.UnlockWebhookLocker{Locker: mockLocker, Webhooks: mockSender, Logger: logger}
+	otherLock := unlockedLock
+	otherLock.Workspace = "staging"
+	When(mockLocker.UnlockByPull("owner/repo", 1)).ThenReturn([]models.ProjectLock{unlockedLock, otherLock}, nil)
+
+	locks, err := locker.UnlockByPull("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 2, len(locks))
+	_, sent := mockSender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, unlockedResult, sent[0])
+	otherResult := unlockedResult
+	otherResult.Workspace = "staging"
+	Equals(t, otherResult, sent[1])
+}
@@ -0,0 +1,6 @@
+// Package azuredevops holds code for Azure DevOps Repos (dev.azure.com) and
+// Azure DevOps Server.
+package azuredevops
+
+// DefaultHostname is the hostname of the hosted version of Azure DevOps.
+const DefaultHostname = "dev.azure.com"
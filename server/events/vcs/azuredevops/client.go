@@ -0,0 +1,431 @@
+package azuredevops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	// apiVersion is the version of the REST API we use.
+	apiVersion = "5.1"
+	// statusAPIVersion is the version of the pull request statuses API which
+	// is only available as a preview.
+	statusAPIVersion = "5.1-preview.1"
+	// pageSize is the number of change entries, teams or team members we
+	// request per page.
+	pageSize = 100
+)
+
+// MaxCommentLength is the maximum number of characters Azure DevOps accepts
+// in a pull request comment.
+const MaxCommentLength = 150000
+
+type Client struct {
+	HttpClient  *http.Client
+	Username    string
+	Token       string
+	BaseURL     string
+	AtlantisURL string
+}
+
+// NewClient builds an Azure DevOps client. Returns an error if the baseURL is
+// malformed. httpClient is the client to use to make the requests, username
+// is the user Atlantis runs as and token is that user's personal access
+// token. baseURL is the URL of the Azure DevOps installation without the
+// organization, ex. https://dev.azure.com. atlantisURL is the URL for Atlantis
+// that will be linked to from the pull request statuses.
+func NewClient(httpClient *http.Client, username string, token string, baseURL string, atlantisURL string) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", baseURL)
+	}
+	if parsedURL.Scheme == "" {
+		return nil, fmt.Errorf("must have 'http://' or 'https://' in base url %q", baseURL)
+	}
+	return &Client{
+		HttpClient:  httpClient,
+		Username:    username,
+		Token:       token,
+		BaseURL:     strings.TrimSuffix(parsedURL.String(), "/"),
+		AtlantisURL: atlantisURL,
+	}, nil
+}
+
+// GetModifiedFiles returns the names of files that were modified in the pull request.
+// The names include the path to the file from the repo root, ex. parent/child/file.txt.
+func (c *Client) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	// Each push to the pull request creates a new iteration. The changes of the
+	// latest iteration are compared against the merge base by default so they
+	// contain every file modified in the pull request.
+	resp, err := c.makeRequest("GET", c.pullURL(repo, pull.Num, "iterations", apiVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+	var iterations Iterations
+	if err := json.Unmarshal(resp, &iterations); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if len(iterations.Value) == 0 {
+		return nil, nil
+	}
+	lastIteration := iterations.Value[len(iterations.Value)-1].ID
+
+	var files []string
+	skip := 0
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		path := fmt.Sprintf("%s&$top=%d&$skip=%d", c.pullURL(repo, pull.Num, fmt.Sprintf("iterations/%d/changes", lastIteration), apiVersion), pageSize, skip)
+		resp, err := c.makeRequest("GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var changes IterationChanges
+		if err := json.Unmarshal(resp, &changes); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, change := range changes.ChangeEntries {
+			// Paths are absolute from the repo root, ex. /parent/file.txt.
+			files = append(files, strings.TrimPrefix(change.Item.Path, "/"))
+			// If the file was renamed, we'll want to run plan in the directory
+			// it was moved from as well.
+			if change.OriginalPath != "" {
+				files = append(files, strings.TrimPrefix(change.OriginalPath, "/"))
+			}
+		}
+		if changes.NextSkip == 0 {
+			break
+		}
+		skip = changes.NextSkip
+	}
+
+	// Now ensure all files are unique.
+	hash := make(map[string]bool)
+	var unique []string
+	for _, f := range files {
+		if !hash[f] {
+			unique = append(unique, f)
+			hash[f] = true
+		}
+	}
+	return unique, nil
+}
+
+// CreateComment creates a comment on the pull request. Azure DevOps comments
+// live in threads so each comment starts a new thread. If comment is longer
+// than MaxCommentLength it's split into multiple comments.
+func (c *Client) CreateComment(repo models.Repo, pullNum int, comment string) error {
+	_, err := c.createThreads(repo, pullNum, comment)
+	return err
+}
+
+// CreateCommentWithIDs creates a comment like CreateComment and returns the
+// IDs of the threads it was split into.
+func (c *Client) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	responses, err := c.createThreads(repo, pullNum, comment)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, resp := range responses {
+		var thread struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(resp, &thread); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		ids = append(ids, strconv.Itoa(thread.ID))
+	}
+	return ids, nil
+}
+
+// UpdateComment replaces the content of the comment that started the thread
+// with id.
+func (c *Client) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	bodyBytes, err := json.Marshal(map[string]string{"content": comment})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	// The comment that starts a thread always has the id 1.
+	resource := fmt.Sprintf("threads/%s/comments/1", url.PathEscape(id))
+	_, err = c.makeRequest("PATCH", c.pullURL(repo, pullNum, resource, apiVersion), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// HideComment closes the thread with id which collapses it.
+func (c *Client) HideComment(repo models.Repo, pullNum int, id string) error {
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		// 4 is a closed thread.
+		"status": 4,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = c.makeRequest("PATCH", c.pullURL(repo, pullNum, "threads/"+url.PathEscape(id), apiVersion), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// createThreads splits comment if it's too long, starts a thread for each
+// part and returns the responses.
+func (c *Client) createThreads(repo models.Repo, pullNum int, comment string) ([][]byte, error) {
+	var responses [][]byte
+	comments, err := common.SplitComment(comment, MaxCommentLength, common.SepEnd, common.SepStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		resp, err := c.createThread(repo, pullNum, comment)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// createThread starts a new thread on the pull request holding comment.
+func (c *Client) createThread(repo models.Repo, pullNum int, comment string) ([]byte, error) {
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{
+				"parentCommentId": 0,
+				"content":         comment,
+				// 1 is a text comment.
+				"commentType": 1,
+			},
+		},
+		// 1 is an active thread.
+		"status": 1,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "json encoding")
+	}
+	return c.makeRequest("POST", c.pullURL(repo, pullNum, "threads", apiVersion), bytes.NewBuffer(bodyBytes))
+}
+
+// PullIsApproved returns true if a reviewer other than the author has voted
+// to approve the pull request.
+func (c *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
+	approvers, err := c.GetApprovers(repo, pull)
+	if err != nil {
+		return false, err
+	}
+	return len(approvers) > 0, nil
+}
+
+// GetApprovers returns the reviewers who voted to approve the pull request,
+// not including the author.
+func (c *Client) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	resp, err := c.makeRequest("GET", c.pullURL(repo, pull.Num, "reviewers", apiVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+	var reviewers struct {
+		Value []Reviewer `json:"value"`
+	}
+	if err := json.Unmarshal(resp, &reviewers); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	var approvers []string
+	for _, reviewer := range reviewers.Value {
+		if reviewer.Vote >= MinApprovedVote && !strings.EqualFold(reviewer.UniqueName, pull.Author) {
+			approvers = append(approvers, reviewer.UniqueName)
+		}
+	}
+	return approvers, nil
+}
+
+// PullIsMergeable returns true if the pull request's test merge succeeded,
+// i.e. it has no conflicts. Branch policies aren't checked since they're
+// evaluated separately from the merge.
+func (c *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	resp, err := c.makeRequest("GET", c.pullURL(repo, pull.Num, "", apiVersion), nil)
+	if err != nil {
+		return false, err
+	}
+	var azurePull PullRequest
+	if err := json.Unmarshal(resp, &azurePull); err != nil {
+		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	return azurePull.MergeStatus != nil && *azurePull.MergeStatus == MergeSucceededStatus, nil
+}
+
+// MergePull completes the pull request. Azure DevOps refuses to complete it
+// if its source branch has moved past the commit we applied.
+func (c *Client) MergePull(repo models.Repo, pull models.PullRequest) error {
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"status": "completed",
+		"lastMergeSourceCommit": map[string]string{
+			"commitId": pull.HeadCommit,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = c.makeRequest("PATCH", c.pullURL(repo, pull.Num, "", apiVersion), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// UpdateStatus updates the status of the pull request. The status name is src
+// lowercased because the aggregated status has always been called "atlantis".
+func (c *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string) error {
+	azureState := "failed"
+	switch status {
+	case models.PendingCommitStatus:
+		azureState = "pending"
+	case models.SuccessCommitStatus:
+		azureState = "succeeded"
+	case models.FailedCommitStatus:
+		azureState = "failed"
+	}
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"state":       azureState,
+		"description": description,
+		"targetUrl":   c.AtlantisURL,
+		"context": map[string]string{
+			"name":  strings.ToLower(src),
+			"genre": "Atlantis Bot",
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = c.makeRequest("POST", c.pullURL(repo, pull.Num, "statuses", statusAPIVersion), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// GetTeamNamesForUser returns the names of the teams in the repo's project
+// that the user is a member of.
+func (c *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	org, project := splitOwner(repo.Owner)
+	var teams Teams
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		teamsPath := fmt.Sprintf("%s/%s/_apis/projects/%s/teams?api-version=%s&$top=%d&$skip=%d", c.BaseURL, org, url.PathEscape(project), apiVersion, pageSize, i*pageSize)
+		resp, err := c.makeRequest("GET", teamsPath, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page Teams
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		teams.Value = append(teams.Value, page.Value...)
+		if len(page.Value) < pageSize {
+			break
+		}
+	}
+
+	var teamNames []string
+	for _, team := range teams.Value {
+		isMember, err := c.isTeamMember(org, project, team.ID, user)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			teamNames = append(teamNames, team.Name)
+		}
+	}
+	return teamNames, nil
+}
+
+// isTeamMember returns true if user is a member of the team with id teamID.
+func (c *Client) isTeamMember(org string, project string, teamID string, user models.User) (bool, error) {
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		membersPath := fmt.Sprintf("%s/%s/_apis/projects/%s/teams/%s/members?api-version=%s&$top=%d&$skip=%d", c.BaseURL, org, url.PathEscape(project), url.PathEscape(teamID), apiVersion, pageSize, i*pageSize)
+		resp, err := c.makeRequest("GET", membersPath, nil)
+		if err != nil {
+			return false, err
+		}
+		var members TeamMembers
+		if err := json.Unmarshal(resp, &members); err != nil {
+			return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, member := range members.Value {
+			if strings.EqualFold(member.Identity.UniqueName, user.Username) {
+				return true, nil
+			}
+		}
+		if len(members.Value) < pageSize {
+			break
+		}
+	}
+	return false, nil
+}
+
+// pullURL returns the URL of a pull request sub-resource, ex. threads, or of
+// the pull request itself if resource is empty.
+func (c *Client) pullURL(repo models.Repo, pullNum int, resource string, version string) string {
+	org, project := splitOwner(repo.Owner)
+	if resource != "" {
+		resource = "/" + resource
+	}
+	return fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullRequests/%d%s?api-version=%s",
+		c.BaseURL, org, url.PathEscape(project), url.PathEscape(repo.Name), pullNum, resource, version)
+}
+
+// splitOwner splits a repo owner into its organization and project. The
+// organization is everything before the last / since on Azure DevOps Server
+// it can include the collection path, ex. tfs/DefaultCollection.
+func splitOwner(owner string) (org string, project string) {
+	lastSlash := strings.LastIndex(owner, "/")
+	if lastSlash == -1 {
+		return "", owner
+	}
+	return owner[:lastSlash], owner[lastSlash+1:]
+}
+
+// prepRequest adds basic auth using the personal access token.
+func (c *Client) prepRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.Username, c.Token)
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+func (c *Client) makeRequest(method string, path string, reqBody io.Reader) ([]byte, error) {
+	req, err := c.prepRequest(method, path, reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing request")
+	}
+	if reqBody != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	requestStr := fmt.Sprintf("%s %s", method, path)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", requestStr, resp.StatusCode, string(respBody))
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading response from request %q", requestStr)
+	}
+	return respBody, nil
+}
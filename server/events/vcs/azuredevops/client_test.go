@@ -0,0 +1,292 @@
+package azuredevops_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/azuredevops"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+var repo = models.Repo{
+	FullName: "org/project/repo",
+	Owner:    "org/project",
+	Name:     "repo",
+	VCSHost: models.VCSHost{
+		Type:     models.AzureDevops,
+		Hostname: "dev.azure.com",
+	},
+}
+
+const pullPath = "/org/project/_apis/git/repositories/repo/pullRequests/1"
+
+func TestNewClient_BaseURLScheme(t *testing.T) {
+	_, err := azuredevops.NewClient(nil, "user", "token", "dev.azure.com", "runatlantis.io")
+	ErrEquals(t, `must have 'http://' or 'https://' in base url "dev.azure.com"`, err)
+}
+
+// Should use the changes from the last iteration, strip the leading / and
+// include renamed files.
+func TestClient_GetModifiedFiles(t *testing.T) {
+	changes, err := ioutil.ReadFile(filepath.Join("testdata", "iteration-changes.json"))
+	Ok(t, err)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		Assert(t, ok, "expected basic auth")
+		Equals(t, "user", user)
+		Equals(t, "token", pass)
+		switch r.RequestURI {
+		case pullPath + "/iterations?api-version=5.1":
+			w.Write([]byte(`{"value": [{"id": 1}, {"id": 2}], "count": 2}`)) // nolint: errcheck
+		case pullPath + "/iterations/2/changes?api-version=5.1&$top=100&$skip=0":
+			w.Write(changes) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+
+	files, err := client.GetModifiedFiles(repo, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, []string{"dir1/main.tf", "new/main.tf", "old/main.tf"}, files)
+}
+
+// Should follow nextSkip until it is no longer returned.
+func TestClient_GetModifiedFilesPagination(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case pullPath + "/iterations?api-version=5.1":
+			w.Write([]byte(`{"value": [{"id": 1}], "count": 1}`)) // nolint: errcheck
+		case pullPath + "/iterations/1/changes?api-version=5.1&$top=100&$skip=0":
+			w.Write([]byte(`{"changeEntries": [{"item": {"path": "/a/main.tf"}}], "nextSkip": 100, "nextTop": 100}`)) // nolint: errcheck
+		case pullPath + "/iterations/1/changes?api-version=5.1&$top=100&$skip=100":
+			w.Write([]byte(`{"changeEntries": [{"item": {"path": "/a/main.tf"}}, {"item": {"path": "/b/main.tf"}}]}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+
+	files, err := client.GetModifiedFiles(repo, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, []string{"a/main.tf", "b/main.tf"}, files)
+}
+
+func TestClient_CreateComment(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case pullPath + "/threads?api-version=5.1":
+			Equals(t, "POST", r.Method)
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			Equals(t, `{"comments":[{"commentType":1,"content":"comment","parentCommentId":0}],"status":1}`, string(body))
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	Ok(t, client.CreateComment(repo, 1, "comment"))
+}
+
+// Votes by the author shouldn't count as approvals.
+func TestClient_PullIsApproved(t *testing.T) {
+	reviewers, err := ioutil.ReadFile(filepath.Join("testdata", "reviewers.json"))
+	Ok(t, err)
+	cases := []struct {
+		author string
+		exp    bool
+	}{
+		{
+			"author@example.com",
+			false,
+		},
+		{
+			"someone@example.com",
+			true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.author, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case pullPath + "/reviewers?api-version=5.1":
+					w.Write(reviewers) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+			approved, err := client.PullIsApproved(repo, models.PullRequest{Num: 1, Author: c.author})
+			Ok(t, err)
+			Equals(t, c.exp, approved)
+		})
+	}
+}
+
+func TestClient_GetApprovers(t *testing.T) {
+	reviewers, err := ioutil.ReadFile(filepath.Join("testdata", "reviewers.json"))
+	Ok(t, err)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case pullPath + "/reviewers?api-version=5.1":
+			w.Write(reviewers) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	approvers, err := client.GetApprovers(repo, models.PullRequest{Num: 1, Author: "someone@example.com"})
+	Ok(t, err)
+	Equals(t, []string{"author@example.com"}, approvers)
+
+	approvers, err = client.GetApprovers(repo, models.PullRequest{Num: 1, Author: "author@example.com"})
+	Ok(t, err)
+	Equals(t, 0, len(approvers))
+}
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	cases := []struct {
+		mergeStatus string
+		exp         bool
+	}{
+		{"succeeded", true},
+		{"conflicts", false},
+		{"queued", false},
+	}
+	for _, c := range cases {
+		t.Run(c.mergeStatus, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case pullPath + "?api-version=5.1":
+					fmt.Fprintf(w, `{"pullRequestId": 1, "status": "active", "mergeStatus": %q}`, c.mergeStatus)
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+			mergeable, err := client.PullIsMergeable(repo, models.PullRequest{Num: 1})
+			Ok(t, err)
+			Equals(t, c.exp, mergeable)
+		})
+	}
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	cases := []struct {
+		status   models.CommitStatus
+		expState string
+	}{
+		{
+			models.PendingCommitStatus,
+			"pending",
+		},
+		{
+			models.SuccessCommitStatus,
+			"succeeded",
+		},
+		{
+			models.FailedCommitStatus,
+			"failed",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.expState, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case pullPath + "/statuses?api-version=5.1-preview.1":
+					Equals(t, "POST", r.Method)
+					var body map[string]interface{}
+					Ok(t, json.NewDecoder(r.Body).Decode(&body))
+					Equals(t, c.expState, body["state"])
+					Equals(t, "description", body["description"])
+					Equals(t, "https://atlantis.example.com", body["targetUrl"])
+					Equals(t, map[string]interface{}{"name": "atlantis", "genre": "Atlantis Bot"}, body["context"])
+					w.WriteHeader(http.StatusOK)
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "https://atlantis.example.com")
+			Ok(t, err)
+			Ok(t, client.UpdateStatus(repo, models.PullRequest{Num: 1}, c.status, "Atlantis", "description"))
+		})
+	}
+}
+
+func TestClient_GetTeamNamesForUser(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/org/_apis/projects/project/teams?api-version=5.1&$top=100&$skip=0":
+			w.Write([]byte(`{"value": [{"id": "t1", "name": "devs"}, {"id": "t2", "name": "admins"}], "count": 2}`)) // nolint: errcheck
+		case "/org/_apis/projects/project/teams/t1/members?api-version=5.1&$top=100&$skip=0":
+			// A full page means there may be more members on the next page.
+			var members []string
+			for i := 0; i < 100; i++ {
+				members = append(members, `{"identity": {"uniqueName": "other@example.com"}}`)
+			}
+			w.Write([]byte(`{"value": [` + strings.Join(members, ",") + `]}`)) // nolint: errcheck
+		case "/org/_apis/projects/project/teams/t1/members?api-version=5.1&$top=100&$skip=100":
+			w.Write([]byte(`{"value": [{"identity": {"uniqueName": "User@example.com"}}]}`)) // nolint: errcheck
+		case "/org/_apis/projects/project/teams/t2/members?api-version=5.1&$top=100&$skip=0":
+			w.Write([]byte(`{"value": [{"identity": {"uniqueName": "other@example.com"}}]}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "user@example.com"})
+	Ok(t, err)
+	Equals(t, []string{"devs"}, teams)
+}
+
+// Errors should include the request and the response body.
+func TestClient_UnexpectedStatus(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusUnauthorized)
+	}))
+	defer testServer.Close()
+
+	client, err := azuredevops.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	err = client.CreateComment(repo, 1, "comment")
+	ErrEquals(t, fmt.Sprintf("making request \"POST %s%s/threads?api-version=5.1\" unexpected status code: 401, body: denied\n", testServer.URL, pullPath), err)
+}
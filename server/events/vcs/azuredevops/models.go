@@ -0,0 +1,124 @@
+package azuredevops
+
+const (
+	PullCreatedEvent        = "git.pullrequest.created"
+	PullUpdatedEvent        = "git.pullrequest.updated"
+	PullMergedEvent         = "git.pullrequest.merged"
+	PullCommentCreatedEvent = "ms.vss-code.git-pullrequest-comment-event"
+
+	PullActiveStatus    = "active"
+	PullCompletedStatus = "completed"
+	PullAbandonedStatus = "abandoned"
+
+	// SourceBranchUpdatedText is contained in the message of an updated
+	// event when new commits were pushed. Azure DevOps sends the same event
+	// type for votes, reviewer changes, etc. so this is how we tell them
+	// apart.
+	SourceBranchUpdatedText = "updated the source branch"
+
+	// MinApprovedVote is the lowest reviewer vote that counts as an approval.
+	// 10 is "approved" and 5 is "approved with suggestions".
+	MinApprovedVote = 5
+
+	// MergeSucceededStatus is the merge status of a pull request that can be
+	// merged without conflicts.
+	MergeSucceededStatus = "succeeded"
+)
+
+// Event is the common envelope of all service hook events.
+type Event struct {
+	EventType *string  `json:"eventType,omitempty" validate:"required"`
+	Message   *Message `json:"message,omitempty"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type PullRequestEvent struct {
+	Event
+	Resource *PullRequest `json:"resource,omitempty" validate:"required"`
+}
+
+type CommentEvent struct {
+	Event
+	Resource *CommentResource `json:"resource,omitempty" validate:"required"`
+}
+
+type CommentResource struct {
+	Comment     *Comment     `json:"comment,omitempty" validate:"required"`
+	PullRequest *PullRequest `json:"pullRequest,omitempty" validate:"required"`
+}
+
+type PullRequest struct {
+	PullRequestID         *int        `json:"pullRequestId,omitempty" validate:"required"`
+	Status                *string     `json:"status,omitempty" validate:"required"`
+	CreatedBy             *Identity   `json:"createdBy,omitempty" validate:"required"`
+	SourceRefName         *string     `json:"sourceRefName,omitempty" validate:"required"`
+	Repository            *Repository `json:"repository,omitempty" validate:"required"`
+	LastMergeSourceCommit *Commit     `json:"lastMergeSourceCommit,omitempty" validate:"required"`
+	ForkSource            *ForkRef    `json:"forkSource,omitempty"`
+	Reviewers             []Reviewer  `json:"reviewers,omitempty"`
+	// MergeStatus is the status of the test merge, ex. "succeeded" or
+	// "conflicts".
+	MergeStatus *string `json:"mergeStatus,omitempty"`
+}
+
+type ForkRef struct {
+	Repository *Repository `json:"repository,omitempty" validate:"required"`
+}
+
+type Repository struct {
+	Name      *string `json:"name,omitempty" validate:"required"`
+	RemoteURL *string `json:"remoteUrl,omitempty" validate:"required"`
+}
+
+type Commit struct {
+	CommitID *string `json:"commitId,omitempty" validate:"required"`
+}
+
+type Identity struct {
+	ID         string `json:"id"`
+	UniqueName string `json:"uniqueName" validate:"required"`
+}
+
+type Reviewer struct {
+	Identity
+	Vote int `json:"vote"`
+}
+
+type Comment struct {
+	Content *string   `json:"content,omitempty" validate:"required"`
+	Author  *Identity `json:"author,omitempty" validate:"required"`
+}
+
+type Iterations struct {
+	Value []struct {
+		ID int `json:"id"`
+	} `json:"value"`
+}
+
+type IterationChanges struct {
+	ChangeEntries []struct {
+		Item struct {
+			Path string `json:"path"`
+		} `json:"item"`
+		// OriginalPath is set if the file was renamed.
+		OriginalPath string `json:"originalPath"`
+	} `json:"changeEntries"`
+	NextSkip int `json:"nextSkip"`
+	NextTop  int `json:"nextTop"`
+}
+
+type Teams struct {
+	Value []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"value"`
+}
+
+type TeamMembers struct {
+	Value []struct {
+		Identity Identity `json:"identity"`
+	} `json:"value"`
+}
@@ -0,0 +1,23 @@
+package azuredevops
+
+import (
+	"crypto/subtle"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateBasicAuth checks that the basic auth credentials sent with a
+// service hook request match the ones configured for Atlantis. Azure DevOps
+// doesn't sign its requests so basic auth is the only way to verify them.
+// ok is the value returned by http.Request.BasicAuth.
+func ValidateBasicAuth(user string, password string, ok bool, expUser string, expPassword string) error {
+	if !ok {
+		return errors.New("missing basic auth credentials")
+	}
+	userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(expUser)) == 1
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(expPassword)) == 1
+	if !userMatch || !passwordMatch {
+		return errors.New("basic auth credentials did not match")
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package azuredevops_test
+
+import (
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/vcs/azuredevops"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestValidateBasicAuth(t *testing.T) {
+	Ok(t, azuredevops.ValidateBasicAuth("user", "pass", true, "user", "pass"))
+}
+
+func TestValidateBasicAuth_Missing(t *testing.T) {
+	err := azuredevops.ValidateBasicAuth("", "", false, "user", "pass")
+	ErrEquals(t, "missing basic auth credentials", err)
+}
+
+func TestValidateBasicAuth_Mismatch(t *testing.T) {
+	cases := []struct {
+		user     string
+		password string
+	}{
+		{"user", "wrong"},
+		{"wrong", "pass"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.user+":"+c.password, func(t *testing.T) {
+			err := azuredevops.ValidateBasicAuth(c.user, c.password, true, "user", "pass")
+			ErrEquals(t, "basic auth credentials did not match", err)
+		})
+	}
+}
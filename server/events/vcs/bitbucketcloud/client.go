@@ -2,30 +2,48 @@ package bitbucketcloud
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/cache"
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
 	"github.com/pkg/errors"
 	"gopkg.in/go-playground/validator.v9"
 )
 
+// MaxCommentLength is the maximum number of characters Bitbucket accepts in a
+// pull request comment.
+const MaxCommentLength = 32768
+
+// maxStatusKeyLength is the maximum length of a commit status key.
+const maxStatusKeyLength = 40
+
 type Client struct {
 	HttpClient  *http.Client
 	Username    string
 	Password    string
 	BaseURL     string
 	AtlantisURL string
+
+	groupsCache *cache.TTL
 }
 
 // NewClient builds a bitbucket cloud client. atlantisURL is the
 // URL for Atlantis that will be linked to from the build status icons. This
 // linking is annoying because we don't have anywhere good to link but a URL is
-// required.
-func NewClient(httpClient *http.Client, username string, password string, atlantisURL string) *Client {
+// required. groupsCacheTTL is how long the groups in a workspace are cached
+// for. If it's 0 they aren't cached.
+func NewClient(httpClient *http.Client, username string, password string, atlantisURL string, groupsCacheTTL time.Duration) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
@@ -35,6 +53,7 @@ func NewClient(httpClient *http.Client, username string, password string, atlant
 		Password:    password,
 		BaseURL:     BaseURL,
 		AtlantisURL: atlantisURL,
+		groupsCache: cache.NewTTL(groupsCacheTTL),
 	}
 }
 
@@ -85,30 +104,82 @@ func (b *Client) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]
 }
 
 // CreateComment creates a comment on the merge request.
+// If comment length is greater than the max comment length we split into
+// multiple comments.
 func (b *Client) CreateComment(repo models.Repo, pullNum int, comment string) error {
+	_, err := b.createComments(repo, pullNum, comment)
+	return err
+}
+
+// CreateCommentWithIDs creates a comment like CreateComment and returns the
+// IDs of the comments it was split into.
+func (b *Client) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	responses, err := b.createComments(repo, pullNum, comment)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, resp := range responses {
+		var created struct {
+			CommentID int `json:"comment_id"`
+		}
+		if err := json.Unmarshal(resp, &created); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		ids = append(ids, strconv.Itoa(created.CommentID))
+	}
+	return ids, nil
+}
+
+// createComments splits comment if it's too long, creates each part and
+// returns the responses.
+func (b *Client) createComments(repo models.Repo, pullNum int, comment string) ([][]byte, error) {
+	var responses [][]byte
+	comments, err := common.SplitComment(comment, MaxCommentLength, common.SepEnd, common.SepStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		resp, err := b.postComment(repo, pullNum, c)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// UpdateComment replaces the content of the comment with id.
+func (b *Client) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
 	bodyBytes, err := json.Marshal(map[string]string{"content": comment})
 	if err != nil {
 		return errors.Wrap(err, "json encoding")
 	}
-	path := fmt.Sprintf("%s/1.0/repositories/%s/pullrequests/%d/comments", b.BaseURL, repo.FullName, pullNum)
-	_, err = b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+	path := fmt.Sprintf("%s/1.0/repositories/%s/pullrequests/%d/comments/%s", b.BaseURL, repo.FullName, pullNum, url.PathEscape(id))
+	_, err = b.makeRequest("PUT", path, bytes.NewBuffer(bodyBytes))
 	return err
 }
 
+// HideComment isn't supported since Bitbucket can't hide comments.
+func (b *Client) HideComment(repo models.Repo, pullNum int, id string) error {
+	return common.ErrHideCommentNotSupported
+}
+
+func (b *Client) postComment(repo models.Repo, pullNum int, comment string) ([]byte, error) {
+	bodyBytes, err := json.Marshal(map[string]string{"content": comment})
+	if err != nil {
+		return nil, errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/1.0/repositories/%s/pullrequests/%d/comments", b.BaseURL, repo.FullName, pullNum)
+	return b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+}
+
 // PullIsApproved returns true if the merge request was approved.
 func (b *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
-	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d", b.BaseURL, repo.FullName, pull.Num)
-	resp, err := b.makeRequest("GET", path, nil)
+	pullResp, err := b.getPullRequest(repo, pull.Num)
 	if err != nil {
 		return false, err
 	}
-	var pullResp PullRequest
-	if err := json.Unmarshal(resp, &pullResp); err != nil {
-		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
-	}
-	if err := validator.New().Struct(pullResp); err != nil {
-		return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
-	}
 	for _, participant := range pullResp.Participants {
 		// Bitbucket allows the author to approve their own pull request. This
 		// defeats the purpose of approvals so we don't count that approval.
@@ -119,8 +190,122 @@ func (b *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool
 	return false, nil
 }
 
-// UpdateStatus updates the status of a commit.
-func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, description string) error {
+// GetApprovers returns the participants who approved the pull request, not
+// including the author.
+func (b *Client) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	pullResp, err := b.getPullRequest(repo, pull.Num)
+	if err != nil {
+		return nil, err
+	}
+	var approvers []string
+	for _, participant := range pullResp.Participants {
+		if *participant.Approved && *participant.User.Username != pull.Author {
+			approvers = append(approvers, *participant.User.Username)
+		}
+	}
+	return approvers, nil
+}
+
+// PullIsMergeable returns true if the pull request is open, has no conflicts,
+// no participant has requested changes and every commit status on its head
+// commit passed, other than Atlantis's own. Bitbucket Cloud's API doesn't tell
+// us whether the repo's merge checks pass and reading its branch restrictions
+// requires admin permissions, so these are the checks we can make ourselves.
+func (b *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	pullResp, err := b.getPullRequest(repo, pull.Num)
+	if err != nil {
+		return false, err
+	}
+	if *pullResp.State != "OPEN" {
+		return false, nil
+	}
+	for _, p := range pullResp.Participants {
+		if p.State != nil && *p.State == "changes_requested" {
+			return false, nil
+		}
+	}
+
+	hasConflicts, err := b.hasConflicts(repo, pull.Num)
+	if err != nil || hasConflicts {
+		return false, err
+	}
+	return b.statusesPassed(repo, *pullResp.Source.Commit.Hash)
+}
+
+// hasConflicts returns true if any of the files in the pull request conflict
+// with its destination branch.
+func (b *Client) hasConflicts(repo models.Repo, pullNum int) (bool, error) {
+	nextPageURL := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d/diffstat", b.BaseURL, repo.FullName, pullNum)
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", nextPageURL, nil)
+		if err != nil {
+			return false, err
+		}
+		var diffStat DiffStat
+		if err := json.Unmarshal(resp, &diffStat); err != nil {
+			return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, v := range diffStat.Values {
+			if v.Status != nil && (*v.Status == "merge conflict" || *v.Status == "local deleted" || *v.Status == "remote deleted") {
+				return true, nil
+			}
+		}
+		if diffStat.Next == nil || *diffStat.Next == "" {
+			break
+		}
+		nextPageURL = *diffStat.Next
+	}
+	return false, nil
+}
+
+// statusesPassed returns true if every commit status on commit that wasn't
+// made by Atlantis is successful. Atlantis's statuses are ignored because the
+// apply that checks mergeability is what makes them pass.
+func (b *Client) statusesPassed(repo models.Repo, commit string) (bool, error) {
+	nextPageURL := fmt.Sprintf("%s/2.0/repositories/%s/commit/%s/statuses", b.BaseURL, repo.FullName, commit)
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", nextPageURL, nil)
+		if err != nil {
+			return false, err
+		}
+		var statuses CommitStatuses
+		if err := json.Unmarshal(resp, &statuses); err != nil {
+			return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(statuses); err != nil {
+			return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, s := range statuses.Values {
+			if *s.Key == "atlantis" || strings.HasPrefix(*s.Key, "atlantis/") {
+				continue
+			}
+			if *s.State != "SUCCESSFUL" {
+				return false, nil
+			}
+		}
+		if statuses.Next == nil || *statuses.Next == "" {
+			break
+		}
+		nextPageURL = *statuses.Next
+	}
+	return true, nil
+}
+
+// MergePull merges the pull request using the repo's default merge strategy.
+func (b *Client) MergePull(repo models.Repo, pull models.PullRequest) error {
+	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d/merge", b.BaseURL, repo.FullName, pull.Num)
+	_, err := b.makeRequest("POST", path, nil)
+	return err
+}
+
+// UpdateStatus updates the status of a commit. The key is src lowercased
+// because the aggregated status has always used the key "atlantis". Keys that
+// are too long are shortened with statusKey and src is kept as the name.
+func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string) error {
 	bbState := "FAILED"
 	switch status {
 	case models.PendingCommitStatus:
@@ -132,7 +317,8 @@ func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status
 	}
 
 	bodyBytes, err := json.Marshal(map[string]string{
-		"key":         "atlantis",
+		"key":         statusKey(src),
+		"name":        src,
 		"url":         b.AtlantisURL,
 		"state":       bbState,
 		"description": description,
@@ -146,7 +332,35 @@ func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status
 	return err
 }
 
-// prepRequest adds the HTTP basic auth.
+// statusKey returns the key of the status named src. Keys over Bitbucket's
+// limit are truncated and end with a hash of src so they stay unique.
+func statusKey(src string) string {
+	key := strings.ToLower(src)
+	if len(key) <= maxStatusKeyLength {
+		return key
+	}
+	sum := sha256.Sum256([]byte(src))
+	hash := hex.EncodeToString(sum[:])[:8]
+	return key[:maxStatusKeyLength-len(hash)-1] + "-" + hash
+}
+
+// getPullRequest returns the pull request.
+func (b *Client) getPullRequest(repo models.Repo, pullNum int) (PullRequest, error) {
+	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d", b.BaseURL, repo.FullName, pullNum)
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	var pullResp PullRequest
+	if err := json.Unmarshal(resp, &pullResp); err != nil {
+		return PullRequest{}, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if err := validator.New().Struct(pullResp); err != nil {
+		return PullRequest{}, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+	}
+	return pullResp, nil
+}
+
 func (b *Client) prepRequest(method string, path string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, path, body)
 	if err != nil {
@@ -182,7 +396,40 @@ func (b *Client) makeRequest(method string, path string, reqBody io.Reader) ([]b
 	return respBody, nil
 }
 
-// GetTeamNamesForUser returns the names of the teams or groups that the user belongs to (in the organization the repository belongs to).
-func (g *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
-	return nil, nil
+// GetTeamNamesForUser returns the names of the groups in the repo's
+// workspace that the user belongs to. Groups are only available in the 1.0
+// API which returns every group with its members in one response, so we cache
+// the groups per workspace rather than per user.
+func (b *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	workspace := repo.Owner
+	var groups []Group
+	if cached, ok := b.groupsCache.Get(workspace); ok {
+		groups = cached.([]Group)
+	} else {
+		resp, err := b.makeRequest("GET", fmt.Sprintf("%s/1.0/groups/%s", b.BaseURL, workspace), nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(resp, &groups); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, g := range groups {
+			if err := validator.New().Struct(g); err != nil {
+				return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+			}
+		}
+		b.groupsCache.Set(workspace, groups)
+	}
+
+	var teamNames []string
+	for _, g := range groups {
+		for _, m := range g.Members {
+			if (m.Username != nil && strings.EqualFold(*m.Username, user.Username)) ||
+				(m.Nickname != nil && strings.EqualFold(*m.Nickname, user.Username)) {
+				teamNames = append(teamNames, *g.Name)
+				break
+			}
+		}
+	}
+	return teamNames, nil
 }
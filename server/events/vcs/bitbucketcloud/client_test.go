@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketcloud"
@@ -70,7 +72,7 @@ func TestClient_GetModifiedFilesPagination(t *testing.T) {
 	defer testServer.Close()
 
 	serverURL = testServer.URL
-	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", 0)
 	client.BaseURL = testServer.URL
 
 	files, err := client.GetModifiedFiles(models.Repo{
@@ -131,7 +133,7 @@ func TestClient_GetModifiedFilesOldNil(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", 0)
 	client.BaseURL = testServer.URL
 
 	files, err := client.GetModifiedFiles(models.Repo{
@@ -197,7 +199,7 @@ func TestClient_PullIsApproved(t *testing.T) {
 			}))
 			defer testServer.Close()
 
-			client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+			client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", 0)
 			client.BaseURL = testServer.URL
 
 			repo, err := models.NewRepo(models.BitbucketServer, "owner/repo", "https://bitbucket.org/owner/repo.git", "user", "token")
@@ -213,3 +215,179 @@ func TestClient_PullIsApproved(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	cases := []struct {
+		description string
+		state       string
+		participant string
+		diffStat    string
+		statuses    string
+		exp         bool
+	}{
+		{
+			"mergeable",
+			"OPEN",
+			`"approved": true, "state": "approved"`,
+			`{"values": [{"status": "modified"}]}`,
+			`{"values": [{"key": "ci", "state": "SUCCESSFUL"}, {"key": "atlantis/apply", "state": "FAILED"}, {"key": "atlantis", "state": "INPROGRESS"}]}`,
+			true,
+		},
+		{
+			"declined",
+			"DECLINED",
+			`"approved": true`,
+			`{"values": []}`,
+			`{"values": []}`,
+			false,
+		},
+		{
+			"changes requested",
+			"OPEN",
+			`"approved": false, "state": "changes_requested"`,
+			`{"values": []}`,
+			`{"values": []}`,
+			false,
+		},
+		{
+			"conflicts",
+			"OPEN",
+			`"approved": true`,
+			`{"values": [{"status": "merge conflict"}]}`,
+			`{"values": []}`,
+			false,
+		},
+		{
+			"other status in progress",
+			"OPEN",
+			`"approved": true`,
+			`{"values": []}`,
+			`{"values": [{"key": "ci", "state": "INPROGRESS"}]}`,
+			false,
+		},
+	}
+
+	pullJSON, err := ioutil.ReadFile(filepath.Join("testdata", "pull-approved.json"))
+	Ok(t, err)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			pull := strings.Replace(string(pullJSON), `"state": "OPEN"`, fmt.Sprintf(`"state": %q`, c.state), 1)
+			pull = strings.Replace(pull, `"approved": true`, c.participant, 1)
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/2.0/repositories/owner/repo/pullrequests/1":
+					w.Write([]byte(pull)) // nolint: errcheck
+				case "/2.0/repositories/owner/repo/pullrequests/1/diffstat":
+					w.Write([]byte(c.diffStat)) // nolint: errcheck
+				case "/2.0/repositories/owner/repo/commit/3428957ade18/statuses":
+					w.Write([]byte(c.statuses)) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", 0)
+			client.BaseURL = testServer.URL
+			repo, err := models.NewRepo(models.BitbucketCloud, "owner/repo", "https://bitbucket.org/owner/repo.git", "user", "token")
+			Ok(t, err)
+			mergeable, err := client.PullIsMergeable(repo, models.PullRequest{Num: 1, BaseRepo: repo})
+			Ok(t, err)
+			Equals(t, c.exp, mergeable)
+		})
+	}
+}
+
+// The key should be the lowercased status name so the aggregated status keeps
+// its original "atlantis" key. Names over the 40 character limit should be
+// truncated and hashed.
+func TestClient_UpdateStatus(t *testing.T) {
+	cases := []struct {
+		src    string
+		expKey string
+	}{
+		{
+			"Atlantis",
+			"atlantis",
+		},
+		{
+			"atlantis/plan: envs/prod (default)",
+			"atlantis/plan: envs/prod (default)",
+		},
+		{
+			"atlantis/plan: envs/prod/us-east-1/networking (default)",
+			"atlantis/plan: envs/prod/us-eas-76c468c0",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.src, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/2.0/repositories/owner/repo/commit/sha/statuses/build":
+					body, err := ioutil.ReadAll(r.Body)
+					Ok(t, err)
+					exp := fmt.Sprintf(`{"description":"Plan Success","key":"%s","name":"%s","state":"SUCCESSFUL","url":"runatlantis.io"}`, c.expKey, c.src)
+					Equals(t, exp, string(body))
+					w.WriteHeader(http.StatusCreated)
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", 0)
+			client.BaseURL = testServer.URL
+
+			repo, err := models.NewRepo(models.BitbucketCloud, "owner/repo", "https://bitbucket.org/owner/repo.git", "user", "token")
+			Ok(t, err)
+			err = client.UpdateStatus(repo, models.PullRequest{Num: 1, HeadCommit: "sha"}, models.SuccessCommitStatus, c.src, "Plan Success")
+			Ok(t, err)
+		})
+	}
+}
+
+// Should return the groups the user is a member of and cache the workspace's
+// groups.
+func TestClient_GetTeamNamesForUser(t *testing.T) {
+	numRequests := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		switch r.RequestURI {
+		case "/1.0/groups/owner":
+			w.Write([]byte(`[
+  {"name": "Administrators", "slug": "administrators", "members": [{"username": "admin", "nickname": "admin"}]},
+  {"name": "Developers", "slug": "developers", "members": [{"username": "admin"}, {"nickname": "lkysow"}]},
+  {"name": "Empty", "slug": "empty", "members": []}
+]`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", time.Hour)
+	client.BaseURL = testServer.URL
+	repo := models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}
+
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "lkysow"})
+	Ok(t, err)
+	Equals(t, []string{"Developers"}, teams)
+
+	teams, err = client.GetTeamNamesForUser(repo, models.User{Username: "admin"})
+	Ok(t, err)
+	Equals(t, []string{"Administrators", "Developers"}, teams)
+	Equals(t, 1, numRequests)
+
+	// With a TTL of 0 the groups shouldn't be cached.
+	client = bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", 0)
+	client.BaseURL = testServer.URL
+	for i := 0; i < 2; i++ {
+		_, err = client.GetTeamNamesForUser(repo, models.User{Username: "lkysow"})
+		Ok(t, err)
+	}
+	Equals(t, 3, numRequests)
+}
@@ -28,6 +28,8 @@ type DiffStat struct {
 	Next   *string         `json:"next,omitempty"`
 }
 type DiffStatValue struct {
+	// Status is the file's status, ex. "modified" or "merge conflict".
+	Status *string `json:"status,omitempty"`
 	// Old is the old file, this can be null.
 	Old *DiffStatFile `json:"old,omitempty"`
 	// New is the new file, this can be null.
@@ -59,7 +61,9 @@ type Link struct {
 }
 type Participant struct {
 	Approved *bool `json:"approved,omitempty" validate:"required"`
-	User     *struct {
+	// State is "approved", "changes_requested" or null.
+	State *string `json:"state,omitempty"`
+	User  *struct {
 		Username *string `json:"username,omitempty" validate:"required"`
 	} `json:"user,omitempty" validate:"required"`
 }
@@ -68,6 +72,15 @@ type Source struct {
 	Commit     *Commit     `json:"commit,omitempty" validate:"required"`
 	Branch     *Branch     `json:"branch,omitempty" validate:"required"`
 }
+type CommitStatuses struct {
+	Values []CommitStatus `json:"values,omitempty" validate:"dive"`
+	Next   *string        `json:"next,omitempty"`
+}
+type CommitStatus struct {
+	Key *string `json:"key,omitempty" validate:"required"`
+	// State is SUCCESSFUL, FAILED, INPROGRESS or STOPPED.
+	State *string `json:"state,omitempty" validate:"required"`
+}
 type Branch struct {
 	Name *string `json:"name,omitempty" validate:"required"`
 }
@@ -80,3 +93,10 @@ type Comment struct {
 type CommentContent struct {
 	Raw *string `json:"raw,omitempty" validate:"required"`
 }
+type Group struct {
+	Name    *string `json:"name,omitempty" validate:"required"`
+	Members []struct {
+		Username *string `json:"username,omitempty"`
+		Nickname *string `json:"nickname,omitempty"`
+	} `json:"members"`
+}
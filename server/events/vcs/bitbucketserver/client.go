@@ -9,13 +9,19 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
 	"github.com/pkg/errors"
 	"gopkg.in/go-playground/validator.v9"
 )
 
+// MaxCommentLength is the maximum number of characters Bitbucket accepts in a
+// pull request comment.
+const MaxCommentLength = 32768
+
 type Client struct {
 	HttpClient  *http.Client
 	Username    string
@@ -118,38 +124,114 @@ func (b *Client) GetProjectKey(repoName string, cloneURL string) (string, error)
 }
 
 // CreateComment creates a comment on the merge request.
+// If comment length is greater than the max comment length we split into
+// multiple comments.
 func (b *Client) CreateComment(repo models.Repo, pullNum int, comment string) error {
-	bodyBytes, err := json.Marshal(map[string]string{"text": comment})
+	_, err := b.createComments(repo, pullNum, comment)
+	return err
+}
+
+// CreateCommentWithIDs creates a comment like CreateComment and returns the
+// IDs of the comments it was split into.
+func (b *Client) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	responses, err := b.createComments(repo, pullNum, comment)
 	if err != nil {
-		return errors.Wrap(err, "json encoding")
+		return nil, err
 	}
-	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	var ids []string
+	for _, resp := range responses {
+		var created Comment
+		if err := json.Unmarshal(resp, &created); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if created.ID == nil {
+			return nil, fmt.Errorf("API response %q was missing the comment's id", string(resp))
+		}
+		ids = append(ids, strconv.Itoa(*created.ID))
+	}
+	return ids, nil
+}
+
+// createComments splits comment if it's too long, creates each part and
+// returns the responses.
+func (b *Client) createComments(repo models.Repo, pullNum int, comment string) ([][]byte, error) {
+	path, err := b.commentsPath(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	var responses [][]byte
+	comments, err := common.SplitComment(comment, MaxCommentLength, common.SepEnd, common.SepStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		bodyBytes, err := json.Marshal(map[string]string{"text": c})
+		if err != nil {
+			return nil, errors.Wrap(err, "json encoding")
+		}
+		resp, err := b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// UpdateComment replaces the text of the comment with id. Bitbucket Server
+// requires the comment's current version so we fetch it first.
+func (b *Client) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	path, err := b.commentsPath(repo, pullNum)
 	if err != nil {
 		return err
 	}
-	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/comments", b.BaseURL, projectKey, repo.Name, pullNum)
-	_, err = b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+	path = fmt.Sprintf("%s/%s", path, url.PathEscape(id))
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	var existing Comment
+	if err := json.Unmarshal(resp, &existing); err != nil {
+		return errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if existing.Version == nil {
+		return fmt.Errorf("API response %q was missing the comment's version", string(resp))
+	}
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"text":    comment,
+		"version": *existing.Version,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = b.makeRequest("PUT", path, bytes.NewBuffer(bodyBytes))
 	return err
 }
 
+// HideComment isn't supported since Bitbucket Server can't hide comments.
+func (b *Client) HideComment(repo models.Repo, pullNum int, id string) error {
+	return common.ErrHideCommentNotSupported
+}
+
+// commentsPath returns the URL of the pull request's comments.
+func (b *Client) commentsPath(repo models.Repo, pullNum int) (string, error) {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/comments", b.BaseURL, projectKey, repo.Name, pullNum), nil
+}
+
 // PullIsApproved returns true if the merge request was approved.
 func (b *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
 	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
 	if err != nil {
 		return false, err
 	}
-	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", b.BaseURL, projectKey, repo.Name, pull.Num)
-	resp, err := b.makeRequest("GET", path, nil)
+	pullResp, err := b.getPullRequest(projectKey, repo, pull.Num)
 	if err != nil {
 		return false, err
 	}
-	var pullResp PullRequest
-	if err := json.Unmarshal(resp, &pullResp); err != nil {
-		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
-	}
-	if err := validator.New().Struct(pullResp); err != nil {
-		return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
-	}
 	for _, reviewer := range pullResp.Reviewers {
 		if *reviewer.Approved {
 			return true, nil
@@ -158,8 +240,94 @@ func (b *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool
 	return false, nil
 }
 
-// UpdateStatus updates the status of a commit.
-func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, description string) error {
+// GetApprovers returns the reviewers who approved the pull request.
+func (b *Client) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return nil, err
+	}
+	pullResp, err := b.getPullRequest(projectKey, repo, pull.Num)
+	if err != nil {
+		return nil, err
+	}
+	var approvers []string
+	for _, reviewer := range pullResp.Reviewers {
+		if *reviewer.Approved && reviewer.User != nil {
+			approvers = append(approvers, *reviewer.User.Username)
+		}
+	}
+	return approvers, nil
+}
+
+// PullIsMergeable returns true if Bitbucket says the pull request can be
+// merged, i.e. it has no conflicts and the repo's merge checks pass.
+func (b *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return false, err
+	}
+	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/merge", b.BaseURL, projectKey, repo.Name, pull.Num)
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return false, err
+	}
+	var status MergeStatus
+	if err := json.Unmarshal(resp, &status); err != nil {
+		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if err := validator.New().Struct(status); err != nil {
+		return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+	}
+	return *status.CanMerge, nil
+}
+
+// MergePull merges the pull request. The merge fails if the pull request's
+// head has moved past the commit we applied.
+func (b *Client) MergePull(repo models.Repo, pull models.PullRequest) error {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return err
+	}
+	bbPull, err := b.getPullRequest(projectKey, repo, pull.Num)
+	if err != nil {
+		return err
+	}
+	if bbPull.FromRef == nil || bbPull.FromRef.LatestCommit == nil {
+		return errors.New("API response was missing the pull request's latest commit")
+	}
+	if *bbPull.FromRef.LatestCommit != pull.HeadCommit {
+		return fmt.Errorf("pull request's head has changed from %s to %s", pull.HeadCommit, *bbPull.FromRef.LatestCommit)
+	}
+	if bbPull.Version == nil {
+		return errors.New("API response was missing the pull request's version")
+	}
+	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/merge?version=%d", b.BaseURL, projectKey, repo.Name, pull.Num, *bbPull.Version)
+	// We send an empty JSON body so the request has a JSON content type
+	// which Bitbucket Server requires to not reject it as a XSRF attempt.
+	_, err = b.makeRequest("POST", path, bytes.NewBufferString("{}"))
+	return err
+}
+
+// getPullRequest returns the pull request in the project with projectKey.
+func (b *Client) getPullRequest(projectKey string, repo models.Repo, pullNum int) (PullRequest, error) {
+	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", b.BaseURL, projectKey, repo.Name, pullNum)
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	var pullResp PullRequest
+	if err := json.Unmarshal(resp, &pullResp); err != nil {
+		return PullRequest{}, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if err := validator.New().Struct(pullResp); err != nil {
+		return PullRequest{}, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+	}
+	return pullResp, nil
+}
+
+// UpdateStatus updates the status of a commit. The key is src lowercased
+// because the aggregated status has always used the key "atlantis".
+func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string) error {
 	bbState := "FAILED"
 	switch status {
 	case models.PendingCommitStatus:
@@ -171,7 +339,8 @@ func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status
 	}
 
 	bodyBytes, err := json.Marshal(map[string]string{
-		"key":         "atlantis",
+		"key":         strings.ToLower(src),
+		"name":        src,
 		"url":         b.AtlantisURL,
 		"state":       bbState,
 		"description": description,
@@ -221,7 +390,63 @@ func (b *Client) makeRequest(method string, path string, reqBody io.Reader) ([]b
 	return respBody, nil
 }
 
-// GetTeamNamesForUser returns the names of the teams or groups that the user belongs to (in the organization the repository belongs to).
-func (g *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+// repoPermissions are the repo permissions a user can have, from highest to
+// lowest. Each permission includes the ones below it.
+var repoPermissions = []string{"REPO_ADMIN", "REPO_WRITE", "REPO_READ"}
+
+// GetTeamNamesForUser returns the permissions the user has on the repo, ex.
+// REPO_WRITE and REPO_READ, including permissions granted on its project or
+// through groups. We look up permissions rather than groups because listing
+// a user's groups requires the Admin global permission while any user can
+// search for users by permission.
+func (b *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, permission := range repoPermissions {
+		hasPermission, err := b.hasRepoPermission(projectKey, repo.Name, user.Username, permission)
+		if err != nil {
+			return nil, err
+		}
+		if hasPermission {
+			return append([]string(nil), repoPermissions[i:]...), nil
+		}
+	}
 	return nil, nil
 }
+
+// hasRepoPermission returns true if username has permission on the repo.
+func (b *Client) hasRepoPermission(projectKey string, repoSlug string, username string, permission string) (bool, error) {
+	nextPageStart := 0
+	baseURL := fmt.Sprintf("%s/rest/api/1.0/users?filter=%s&permission.1=%s&permission.1.projectKey=%s&permission.1.repositorySlug=%s",
+		b.BaseURL, url.QueryEscape(username), permission, url.QueryEscape(projectKey), url.QueryEscape(repoSlug))
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", fmt.Sprintf("%s&start=%d", baseURL, nextPageStart), nil)
+		if err != nil {
+			return false, err
+		}
+		var users Users
+		if err := json.Unmarshal(resp, &users); err != nil {
+			return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(users); err != nil {
+			return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		// The filter also matches display names and emails so we need to
+		// check for the exact username.
+		for _, u := range users.Values {
+			if strings.EqualFold(*u.Name, username) {
+				return true, nil
+			}
+		}
+		if *users.IsLastPage || users.NextPageStart == nil {
+			break
+		}
+		nextPageStart = *users.NextPageStart
+	}
+	return false, nil
+}
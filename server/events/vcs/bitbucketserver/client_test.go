@@ -120,7 +120,7 @@ func TestClient_GetModifiedFilesOldNil(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io", 0)
 	client.BaseURL = testServer.URL
 
 	files, err := client.GetModifiedFiles(models.Repo{
@@ -139,3 +139,143 @@ func TestClient_GetModifiedFilesOldNil(t *testing.T) {
 	Ok(t, err)
 	Equals(t, []string{"parent/child/file1.txt"}, files)
 }
+
+// Should return the user's highest permission and the ones it includes and
+// follow pagination.
+func TestClient_GetTeamNamesForUser(t *testing.T) {
+	numRequests := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		switch r.RequestURI {
+		case "/rest/api/1.0/users?filter=lkysow&permission.1=REPO_ADMIN&permission.1.projectKey=proj&permission.1.repositorySlug=repo&start=0":
+			w.Write([]byte(`{"isLastPage": true, "values": []}`)) // nolint: errcheck
+		case "/rest/api/1.0/users?filter=lkysow&permission.1=REPO_WRITE&permission.1.projectKey=proj&permission.1.repositorySlug=repo&start=0":
+			// The filter also matches other users.
+			w.Write([]byte(`{"isLastPage": false, "nextPageStart": 1, "values": [{"name": "lkysow2"}]}`)) // nolint: errcheck
+		case "/rest/api/1.0/users?filter=lkysow&permission.1=REPO_WRITE&permission.1.projectKey=proj&permission.1.repositorySlug=repo&start=1":
+			w.Write([]byte(`{"isLastPage": true, "values": [{"name": "lkysow"}]}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(nil, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	repo := models.Repo{
+		FullName:          "proj/repo",
+		Name:              "repo",
+		SanitizedCloneURL: testServer.URL + "/scm/proj/repo.git",
+	}
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "lkysow"})
+	Ok(t, err)
+	Equals(t, []string{"REPO_WRITE", "REPO_READ"}, teams)
+	Equals(t, 3, numRequests)
+}
+
+// Users without any permission on the repo aren't in any teams.
+func TestClient_GetTeamNamesForUserNoPermissions(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"isLastPage": true, "values": []}`)) // nolint: errcheck
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(nil, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	teams, err := client.GetTeamNamesForUser(models.Repo{
+		FullName:          "proj/repo",
+		Name:              "repo",
+		SanitizedCloneURL: testServer.URL + "/scm/proj/repo.git",
+	}, models.User{Username: "lkysow"})
+	Ok(t, err)
+	Equals(t, 0, len(teams))
+}
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	cases := []struct {
+		resp string
+		exp  bool
+	}{
+		{`{"canMerge": true, "conflicted": false, "vetoes": []}`, true},
+		{`{"canMerge": false, "conflicted": false, "vetoes": [{"summaryMessage": "Not enough approved reviewers"}]}`, false},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%t", c.exp), func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/rest/api/1.0/projects/proj/repos/repo/pull-requests/1/merge":
+					w.Write([]byte(c.resp)) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+			mergeable, err := client.PullIsMergeable(models.Repo{
+				FullName:          "proj/repo",
+				Name:              "repo",
+				SanitizedCloneURL: testServer.URL + "/scm/proj/repo.git",
+			}, models.PullRequest{Num: 1})
+			Ok(t, err)
+			Equals(t, c.exp, mergeable)
+		})
+	}
+}
+
+func TestClient_MergePull(t *testing.T) {
+	pullJSON := `{
+  "id": 1,
+  "version": 3,
+  "state": "OPEN",
+  "reviewers": [],
+  "fromRef": {"displayId": "branch", "latestCommit": %s, "repository": {"slug": "repo", "project": {"name": "proj", "key": "proj"}}},
+  "toRef": {"displayId": "master", "latestCommit": "base", "repository": {"slug": "repo", "project": {"name": "proj", "key": "proj"}}}
+}`
+	cases := []struct {
+		description  string
+		latestCommit string
+		expErr       string
+	}{
+		{"merged", `"sha"`, ""},
+		{"head changed", `"newsha"`, "pull request's head has changed from sha to newsha"},
+		{"missing latest commit", `null`, "API response"},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			merged := false
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/rest/api/1.0/projects/proj/repos/repo/pull-requests/1":
+					fmt.Fprintf(w, pullJSON, c.latestCommit)
+				case "/rest/api/1.0/projects/proj/repos/repo/pull-requests/1/merge?version=3":
+					Equals(t, "POST", r.Method)
+					merged = true
+					w.Write([]byte(`{}`)) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+			err = client.MergePull(models.Repo{
+				FullName:          "proj/repo",
+				Name:              "repo",
+				SanitizedCloneURL: testServer.URL + "/scm/proj/repo.git",
+			}, models.PullRequest{Num: 1, HeadCommit: "sha"})
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				Equals(t, false, merged)
+				return
+			}
+			Ok(t, err)
+			Equals(t, true, merged)
+		})
+	}
+}
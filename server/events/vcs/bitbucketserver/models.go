@@ -22,15 +22,25 @@ type CommonEventData struct {
 }
 
 type PullRequest struct {
-	ID        *int    `json:"id,omitempty" validate:"required"`
-	FromRef   *Ref    `json:"fromRef,omitempty" validate:"required"`
-	ToRef     *Ref    `json:"toRef,omitempty" validate:"required"`
-	State     *string `json:"state,omitempty" validate:"required"`
+	ID      *int    `json:"id,omitempty" validate:"required"`
+	FromRef *Ref    `json:"fromRef,omitempty" validate:"required"`
+	ToRef   *Ref    `json:"toRef,omitempty" validate:"required"`
+	State   *string `json:"state,omitempty" validate:"required"`
+	// Version must be sent when merging so we don't merge a pull request
+	// that has been updated since we fetched it.
+	Version   *int `json:"version,omitempty"`
 	Reviewers []struct {
-		Approved *bool `json:"approved,omitempty" validate:"required"`
+		Approved *bool  `json:"approved,omitempty" validate:"required"`
+		User     *Actor `json:"user,omitempty"`
 	} `json:"reviewers,omitempty" validate:"required"`
 }
 
+// MergeStatus is whether a pull request can be merged, including whether
+// the repo's merge checks pass.
+type MergeStatus struct {
+	CanMerge *bool `json:"canMerge,omitempty" validate:"required"`
+}
+
 type Ref struct {
 	Repository   *Repository `json:"repository,omitempty" validate:"required"`
 	DisplayID    *string     `json:"displayId,omitempty" validate:"required"`
@@ -53,6 +63,9 @@ type Actor struct {
 
 type Comment struct {
 	Text *string `json:"text,omitempty" validate:"required"`
+	ID   *int    `json:"id,omitempty"`
+	// Version must be sent when updating the comment.
+	Version *int `json:"version,omitempty"`
 }
 
 type Changes struct {
@@ -64,3 +77,11 @@ type Changes struct {
 	NextPageStart *string `json:"nextPageStart,omitempty"`
 	IsLastPage    *bool   `json:"isLastPage,omitempty" validate:"required"`
 }
+
+type Users struct {
+	Values []struct {
+		Name *string `json:"name,omitempty" validate:"required"`
+	} `json:"values,omitempty" validate:"required"`
+	NextPageStart *int  `json:"nextPageStart,omitempty"`
+	IsLastPage    *bool `json:"isLastPage,omitempty" validate:"required"`
+}
@@ -0,0 +1,78 @@
+// Package cache provides an in-memory cache for VCS API responses.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepSize is the number of entries above which Set removes expired entries
+// so the cache doesn't grow forever with keys that are never read again.
+const sweepSize = 1000
+
+// TTL is a thread-safe in-memory cache whose entries expire after a fixed
+// duration.
+type TTL struct {
+	ttl     time.Duration
+	entries map[string]entry
+	mutex   sync.Mutex
+	// now is used to get the current time. It's a field so it can be
+	// overridden in tests.
+	now func() time.Time
+}
+
+type entry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewTTL returns a cache whose entries expire after ttl. If ttl <= 0 then
+// nothing is cached.
+func NewTTL(ttl time.Duration) *TTL {
+	return &TTL{
+		ttl:     ttl,
+		entries: make(map[string]entry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value cached at key and true, or nil and false if there's
+// no value or it has expired.
+func (c *TTL) Get(key string) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set caches value at key.
+func (c *TTL) Set(key string, value interface{}) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.now()
+	if len(c.entries) >= sweepSize {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = entry{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Delete removes the value cached at key.
+func (c *TTL) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestTTL_Expires(t *testing.T) {
+	now := time.Now()
+	c := NewTTL(time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("key", "value")
+	v, ok := c.Get("key")
+	Assert(t, ok, "expected value to be cached")
+	Equals(t, "value", v)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("key")
+	Assert(t, !ok, "expected value to have expired")
+}
+
+func TestTTL_Delete(t *testing.T) {
+	c := NewTTL(time.Minute)
+	c.Set("key", "value")
+	c.Delete("key")
+	_, ok := c.Get("key")
+	Assert(t, !ok, "expected value to be deleted")
+}
+
+func TestTTL_Disabled(t *testing.T) {
+	c := NewTTL(0)
+	c.Set("key", "value")
+	_, ok := c.Get("key")
+	Assert(t, !ok, "expected nothing to be cached")
+}
+
+func TestTTL_SweepsExpired(t *testing.T) {
+	now := time.Now()
+	c := NewTTL(time.Minute)
+	c.now = func() time.Time { return now }
+	for i := 0; i < sweepSize; i++ {
+		c.Set(fmt.Sprintf("key%d", i), i)
+	}
+	now = now.Add(time.Minute)
+	c.Set("key", "value")
+	Equals(t, 1, len(c.entries))
+}
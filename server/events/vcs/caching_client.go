@@ -0,0 +1,180 @@
+package vcs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/cache"
+	"github.com/google/go-github/github"
+)
+
+// ResponseCache caches VCS API responses that are requested often but rarely
+// change. It's shared by the CachingClients of every VCS host so that pull
+// request webhooks can invalidate it no matter which host they come from.
+type ResponseCache struct {
+	teamsTTL time.Duration
+
+	teams *cache.TTL
+	files *cache.TTL
+	pulls *cache.TTL
+}
+
+// NewResponseCache returns a cache that caches the teams a user is in for
+// teamsTTL, the files modified by a pull request for filesTTL and pull
+// requests for pullsTTL. A TTL of 0 disables that cache.
+func NewResponseCache(teamsTTL time.Duration, filesTTL time.Duration, pullsTTL time.Duration) *ResponseCache {
+	return &ResponseCache{
+		teamsTTL: teamsTTL,
+		teams:    cache.NewTTL(teamsTTL),
+		files:    cache.NewTTL(filesTTL),
+		pulls:    cache.NewTTL(pullsTTL),
+	}
+}
+
+// TeamsTTL returns how long the teams a user is in are cached for. Clients
+// that cache data used to look up teams should cache it for as long.
+func (r *ResponseCache) TeamsTTL() time.Duration {
+	return r.teamsTTL
+}
+
+// InvalidatePull removes everything cached for the pull request. It should be
+// called when we receive a webhook saying the pull request has changed.
+func (r *ResponseCache) InvalidatePull(repo models.Repo, pullNum int) {
+	key := r.pullKey(repo, pullNum)
+	r.files.Delete(key)
+	r.pulls.Delete(key)
+}
+
+func (r *ResponseCache) pullKey(repo models.Repo, pullNum int) string {
+	return fmt.Sprintf("%s/%s#%d", repo.VCSHost.Hostname, repo.FullName, pullNum)
+}
+
+// cachedFiles are the files modified by a pull request at headCommit.
+type cachedFiles struct {
+	headCommit string
+	files      []string
+}
+
+// CachingClient is a Client that caches the responses of Client in Cache.
+// Comments, statuses, approvals and mergeability aren't cached.
+type CachingClient struct {
+	Client Client
+	Cache  *ResponseCache
+}
+
+// GetModifiedFiles returns the files modified by pull. They're cached by the
+// pull request's head commit so pushing new commits doesn't return stale
+// files even if we never got the webhook.
+func (c *CachingClient) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	key := c.Cache.pullKey(repo, pull.Num)
+	if v, ok := c.Cache.files.Get(key); ok && pull.HeadCommit != "" {
+		if cached := v.(cachedFiles); cached.headCommit == pull.HeadCommit {
+			return copyStrings(cached.files), nil
+		}
+	}
+	files, err := c.Client.GetModifiedFiles(repo, pull)
+	if err != nil {
+		return nil, err
+	}
+	if pull.HeadCommit != "" {
+		c.Cache.files.Set(key, cachedFiles{headCommit: pull.HeadCommit, files: copyStrings(files)})
+	}
+	return files, nil
+}
+
+func (c *CachingClient) CreateComment(repo models.Repo, pullNum int, comment string) error {
+	return c.Client.CreateComment(repo, pullNum, comment)
+}
+
+func (c *CachingClient) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	return c.Client.CreateCommentWithIDs(repo, pullNum, comment)
+}
+
+func (c *CachingClient) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	return c.Client.UpdateComment(repo, pullNum, id, comment)
+}
+
+func (c *CachingClient) HideComment(repo models.Repo, pullNum int, id string) error {
+	return c.Client.HideComment(repo, pullNum, id)
+}
+
+// PullIsApproved isn't cached since approvals can change without us getting
+// a pull request webhook.
+func (c *CachingClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return c.Client.PullIsApproved(repo, pull)
+}
+
+// GetApprovers isn't cached for the same reason as PullIsApproved.
+func (c *CachingClient) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	return c.Client.GetApprovers(repo, pull)
+}
+
+// PullIsMergeable isn't cached since it depends on statuses and reviews.
+func (c *CachingClient) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return c.Client.PullIsMergeable(repo, pull)
+}
+
+func (c *CachingClient) MergePull(repo models.Repo, pull models.PullRequest) error {
+	return c.Client.MergePull(repo, pull)
+}
+
+func (c *CachingClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error {
+	return c.Client.UpdateStatus(repo, pull, state, src, description)
+}
+
+// GetTeamNamesForUser returns the teams user is in. Teams are cached per
+// repo since on Bitbucket Server they're the user's permissions on the repo.
+func (c *CachingClient) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	key := fmt.Sprintf("%s/%s/%s", repo.VCSHost.Hostname, repo.FullName, user.Username)
+	if v, ok := c.Cache.teams.Get(key); ok {
+		return copyStrings(v.([]string)), nil
+	}
+	teams, err := c.Client.GetTeamNamesForUser(repo, user)
+	if err != nil {
+		return nil, err
+	}
+	c.Cache.teams.Set(key, copyStrings(teams))
+	return teams, nil
+}
+
+// CachingGithubPullGetter caches the pull requests returned by Client. We
+// fetch the pull request for every comment to find its head commit so the
+// cache is invalidated by the pull request's webhooks when it's updated. In
+// case we missed a webhook, we also check the cached head commit is still the
+// head before using it.
+type CachingGithubPullGetter struct {
+	Client *GithubClient
+	Cache  *ResponseCache
+}
+
+// GetPullRequest returns the pull request.
+func (c *CachingGithubPullGetter) GetPullRequest(repo models.Repo, num int) (*github.PullRequest, error) {
+	key := c.Cache.pullKey(repo, num)
+	if v, ok := c.Cache.pulls.Get(key); ok {
+		pull := *v.(*github.PullRequest)
+		cachedHead := pull.Head.GetSHA()
+		headCommit, err := c.Client.GetPullHeadCommit(repo, num, cachedHead)
+		if err != nil {
+			return nil, err
+		}
+		if headCommit == cachedHead {
+			return &pull, nil
+		}
+		c.Cache.pulls.Delete(key)
+	}
+	pull, err := c.Client.GetPullRequest(repo, num)
+	if err != nil {
+		return nil, err
+	}
+	cached := *pull
+	c.Cache.pulls.Set(key, &cached)
+	return pull, nil
+}
+
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
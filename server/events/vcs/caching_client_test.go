@@ -0,0 +1,173 @@
+package vcs_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/cloudposse/atlantis/server/events/vcs/mocks"
+	"github.com/cloudposse/atlantis/server/events/vcs/mocks/matchers"
+	. "github.com/cloudposse/atlantis/testing"
+	. "github.com/petergtz/pegomock"
+)
+
+var cachingRepo = models.Repo{
+	FullName: "owner/repo",
+	Owner:    "owner",
+	Name:     "repo",
+	VCSHost: models.VCSHost{
+		Type:     models.Github,
+		Hostname: "github.com",
+	},
+}
+
+func TestCachingClient_GetModifiedFiles(t *testing.T) {
+	RegisterMockTestingT(t)
+	underlying := mocks.NewMockClient()
+	cache := vcs.NewResponseCache(time.Hour, time.Hour, time.Hour)
+	client := &vcs.CachingClient{Client: underlying, Cache: cache}
+	pull := models.PullRequest{Num: 1, HeadCommit: "sha1"}
+	When(underlying.GetModifiedFiles(cachingRepo, pull)).ThenReturn([]string{"main.tf"}, nil)
+
+	for i := 0; i < 2; i++ {
+		files, err := client.GetModifiedFiles(cachingRepo, pull)
+		Ok(t, err)
+		Equals(t, []string{"main.tf"}, files)
+	}
+	underlying.VerifyWasCalledOnce().GetModifiedFiles(cachingRepo, pull)
+
+	// A new head commit should be fetched.
+	newPull := models.PullRequest{Num: 1, HeadCommit: "sha2"}
+	When(underlying.GetModifiedFiles(cachingRepo, newPull)).ThenReturn([]string{"other.tf"}, nil)
+	files, err := client.GetModifiedFiles(cachingRepo, newPull)
+	Ok(t, err)
+	Equals(t, []string{"other.tf"}, files)
+
+	// As should files for a pull request that was invalidated.
+	cache.InvalidatePull(cachingRepo, 1)
+	_, err = client.GetModifiedFiles(cachingRepo, newPull)
+	Ok(t, err)
+	underlying.VerifyWasCalled(Times(2)).GetModifiedFiles(cachingRepo, newPull)
+}
+
+func TestCachingClient_GetModifiedFilesErr(t *testing.T) {
+	RegisterMockTestingT(t)
+	underlying := mocks.NewMockClient()
+	client := &vcs.CachingClient{Client: underlying, Cache: vcs.NewResponseCache(time.Hour, time.Hour, time.Hour)}
+	pull := models.PullRequest{Num: 1, HeadCommit: "sha1"}
+	When(underlying.GetModifiedFiles(cachingRepo, pull)).ThenReturn(nil, errors.New("err"))
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetModifiedFiles(cachingRepo, pull)
+		ErrEquals(t, "err", err)
+	}
+	// Errors shouldn't be cached.
+	underlying.VerifyWasCalled(Times(2)).GetModifiedFiles(cachingRepo, pull)
+}
+
+func TestCachingClient_GetTeamNamesForUser(t *testing.T) {
+	RegisterMockTestingT(t)
+	underlying := mocks.NewMockClient()
+	client := &vcs.CachingClient{Client: underlying, Cache: vcs.NewResponseCache(time.Hour, time.Hour, time.Hour)}
+	user := models.User{Username: "user"}
+	When(underlying.GetTeamNamesForUser(cachingRepo, user)).ThenReturn([]string{"team"}, nil)
+
+	for i := 0; i < 2; i++ {
+		teams, err := client.GetTeamNamesForUser(cachingRepo, user)
+		Ok(t, err)
+		Equals(t, []string{"team"}, teams)
+	}
+	underlying.VerifyWasCalledOnce().GetTeamNamesForUser(cachingRepo, user)
+
+	// Other users shouldn't get the cached teams.
+	_, err := client.GetTeamNamesForUser(cachingRepo, models.User{Username: "other"})
+	Ok(t, err)
+	underlying.VerifyWasCalledOnce().GetTeamNamesForUser(cachingRepo, models.User{Username: "other"})
+}
+
+func TestCachingClient_Disabled(t *testing.T) {
+	RegisterMockTestingT(t)
+	underlying := mocks.NewMockClient()
+	client := &vcs.CachingClient{Client: underlying, Cache: vcs.NewResponseCache(0, 0, 0)}
+	user := models.User{Username: "user"}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetTeamNamesForUser(cachingRepo, user)
+		Ok(t, err)
+	}
+	underlying.VerifyWasCalled(Times(2)).GetTeamNamesForUser(cachingRepo, user)
+}
+
+// Approvals, comments and statuses should never be cached.
+func TestCachingClient_PassesThrough(t *testing.T) {
+	RegisterMockTestingT(t)
+	underlying := mocks.NewMockClient()
+	client := &vcs.CachingClient{Client: underlying, Cache: vcs.NewResponseCache(time.Hour, time.Hour, time.Hour)}
+	pull := models.PullRequest{Num: 1, HeadCommit: "sha1"}
+	for i := 0; i < 2; i++ {
+		_, err := client.PullIsApproved(cachingRepo, pull)
+		Ok(t, err)
+		Ok(t, client.CreateComment(cachingRepo, 1, "comment"))
+		Ok(t, client.UpdateStatus(cachingRepo, pull, models.SuccessCommitStatus, "src", "description"))
+	}
+	underlying.VerifyWasCalled(Times(2)).PullIsApproved(cachingRepo, pull)
+	underlying.VerifyWasCalled(Times(2)).CreateComment(cachingRepo, 1, "comment")
+	underlying.VerifyWasCalled(Times(2)).UpdateStatus(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnyVcsCommitStatus(), AnyString(), AnyString())
+}
+
+func TestCachingGithubPullGetter_GetPullRequest(t *testing.T) {
+	calls := 0
+	head := "sha1"
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v3/repos/owner/repo/pulls/1":
+			calls++
+			fmt.Fprintf(w, `{"number": 1, "head": {"sha": "%s"}}`, head)
+		case "/api/v3/repos/owner/repo/commits/refs/pull/1/head":
+			if r.Header.Get("If-None-Match") == `"`+head+`"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, head)
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	githubClient, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	cache := vcs.NewResponseCache(time.Hour, time.Hour, time.Hour)
+	getter := &vcs.CachingGithubPullGetter{Client: githubClient, Cache: cache}
+	for i := 0; i < 2; i++ {
+		pull, err := getter.GetPullRequest(cachingRepo, 1)
+		Ok(t, err)
+		Equals(t, "sha1", *pull.Head.SHA)
+	}
+	Equals(t, 1, calls)
+
+	// Once the pull request's webhook invalidates it, it should be fetched
+	// again.
+	cache.InvalidatePull(cachingRepo, 1)
+	pull, err := getter.GetPullRequest(cachingRepo, 1)
+	Ok(t, err)
+	Equals(t, "sha1", *pull.Head.SHA)
+	Equals(t, 2, calls)
+
+	// If we missed the webhook, the new head commit should still be noticed.
+	head = "sha2"
+	pull, err = getter.GetPullRequest(cachingRepo, 1)
+	Ok(t, err)
+	Equals(t, "sha2", *pull.Head.SHA)
+	Equals(t, 3, calls)
+}
@@ -23,7 +23,30 @@ import (
 type Client interface {
 	GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error)
 	CreateComment(repo models.Repo, pullNum int, comment string) error
+	// CreateCommentWithIDs creates comment like CreateComment and returns the
+	// IDs of the comments it was split into, in order.
+	CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error)
+	// UpdateComment replaces the comment with id. comment must fit in a
+	// single comment.
+	UpdateComment(repo models.Repo, pullNum int, id string, comment string) error
+	// HideComment hides or collapses the comment with id because it's
+	// outdated. It returns common.ErrHideCommentNotSupported if the VCS host
+	// can't.
+	HideComment(repo models.Repo, pullNum int, id string) error
 	PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error)
-	UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) error
+	// GetApprovers returns the usernames of the users whose approval of pull
+	// is still in effect, ex. it hasn't been dismissed. Each user is returned
+	// once.
+	GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error)
+	// PullIsMergeable returns true if the VCS host would allow pull to be
+	// merged, ex. it has no conflicts and passes branch protection.
+	PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error)
+	// MergePull merges pull. Implementations should refuse to merge if pull's
+	// head is no longer pull.HeadCommit, when the VCS host supports it.
+	MergePull(repo models.Repo, pull models.PullRequest) error
+	// UpdateStatus sets the status called src on the head commit of pull. src
+	// is "Atlantis" for the aggregated status or the project's status name,
+	// ex. "atlantis/plan: envs/prod (default)".
+	UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error
 	GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error)
 }
@@ -0,0 +1,143 @@
+// Package common holds code shared between the VCS clients.
+package common
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrHideCommentNotSupported is returned when hiding a comment on a VCS host
+// that doesn't support hiding or collapsing comments.
+var ErrHideCommentNotSupported = errors.New("hiding comments isn't supported by this VCS host")
+
+// SepEnd is appended to every comment but the last when a comment is split.
+const SepEnd = "\n\n**Warning**: Output length greater than max comment size. Continued in next comment."
+
+// SepStart is prepended to every comment but the first when a comment is
+// split.
+const SepStart = "Continued from previous comment.\n\n"
+
+// detailsOpen is how the markdown renderer opens a collapsed section. We
+// reopen sections with it when a split lands inside one.
+const detailsOpen = "<details><summary>Show Output</summary>\n\n"
+
+// ErrCommentTooSmall is returned by SplitComment when the max comment size is
+// too small to fit the separators and any of the comment.
+var ErrCommentTooSmall = errors.New("max comment size is too small to split comment")
+
+// SplitComment splits comment into comments of at most maxSize characters.
+// sepEnd is appended to every comment but the last and sepStart is prepended
+// to every comment but the first. If a split lands inside a code block or a
+// collapsed <details> section, the section is closed at the end of the
+// comment and reopened at the start of the next so each comment renders
+// correctly. If maxSize is too small to fit the separators we return
+// ErrCommentTooSmall rather than posting nothing.
+func SplitComment(comment string, maxSize int, sepEnd string, sepStart string) ([]string, error) {
+	// If we're under the limit then no need to split.
+	if len(comment) <= maxSize {
+		return []string{comment}, nil
+	}
+
+	var comments []string
+	prefix := ""
+	offset := 0
+	for {
+		rest := comment[offset:]
+		if len(prefix)+len(rest) <= maxSize {
+			return append(comments, prefix+rest), nil
+		}
+
+		budget := maxSize - len(prefix) - len(sepEnd)
+		portion, closing, reopening, ok := fitPortion(comment, offset, budget)
+		if !ok {
+			return nil, ErrCommentTooSmall
+		}
+
+		comments = append(comments, prefix+portion+closing+sepEnd)
+		offset += len(portion)
+		prefix = sepStart + reopening
+	}
+}
+
+// TruncateComment truncates comment so that it and suffix fit in maxSize
+// characters, closing any sections that were left open. If comment already
+// fits it's returned unchanged.
+func TruncateComment(comment string, maxSize int, suffix string) string {
+	if len(comment) <= maxSize {
+		return comment
+	}
+	portion, closing, _, ok := fitPortion(comment, 0, maxSize-len(suffix))
+	if !ok {
+		return suffix
+	}
+	return portion + closing + suffix
+}
+
+// fitPortion returns the longest portion of comment starting at offset that
+// fits in budget characters along with the markup needed to close the
+// sections left open at its end, as well as that markup and the markup needed
+// to reopen the sections. ok is false if nothing fits.
+func fitPortion(comment string, offset int, budget int) (portion string, closing string, reopening string, ok bool) {
+	rest := comment[offset:]
+	avail := budget
+	for avail > 0 {
+		portion = rest[:cutIndex(rest, avail)]
+		if portion == "" {
+			return "", "", "", false
+		}
+		closing, reopening = openMarkup(comment[:offset+len(portion)])
+		overflow := len(portion) + len(closing) - budget
+		if overflow <= 0 {
+			return portion, closing, reopening, true
+		}
+		avail = len(portion) - overflow
+	}
+	return "", "", "", false
+}
+
+// cutIndex returns the index at or before max to cut s at. We prefer to cut
+// after a newline so lines aren't split across comments, as long as that
+// doesn't throw away more than half the space, and never cut inside a
+// multi-byte character.
+func cutIndex(s string, max int) int {
+	if max >= len(s) {
+		return len(s)
+	}
+	if i := strings.LastIndex(s[:max], "\n"); i >= max/2 {
+		return i + 1
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return max
+}
+
+// openMarkup returns the markup needed to close the code block and <details>
+// sections left open at the end of s, and the markup needed to reopen them.
+func openMarkup(s string) (closing string, reopening string) {
+	openDetails := strings.Count(s, "<details>") - strings.Count(s, "</details>")
+	fence := ""
+	for _, line := range strings.Split(s, "\n") {
+		if !strings.HasPrefix(line, "```") {
+			continue
+		}
+		if fence == "" {
+			fence = line
+		} else {
+			fence = ""
+		}
+	}
+
+	if fence != "" {
+		closing = "\n```\n"
+	}
+	for i := 0; i < openDetails; i++ {
+		closing += "</details>\n"
+		reopening += detailsOpen
+	}
+	if fence != "" {
+		reopening += fence + "\n"
+	}
+	return closing, reopening
+}
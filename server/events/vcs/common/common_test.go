@@ -0,0 +1,119 @@
+package common_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestSplitComment(t *testing.T) {
+	cases := []struct {
+		comment string
+		max     int
+		exp     []string
+	}{
+		// Test when comment is <= max length.
+		{
+			"",
+			5,
+			[]string{""},
+		},
+		{
+			"1",
+			5,
+			[]string{"1"},
+		},
+		{
+			"12345",
+			5,
+			[]string{"12345"},
+		},
+		// Now test when we need to join.
+		{
+			"123456",
+			5,
+			[]string{"1join", "23456"},
+		},
+		{
+			"123456",
+			10,
+			[]string{"123456"},
+		},
+		{
+			"12345678901",
+			10,
+			[]string{"123456join", "78901"},
+		},
+		// Should prefer splitting at newlines.
+		{
+			"1234\n6789\n1234",
+			10,
+			[]string{"1234\njoin", "6789\n1234"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			split, err := common.SplitComment(c.comment, c.max, "join", "")
+			Ok(t, err)
+			Equals(t, c.exp, split)
+		})
+	}
+}
+
+// Test the edge case of max < len("join").
+func TestSplitComment_TooSmall(t *testing.T) {
+	for _, c := range []struct {
+		comment string
+		max     int
+	}{
+		{"abc", 2},
+		{"abcde", 4},
+	} {
+		t.Run(c.comment, func(t *testing.T) {
+			_, err := common.SplitComment(c.comment, c.max, "join", "")
+			Equals(t, common.ErrCommentTooSmall, err)
+		})
+	}
+}
+
+// Code blocks and collapsed sections should be closed at the end of each
+// comment and reopened at the start of the next.
+func TestSplitComment_ReopensSections(t *testing.T) {
+	comment := "Ran Plan\n<details><summary>Show Output</summary>\n\n```diff\n" +
+		strings.Repeat("+ resource\n", 10) +
+		"```\n</details>\n"
+	split, err := common.SplitComment(comment, 100, "\nEND", "START\n")
+	Ok(t, err)
+	Assert(t, len(split) > 1, "expected comment to be split")
+	for i, s := range split {
+		Assert(t, len(s) <= 100, "comment %d was %d characters", i, len(s))
+		Equals(t, strings.Count(s, "<details>"), strings.Count(s, "</details>"))
+		Equals(t, 0, strings.Count(s, "```")%2)
+		if i > 0 {
+			Assert(t, strings.HasPrefix(s, "START\n<details><summary>Show Output</summary>\n\n```diff\n"), "comment %d didn't reopen the sections: %q", i, s)
+		}
+		if i < len(split)-1 {
+			Assert(t, strings.HasSuffix(s, "```\n</details>\n\nEND"), "comment %d didn't close the sections: %q", i, s)
+		}
+	}
+	Equals(t, 10, strings.Count(strings.Join(split, ""), "+ resource\n"))
+}
+
+// Shouldn't split multi-byte characters.
+func TestSplitComment_MultiByte(t *testing.T) {
+	split, err := common.SplitComment("éééé", 6, "j", "")
+	Ok(t, err)
+	Equals(t, []string{"ééj", "éé"}, split)
+}
+
+func TestTruncateComment(t *testing.T) {
+	Equals(t, "short", common.TruncateComment("short", 10, "...more"))
+	Equals(t, "123...more", common.TruncateComment("1234567890abc", 10, "...more"))
+
+	comment := "Ran Plan\n```diff\n" + strings.Repeat("+ resource\n", 10) + "```\n"
+	truncated := common.TruncateComment(comment, 50, "\nmore")
+	Assert(t, len(truncated) <= 50, "truncated comment was %d characters", len(truncated))
+	Equals(t, "Ran Plan\n```diff\n+ resource\n+ resource\n\n```\n\nmore", truncated)
+}
@@ -0,0 +1,407 @@
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
+	"github.com/pkg/errors"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// MaxCommentLength is the maximum number of characters we put in a single
+// comment. Gitea doesn't enforce a limit but very long comments are slow to
+// render so we split them like GitHub does.
+const MaxCommentLength = 65536
+
+// pageSize is the number of items we request per page from paginated
+// endpoints. Gitea caps this at 50 by default.
+const pageSize = 50
+
+type Client struct {
+	HttpClient  *http.Client
+	Username    string
+	Token       string
+	BaseURL     string
+	AtlantisURL string
+}
+
+// NewClient builds a Gitea client. Returns an error if the baseURL is
+// malformed. httpClient is the client to use to make the requests, username
+// is the user Atlantis runs as and token is that user's access token which is
+// sent in the Authorization header. baseURL is the URL of the Gitea
+// installation, ex. https://gitea.corp.com. Don't include the '/api/v1' path.
+// atlantisURL is the URL for Atlantis that will be linked to from the commit
+// statuses.
+func NewClient(httpClient *http.Client, username string, token string, baseURL string, atlantisURL string) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", baseURL)
+	}
+	if parsedURL.Scheme == "" {
+		return nil, fmt.Errorf("must have 'http://' or 'https://' in base url %q", baseURL)
+	}
+	// Gitea can be served from a sub-path so we keep the path but remove the
+	// trailing '/'.
+	return &Client{
+		HttpClient:  httpClient,
+		Username:    username,
+		Token:       token,
+		BaseURL:     strings.TrimSuffix(parsedURL.String(), "/"),
+		AtlantisURL: atlantisURL,
+	}, nil
+}
+
+// GetModifiedFiles returns the names of files that were modified in the pull request.
+// The names include the path to the file from the repo root, ex. parent/child/file.txt.
+func (c *Client) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	var files []string
+
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for page := 1; page <= maxLoops; page++ {
+		path := fmt.Sprintf("%s/repos/%s/pulls/%d/files?page=%d&limit=%d", c.apiURL(), repo.FullName, pull.Num, page, pageSize)
+		resp, err := c.makeRequest("GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var changed []ChangedFile
+		if err := json.Unmarshal(resp, &changed); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, f := range changed {
+			files = append(files, f.Filename)
+			// If the file was renamed, we'll want to run plan in the directory
+			// it was moved from as well.
+			if f.PreviousFilename != "" {
+				files = append(files, f.PreviousFilename)
+			}
+		}
+		if len(changed) < pageSize {
+			break
+		}
+	}
+
+	// Now ensure all files are unique.
+	hash := make(map[string]bool)
+	var unique []string
+	for _, f := range files {
+		if !hash[f] {
+			unique = append(unique, f)
+			hash[f] = true
+		}
+	}
+	return unique, nil
+}
+
+// CreateComment creates a comment on the pull request.
+// If comment length is greater than the max comment length we split into
+// multiple comments.
+func (c *Client) CreateComment(repo models.Repo, pullNum int, comment string) error {
+	_, err := c.createComments(repo, pullNum, comment)
+	return err
+}
+
+// CreateCommentWithIDs creates a comment like CreateComment and returns the
+// IDs of the comments it was split into.
+func (c *Client) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	responses, err := c.createComments(repo, pullNum, comment)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, resp := range responses {
+		var created struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(resp, &created); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		ids = append(ids, strconv.FormatInt(created.ID, 10))
+	}
+	return ids, nil
+}
+
+// createComments splits comment if it's too long, creates each part and
+// returns the responses.
+func (c *Client) createComments(repo models.Repo, pullNum int, comment string) ([][]byte, error) {
+	// Pull requests are issues in Gitea so comments are made via the issues
+	// API.
+	path := fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.apiURL(), repo.FullName, pullNum)
+	var responses [][]byte
+	comments, err := common.SplitComment(comment, MaxCommentLength, common.SepEnd, common.SepStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		bodyBytes, err := json.Marshal(map[string]string{"body": comment})
+		if err != nil {
+			return nil, errors.Wrap(err, "json encoding")
+		}
+		resp, err := c.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// UpdateComment replaces the body of the comment with id.
+func (c *Client) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	path := fmt.Sprintf("%s/repos/%s/issues/comments/%s", c.apiURL(), repo.FullName, url.PathEscape(id))
+	bodyBytes, err := json.Marshal(map[string]string{"body": comment})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = c.makeRequest("PATCH", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// HideComment isn't supported since Gitea can't hide comments.
+func (c *Client) HideComment(repo models.Repo, pullNum int, id string) error {
+	return common.ErrHideCommentNotSupported
+}
+
+// PullIsApproved returns true if the pull request was approved by at least
+// one reviewer and that approval hasn't been dismissed.
+func (c *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
+	path := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews", c.apiURL(), repo.FullName, pull.Num)
+	resp, err := c.makeRequest("GET", path, nil)
+	if err != nil {
+		return false, err
+	}
+	var reviews []Review
+	if err := json.Unmarshal(resp, &reviews); err != nil {
+		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	for _, review := range reviews {
+		if review.State == ApprovedReviewState && !review.Dismissed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetApprovers returns the users whose latest review approved the pull
+// request and hasn't been dismissed.
+func (c *Client) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	path := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews", c.apiURL(), repo.FullName, pull.Num)
+	resp, err := c.makeRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var reviews []Review
+	if err := json.Unmarshal(resp, &reviews); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	// Reviews are listed oldest first so later reviews override earlier ones.
+	var users []string
+	approved := make(map[string]bool)
+	for _, review := range reviews {
+		if review.User == nil || review.User.Login == nil {
+			continue
+		}
+		login := *review.User.Login
+		switch {
+		case review.State == ApprovedReviewState:
+			if _, ok := approved[login]; !ok {
+				users = append(users, login)
+			}
+			approved[login] = !review.Dismissed
+		case review.State == RequestChangesReviewState:
+			if _, ok := approved[login]; ok {
+				approved[login] = false
+			}
+		}
+	}
+	var approvers []string
+	for _, u := range users {
+		if approved[u] {
+			approvers = append(approvers, u)
+		}
+	}
+	return approvers, nil
+}
+
+// PullIsMergeable returns true if Gitea says the pull request can be merged.
+func (c *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	giteaPull, err := c.GetPullRequest(repo, pull.Num)
+	if err != nil {
+		return false, err
+	}
+	return giteaPull.Mergeable, nil
+}
+
+// MergePull merges the pull request with a merge commit. Gitea versions that
+// support it will refuse to merge if the pull request's head has moved past
+// the commit we applied.
+func (c *Client) MergePull(repo models.Repo, pull models.PullRequest) error {
+	path := fmt.Sprintf("%s/repos/%s/pulls/%d/merge", c.apiURL(), repo.FullName, pull.Num)
+	bodyBytes, err := json.Marshal(map[string]string{
+		"Do":             "merge",
+		"head_commit_id": pull.HeadCommit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = c.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// UpdateStatus updates the status of a commit.
+func (c *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string) error {
+	giteaState := "failure"
+	switch status {
+	case models.PendingCommitStatus:
+		giteaState = "pending"
+	case models.SuccessCommitStatus:
+		giteaState = "success"
+	case models.FailedCommitStatus:
+		giteaState = "failure"
+	}
+
+	bodyBytes, err := json.Marshal(map[string]string{
+		"state":       giteaState,
+		"target_url":  c.AtlantisURL,
+		"description": description,
+		"context":     src,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/repos/%s/statuses/%s", c.apiURL(), repo.FullName, pull.HeadCommit)
+	_, err = c.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// GetTeamNamesForUser returns the names of the teams that the user belongs to
+// in the organization the repository belongs to. If the repo is owned by a
+// user rather than an organization, Gitea will return a 404 and we return an
+// error.
+func (c *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	var teams []Team
+
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for page := 1; page <= maxLoops; page++ {
+		path := fmt.Sprintf("%s/orgs/%s/teams?page=%d&limit=%d", c.apiURL(), repo.Owner, page, pageSize)
+		resp, err := c.makeRequest("GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var pageTeams []Team
+		if err := json.Unmarshal(resp, &pageTeams); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		teams = append(teams, pageTeams...)
+		if len(pageTeams) < pageSize {
+			break
+		}
+	}
+
+	var teamNames []string
+	for _, team := range teams {
+		memberPath := fmt.Sprintf("%s/teams/%d/members/%s", c.apiURL(), team.ID, user.Username)
+		isMember, err := c.exists(memberPath)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			teamNames = append(teamNames, team.Name)
+		}
+	}
+	return teamNames, nil
+}
+
+// GetPullRequest returns the pull request.
+func (c *Client) GetPullRequest(repo models.Repo, num int) (*PullRequest, error) {
+	path := fmt.Sprintf("%s/repos/%s/pulls/%d", c.apiURL(), repo.FullName, num)
+	resp, err := c.makeRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var pull PullRequest
+	if err := json.Unmarshal(resp, &pull); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if err := validator.New().Struct(pull); err != nil {
+		return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+	}
+	return &pull, nil
+}
+
+func (c *Client) apiURL() string {
+	return c.BaseURL + "/api/v1"
+}
+
+// prepRequest adds the token auth header.
+func (c *Client) prepRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "token "+c.Token)
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+// exists makes a GET request to path and returns true if the response was a
+// 2xx and false if it was a 404.
+func (c *Client) exists(path string) (bool, error) {
+	req, err := c.prepRequest("GET", path, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "constructing request")
+	}
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	default:
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return false, fmt.Errorf("making request %q unexpected status code: %d, body: %s", fmt.Sprintf("GET %s", path), resp.StatusCode, string(respBody))
+	}
+}
+
+func (c *Client) makeRequest(method string, path string, reqBody io.Reader) ([]byte, error) {
+	req, err := c.prepRequest(method, path, reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing request")
+	}
+	if reqBody != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	requestStr := fmt.Sprintf("%s %s", method, path)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", requestStr, resp.StatusCode, string(respBody))
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading response from request %q", requestStr)
+	}
+	return respBody, nil
+}
@@ -0,0 +1,302 @@
+package gitea_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/gitea"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+var repo = models.Repo{
+	FullName: "owner/repo",
+	Owner:    "owner",
+	Name:     "repo",
+	VCSHost: models.VCSHost{
+		Type:     models.Gitea,
+		Hostname: "gitea.example.com",
+	},
+}
+
+func TestNewClient_BaseURLScheme(t *testing.T) {
+	_, err := gitea.NewClient(nil, "user", "token", "gitea.example.com", "runatlantis.io")
+	ErrEquals(t, `must have 'http://' or 'https://' in base url "gitea.example.com"`, err)
+}
+
+func TestNewClient_TrailingSlash(t *testing.T) {
+	client, err := gitea.NewClient(nil, "user", "token", "https://corp.com/gitea/", "runatlantis.io")
+	Ok(t, err)
+	Equals(t, "https://corp.com/gitea", client.BaseURL)
+}
+
+// Should follow pagination and include renamed files.
+func TestClient_GetModifiedFilesPagination(t *testing.T) {
+	// Build a full first page so the client requests the next one.
+	var firstPage []map[string]string
+	for i := 0; i < 50; i++ {
+		firstPage = append(firstPage, map[string]string{"filename": fmt.Sprintf("dir%d/main.tf", i)})
+	}
+	firstResp, err := json.Marshal(firstPage)
+	Ok(t, err)
+	secondResp := `[{"filename": "dir0/main.tf"}, {"filename": "new/main.tf", "previous_filename": "old/main.tf"}]`
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "token token", r.Header.Get("Authorization"))
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/pulls/1/files?page=1&limit=50":
+			w.Write(firstResp) // nolint: errcheck
+		case "/api/v1/repos/owner/repo/pulls/1/files?page=2&limit=50":
+			w.Write([]byte(secondResp)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+
+	files, err := client.GetModifiedFiles(repo, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, 52, len(files))
+	Equals(t, "new/main.tf", files[50])
+	Equals(t, "old/main.tf", files[51])
+}
+
+func TestClient_CreateComment(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/issues/1/comments":
+			Equals(t, "POST", r.Method)
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			Equals(t, `{"body":"comment"}`, string(body))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	Ok(t, client.CreateComment(repo, 1, "comment"))
+}
+
+func TestClient_PullIsApproved(t *testing.T) {
+	cases := []struct {
+		description string
+		testdata    string
+		exp         bool
+	}{
+		{
+			"approved",
+			"reviews-approved.json",
+			true,
+		},
+		{
+			"approval was dismissed",
+			"reviews-dismissed.json",
+			false,
+		},
+		{
+			"changes requested",
+			"reviews-changes-requested.json",
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			resp, err := ioutil.ReadFile(filepath.Join("testdata", c.testdata))
+			Ok(t, err)
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/api/v1/repos/owner/repo/pulls/1/reviews":
+					w.Write(resp) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+
+			approved, err := client.PullIsApproved(repo, models.PullRequest{Num: 1})
+			Ok(t, err)
+			Equals(t, c.exp, approved)
+		})
+	}
+}
+
+func TestClient_GetApprovers(t *testing.T) {
+	cases := []struct {
+		description string
+		testdata    string
+		exp         []string
+	}{
+		{
+			"approved",
+			"reviews-approved.json",
+			[]string{"approver"},
+		},
+		{
+			"approval was dismissed",
+			"reviews-dismissed.json",
+			nil,
+		},
+		{
+			"changes requested",
+			"reviews-changes-requested.json",
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			resp, err := ioutil.ReadFile(filepath.Join("testdata", c.testdata))
+			Ok(t, err)
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/api/v1/repos/owner/repo/pulls/1/reviews":
+					w.Write(resp) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+
+			approvers, err := client.GetApprovers(repo, models.PullRequest{Num: 1})
+			Ok(t, err)
+			Equals(t, c.exp, approvers)
+		})
+	}
+}
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	resp, err := ioutil.ReadFile(filepath.Join("testdata", "pull.json"))
+	Ok(t, err)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/pulls/1":
+			w.Write(resp) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	mergeable, err := client.PullIsMergeable(repo, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, true, mergeable)
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	cases := []struct {
+		status   models.CommitStatus
+		expState string
+	}{
+		{models.PendingCommitStatus, "pending"},
+		{models.SuccessCommitStatus, "success"},
+		{models.FailedCommitStatus, "failure"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.status.String(), func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/api/v1/repos/owner/repo/statuses/sha":
+					body, err := ioutil.ReadAll(r.Body)
+					Ok(t, err)
+					exp := fmt.Sprintf(`{"context":"atlantis/plan: . (default)","description":"description","state":"%s","target_url":"https://runatlantis.io"}`, c.expState)
+					Equals(t, exp, string(body))
+					w.WriteHeader(http.StatusCreated)
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "https://runatlantis.io")
+			Ok(t, err)
+			err = client.UpdateStatus(repo, models.PullRequest{Num: 1, HeadCommit: "sha"}, c.status, "atlantis/plan: . (default)", "description")
+			Ok(t, err)
+		})
+	}
+}
+
+func TestClient_GetTeamNamesForUser(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/orgs/owner/teams?page=1&limit=50":
+			// A full page means there may be more teams on the next page.
+			var teams []string
+			for i := 1; i <= 50; i++ {
+				teams = append(teams, fmt.Sprintf(`{"id": %d, "name": "team%d"}`, i+100, i))
+			}
+			w.Write([]byte("[" + strings.Join(teams, ",") + "]")) // nolint: errcheck
+		case "/api/v1/orgs/owner/teams?page=2&limit=50":
+			w.Write([]byte(`[{"id": 1, "name": "Owners"}, {"id": 2, "name": "devops"}]`)) // nolint: errcheck
+		case "/api/v1/teams/1/members/lkysow":
+			http.Error(w, "not found", http.StatusNotFound)
+		case "/api/v1/teams/2/members/lkysow":
+			w.Write([]byte(`{"id": 3, "login": "lkysow"}`)) // nolint: errcheck
+		default:
+			if strings.HasPrefix(r.RequestURI, "/api/v1/teams/") {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "lkysow"})
+	Ok(t, err)
+	Equals(t, []string{"devops"}, teams)
+}
+
+func TestClient_GetPullRequest(t *testing.T) {
+	resp, err := ioutil.ReadFile(filepath.Join("testdata", "pull.json"))
+	Ok(t, err)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/pulls/1":
+			w.Write(resp) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "user", "token", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	pull, err := client.GetPullRequest(repo, 1)
+	Ok(t, err)
+	Equals(t, "e1749643d655d7c7014001a6c0f58abaf42ad850", *pull.Head.SHA)
+	Equals(t, "feature", *pull.Head.Ref)
+	Equals(t, "author", *pull.User.Login)
+}
@@ -0,0 +1,87 @@
+package gitea
+
+const (
+	PullRequestEventHeader  = "pull_request"
+	IssueCommentEventHeader = "issue_comment"
+
+	PullOpenedAction       = "opened"
+	PullReopenedAction     = "reopened"
+	PullSynchronizedAction = "synchronized"
+	PullClosedAction       = "closed"
+	CommentCreatedAction   = "created"
+
+	// ApprovedReviewState is the state of a review that approved the pull
+	// request.
+	ApprovedReviewState = "APPROVED"
+	// RequestChangesReviewState is the state of a review that requested
+	// changes.
+	RequestChangesReviewState = "REQUEST_CHANGES"
+)
+
+type PullRequestEvent struct {
+	Action      *string      `json:"action,omitempty" validate:"required"`
+	PullRequest *PullRequest `json:"pull_request,omitempty" validate:"required"`
+	Repository  *Repository  `json:"repository,omitempty" validate:"required"`
+	Sender      *User        `json:"sender,omitempty" validate:"required"`
+}
+
+type IssueCommentEvent struct {
+	Action     *string     `json:"action,omitempty" validate:"required"`
+	Issue      *Issue      `json:"issue,omitempty" validate:"required"`
+	Comment    *Comment    `json:"comment,omitempty" validate:"required"`
+	Repository *Repository `json:"repository,omitempty" validate:"required"`
+	Sender     *User       `json:"sender,omitempty" validate:"required"`
+	// IsPull is true if the comment was made on a pull request rather than
+	// an issue.
+	IsPull bool `json:"is_pull"`
+}
+
+type Issue struct {
+	Number *int `json:"number,omitempty" validate:"required"`
+}
+
+type PullRequest struct {
+	Number  *int    `json:"number,omitempty" validate:"required"`
+	HTMLURL *string `json:"html_url,omitempty" validate:"required"`
+	State   *string `json:"state,omitempty" validate:"required"`
+	User    *User   `json:"user,omitempty" validate:"required"`
+	Head    *Branch `json:"head,omitempty" validate:"required"`
+	Base    *Branch `json:"base,omitempty" validate:"required"`
+	// Mergeable is false if the pull request has conflicts.
+	Mergeable bool `json:"mergeable"`
+}
+
+type Branch struct {
+	Ref        *string     `json:"ref,omitempty" validate:"required"`
+	SHA        *string     `json:"sha,omitempty" validate:"required"`
+	Repository *Repository `json:"repo,omitempty" validate:"required"`
+}
+
+type Repository struct {
+	FullName *string `json:"full_name,omitempty" validate:"required"`
+	CloneURL *string `json:"clone_url,omitempty" validate:"required"`
+}
+
+type User struct {
+	Login *string `json:"login,omitempty" validate:"required"`
+}
+
+type Comment struct {
+	Body *string `json:"body,omitempty" validate:"required"`
+}
+
+type ChangedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+}
+
+type Review struct {
+	State     string `json:"state"`
+	Dismissed bool   `json:"dismissed"`
+	User      *User  `json:"user"`
+}
+
+type Team struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,29 @@
+package gitea
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateSignature checks that signature is the hex encoded HMAC-SHA256 of
+// payload keyed with secretKey. Gitea sends this signature in the
+// X-Gitea-Signature header. Unlike GitHub, there is no "sha256=" prefix.
+func ValidateSignature(payload []byte, signature string, secretKey []byte) error {
+	if signature == "" {
+		return errors.New("missing signature")
+	}
+	messageMAC, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("error decoding signature %q: %v", signature, err)
+	}
+	mac := hmac.New(sha256.New, secretKey)
+	mac.Write(payload) // nolint: errcheck
+	if !hmac.Equal(messageMAC, mac.Sum(nil)) {
+		return errors.New("payload signature check failed")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package gitea_test
+
+import (
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/vcs/gitea"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestValidateSignature(t *testing.T) {
+	body := `{"action":"opened","number":1}`
+	secret := "mysecret"
+	sig := `c97bce3bc9d95a2444edf0576809613d4fe1939256147c885261ea141e398a32`
+	err := gitea.ValidateSignature([]byte(body), sig, []byte(secret))
+	Ok(t, err)
+}
+
+func TestValidateSignature_Invalid(t *testing.T) {
+	body := `{"action":"opened","number":2}`
+	secret := "mysecret"
+	sig := `c97bce3bc9d95a2444edf0576809613d4fe1939256147c885261ea141e398a32`
+	err := gitea.ValidateSignature([]byte(body), sig, []byte(secret))
+	ErrEquals(t, "payload signature check failed", err)
+}
+
+func TestValidateSignature_Missing(t *testing.T) {
+	err := gitea.ValidateSignature([]byte(`{}`), "", []byte("mysecret"))
+	ErrEquals(t, "missing signature", err)
+}
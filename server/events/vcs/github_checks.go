@@ -0,0 +1,112 @@
+package vcs
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/pkg/errors"
+)
+
+// githubChecksAcceptHeader is required by the Checks API while it's in
+// preview.
+const githubChecksAcceptHeader = "application/vnd.github.antiope-preview+json"
+
+// maxCheckRunOutputSize is the maximum number of characters GitHub accepts
+// for a check run's output summary and text.
+const maxCheckRunOutputSize = 65535
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_github_checks_client.go GithubChecksClient
+
+// GithubChecksClient creates and updates GitHub check runs. The Checks API is
+// only available when authenticated as a GitHub App.
+type GithubChecksClient interface {
+	// UpdateCheckRun creates or updates the check run called name on the head
+	// commit of pull. title and summary are shown on the check and text holds
+	// the full details, all as markdown.
+	UpdateCheckRun(repo models.Repo, pull models.PullRequest, name string, state models.CommitStatus, title string, summary string, text string) error
+}
+
+type githubCheckRun struct {
+	ID         int                   `json:"id,omitempty"`
+	Name       string                `json:"name,omitempty"`
+	HeadSHA    string                `json:"head_sha,omitempty"`
+	Status     string                `json:"status"`
+	Conclusion string                `json:"conclusion,omitempty"`
+	Output     *githubCheckRunOutput `json:"output,omitempty"`
+}
+
+type githubCheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	Text    string `json:"text,omitempty"`
+}
+
+type githubCheckRunsList struct {
+	CheckRuns []githubCheckRun `json:"check_runs"`
+}
+
+// UpdateCheckRun creates the check run if it doesn't exist on the head commit
+// yet, otherwise it updates the existing one so re-running a command doesn't
+// leave stale check runs behind.
+// See https://developer.github.com/v3/checks/runs/.
+func (g *GithubClient) UpdateCheckRun(repo models.Repo, pull models.PullRequest, name string, state models.CommitStatus, title string, summary string, text string) error {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return err
+	}
+
+	checkRun := githubCheckRun{
+		Name:    name,
+		HeadSHA: pull.HeadCommit,
+		Output: &githubCheckRunOutput{
+			Title:   title,
+			Summary: truncateCheckRunOutput(summary),
+			Text:    truncateCheckRunOutput(text),
+		},
+	}
+	switch state {
+	case models.PendingCommitStatus:
+		checkRun.Status = "in_progress"
+	case models.SuccessCommitStatus:
+		checkRun.Status = "completed"
+		checkRun.Conclusion = "success"
+	case models.FailedCommitStatus:
+		checkRun.Status = "completed"
+		checkRun.Conclusion = "failure"
+	}
+
+	listURL := fmt.Sprintf("repos/%s/%s/commits/%s/check-runs?check_name=%s", repo.Owner, repo.Name, pull.HeadCommit, url.QueryEscape(name))
+	req, err := client.NewRequest("GET", listURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", githubChecksAcceptHeader)
+	existing := new(githubCheckRunsList)
+	if _, err := client.Do(g.ctx, req, existing); err != nil {
+		return errors.Wrapf(err, "listing check runs named %q", name)
+	}
+
+	method := "POST"
+	runURL := fmt.Sprintf("repos/%s/%s/check-runs", repo.Owner, repo.Name)
+	if len(existing.CheckRuns) > 0 {
+		method = "PATCH"
+		runURL = fmt.Sprintf("%s/%d", runURL, existing.CheckRuns[0].ID)
+	}
+	req, err = client.NewRequest(method, runURL, checkRun)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", githubChecksAcceptHeader)
+	_, err = client.Do(g.ctx, req, nil)
+	return errors.Wrapf(err, "updating check run %q", name)
+}
+
+// truncateCheckRunOutput truncates s so GitHub doesn't reject it.
+func truncateCheckRunOutput(s string) string {
+	const truncatedMsg = "\n\n...output truncated"
+	if len(s) <= maxCheckRunOutputSize {
+		return s
+	}
+	return s[:maxCheckRunOutputSize-len(truncatedMsg)] + truncatedMsg
+}
@@ -0,0 +1,78 @@
+package vcs_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestGithubClient_UpdateCheckRun(t *testing.T) {
+	cases := []struct {
+		description string
+		existing    string
+		expMethod   string
+		expURI      string
+	}{
+		{
+			"creates check run",
+			`{"total_count":0,"check_runs":[]}`,
+			"POST",
+			"/api/v3/repos/owner/repo/check-runs",
+		},
+		{
+			"updates existing check run",
+			`{"total_count":1,"check_runs":[{"id":4,"name":"atlantis/plan: . (default)"}]}`,
+			"PATCH",
+			"/api/v3/repos/owner/repo/check-runs/4",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			updated := false
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					Equals(t, "application/vnd.github.antiope-preview+json", r.Header.Get("Accept"))
+					switch r.RequestURI {
+					case "/api/v3/repos/owner/repo/commits/sha/check-runs?check_name=atlantis%2Fplan%3A+.+%28default%29":
+						w.Write([]byte(c.existing)) // nolint: errcheck
+					case c.expURI:
+						Equals(t, c.expMethod, r.Method)
+						body, err := ioutil.ReadAll(r.Body)
+						Ok(t, err)
+						exp := `{"name":"atlantis/plan: . (default)","head_sha":"sha","status":"completed","conclusion":"failure","output":{"title":"Plan Failed","summary":"summary","text":"text"}}` + "\n"
+						Equals(t, exp, string(body))
+						updated = true
+						w.Write([]byte("{}")) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			err = client.UpdateCheckRun(models.Repo{
+				FullName: "owner/repo",
+				Owner:    "owner",
+				Name:     "repo",
+			}, models.PullRequest{
+				Num:        1,
+				HeadCommit: "sha",
+			}, "atlantis/plan: . (default)", models.FailedCommitStatus, "Plan Failed", "summary", "text")
+			Ok(t, err)
+			Assert(t, updated, fmt.Sprintf("expected %s to %s", c.expMethod, c.expURI))
+		})
+	}
+}
@@ -16,53 +16,90 @@ package vcs
 import (
 	"context"
 	"fmt"
-	"math"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
 )
 
-// maxCommentBodySize is derived from the error message when you go over
+// GithubMaxCommentLength is derived from the error message when you go over
 // this limit.
-const maxCommentBodySize = 65536
+const GithubMaxCommentLength = 65536
 
 // GithubClient is used to perform GitHub actions.
 type GithubClient struct {
-	client *github.Client
-	ctx    context.Context
-}
-
-// NewGithubClient returns a valid GitHub client.
-func NewGithubClient(hostname string, user string, pass string) (*GithubClient, error) {
-	tp := github.BasicAuthTransport{
-		Username: strings.TrimSpace(user),
-		Password: strings.TrimSpace(pass),
-	}
-	client := github.NewClient(tp.Client())
-	// If we're using github.com then we don't need to do any additional configuration
-	// for the client. It we're using Github Enterprise, then we need to manually
-	// set the base url for the API.
-	if hostname != "github.com" {
-		baseURL := fmt.Sprintf("https://%s/api/v3/", hostname)
-		base, err := url.Parse(baseURL)
-		if err != nil {
-			return nil, errors.Wrapf(err, "Invalid github hostname trying to parse %s", baseURL)
-		}
-		client.BaseURL = base
-	}
+	baseURL     *url.URL
+	credentials GithubCredentials
+	// clients holds a client per repo owner because when running as a GitHub
+	// App, each owner has its own installation and token.
+	clients map[string]*github.Client
+	mutex   sync.Mutex
+	ctx     context.Context
+}
 
+// NewGithubClient returns a valid GitHub client. credentials is either
+// GithubUserCredentials or GithubAppCredentials.
+func NewGithubClient(hostname string, credentials GithubCredentials) (*GithubClient, error) {
+	baseURL, err := githubBaseURL(hostname)
+	if err != nil {
+		return nil, err
+	}
 	return &GithubClient{
-		client: client,
-		ctx:    context.Background(),
+		baseURL:     baseURL,
+		credentials: credentials,
+		clients:     make(map[string]*github.Client),
+		ctx:         context.Background(),
 	}, nil
 }
 
+// githubBaseURL returns the API URL for hostname. If we're using github.com
+// then we don't need to do any additional configuration for the client and
+// we return nil. If we're using Github Enterprise, then we need to manually
+// set the base url for the API.
+func githubBaseURL(hostname string) (*url.URL, error) {
+	if hostname == "github.com" {
+		return nil, nil
+	}
+	baseURL := fmt.Sprintf("https://%s/api/v3/", hostname)
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid github hostname trying to parse %s", baseURL)
+	}
+	return base, nil
+}
+
+// client returns the client to use for repos owned by owner.
+func (g *GithubClient) client(owner string) (*github.Client, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if client, ok := g.clients[owner]; ok {
+		return client, nil
+	}
+	httpClient, err := g.credentials.Client(owner)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating GitHub client for %s", owner)
+	}
+	client := github.NewClient(httpClient)
+	if g.baseURL != nil {
+		client.BaseURL = g.baseURL
+	}
+	g.clients[owner] = client
+	return client, nil
+}
+
 // GetModifiedFiles returns the names of files that were modified in the pull request.
 // The names include the path to the file from the repo root, ex. parent/child/file.txt.
 func (g *GithubClient) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return nil, err
+	}
 	var files []string
 	nextPage := 0
 	for {
@@ -72,7 +109,7 @@ func (g *GithubClient) GetModifiedFiles(repo models.Repo, pull models.PullReques
 		if nextPage != 0 {
 			opts.Page = nextPage
 		}
-		pageFiles, resp, err := g.client.PullRequests.ListFiles(g.ctx, repo.Owner, repo.Name, pull.Num, &opts)
+		pageFiles, resp, err := client.PullRequests.ListFiles(g.ctx, repo.Owner, repo.Name, pull.Num, &opts)
 		if err != nil {
 			return files, err
 		}
@@ -91,19 +128,96 @@ func (g *GithubClient) GetModifiedFiles(repo models.Repo, pull models.PullReques
 // If comment length is greater than the max comment length we split into
 // multiple comments.
 func (g *GithubClient) CreateComment(repo models.Repo, pullNum int, comment string) error {
-	comments := g.splitAtMaxChars(comment, maxCommentBodySize, "\ncontinued...\n")
+	_, err := g.CreateCommentWithIDs(repo, pullNum, comment)
+	return err
+}
+
+// CreateCommentWithIDs creates a comment like CreateComment and returns the
+// IDs of the comments it was split into.
+func (g *GithubClient) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	comments, err := common.SplitComment(comment, GithubMaxCommentLength, common.SepEnd, common.SepStart)
+	if err != nil {
+		return nil, err
+	}
 	for _, c := range comments {
-		_, _, err := g.client.Issues.CreateComment(g.ctx, repo.Owner, repo.Name, pullNum, &github.IssueComment{Body: &c})
+		created, _, err := client.Issues.CreateComment(g.ctx, repo.Owner, repo.Name, pullNum, &github.IssueComment{Body: &c})
 		if err != nil {
-			return err
+			return ids, err
 		}
+		ids = append(ids, strconv.Itoa(created.GetID()))
+	}
+	return ids, nil
+}
+
+// UpdateComment replaces the body of the comment with id.
+func (g *GithubClient) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return err
+	}
+	commentID, err := strconv.Atoi(id)
+	if err != nil {
+		return errors.Wrapf(err, "invalid comment id %q", id)
+	}
+	_, _, err = client.Issues.EditComment(g.ctx, repo.Owner, repo.Name, commentID, &github.IssueComment{Body: &comment})
+	return err
+}
+
+// HideComment minimizes the comment with id as outdated. Minimizing is only
+// available in the GraphQL API which needs the comment's node id.
+func (g *GithubClient) HideComment(repo models.Repo, pullNum int, id string) error {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return err
+	}
+	// Our version of go-github doesn't have the node_id field so we decode it
+	// ourselves.
+	req, err := client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/issues/comments/%s", repo.Owner, repo.Name, id), nil)
+	if err != nil {
+		return err
+	}
+	var ghComment struct {
+		NodeID string `json:"node_id"`
+	}
+	if _, err := client.Do(g.ctx, req, &ghComment); err != nil {
+		return errors.Wrap(err, "getting comment")
+	}
+
+	req, err = client.NewRequest("POST", githubGraphQLURL(client), map[string]interface{}{
+		"query": `mutation($id: ID!) { minimizeComment(input: {subjectId: $id, classifier: OUTDATED}) { clientMutationId } }`,
+		"variables": map[string]string{
+			"id": ghComment.NodeID,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := client.Do(g.ctx, req, &resp); err != nil {
+		return errors.Wrap(err, "minimizing comment")
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("minimizing comment: %s", resp.Errors[0].Message)
 	}
 	return nil
 }
 
 // PullIsApproved returns true if the pull request was approved.
 func (g *GithubClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
-	reviews, _, err := g.client.PullRequests.ListReviews(g.ctx, repo.Owner, repo.Name, pull.Num, nil)
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return false, err
+	}
+	reviews, _, err := client.PullRequests.ListReviews(g.ctx, repo.Owner, repo.Name, pull.Num, nil)
 	if err != nil {
 		return false, errors.Wrap(err, "getting reviews")
 	}
@@ -115,16 +229,262 @@ func (g *GithubClient) PullIsApproved(repo models.Repo, pull models.PullRequest)
 	return false, nil
 }
 
+// GetApprovers returns the users whose latest review approved the pull
+// request. A later review requesting changes or a dismissed approval means
+// the user no longer approves it.
+func (g *GithubClient) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return nil, err
+	}
+	var users []string
+	approved := make(map[string]bool)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := client.PullRequests.ListReviews(g.ctx, repo.Owner, repo.Name, pull.Num, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting reviews")
+		}
+		// Reviews are listed oldest first so later reviews override earlier
+		// ones. Comments don't change whether the user approves.
+		for _, review := range reviews {
+			if review == nil || review.User == nil {
+				continue
+			}
+			login := review.User.GetLogin()
+			switch review.GetState() {
+			case "APPROVED":
+				if _, ok := approved[login]; !ok {
+					users = append(users, login)
+				}
+				approved[login] = true
+			case "CHANGES_REQUESTED", "DISMISSED":
+				if _, ok := approved[login]; ok {
+					approved[login] = false
+				}
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	var approvers []string
+	for _, u := range users {
+		if approved[u] {
+			approvers = append(approvers, u)
+		}
+	}
+	return approvers, nil
+}
+
+// PullIsMergeable returns true if the pull request can be merged. This is when
+// GitHub's merge button is clickable which is when the mergeable_state is
+// clean (no conflicts and branch protection is satisfied), unstable (a commit
+// status that isn't required is failing or pending) or has_hooks (GitHub
+// Enterprise only, the repo has pre-receive hooks). It's also mergeable when
+// it's blocked only by Atlantis's own statuses, since the apply that checks
+// this requirement is what makes them pass.
+// See https://developer.github.com/v4/enum/mergestatestatus/.
+func (g *GithubClient) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return false, err
+	}
+	// Our version of go-github doesn't have the mergeable_state field so we
+	// decode it ourselves.
+	req, err := client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/pulls/%d", repo.Owner, repo.Name, pull.Num), nil)
+	if err != nil {
+		return false, err
+	}
+	var ghPull struct {
+		MergeableState string `json:"mergeable_state"`
+		Head           struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if _, err := client.Do(g.ctx, req, &ghPull); err != nil {
+		return false, errors.Wrap(err, "getting pull request")
+	}
+	switch ghPull.MergeableState {
+	case "clean", "unstable", "has_hooks":
+		return true, nil
+	case "blocked":
+		return g.onlyBlockedByAtlantis(client, repo, pull.Num, ghPull.Head.SHA)
+	}
+	return false, nil
+}
+
+// onlyBlockedByAtlantis returns true if the reviews the pull request needs
+// have been given and every status and check run on its head commit passed,
+// other than Atlantis's own. We can't read the branch protection rules
+// without admin permissions so we require every other status to pass, not
+// just the required ones.
+func (g *GithubClient) onlyBlockedByAtlantis(client *github.Client, repo models.Repo, pullNum int, headSHA string) (bool, error) {
+	// reviewDecision is only available in the GraphQL API. It's null if
+	// reviews aren't required.
+	req, err := client.NewRequest("POST", githubGraphQLURL(client), map[string]interface{}{
+		"query": `query($owner: String!, $name: String!, $number: Int!) { repository(owner: $owner, name: $name) { pullRequest(number: $number) { reviewDecision } } }`,
+		"variables": map[string]interface{}{
+			"owner":  repo.Owner,
+			"name":   repo.Name,
+			"number": pullNum,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	var reviewResp struct {
+		Data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewDecision *string `json:"reviewDecision"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := client.Do(g.ctx, req, &reviewResp); err != nil {
+		return false, errors.Wrap(err, "getting review decision")
+	}
+	if len(reviewResp.Errors) > 0 {
+		return false, fmt.Errorf("getting review decision: %s", reviewResp.Errors[0].Message)
+	}
+	if decision := reviewResp.Data.Repository.PullRequest.ReviewDecision; decision != nil && *decision != "APPROVED" {
+		return false, nil
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		status, resp, err := client.Repositories.GetCombinedStatus(g.ctx, repo.Owner, repo.Name, headSHA, opts)
+		if err != nil {
+			return false, errors.Wrap(err, "getting commit statuses")
+		}
+		for _, s := range status.Statuses {
+			if !isAtlantisStatus(s.GetContext()) && s.GetState() != "success" {
+				return false, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	nextPage := 1
+	for nextPage != 0 {
+		req, err := client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/commits/%s/check-runs?per_page=100&page=%d", repo.Owner, repo.Name, headSHA, nextPage), nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Accept", githubChecksAcceptHeader)
+		checkRuns := new(githubCheckRunsList)
+		resp, err := client.Do(g.ctx, req, checkRuns)
+		if err != nil {
+			return false, errors.Wrap(err, "listing check runs")
+		}
+		for _, run := range checkRuns.CheckRuns {
+			if isAtlantisStatus(run.Name) {
+				continue
+			}
+			if run.Status != "completed" {
+				return false, nil
+			}
+			switch run.Conclusion {
+			case "success", "neutral", "skipped":
+			default:
+				return false, nil
+			}
+		}
+		nextPage = resp.NextPage
+	}
+	return true, nil
+}
+
+// isAtlantisStatus returns true if the commit status or check run called
+// name was made by Atlantis, ex. Atlantis or atlantis/plan.
+func isAtlantisStatus(name string) bool {
+	name = strings.ToLower(name)
+	return name == "atlantis" || strings.HasPrefix(name, "atlantis/")
+}
+
+// githubGraphQLURL returns the URL of the GraphQL endpoint relative to
+// client's base URL. It's /graphql on github.com and /api/graphql on GitHub
+// Enterprise whose REST API is under /api/v3/.
+func githubGraphQLURL(client *github.Client) string {
+	if strings.HasSuffix(client.BaseURL.Path, "/v3/") {
+		return "../graphql"
+	}
+	return "graphql"
+}
+
+// MergePull merges the pull request using the first merge method the repo
+// allows out of merge commits, rebasing and squashing. The merge fails if the
+// pull request's head has moved past the commit we applied.
+func (g *GithubClient) MergePull(repo models.Repo, pull models.PullRequest) error {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return err
+	}
+	ghRepo, _, err := client.Repositories.Get(g.ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return errors.Wrap(err, "fetching repo info")
+	}
+	var method string
+	switch {
+	case ghRepo.GetAllowMergeCommit():
+		method = "merge"
+	case ghRepo.GetAllowRebaseMerge():
+		method = "rebase"
+	case ghRepo.GetAllowSquashMerge():
+		method = "squash"
+	default:
+		return errors.New("repo doesn't allow merge commits, rebasing or squashing")
+	}
+	options := &github.PullRequestOptions{
+		MergeMethod: method,
+		SHA:         pull.HeadCommit,
+	}
+	mergeResult, _, err := client.PullRequests.Merge(g.ctx, repo.Owner, repo.Name, pull.Num, "", options)
+	if err != nil {
+		return errors.Wrap(err, "merging pull request")
+	}
+	if !mergeResult.GetMerged() {
+		return fmt.Errorf("could not merge pull request: %s", mergeResult.GetMessage())
+	}
+	return nil
+}
+
 // GetPullRequest returns the pull request.
 func (g *GithubClient) GetPullRequest(repo models.Repo, num int) (*github.PullRequest, error) {
-	pull, _, err := g.client.PullRequests.Get(g.ctx, repo.Owner, repo.Name, num)
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return nil, err
+	}
+	pull, _, err := client.PullRequests.Get(g.ctx, repo.Owner, repo.Name, num)
 	return pull, err
 }
 
+// GetPullHeadCommit returns the sha of the pull request's head commit. If
+// lastSHA is still the head, GitHub responds with 304 Not Modified, which
+// doesn't count against our rate limit, and we return lastSHA.
+func (g *GithubClient) GetPullHeadCommit(repo models.Repo, num int, lastSHA string) (string, error) {
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return "", err
+	}
+	sha, resp, err := client.Repositories.GetCommitSHA1(g.ctx, repo.Owner, repo.Name, fmt.Sprintf("refs/pull/%d/head", num), lastSHA)
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
+		return lastSHA, nil
+	}
+	return sha, err
+}
+
 // UpdateStatus updates the status badge on the pull request.
 // See https://github.com/blog/1227-commit-status-api.
-func (g *GithubClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) error {
-	const statusContext = "Atlantis"
+func (g *GithubClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error {
 	ghState := "error"
 	switch state {
 	case models.PendingCommitStatus:
@@ -137,46 +497,13 @@ func (g *GithubClient) UpdateStatus(repo models.Repo, pull models.PullRequest, s
 	status := &github.RepoStatus{
 		State:       github.String(ghState),
 		Description: github.String(description),
-		Context:     github.String(statusContext)}
-	_, _, err := g.client.Repositories.CreateStatus(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, status)
-	return err
-}
-
-// splitAtMaxChars splits comment into a slice with string up to max
-// len separated by join which gets appended to the ends of the middle strings.
-// If max <= len(join) we return an empty slice since this is an edge case we
-// don't want to handle.
-// nolint: unparam
-func (g *GithubClient) splitAtMaxChars(comment string, max int, join string) []string {
-	// If we're under the limit then no need to split.
-	if len(comment) <= max {
-		return []string{comment}
-	}
-
-	// If we can't fit the joining string in then this doesn't make sense.
-	if max <= len(join) {
-		return nil
-	}
-
-	var comments []string
-	maxSize := max - len(join)
-	numComments := int(math.Ceil(float64(len(comment)) / float64(maxSize)))
-	for i := 0; i < numComments; i++ {
-		upTo := g.min(len(comment), (i+1)*maxSize)
-		portion := comment[i*maxSize : upTo]
-		if i < numComments-1 {
-			portion += join
-		}
-		comments = append(comments, portion)
-	}
-	return comments
-}
-
-func (g *GithubClient) min(a, b int) int {
-	if a < b {
-		return a
+		Context:     github.String(src)}
+	client, err := g.client(repo.Owner)
+	if err != nil {
+		return err
 	}
-	return b
+	_, _, err = client.Repositories.CreateStatus(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, status)
+	return err
 }
 
 // GetTeamNamesForUser returns the names of the teams or groups that the user belongs to (in the organization the repository belongs to).
@@ -184,13 +511,17 @@ func (g *GithubClient) GetTeamNamesForUser(repo models.Repo, user models.User) (
 	var teamNames []string
 	opts := &github.ListOptions{}
 	org := repo.Owner
+	client, err := g.client(org)
+	if err != nil {
+		return nil, err
+	}
 	for {
-		teams, resp, err := g.client.Organizations.ListTeams(g.ctx, org, opts)
+		teams, resp, err := client.Organizations.ListTeams(g.ctx, org, opts)
 		if err != nil {
 			return nil, err
 		}
 		for _, t := range teams {
-			ok, _, err := g.client.Organizations.IsTeamMember(g.ctx, t.GetID(), user.Username)
+			ok, _, err := client.Organizations.IsTeamMember(g.ctx, t.GetID(), user.Username)
 			if err != nil {
 				return nil, err
 			}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/cloudposse/atlantis/server/events/models"
@@ -55,7 +56,7 @@ func TestGithubClient_GetModifiedFiles(t *testing.T) {
 
 	testServerURL, err := url.Parse(testServer.URL)
 	Ok(t, err)
-	client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
 	Ok(t, err)
 	defer disableSSLVerification()()
 
@@ -116,7 +117,7 @@ func TestGithubClient_UpdateStatus(t *testing.T) {
 
 			testServerURL, err := url.Parse(testServer.URL)
 			Ok(t, err)
-			client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
 			Ok(t, err)
 			defer disableSSLVerification()()
 
@@ -132,7 +133,7 @@ func TestGithubClient_UpdateStatus(t *testing.T) {
 				},
 			}, models.PullRequest{
 				Num: 1,
-			}, c.status, "description")
+			}, c.status, "Atlantis", "description")
 			Ok(t, err)
 		})
 	}
@@ -147,3 +148,214 @@ func disableSSLVerification() func() {
 		http.DefaultTransport.(*http.Transport).TLSClientConfig = orig
 	}
 }
+
+func TestGithubClient_GetApprovers(t *testing.T) {
+	// alice approved then requested changes, bob commented then approved and
+	// carol approved twice.
+	resp := `[
+  {"id": 1, "user": {"login": "alice"}, "state": "APPROVED"},
+  {"id": 2, "user": {"login": "bob"}, "state": "COMMENTED"},
+  {"id": 3, "user": {"login": "carol"}, "state": "APPROVED"},
+  {"id": 4, "user": {"login": "alice"}, "state": "CHANGES_REQUESTED"},
+  {"id": 5, "user": {"login": "bob"}, "state": "APPROVED"},
+  {"id": 6, "user": {"login": "carol"}, "state": "APPROVED"}
+]`
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/pulls/1/reviews?per_page=100":
+				w.Write([]byte(resp)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	approvers, err := client.GetApprovers(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}, models.PullRequest{
+		Num: 1,
+	})
+	Ok(t, err)
+	Equals(t, []string{"carol", "bob"}, approvers)
+}
+
+func TestGithubClient_PullIsMergeable(t *testing.T) {
+	cases := []struct {
+		state string
+		exp   bool
+	}{
+		{"clean", true},
+		{"unstable", true},
+		{"has_hooks", true},
+		{"behind", false},
+		{"dirty", false},
+		{"unknown", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.state, func(t *testing.T) {
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v3/repos/owner/repo/pulls/1":
+						fmt.Fprintf(w, `{"number": 1, "mergeable": true, "mergeable_state": %q}`, c.state)
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			mergeable, err := client.PullIsMergeable(models.Repo{
+				FullName: "owner/repo",
+				Owner:    "owner",
+				Name:     "repo",
+			}, models.PullRequest{
+				Num: 1,
+			})
+			Ok(t, err)
+			Equals(t, c.exp, mergeable)
+		})
+	}
+}
+
+// A blocked pull request is mergeable if it's only blocked by Atlantis's own
+// statuses.
+func TestGithubClient_PullIsMergeableBlocked(t *testing.T) {
+	cases := []struct {
+		description    string
+		reviewDecision string
+		statuses       string
+		checkRuns      string
+		exp            bool
+	}{
+		{
+			"only atlantis statuses failing",
+			`"APPROVED"`,
+			`[{"context": "ci", "state": "success"}, {"context": "atlantis/apply", "state": "failure"}, {"context": "Atlantis", "state": "pending"}]`,
+			`[{"name": "lint", "status": "completed", "conclusion": "neutral"}, {"name": "atlantis/apply: . (default)", "status": "in_progress"}]`,
+			true,
+		},
+		{
+			"only aggregated atlantis status pending",
+			`"APPROVED"`,
+			`[{"context": "ci", "state": "success"}, {"context": "Atlantis", "state": "pending"}]`,
+			`[]`,
+			true,
+		},
+		{
+			"reviews not required",
+			`null`,
+			`[{"context": "atlantis/apply", "state": "failure"}]`,
+			`[]`,
+			true,
+		},
+		{
+			"review required",
+			`"REVIEW_REQUIRED"`,
+			`[{"context": "atlantis/apply", "state": "failure"}]`,
+			`[]`,
+			false,
+		},
+		{
+			"other status pending",
+			`"APPROVED"`,
+			`[{"context": "ci", "state": "pending"}, {"context": "atlantis/apply", "state": "failure"}]`,
+			`[]`,
+			false,
+		},
+		{
+			"other check run failed",
+			`"APPROVED"`,
+			`[{"context": "atlantis/apply", "state": "failure"}]`,
+			`[{"name": "lint", "status": "completed", "conclusion": "failure"}]`,
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v3/repos/owner/repo/pulls/1":
+						w.Write([]byte(`{"number": 1, "mergeable": true, "mergeable_state": "blocked", "head": {"sha": "sha"}}`)) // nolint: errcheck
+					case "/api/graphql":
+						body, err := ioutil.ReadAll(r.Body)
+						Ok(t, err)
+						Assert(t, strings.Contains(string(body), "reviewDecision"), "expected a reviewDecision query, got %q", body)
+						fmt.Fprintf(w, `{"data": {"repository": {"pullRequest": {"reviewDecision": %s}}}}`, c.reviewDecision)
+					case "/api/v3/repos/owner/repo/commits/sha/status?per_page=100":
+						fmt.Fprintf(w, `{"state": "failure", "statuses": %s}`, c.statuses)
+					case "/api/v3/repos/owner/repo/commits/sha/check-runs?per_page=100&page=1":
+						fmt.Fprintf(w, `{"check_runs": %s}`, c.checkRuns)
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			mergeable, err := client.PullIsMergeable(models.Repo{
+				FullName: "owner/repo",
+				Owner:    "owner",
+				Name:     "repo",
+			}, models.PullRequest{
+				Num: 1,
+			})
+			Ok(t, err)
+			Equals(t, c.exp, mergeable)
+		})
+	}
+}
+
+func TestGithubClient_HideComment(t *testing.T) {
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/issues/comments/1":
+				w.Write([]byte(`{"id": 1, "node_id": "MDEyOklzc3VlQ29tbWVudDE="}`)) // nolint: errcheck
+			case "/api/graphql":
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				Assert(t, strings.Contains(string(body), "minimizeComment"), "expected a minimizeComment mutation, got %q", body)
+				Assert(t, strings.Contains(string(body), `"id":"MDEyOklzc3VlQ29tbWVudDE="`), "expected the comment's node id, got %q", body)
+				w.Write([]byte(`{"data": {"minimizeComment": {"clientMutationId": null}}}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{User: "user", Token: "pass"})
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	err = client.HideComment(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}, 1, "1")
+	Ok(t, err)
+}
@@ -0,0 +1,345 @@
+package vcs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// githubAppAcceptHeader is required by the GitHub Apps API while it's in
+// preview.
+const githubAppAcceptHeader = "application/vnd.github.machine-man-preview+json"
+
+// githubAppUser is the username GitHub expects when cloning with an
+// installation token.
+const githubAppUser = "x-access-token"
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_github_credentials.go GithubCredentials
+
+// GithubCredentials handles creating http.Clients that authenticate against
+// GitHub and tokens that can be used to clone repos.
+type GithubCredentials interface {
+	// Client returns an http.Client that authenticates API calls for repos
+	// owned by owner.
+	Client(owner string) (*http.Client, error)
+	// GetToken returns a token that can be used to clone repos owned by owner.
+	GetToken(owner string) (string, error)
+	// GetUser returns the username to use with the token when cloning.
+	GetUser() string
+}
+
+// GithubUserCredentials authenticates as a GitHub user with a personal
+// access token.
+type GithubUserCredentials struct {
+	User  string
+	Token string
+	// Transport makes the API requests. If nil, http.DefaultTransport is
+	// used.
+	Transport http.RoundTripper
+}
+
+// Client returns a client that uses basic auth. The owner is ignored since
+// the user's token is used for all repos.
+func (c *GithubUserCredentials) Client(owner string) (*http.Client, error) {
+	tp := github.BasicAuthTransport{
+		Username:  strings.TrimSpace(c.User),
+		Password:  strings.TrimSpace(c.Token),
+		Transport: c.Transport,
+	}
+	return tp.Client(), nil
+}
+
+// GetToken returns the user's token.
+func (c *GithubUserCredentials) GetToken(owner string) (string, error) {
+	return c.Token, nil
+}
+
+// GetUser returns the username.
+func (c *GithubUserCredentials) GetUser() string {
+	return c.User
+}
+
+// GithubAppCredentials authenticates as a GitHub App installation. Each
+// organization or user that installs the app gets its own installation so
+// tokens are minted, cached and refreshed per owner.
+type GithubAppCredentials struct {
+	AppID    int
+	Key      *rsa.PrivateKey
+	Hostname string
+	// Transport makes the API requests. If nil, http.DefaultTransport is
+	// used.
+	Transport http.RoundTripper
+
+	tokens map[string]*githubInstallationToken
+	// minting holds the token requests in flight per owner so concurrent
+	// callers wait for the same request instead of minting their own.
+	minting map[string]*githubTokenRequest
+	// mutex guards tokens and minting. It isn't held while minting tokens so
+	// a slow request for one owner doesn't block the others.
+	mutex sync.Mutex
+}
+
+type githubInstallationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// githubTokenRequest is a token request in flight. done is closed once token
+// and err are set.
+type githubTokenRequest struct {
+	done  chan struct{}
+	token *githubInstallationToken
+	err   error
+}
+
+// NewGithubAppCredentials reads the app's PEM encoded private key from
+// keyPath. hostname is the GitHub hostname, ex. github.com.
+func NewGithubAppCredentials(appID int, keyPath string, hostname string) (*GithubAppCredentials, error) {
+	keyBytes, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading GitHub App private key %s", keyPath)
+	}
+	key, err := parseRSAPrivateKey(keyBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing GitHub App private key %s", keyPath)
+	}
+	return &GithubAppCredentials{
+		AppID:    appID,
+		Key:      key,
+		Hostname: hostname,
+	}, nil
+}
+
+// Client returns a client that authenticates with the installation token for
+// owner. Tokens are fetched lazily so they're refreshed as they expire.
+func (c *GithubAppCredentials) Client(owner string) (*http.Client, error) {
+	return &http.Client{
+		Transport: &githubInstallationTransport{credentials: c, owner: owner, underlying: c.transport()},
+	}, nil
+}
+
+func (c *GithubAppCredentials) transport() http.RoundTripper {
+	if c.Transport == nil {
+		return http.DefaultTransport
+	}
+	return c.Transport
+}
+
+// GetUser returns the username GitHub expects with installation tokens.
+func (c *GithubAppCredentials) GetUser() string {
+	return githubAppUser
+}
+
+// GetToken returns a valid installation token for owner, minting a new one
+// if there's no cached token or it's about to expire.
+func (c *GithubAppCredentials) GetToken(owner string) (string, error) {
+	c.mutex.Lock()
+	// Refresh a minute early so the token doesn't expire mid-request.
+	if token, ok := c.tokens[owner]; ok && time.Now().Add(time.Minute).Before(token.ExpiresAt) {
+		c.mutex.Unlock()
+		return token.Token, nil
+	}
+	if req, ok := c.minting[owner]; ok {
+		c.mutex.Unlock()
+		<-req.done
+		if req.err != nil {
+			return "", req.err
+		}
+		return req.token.Token, nil
+	}
+	req := &githubTokenRequest{done: make(chan struct{})}
+	if c.minting == nil {
+		c.minting = make(map[string]*githubTokenRequest)
+	}
+	c.minting[owner] = req
+	c.mutex.Unlock()
+
+	req.token, req.err = c.mintToken(owner)
+
+	c.mutex.Lock()
+	if req.err == nil {
+		if c.tokens == nil {
+			c.tokens = make(map[string]*githubInstallationToken)
+		}
+		c.tokens[owner] = req.token
+	}
+	delete(c.minting, owner)
+	c.mutex.Unlock()
+	close(req.done)
+
+	if req.err != nil {
+		return "", req.err
+	}
+	return req.token.Token, nil
+}
+
+// mintToken creates a new installation token for owner.
+func (c *GithubAppCredentials) mintToken(owner string) (*githubInstallationToken, error) {
+	appClient, err := c.appClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	installationID, err := c.installationID(ctx, appClient, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := appClient.NewRequest("POST", fmt.Sprintf("installations/%d/access_tokens", installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", githubAppAcceptHeader)
+	token := new(githubInstallationToken)
+	if _, err := appClient.Do(ctx, req, token); err != nil {
+		return nil, errors.Wrapf(err, "creating installation token for %s", owner)
+	}
+	return token, nil
+}
+
+// installationID returns the ID of the app's installation on owner which can
+// be either an organization or a user.
+func (c *GithubAppCredentials) installationID(ctx context.Context, appClient *github.Client, owner string) (int, error) {
+	var lastErr error
+	for _, path := range []string{"orgs/%s/installation", "users/%s/installation"} {
+		req, err := appClient.NewRequest("GET", fmt.Sprintf(path, owner), nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Accept", githubAppAcceptHeader)
+		installation := new(github.Installation)
+		resp, err := appClient.Do(ctx, req, installation)
+		if err == nil {
+			return installation.GetID(), nil
+		}
+		lastErr = err
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			break
+		}
+	}
+	return 0, errors.Wrapf(lastErr, "finding GitHub App installation for %s", owner)
+}
+
+// appClient returns a client that authenticates as the app itself. This is
+// only used to look up installations and mint installation tokens.
+func (c *GithubAppCredentials) appClient() (*github.Client, error) {
+	jwt, err := c.signJWT(time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "signing GitHub App JWT")
+	}
+	client := github.NewClient(&http.Client{
+		Transport: &githubBearerTransport{token: jwt, underlying: c.transport()},
+	})
+	baseURL, err := githubBaseURL(c.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	if baseURL != nil {
+		client.BaseURL = baseURL
+	}
+	return client, nil
+}
+
+// signJWT returns an RS256 signed JWT identifying the app. GitHub rejects
+// JWTs that expire more than 10 minutes in the future and we backdate the
+// issued at time to allow for clock drift.
+func (c *GithubAppCredentials) signJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": int64(c.AppID),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.Key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey parses a PEM encoded PKCS1 or PKCS8 RSA private key.
+// GitHub generates PKCS1 keys but we accept both in case the key was
+// converted.
+func parseRSAPrivateKey(keyBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an RSA private key")
+	}
+	return key, nil
+}
+
+// githubBearerTransport adds a bearer token to each request.
+type githubBearerTransport struct {
+	token      string
+	underlying http.RoundTripper
+}
+
+func (t *githubBearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req2 := cloneRequest(req)
+	req2.Header.Set("Authorization", "Bearer "+t.token)
+	return t.underlying.RoundTrip(req2)
+}
+
+// githubInstallationTransport adds the installation token for owner to each
+// request.
+type githubInstallationTransport struct {
+	credentials *GithubAppCredentials
+	owner       string
+	underlying  http.RoundTripper
+}
+
+func (t *githubInstallationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.credentials.GetToken(t.owner)
+	if err != nil {
+		return nil, err
+	}
+	req2 := cloneRequest(req)
+	req2.Header.Set("Authorization", "token "+token)
+	return t.underlying.RoundTrip(req2)
+}
+
+// cloneRequest returns a shallow copy of req with a deep copy of its headers
+// since RoundTrippers must not modify the request.
+func cloneRequest(req *http.Request) *http.Request {
+	req2 := new(http.Request)
+	*req2 = *req
+	req2.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		req2.Header[k] = append([]string(nil), v...)
+	}
+	return req2
+}
@@ -0,0 +1,211 @@
+package vcs_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+func TestNewGithubAppCredentials_InvalidKey(t *testing.T) {
+	keyFile, err := ioutil.TempFile("", "")
+	Ok(t, err)
+	defer os.Remove(keyFile.Name()) // nolint: errcheck
+	_, err = keyFile.WriteString("not a key")
+	Ok(t, err)
+
+	_, err = vcs.NewGithubAppCredentials(1, keyFile.Name(), "github.com")
+	ErrContains(t, "no PEM data found", err)
+}
+
+// Should find the installation for the owner, fall back to the user
+// installation endpoint if the owner isn't an org and cache the token.
+func TestGithubAppCredentials_GetToken(t *testing.T) {
+	key, keyPath := githubAppKey(t)
+	defer os.Remove(keyPath) // nolint: errcheck
+
+	tokensMinted := 0
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/orgs/owner/installation":
+				verifyGithubAppJWT(t, r, &key.PublicKey, 123)
+				http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+			case "/api/v3/users/owner/installation":
+				verifyGithubAppJWT(t, r, &key.PublicKey, 123)
+				w.Write([]byte(`{"id": 5}`)) // nolint: errcheck
+			case "/api/v3/installations/5/access_tokens":
+				Equals(t, "POST", r.Method)
+				verifyGithubAppJWT(t, r, &key.PublicKey, 123)
+				tokensMinted++
+				fmt.Fprintf(w, `{"token": "installation-token", "expires_at": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+	defer disableSSLVerification()()
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	creds, err := vcs.NewGithubAppCredentials(123, keyPath, testServerURL.Host)
+	Ok(t, err)
+
+	token, err := creds.GetToken("owner")
+	Ok(t, err)
+	Equals(t, "installation-token", token)
+	token, err = creds.GetToken("owner")
+	Ok(t, err)
+	Equals(t, "installation-token", token)
+	Equals(t, 1, tokensMinted)
+	Equals(t, "x-access-token", creds.GetUser())
+}
+
+// Concurrent calls should share one token request and a slow request for
+// one owner shouldn't block requests for other owners.
+func TestGithubAppCredentials_GetTokenConcurrent(t *testing.T) {
+	_, keyPath := githubAppKey(t)
+	defer os.Remove(keyPath) // nolint: errcheck
+
+	var mutex sync.Mutex
+	tokensMinted := make(map[string]int)
+	slowOwnerBlocked := make(chan struct{})
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/orgs/slow/installation":
+				w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+			case "/api/v3/orgs/fast/installation":
+				w.Write([]byte(`{"id": 2}`)) // nolint: errcheck
+			case "/api/v3/installations/1/access_tokens", "/api/v3/installations/2/access_tokens":
+				owner := "fast"
+				if r.RequestURI == "/api/v3/installations/1/access_tokens" {
+					owner = "slow"
+					<-slowOwnerBlocked
+				}
+				mutex.Lock()
+				tokensMinted[owner]++
+				mutex.Unlock()
+				fmt.Fprintf(w, `{"token": "%s-token", "expires_at": %q}`, owner, time.Now().Add(time.Hour).Format(time.RFC3339)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+	defer disableSSLVerification()()
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	creds, err := vcs.NewGithubAppCredentials(123, keyPath, testServerURL.Host)
+	Ok(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := creds.GetToken("slow")
+			Ok(t, err)
+			Equals(t, "slow-token", token)
+		}()
+	}
+	// The slow owner's request is still blocked.
+	token, err := creds.GetToken("fast")
+	Ok(t, err)
+	Equals(t, "fast-token", token)
+	close(slowOwnerBlocked)
+	wg.Wait()
+	Equals(t, map[string]int{"slow": 1, "fast": 1}, tokensMinted)
+}
+
+// API calls should be authenticated with the installation token.
+func TestGithubClient_AppCredentials(t *testing.T) {
+	_, keyPath := githubAppKey(t)
+	defer os.Remove(keyPath) // nolint: errcheck
+
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/orgs/owner/installation":
+				w.Write([]byte(`{"id": 5}`)) // nolint: errcheck
+			case "/api/v3/installations/5/access_tokens":
+				fmt.Fprintf(w, `{"token": "installation-token", "expires_at": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339)) // nolint: errcheck
+			case "/api/v3/repos/owner/repo/pulls/1/files?per_page=300":
+				Equals(t, "token installation-token", r.Header.Get("Authorization"))
+				w.Write([]byte(`[{"filename": "file1.txt"}]`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+	defer disableSSLVerification()()
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	creds, err := vcs.NewGithubAppCredentials(123, keyPath, testServerURL.Host)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, creds)
+	Ok(t, err)
+
+	files, err := client.GetModifiedFiles(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, []string{"file1.txt"}, files)
+}
+
+// githubAppKey generates a private key and writes it to a temp file in the
+// PEM format GitHub uses.
+func githubAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Ok(t, err)
+	keyFile, err := ioutil.TempFile("", "")
+	Ok(t, err)
+	defer keyFile.Close() // nolint: errcheck
+	Ok(t, pem.Encode(keyFile, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	return key, keyFile.Name()
+}
+
+// verifyGithubAppJWT checks that the request has a bearer JWT for appID that
+// was signed by the app's key.
+func verifyGithubAppJWT(t *testing.T, r *http.Request, pub *rsa.PublicKey, appID int64) {
+	auth := r.Header.Get("Authorization")
+	Assert(t, strings.HasPrefix(auth, "Bearer "), "expected bearer auth, got %q", auth)
+	parts := strings.Split(strings.TrimPrefix(auth, "Bearer "), ".")
+	Equals(t, 3, len(parts))
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	Ok(t, err)
+	hashed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	Ok(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, hashed[:], sig))
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	Ok(t, err)
+	var claims map[string]int64
+	Ok(t, json.Unmarshal(claimsJSON, &claims))
+	Equals(t, appID, claims["iss"])
+	Assert(t, claims["exp"] <= time.Now().Add(10*time.Minute).Unix(), "exp must be at most 10 minutes in the future")
+}
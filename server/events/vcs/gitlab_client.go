@@ -15,12 +15,21 @@ package vcs
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/common"
 	"github.com/lkysow/go-gitlab"
+	"github.com/pkg/errors"
 )
 
+// GitlabMaxCommentLength is the maximum number of characters GitLab accepts in
+// a note.
+const GitlabMaxCommentLength = 1000000
+
 type GitlabClient struct {
 	Client *gitlab.Client
 }
@@ -61,11 +70,46 @@ func (g *GitlabClient) GetModifiedFiles(repo models.Repo, pull models.PullReques
 }
 
 // CreateComment creates a comment on the merge request.
+// If comment length is greater than the max comment length we split into
+// multiple comments.
 func (g *GitlabClient) CreateComment(repo models.Repo, pullNum int, comment string) error {
-	_, _, err := g.Client.Notes.CreateMergeRequestNote(repo.FullName, pullNum, &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.String(comment)})
+	_, err := g.CreateCommentWithIDs(repo, pullNum, comment)
+	return err
+}
+
+// CreateCommentWithIDs creates a comment like CreateComment and returns the
+// IDs of the notes it was split into.
+func (g *GitlabClient) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	var ids []string
+	comments, err := common.SplitComment(comment, GitlabMaxCommentLength, common.SepEnd, common.SepStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		note, _, err := g.Client.Notes.CreateMergeRequestNote(repo.FullName, pullNum, &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.String(c)})
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, strconv.Itoa(note.ID))
+	}
+	return ids, nil
+}
+
+// UpdateComment replaces the body of the note with id.
+func (g *GitlabClient) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	noteID, err := strconv.Atoi(id)
+	if err != nil {
+		return errors.Wrapf(err, "invalid note id %q", id)
+	}
+	_, _, err = g.Client.Notes.UpdateMergeRequestNote(repo.FullName, pullNum, noteID, &gitlab.UpdateMergeRequestNoteOptions{Body: gitlab.String(comment)})
 	return err
 }
 
+// HideComment isn't supported since GitLab can't hide notes.
+func (g *GitlabClient) HideComment(repo models.Repo, pullNum int, id string) error {
+	return common.ErrHideCommentNotSupported
+}
+
 // PullIsApproved returns true if the merge request was approved.
 func (g *GitlabClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
 	approvals, _, err := g.Client.MergeRequests.GetMergeRequestApprovals(repo.FullName, pull.Num)
@@ -78,10 +122,40 @@ func (g *GitlabClient) PullIsApproved(repo models.Repo, pull models.PullRequest)
 	return true, nil
 }
 
-// UpdateStatus updates the build status of a commit.
-func (g *GitlabClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) error {
-	const statusContext = "Atlantis"
+// GetApprovers returns the users who approved the merge request.
+func (g *GitlabClient) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	approvals, _, err := g.Client.MergeRequests.GetMergeRequestApprovals(repo.FullName, pull.Num)
+	if err != nil {
+		return nil, err
+	}
+	var approvers []string
+	for _, a := range approvals.ApprovedBy {
+		approvers = append(approvers, a.User.Username)
+	}
+	return approvers, nil
+}
 
+// PullIsMergeable returns true if GitLab says the merge request can be merged,
+// ex. it has no conflicts.
+func (g *GitlabClient) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	mr, _, err := g.Client.MergeRequests.GetMergeRequest(repo.FullName, pull.Num)
+	if err != nil {
+		return false, err
+	}
+	return mr.MergeStatus == "can_be_merged", nil
+}
+
+// MergePull accepts the merge request. The merge fails if the merge request's
+// head has moved past the commit we applied.
+func (g *GitlabClient) MergePull(repo models.Repo, pull models.PullRequest) error {
+	_, _, err := g.Client.MergeRequests.AcceptMergeRequest(repo.FullName, pull.Num, &gitlab.AcceptMergeRequestOptions{
+		Sha: gitlab.String(pull.HeadCommit),
+	})
+	return errors.Wrap(err, "unable to merge merge request, it may not be in a mergeable state")
+}
+
+// UpdateStatus updates the build status of a commit.
+func (g *GitlabClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error {
 	gitlabState := gitlab.Failed
 	switch state {
 	case models.PendingCommitStatus:
@@ -93,7 +167,7 @@ func (g *GitlabClient) UpdateStatus(repo models.Repo, pull models.PullRequest, s
 	}
 	_, _, err := g.Client.Commits.SetCommitStatus(repo.FullName, pull.HeadCommit, &gitlab.SetCommitStatusOptions{
 		State:       gitlabState,
-		Context:     gitlab.String(statusContext),
+		Context:     gitlab.String(src),
 		Description: gitlab.String(description),
 	})
 	return err
@@ -104,7 +178,147 @@ func (g *GitlabClient) GetMergeRequest(repoFullName string, pullNum int) (*gitla
 	return mr, err
 }
 
-// GetTeamNamesForUser returns the names of the teams or groups that the user belongs to (in the organization the repository belongs to).
+// GetTeamNamesForUser returns the full paths, ex. "org/devops", of the groups
+// and subgroups under the repo's top-level group that the user is a member
+// of, either directly or through a parent group. If the repo is owned by a
+// user rather than a group, there are no groups so we return nil.
+// Since this takes a few API calls, it should be wrapped by a CachingClient.
 func (g *GitlabClient) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
-	return nil, nil
+	topLevelGroup := strings.Split(repo.Owner, "/")[0]
+	// With an admin token we can list the user's groups in a single query.
+	// Otherwise we have to check the user's membership group by group.
+	teamNames, err := g.listGroupsAsUser(topLevelGroup, user.Username)
+	if err == errGitlabNotAdmin {
+		return g.walkGroupsForUser(topLevelGroup, user.Username)
+	}
+	return teamNames, err
+}
+
+// errGitlabNotAdmin is returned by listGroupsAsUser when our token can't
+// make requests on behalf of other users.
+var errGitlabNotAdmin = errors.New("token is not an admin token")
+
+// listGroupsAsUser lists the groups under topLevelGroup that username has at
+// least guest access to by making the request as that user. This requires an
+// admin token, otherwise errGitlabNotAdmin is returned.
+func (g *GitlabClient) listGroupsAsUser(topLevelGroup string, username string) ([]string, error) {
+	const maxPerPage = 100
+	// The vendored client doesn't support min_access_level so we make the
+	// request ourselves.
+	type listGroupsOptions struct {
+		gitlab.ListOptions
+		MinAccessLevel int `url:"min_access_level"`
+	}
+	var teamNames []string
+	nextPage := 1
+	for {
+		opts := listGroupsOptions{
+			ListOptions:    gitlab.ListOptions{Page: nextPage, PerPage: maxPerPage},
+			MinAccessLevel: int(gitlab.GuestPermissions),
+		}
+		req, err := g.Client.NewRequest("GET", "groups", opts, []gitlab.OptionFunc{gitlab.WithSudo(username)})
+		if err != nil {
+			return nil, err
+		}
+		var page []*gitlab.Group
+		resp, err := g.Client.Do(req, &page)
+		if resp != nil && resp.StatusCode == http.StatusForbidden {
+			return nil, errGitlabNotAdmin
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing groups of %s", username)
+		}
+		for _, group := range page {
+			if group.FullPath == topLevelGroup || strings.HasPrefix(group.FullPath, topLevelGroup+"/") {
+				teamNames = append(teamNames, group.FullPath)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return teamNames, nil
+}
+
+// walkGroupsForUser walks the groups under topLevelGroup and returns those
+// that username is a member of. Once the user is a member of a group they're
+// a member of all its subgroups so we don't check those.
+func (g *GitlabClient) walkGroupsForUser(topLevelGroup string, username string) ([]string, error) {
+	users, _, err := g.Client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.String(username)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "looking up user %s", username)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	userID := users[0].ID
+
+	root, resp, err := g.Client.Groups.GetGroup(topLevelGroup)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting group %s", topLevelGroup)
+	}
+
+	type walkedGroup struct {
+		group     *gitlab.Group
+		inherited bool
+	}
+	var teamNames []string
+	groups := []walkedGroup{{group: root}}
+	for len(groups) > 0 {
+		group := groups[0]
+		groups = groups[1:]
+
+		member := group.inherited
+		if !member {
+			_, resp, err := g.Client.GroupMembers.GetGroupMember(group.group.ID, userID)
+			if err == nil {
+				member = true
+			} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+				return nil, errors.Wrapf(err, "getting membership of %s in group %s", username, group.group.FullPath)
+			}
+		}
+		if member {
+			teamNames = append(teamNames, group.group.FullPath)
+		}
+
+		subgroups, err := g.listSubgroups(group.group.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing subgroups of %s", group.group.FullPath)
+		}
+		for _, subgroup := range subgroups {
+			groups = append(groups, walkedGroup{group: subgroup, inherited: member})
+		}
+	}
+	return teamNames, nil
+}
+
+// listSubgroups returns the direct subgroups of the group with id groupID.
+// The vendored client doesn't support the subgroups API so we make the
+// request ourselves.
+func (g *GitlabClient) listSubgroups(groupID int) ([]*gitlab.Group, error) {
+	const maxPerPage = 100
+	var subgroups []*gitlab.Group
+	nextPage := 1
+	for {
+		opts := gitlab.ListOptions{Page: nextPage, PerPage: maxPerPage}
+		req, err := g.Client.NewRequest("GET", fmt.Sprintf("groups/%d/subgroups", groupID), opts, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []*gitlab.Group
+		resp, err := g.Client.Do(req, &page)
+		if err != nil {
+			return nil, err
+		}
+		subgroups = append(subgroups, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return subgroups, nil
 }
@@ -0,0 +1,107 @@
+package vcs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
+	. "github.com/cloudposse/atlantis/testing"
+	"github.com/lkysow/go-gitlab"
+)
+
+// Should walk the subgroups of the repo's top-level group and return the
+// groups the user is a direct member of.
+func TestGitlabClient_GetTeamNamesForUser(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v4/groups?min_access_level=10&page=1&per_page=100":
+			http.Error(w, `{"message": "403 Forbidden - Must be admin to use sudo"}`, http.StatusForbidden)
+		case "/api/v4/users?username=lkysow":
+			w.Write([]byte(`[{"id": 7, "username": "lkysow"}]`)) // nolint: errcheck
+		case "/api/v4/groups/org":
+			w.Write([]byte(`{"id": 1, "full_path": "org"}`)) // nolint: errcheck
+		case "/api/v4/groups/1/members/7":
+			http.Error(w, `{"message": "404 Not found"}`, http.StatusNotFound)
+		case "/api/v4/groups/1/subgroups?page=1&per_page=100":
+			w.Header().Set("Link", `<https://gitlab.com/api/v4/groups?page=2>; rel="next"`)
+			w.Write([]byte(`[{"id": 2, "full_path": "org/devops"}]`)) // nolint: errcheck
+		case "/api/v4/groups/1/subgroups?page=2&per_page=100":
+			w.Write([]byte(`[{"id": 3, "full_path": "org/frontend"}]`)) // nolint: errcheck
+		case "/api/v4/groups/2/members/7":
+			w.Write([]byte(`{"id": 7, "username": "lkysow"}`)) // nolint: errcheck
+		case "/api/v4/groups/2/subgroups?page=1&per_page=100":
+			w.Write([]byte(`[{"id": 4, "full_path": "org/devops/sre"}]`)) // nolint: errcheck
+		case "/api/v4/groups/3/members/7":
+			http.Error(w, `{"message": "404 Not found"}`, http.StatusNotFound)
+		case "/api/v4/groups/3/subgroups?page=1&per_page=100":
+			w.Write([]byte(`[]`)) // nolint: errcheck
+		case "/api/v4/groups/4/subgroups?page=1&per_page=100":
+			w.Write([]byte(`[]`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := &vcs.GitlabClient{Client: gitlab.NewClient(nil, "token")}
+	Ok(t, client.Client.SetBaseURL(testServer.URL+"/api/v4/"))
+	repo := models.Repo{FullName: "org/devops/repo", Owner: "org/devops", Name: "repo"}
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "lkysow"})
+	Ok(t, err)
+	Equals(t, []string{"org/devops", "org/devops/sre"}, teams)
+}
+
+// With an admin token we should list the user's groups in a single query.
+func TestGitlabClient_GetTeamNamesForUserAdmin(t *testing.T) {
+	numRequests := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		switch r.RequestURI {
+		case "/api/v4/groups?min_access_level=10&page=1&per_page=100":
+			Equals(t, "lkysow", r.Header.Get("SUDO"))
+			w.Header().Set("Link", `<https://gitlab.com/api/v4/groups?page=2>; rel="next"`)
+			w.Write([]byte(`[{"id": 2, "full_path": "org/devops"}, {"id": 5, "full_path": "other"}]`)) // nolint: errcheck
+		case "/api/v4/groups?min_access_level=10&page=2&per_page=100":
+			w.Write([]byte(`[{"id": 4, "full_path": "org/devops/sre"}, {"id": 6, "full_path": "organization"}]`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := &vcs.GitlabClient{Client: gitlab.NewClient(nil, "token")}
+	Ok(t, client.Client.SetBaseURL(testServer.URL+"/api/v4/"))
+	repo := models.Repo{FullName: "org/devops/repo", Owner: "org/devops", Name: "repo"}
+	teams, err := client.GetTeamNamesForUser(repo, models.User{Username: "lkysow"})
+	Ok(t, err)
+	Equals(t, []string{"org/devops", "org/devops/sre"}, teams)
+	Equals(t, 2, numRequests)
+}
+
+// If the repo is owned by a user there are no groups.
+func TestGitlabClient_GetTeamNamesForUserUserNamespace(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v4/groups?min_access_level=10&page=1&per_page=100":
+			http.Error(w, `{"message": "403 Forbidden - Must be admin to use sudo"}`, http.StatusForbidden)
+		case "/api/v4/users?username=lkysow":
+			w.Write([]byte(`[{"id": 7, "username": "lkysow"}]`)) // nolint: errcheck
+		case "/api/v4/groups/lkysow":
+			http.Error(w, `{"message": "404 Group Not Found"}`, http.StatusNotFound)
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := &vcs.GitlabClient{Client: gitlab.NewClient(nil, "token")}
+	Ok(t, client.Client.SetBaseURL(testServer.URL+"/api/v4/"))
+	teams, err := client.GetTeamNamesForUser(models.Repo{Owner: "lkysow", Name: "repo"}, models.User{Username: "lkysow"})
+	Ok(t, err)
+	Equals(t, 0, len(teams))
+}
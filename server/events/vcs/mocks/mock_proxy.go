@@ -62,8 +62,8 @@ func (mock *MockClientProxy) PullIsApproved(repo models.Repo, pull models.PullRe
 	return ret0, ret1
 }
 
-func (mock *MockClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) error {
-	params := []pegomock.Param{repo, pull, state, description}
+func (mock *MockClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error {
+	params := []pegomock.Param{repo, pull, state, src, description}
 	result := pegomock.GetGenericMockFrom(mock).Invoke("UpdateStatus", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
 	var ret0 error
 	if len(result) != 0 {
@@ -74,8 +74,104 @@ func (mock *MockClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequ
 	return ret0
 }
 
+func (mock *MockClientProxy) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	params := []pegomock.Param{repo, pull}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GetApprovers", params, []reflect.Type{reflect.TypeOf((*[]string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []string
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockClientProxy) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	params := []pegomock.Param{repo, pull}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("PullIsMergeable", params, []reflect.Type{reflect.TypeOf((*bool)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 bool
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(bool)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
 func (mock *MockClientProxy) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
-	return nil, nil
+	params := []pegomock.Param{repo, user}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GetTeamNamesForUser", params, []reflect.Type{reflect.TypeOf((*[]string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []string
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockClientProxy) MergePull(repo models.Repo, pull models.PullRequest) error {
+	params := []pegomock.Param{repo, pull}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("MergePull", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockClientProxy) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	params := []pegomock.Param{repo, pullNum, comment}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("CreateCommentWithIDs", params, []reflect.Type{reflect.TypeOf((*[]string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []string
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockClientProxy) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	params := []pegomock.Param{repo, pullNum, id, comment}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("UpdateComment", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockClientProxy) HideComment(repo models.Repo, pullNum int, id string) error {
+	params := []pegomock.Param{repo, pullNum, id}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("HideComment", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
 }
 
 func (mock *MockClientProxy) VerifyWasCalledOnce() *VerifierClientProxy {
@@ -193,8 +289,8 @@ func (c *ClientProxy_PullIsApproved_OngoingVerification) GetAllCapturedArguments
 	return
 }
 
-func (verifier *VerifierClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) *ClientProxy_UpdateStatus_OngoingVerification {
-	params := []pegomock.Param{repo, pull, state, description}
+func (verifier *VerifierClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) *ClientProxy_UpdateStatus_OngoingVerification {
+	params := []pegomock.Param{repo, pull, state, src, description}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "UpdateStatus", params)
 	return &ClientProxy_UpdateStatus_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -204,12 +300,12 @@ type ClientProxy_UpdateStatus_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *ClientProxy_UpdateStatus_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest, models.CommitStatus, string) {
-	repo, pull, state, description := c.GetAllCapturedArguments()
-	return repo[len(repo)-1], pull[len(pull)-1], state[len(state)-1], description[len(description)-1]
+func (c *ClientProxy_UpdateStatus_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest, models.CommitStatus, string, string) {
+	repo, pull, state, src, description := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1], state[len(state)-1], src[len(src)-1], description[len(description)-1]
 }
 
-func (c *ClientProxy_UpdateStatus_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest, _param2 []models.CommitStatus, _param3 []string) {
+func (c *ClientProxy_UpdateStatus_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest, _param2 []models.CommitStatus, _param3 []string, _param4 []string) {
 	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(params) > 0 {
 		_param0 = make([]models.Repo, len(params[0]))
@@ -228,6 +324,243 @@ func (c *ClientProxy_UpdateStatus_OngoingVerification) GetAllCapturedArguments()
 		for u, param := range params[3] {
 			_param3[u] = param.(string)
 		}
+		_param4 = make([]string, len(params[4]))
+		for u, param := range params[4] {
+			_param4[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierClientProxy) GetApprovers(repo models.Repo, pull models.PullRequest) *ClientProxy_GetApprovers_OngoingVerification {
+	params := []pegomock.Param{repo, pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetApprovers", params)
+	return &ClientProxy_GetApprovers_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type ClientProxy_GetApprovers_OngoingVerification struct {
+	mock              *MockClientProxy
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *ClientProxy_GetApprovers_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
+	repo, pull := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1]
+}
+
+func (c *ClientProxy_GetApprovers_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierClientProxy) PullIsMergeable(repo models.Repo, pull models.PullRequest) *ClientProxy_PullIsMergeable_OngoingVerification {
+	params := []pegomock.Param{repo, pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "PullIsMergeable", params)
+	return &ClientProxy_PullIsMergeable_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type ClientProxy_PullIsMergeable_OngoingVerification struct {
+	mock              *MockClientProxy
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *ClientProxy_PullIsMergeable_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
+	repo, pull := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1]
+}
+
+func (c *ClientProxy_PullIsMergeable_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierClientProxy) GetTeamNamesForUser(repo models.Repo, user models.User) *ClientProxy_GetTeamNamesForUser_OngoingVerification {
+	params := []pegomock.Param{repo, user}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetTeamNamesForUser", params)
+	return &ClientProxy_GetTeamNamesForUser_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type ClientProxy_GetTeamNamesForUser_OngoingVerification struct {
+	mock              *MockClientProxy
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *ClientProxy_GetTeamNamesForUser_OngoingVerification) GetCapturedArguments() (models.Repo, models.User) {
+	repo, user := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], user[len(user)-1]
+}
+
+func (c *ClientProxy_GetTeamNamesForUser_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.User) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.User, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.User)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierClientProxy) MergePull(repo models.Repo, pull models.PullRequest) *ClientProxy_MergePull_OngoingVerification {
+	params := []pegomock.Param{repo, pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "MergePull", params)
+	return &ClientProxy_MergePull_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type ClientProxy_MergePull_OngoingVerification struct {
+	mock              *MockClientProxy
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *ClientProxy_MergePull_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
+	repo, pull := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1]
+}
+
+func (c *ClientProxy_MergePull_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierClientProxy) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) *ClientProxy_CreateCommentWithIDs_OngoingVerification {
+	params := []pegomock.Param{repo, pullNum, comment}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "CreateCommentWithIDs", params)
+	return &ClientProxy_CreateCommentWithIDs_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type ClientProxy_CreateCommentWithIDs_OngoingVerification struct {
+	mock              *MockClientProxy
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *ClientProxy_CreateCommentWithIDs_OngoingVerification) GetCapturedArguments() (models.Repo, int, string) {
+	repo, pullNum, comment := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pullNum[len(pullNum)-1], comment[len(comment)-1]
+}
+
+func (c *ClientProxy_CreateCommentWithIDs_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []int, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]int, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(int)
+		}
+		_param2 = make([]string, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierClientProxy) UpdateComment(repo models.Repo, pullNum int, id string, comment string) *ClientProxy_UpdateComment_OngoingVerification {
+	params := []pegomock.Param{repo, pullNum, id, comment}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "UpdateComment", params)
+	return &ClientProxy_UpdateComment_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type ClientProxy_UpdateComment_OngoingVerification struct {
+	mock              *MockClientProxy
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *ClientProxy_UpdateComment_OngoingVerification) GetCapturedArguments() (models.Repo, int, string, string) {
+	repo, pullNum, id, comment := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pullNum[len(pullNum)-1], id[len(id)-1], comment[len(comment)-1]
+}
+
+func (c *ClientProxy_UpdateComment_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []int, _param2 []string, _param3 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]int, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(int)
+		}
+		_param2 = make([]string, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+		_param3 = make([]string, len(params[3]))
+		for u, param := range params[3] {
+			_param3[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierClientProxy) HideComment(repo models.Repo, pullNum int, id string) *ClientProxy_HideComment_OngoingVerification {
+	params := []pegomock.Param{repo, pullNum, id}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "HideComment", params)
+	return &ClientProxy_HideComment_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type ClientProxy_HideComment_OngoingVerification struct {
+	mock              *MockClientProxy
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *ClientProxy_HideComment_OngoingVerification) GetCapturedArguments() (models.Repo, int, string) {
+	repo, pullNum, id := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pullNum[len(pullNum)-1], id[len(id)-1]
+}
+
+func (c *ClientProxy_HideComment_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []int, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]int, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(int)
+		}
+		_param2 = make([]string, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
 	}
 	return
 }
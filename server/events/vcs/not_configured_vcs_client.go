@@ -32,10 +32,28 @@ func (a *NotConfiguredVCSClient) GetModifiedFiles(repo models.Repo, pull models.
 func (a *NotConfiguredVCSClient) CreateComment(repo models.Repo, pullNum int, comment string) error {
 	return a.err()
 }
+func (a *NotConfiguredVCSClient) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	return nil, a.err()
+}
+func (a *NotConfiguredVCSClient) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	return a.err()
+}
+func (a *NotConfiguredVCSClient) HideComment(repo models.Repo, pullNum int, id string) error {
+	return a.err()
+}
 func (a *NotConfiguredVCSClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
 	return false, a.err()
 }
-func (a *NotConfiguredVCSClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) error {
+func (a *NotConfiguredVCSClient) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	return nil, a.err()
+}
+func (a *NotConfiguredVCSClient) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return false, a.err()
+}
+func (a *NotConfiguredVCSClient) MergePull(repo models.Repo, pull models.PullRequest) error {
+	return a.err()
+}
+func (a *NotConfiguredVCSClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error {
 	return a.err()
 }
 func (a *NotConfiguredVCSClient) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
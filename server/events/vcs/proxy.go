@@ -24,8 +24,14 @@ import (
 type ClientProxy interface {
 	GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error)
 	CreateComment(repo models.Repo, pullNum int, comment string) error
+	CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error)
+	UpdateComment(repo models.Repo, pullNum int, id string, comment string) error
+	HideComment(repo models.Repo, pullNum int, id string) error
 	PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error)
-	UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) error
+	GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error)
+	PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error)
+	MergePull(repo models.Repo, pull models.PullRequest) error
+	UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error
 	GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error)
 }
 
@@ -35,9 +41,19 @@ type DefaultClientProxy struct {
 	// clients maps from the vcs host type to the client that implements the
 	// api for that host type, ex. github -> github client.
 	clients map[models.VCSHostType]Client
+	// hostClients maps from the vcs host type and hostname to the client for
+	// an additional host of that type, ex. github/github.example.com -> github
+	// enterprise client. Repos on other hostnames use clients.
+	hostClients map[vcsHostKey]Client
 }
 
-func NewDefaultClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client) *DefaultClientProxy {
+// vcsHostKey identifies a VCS host.
+type vcsHostKey struct {
+	hostType models.VCSHostType
+	hostname string
+}
+
+func NewDefaultClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, giteaClient Client, azureDevopsClient Client) *DefaultClientProxy {
 	if githubClient == nil {
 		githubClient = &NotConfiguredVCSClient{}
 	}
@@ -50,32 +66,80 @@ func NewDefaultClientProxy(githubClient Client, gitlabClient Client, bitbucketCl
 	if bitbucketServerClient == nil {
 		bitbucketServerClient = &NotConfiguredVCSClient{}
 	}
+	if giteaClient == nil {
+		giteaClient = &NotConfiguredVCSClient{}
+	}
+	if azureDevopsClient == nil {
+		azureDevopsClient = &NotConfiguredVCSClient{}
+	}
 	return &DefaultClientProxy{
 		clients: map[models.VCSHostType]Client{
 			models.Github:          githubClient,
 			models.Gitlab:          gitlabClient,
 			models.BitbucketCloud:  bitbucketCloudClient,
 			models.BitbucketServer: bitbucketServerClient,
+			models.Gitea:           giteaClient,
+			models.AzureDevops:     azureDevopsClient,
 		},
+		hostClients: make(map[vcsHostKey]Client),
 	}
 }
 
+// AddHost adds the client for an additional host of type hostType. Calls for
+// repos on hostname will go to client instead of the default client for
+// hostType.
+func (d *DefaultClientProxy) AddHost(hostType models.VCSHostType, hostname string, client Client) {
+	d.hostClients[vcsHostKey{hostType: hostType, hostname: hostname}] = client
+}
+
+// client returns the client for repo's VCS host.
+func (d *DefaultClientProxy) client(repo models.Repo) Client {
+	if c, ok := d.hostClients[vcsHostKey{hostType: repo.VCSHost.Type, hostname: repo.VCSHost.Hostname}]; ok {
+		return c
+	}
+	return d.clients[repo.VCSHost.Type]
+}
+
 func (d *DefaultClientProxy) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
-	return d.clients[repo.VCSHost.Type].GetModifiedFiles(repo, pull)
+	return d.client(repo).GetModifiedFiles(repo, pull)
 }
 
 func (d *DefaultClientProxy) CreateComment(repo models.Repo, pullNum int, comment string) error {
-	return d.clients[repo.VCSHost.Type].CreateComment(repo, pullNum, comment)
+	return d.client(repo).CreateComment(repo, pullNum, comment)
+}
+
+func (d *DefaultClientProxy) CreateCommentWithIDs(repo models.Repo, pullNum int, comment string) ([]string, error) {
+	return d.client(repo).CreateCommentWithIDs(repo, pullNum, comment)
+}
+
+func (d *DefaultClientProxy) UpdateComment(repo models.Repo, pullNum int, id string, comment string) error {
+	return d.client(repo).UpdateComment(repo, pullNum, id, comment)
+}
+
+func (d *DefaultClientProxy) HideComment(repo models.Repo, pullNum int, id string) error {
+	return d.client(repo).HideComment(repo, pullNum, id)
 }
 
 func (d *DefaultClientProxy) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
-	return d.clients[repo.VCSHost.Type].PullIsApproved(repo, pull)
+	return d.client(repo).PullIsApproved(repo, pull)
+}
+
+func (d *DefaultClientProxy) GetApprovers(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	return d.client(repo).GetApprovers(repo, pull)
+}
+
+func (d *DefaultClientProxy) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return d.client(repo).PullIsMergeable(repo, pull)
+}
+
+func (d *DefaultClientProxy) MergePull(repo models.Repo, pull models.PullRequest) error {
+	return d.client(repo).MergePull(repo, pull)
 }
 
-func (d *DefaultClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, description string) error {
-	return d.clients[repo.VCSHost.Type].UpdateStatus(repo, pull, state, description)
+func (d *DefaultClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string) error {
+	return d.client(repo).UpdateStatus(repo, pull, state, src, description)
 }
 
 func (d *DefaultClientProxy) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
-	return d.clients[repo.VCSHost.Type].GetTeamNamesForUser(repo, user)
+	return d.client(repo).GetTeamNamesForUser(repo, user)
 }
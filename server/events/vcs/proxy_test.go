@@ -0,0 +1,58 @@
+package vcs_test
+
+import (
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/cloudposse/atlantis/server/events/vcs/mocks"
+	. "github.com/cloudposse/atlantis/testing"
+	. "github.com/petergtz/pegomock"
+)
+
+// Repos on an additional host should use its client and other repos of the
+// same type should use the default client.
+func TestDefaultClientProxy_AddHost(t *testing.T) {
+	RegisterMockTestingT(t)
+	defaultClient := mocks.NewMockClient()
+	enterpriseClient := mocks.NewMockClient()
+	proxy := vcs.NewDefaultClientProxy(defaultClient, nil, nil, nil, nil, nil)
+	proxy.AddHost(models.Github, "github.example.com", enterpriseClient)
+
+	githubRepo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost:  models.VCSHost{Type: models.Github, Hostname: "github.com"},
+	}
+	enterpriseRepo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost:  models.VCSHost{Type: models.Github, Hostname: "github.example.com"},
+	}
+	proxy.CreateComment(githubRepo, 1, "comment")     // nolint: errcheck
+	proxy.CreateComment(enterpriseRepo, 1, "comment") // nolint: errcheck
+
+	defaultClient.VerifyWasCalledOnce().CreateComment(githubRepo, 1, "comment")
+	defaultClient.VerifyWasCalled(Never()).CreateComment(enterpriseRepo, 1, "comment")
+	enterpriseClient.VerifyWasCalledOnce().CreateComment(enterpriseRepo, 1, "comment")
+}
+
+// A host of a type without a default client should still be used.
+func TestDefaultClientProxy_AddHostWithoutDefault(t *testing.T) {
+	RegisterMockTestingT(t)
+	gitlabClient := mocks.NewMockClient()
+	proxy := vcs.NewDefaultClientProxy(nil, nil, nil, nil, nil, nil)
+	proxy.AddHost(models.Gitlab, "gitlab.example.com", gitlabClient)
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost:  models.VCSHost{Type: models.Gitlab, Hostname: "gitlab.example.com"},
+	}
+	proxy.CreateComment(repo, 1, "comment") // nolint: errcheck
+	gitlabClient.VerifyWasCalledOnce().CreateComment(repo, 1, "comment")
+
+	otherRepo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost:  models.VCSHost{Type: models.Gitlab, Hostname: "gitlab.com"},
+	}
+	err := proxy.CreateComment(otherRepo, 1, "comment")
+	Assert(t, err != nil, "expected an error since gitlab.com isn't configured")
+}
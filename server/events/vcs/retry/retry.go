@@ -0,0 +1,302 @@
+// Package retry retries VCS API requests that fail because of transient
+// errors or rate limits.
+package retry
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/logging"
+)
+
+const (
+	// DefaultMaxRetries is how many times we retry a request by default.
+	DefaultMaxRetries = 3
+	// defaultBaseDelay is how long we wait before the first retry if the
+	// host doesn't tell us how long to wait. It doubles on every retry.
+	defaultBaseDelay = 500 * time.Millisecond
+	// defaultMaxDelay caps the exponential backoff.
+	defaultMaxDelay = 10 * time.Second
+	// defaultMaxWait is the longest we'll wait when a host tells us how long
+	// to wait. If a rate limit won't reset for longer than this, we return
+	// the response instead of blocking the command.
+	defaultMaxWait = time.Minute
+)
+
+// Stats counts the requests made through a Transport. It's safe for
+// concurrent use.
+type Stats struct {
+	// Requests is the number of requests made, not including retries.
+	Requests int64 `json:"requests"`
+	// Retries is the number of times a request was retried.
+	Retries int64 `json:"retries"`
+	// RateLimited is the number of responses that said we were rate limited.
+	RateLimited int64 `json:"rate_limited"`
+	// Failures is the number of requests that still failed after retrying.
+	Failures int64 `json:"failures"`
+}
+
+// Snapshot returns a copy of the current counts.
+func (s *Stats) Snapshot() Stats {
+	return Stats{
+		Requests:    atomic.LoadInt64(&s.Requests),
+		Retries:     atomic.LoadInt64(&s.Retries),
+		RateLimited: atomic.LoadInt64(&s.RateLimited),
+		Failures:    atomic.LoadInt64(&s.Failures),
+	}
+}
+
+// Transport is an http.RoundTripper that retries requests with exponential
+// backoff. Rate limited requests are retried after the time the host asks us
+// to wait for in its Retry-After or rate limit reset headers. Server errors
+// and network errors are only retried for idempotent methods since the host
+// might have processed the request, ex. a comment might have been created.
+type Transport struct {
+	// Underlying makes the requests. If nil, http.DefaultTransport is used.
+	Underlying http.RoundTripper
+	// MaxRetries is how many times a request is retried. If 0, requests
+	// aren't retried.
+	MaxRetries int
+	// BaseDelay, MaxDelay and MaxWait override the defaults if set.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	MaxWait   time.Duration
+	// Logger logs when we're throttled or retrying. Can be nil.
+	Logger logging.SimpleLogging
+	// Stats is updated with every request. Can be nil.
+	Stats *Stats
+}
+
+// NewTransport returns a Transport that retries up to maxRetries times.
+func NewTransport(underlying http.RoundTripper, maxRetries int, logger logging.SimpleLogging, stats *Stats) *Transport {
+	return &Transport{
+		Underlying: underlying,
+		MaxRetries: maxRetries,
+		Logger:     logger,
+		Stats:      stats,
+	}
+}
+
+// RoundTrip makes the request, retrying it if it fails.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count(func(s *Stats) *int64 { return &s.Requests })
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := t.rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.underlying().RoundTrip(attemptReq)
+
+		rateLimited := err == nil && isRateLimited(resp)
+		if rateLimited {
+			t.count(func(s *Stats) *int64 { return &s.RateLimited })
+		}
+		if !t.shouldRetry(req, resp, err, rateLimited) {
+			if err != nil || resp.StatusCode >= 500 || rateLimited {
+				t.count(func(s *Stats) *int64 { return &s.Failures })
+			}
+			return resp, err
+		}
+
+		delay, ok := t.delay(resp, attempt)
+		if attempt >= t.MaxRetries || !ok || !canRewind(req) {
+			t.count(func(s *Stats) *int64 { return &s.Failures })
+			if rateLimited {
+				t.log("%s %s was rate limited by %s and won't be retried", req.Method, req.URL.Path, req.URL.Host)
+			}
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so the connection can be reused.
+			io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck
+			resp.Body.Close()                  // nolint: errcheck
+		}
+
+		reason := "rate limited"
+		if err != nil {
+			reason = err.Error()
+		} else if !rateLimited {
+			reason = resp.Status
+		}
+		t.log("%s %s to %s failed (%s), retrying in %s (retry %d/%d)", req.Method, req.URL.Path, req.URL.Host, reason, delay, attempt+1, t.MaxRetries)
+		t.count(func(s *Stats) *int64 { return &s.Retries })
+		if err := t.wait(req, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry returns true if the request failed in a way that retrying
+// might fix.
+func (t *Transport) shouldRetry(req *http.Request, resp *http.Response, err error, rateLimited bool) bool {
+	if rateLimited {
+		return true
+	}
+	if !isIdempotent(req.Method) {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait before the next attempt. ok is false if the
+// host asked us to wait for longer than MaxWait.
+func (t *Transport) delay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp != nil {
+		if d, found := retryAfter(resp); found {
+			return d, d <= t.maxWait()
+		}
+	}
+
+	base := t.BaseDelay
+	if base == 0 {
+		base = defaultBaseDelay
+	}
+	max := t.MaxDelay
+	if max == 0 {
+		max = defaultMaxDelay
+	}
+	d := base << uint(attempt)
+	if d > max || d <= 0 {
+		d = max
+	}
+	// Add up to 25% jitter so concurrent commands don't retry in lockstep.
+	d += time.Duration(rand.Int63n(int64(d)/4 + 1))
+	return d, true
+}
+
+func (t *Transport) maxWait() time.Duration {
+	if t.MaxWait == 0 {
+		return defaultMaxWait
+	}
+	return t.MaxWait
+}
+
+// rewind returns the request to send for attempt. Every attempt after the
+// first needs a fresh copy of the body.
+func (t *Transport) rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	// RoundTrippers mustn't modify the request so we send a shallow copy with
+	// its own headers.
+	req2 := new(http.Request)
+	*req2 = *req
+	req2.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		req2.Header[k] = append([]string(nil), v...)
+	}
+	req2.Body = body
+	return req2, nil
+}
+
+// wait sleeps for d or until the request is cancelled.
+func (t *Transport) wait(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+func (t *Transport) underlying() http.RoundTripper {
+	if t.Underlying == nil {
+		return http.DefaultTransport
+	}
+	return t.Underlying
+}
+
+func (t *Transport) count(field func(s *Stats) *int64) {
+	if t.Stats != nil {
+		atomic.AddInt64(field(t.Stats), 1)
+	}
+}
+
+func (t *Transport) log(format string, a ...interface{}) {
+	if t.Logger != nil {
+		t.Logger.Warn(format, a...)
+	}
+}
+
+// canRewind returns true if the request's body can be sent again.
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// isRateLimited returns true if resp says we've been rate limited. GitHub
+// responds with a 403 for both its primary and secondary rate limits so we
+// look at the headers to tell them apart from permission errors.
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		if resp.Header.Get("Retry-After") != "" {
+			return true
+		}
+		return rateLimitRemaining(resp) == "0"
+	}
+	return false
+}
+
+// retryAfter returns how long resp asks us to wait before retrying.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return nonNegative(time.Until(at)), true
+		}
+	}
+	if rateLimitRemaining(resp) == "0" {
+		// GitHub uses X-RateLimit-Reset and GitLab uses RateLimit-Reset, both
+		// as a Unix timestamp.
+		for _, h := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+			if secs, err := strconv.ParseInt(resp.Header.Get(h), 10, 64); err == nil {
+				return nonNegative(time.Until(time.Unix(secs, 0))), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func rateLimitRemaining(resp *http.Response) string {
+	if v := resp.Header.Get("X-RateLimit-Remaining"); v != "" {
+		return v
+	}
+	return resp.Header.Get("RateLimit-Remaining")
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,173 @@
+package retry_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/events/vcs/retry"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+// newTransport returns a transport with short delays so the tests run
+// quickly.
+func newTransport(stats *retry.Stats) *retry.Transport {
+	return &retry.Transport{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Millisecond,
+		MaxWait:    5 * time.Second,
+		Stats:      stats,
+	}
+}
+
+// testServer responds with the responses in order, then with 200s. It
+// returns the number of requests it received.
+func testServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *int64) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		Ok(t, err)
+		Equals(t, r.Header.Get("X-Body"), string(body))
+		i := int(atomic.AddInt64(&calls, 1)) - 1
+		if i < len(responses) {
+			responses[i](w)
+			return
+		}
+		w.Write([]byte("ok")) // nolint: errcheck
+	}))
+	return server, &calls
+}
+
+func status(code int, headers ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+	}
+}
+
+func do(t *testing.T, transport *retry.Transport, method string, url string, body string) *http.Response {
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	Ok(t, err)
+	req.Header.Set("X-Body", body)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	Ok(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+	return resp
+}
+
+func TestTransport_Success(t *testing.T) {
+	server, calls := testServer(t)
+	defer server.Close()
+	stats := &retry.Stats{}
+	resp := do(t, newTransport(stats), "GET", server.URL, "")
+	Equals(t, http.StatusOK, resp.StatusCode)
+	Equals(t, int64(1), *calls)
+	Equals(t, retry.Stats{Requests: 1}, stats.Snapshot())
+}
+
+func TestTransport_RetriesServerErrors(t *testing.T) {
+	server, calls := testServer(t, status(http.StatusBadGateway), status(http.StatusServiceUnavailable))
+	defer server.Close()
+	stats := &retry.Stats{}
+	resp := do(t, newTransport(stats), "GET", server.URL, "")
+	Equals(t, http.StatusOK, resp.StatusCode)
+	Equals(t, int64(3), *calls)
+	Equals(t, retry.Stats{Requests: 1, Retries: 2}, stats.Snapshot())
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	fail := status(http.StatusInternalServerError)
+	server, calls := testServer(t, fail, fail, fail, fail, fail)
+	defer server.Close()
+	stats := &retry.Stats{}
+	resp := do(t, newTransport(stats), "GET", server.URL, "")
+	Equals(t, http.StatusInternalServerError, resp.StatusCode)
+	Equals(t, int64(4), *calls)
+	Equals(t, retry.Stats{Requests: 1, Retries: 3, Failures: 1}, stats.Snapshot())
+}
+
+// We shouldn't retry requests that might create something twice.
+func TestTransport_DoesNotRetryPostOnServerError(t *testing.T) {
+	server, calls := testServer(t, status(http.StatusBadGateway))
+	defer server.Close()
+	resp := do(t, newTransport(nil), "POST", server.URL, "comment")
+	Equals(t, http.StatusBadGateway, resp.StatusCode)
+	Equals(t, int64(1), *calls)
+}
+
+func TestTransport_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := testServer(t, status(http.StatusNotFound), status(http.StatusForbidden))
+	defer server.Close()
+	resp := do(t, newTransport(nil), "GET", server.URL, "")
+	Equals(t, http.StatusNotFound, resp.StatusCode)
+	resp = do(t, newTransport(nil), "GET", server.URL, "")
+	Equals(t, http.StatusForbidden, resp.StatusCode)
+	Equals(t, int64(2), *calls)
+}
+
+// Rate limited requests were never processed so they can be retried even if
+// they aren't idempotent. The body should be sent again.
+func TestTransport_RetriesRateLimitedPost(t *testing.T) {
+	cases := []struct {
+		description string
+		response    func(w http.ResponseWriter)
+	}{
+		{
+			"429",
+			status(http.StatusTooManyRequests),
+		},
+		{
+			"github secondary rate limit",
+			status(http.StatusForbidden, "Retry-After", "0"),
+		},
+		{
+			"github primary rate limit",
+			status(http.StatusForbidden, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10)),
+		},
+		{
+			"gitlab rate limit",
+			status(http.StatusTooManyRequests, "RateLimit-Remaining", "0", "RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10)),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			server, calls := testServer(t, c.response)
+			defer server.Close()
+			stats := &retry.Stats{}
+			resp := do(t, newTransport(stats), "POST", server.URL, "comment")
+			Equals(t, http.StatusOK, resp.StatusCode)
+			Equals(t, int64(2), *calls)
+			Equals(t, retry.Stats{Requests: 1, Retries: 1, RateLimited: 1}, stats.Snapshot())
+		})
+	}
+}
+
+// If the host asks us to wait for longer than MaxWait, we return the response
+// instead of blocking.
+func TestTransport_DoesNotWaitPastMaxWait(t *testing.T) {
+	server, calls := testServer(t, status(http.StatusTooManyRequests, "Retry-After", "3600"))
+	defer server.Close()
+	stats := &retry.Stats{}
+	resp := do(t, newTransport(stats), "GET", server.URL, "")
+	Equals(t, http.StatusTooManyRequests, resp.StatusCode)
+	Equals(t, int64(1), *calls)
+	Equals(t, retry.Stats{Requests: 1, RateLimited: 1, Failures: 1}, stats.Snapshot())
+}
+
+func TestTransport_NoRetries(t *testing.T) {
+	server, calls := testServer(t, status(http.StatusBadGateway))
+	defer server.Close()
+	transport := newTransport(nil)
+	transport.MaxRetries = 0
+	resp := do(t, transport, "GET", server.URL, "")
+	Equals(t, http.StatusBadGateway, resp.StatusCode)
+	Equals(t, int64(1), *calls)
+}
@@ -1 +1,28 @@
 package vcs
+
+import (
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/azuredevops"
+	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketcloud"
+	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/cloudposse/atlantis/server/events/vcs/gitea"
+)
+
+// MaxCommentLength returns the maximum number of characters that fit in a
+// single comment on hostType. Longer comments are split by the clients.
+func MaxCommentLength(hostType models.VCSHostType) int {
+	switch hostType {
+	case models.Gitlab:
+		return GitlabMaxCommentLength
+	case models.BitbucketCloud:
+		return bitbucketcloud.MaxCommentLength
+	case models.BitbucketServer:
+		return bitbucketserver.MaxCommentLength
+	case models.Gitea:
+		return gitea.MaxCommentLength
+	case models.AzureDevops:
+		return azuredevops.MaxCommentLength
+	default:
+		return GithubMaxCommentLength
+	}
+}
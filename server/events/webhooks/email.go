@@ -0,0 +1,282 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/pkg/errors"
+)
+
+// The STARTTLS modes of SMTPConfig.
+const (
+	// StartTLSRequired fails if the server doesn't support STARTTLS.
+	StartTLSRequired = "required"
+	// StartTLSOpportunistic uses STARTTLS if the server supports it.
+	StartTLSOpportunistic = "opportunistic"
+	// StartTLSDisabled never uses STARTTLS.
+	StartTLSDisabled = "disabled"
+)
+
+const (
+	// DefaultSMTPPort is the port used if SMTPConfig.Port is 0. It's the
+	// submission port.
+	DefaultSMTPPort = 587
+	// DefaultEmailTimeout is how long sending an email can take.
+	DefaultEmailTimeout = 10 * time.Second
+)
+
+// SMTPConfig is how to connect to the SMTP server that emails are sent
+// through.
+type SMTPConfig struct {
+	Host string
+	// Port defaults to DefaultSMTPPort.
+	Port int
+	// Username and Password are used to authenticate with PLAIN auth. If
+	// Username is empty, no authentication is done.
+	Username string
+	Password string
+	// From is the address emails are sent from, ex. atlantis@example.com.
+	From string
+	// StartTLS is one of StartTLSRequired, StartTLSOpportunistic or
+	// StartTLSDisabled. It defaults to StartTLSRequired.
+	StartTLS string
+}
+
+// EmailWebhook sends emails through an SMTP server.
+type EmailWebhook struct {
+	Filter Filter
+	SMTP   SMTPConfig
+	// To are the addresses each email is sent to.
+	To []string
+	// TLSConfig is used for STARTTLS. If nil, the server's certificate is
+	// verified against the system's roots.
+	TLSConfig *tls.Config
+	Timeout   time.Duration
+}
+
+// NewEmail returns an EmailWebhook after validating its configuration.
+func NewEmail(filter Filter, to []string, config SMTPConfig) (*EmailWebhook, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, errors.New("must specify top-level \"smtp\" \"host\" and \"from\" if using a webhook of \"kind: email\"")
+	}
+	if len(to) == 0 {
+		return nil, errors.New("must specify \"to\" if using a webhook of \"kind: email\"")
+	}
+	switch config.StartTLS {
+	case "":
+		config.StartTLS = StartTLSRequired
+	case StartTLSRequired, StartTLSOpportunistic, StartTLSDisabled:
+	default:
+		return nil, fmt.Errorf("\"smtp\" \"starttls: %s\" not supported. Supported values are %s, %s and %s", config.StartTLS, StartTLSRequired, StartTLSOpportunistic, StartTLSDisabled)
+	}
+	if config.Port == 0 {
+		config.Port = DefaultSMTPPort
+	}
+	return &EmailWebhook{
+		Filter:  filter,
+		SMTP:    config,
+		To:      to,
+		Timeout: DefaultEmailTimeout,
+	}, nil
+}
+
+// Send emails a summary of the event if it matches the filter.
+func (e *EmailWebhook) Send(log *logging.SimpleLogger, event Event) error {
+	if !e.Filter.Matches(event) {
+		return nil
+	}
+	subject, text, htmlBody := emailContent(event)
+	msg, err := e.message(subject, text, htmlBody)
+	if err != nil {
+		return errors.Wrap(err, "building email")
+	}
+	if err := e.sendMail(msg); err != nil {
+		return errors.Wrapf(err, "sending email through %s", e.SMTP.Host)
+	}
+	return nil
+}
+
+// sendMail sends msg to e.To. It's like smtp.SendMail but lets STARTTLS be
+// configured and times out.
+func (e *EmailWebhook) sendMail(msg []byte) error {
+	addr := net.JoinHostPort(e.SMTP.Host, strconv.Itoa(e.SMTP.Port))
+	conn, err := net.DialTimeout("tcp", addr, e.Timeout)
+	if err != nil {
+		return err
+	}
+	if e.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(e.Timeout)) // nolint: errcheck
+	}
+	c, err := smtp.NewClient(conn, e.SMTP.Host)
+	if err != nil {
+		conn.Close() // nolint: errcheck
+		return err
+	}
+	defer c.Close() // nolint: errcheck
+
+	if e.SMTP.StartTLS != StartTLSDisabled {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			tlsConfig := e.TLSConfig
+			if tlsConfig == nil {
+				tlsConfig = &tls.Config{ServerName: e.SMTP.Host} // nolint: gosec
+			}
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		} else if e.SMTP.StartTLS == StartTLSRequired {
+			return errors.New("server doesn't support STARTTLS")
+		}
+	}
+	if e.SMTP.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.SMTP.Username, e.SMTP.Password, e.SMTP.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.SMTP.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message returns a multipart email with text and html versions of the body.
+func (e *EmailWebhook) message(subject string, text string, htmlBody string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.SMTP.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes()) // nolint: errcheck
+	return msg.Bytes(), nil
+}
+
+// emailContent returns the subject and the text and html bodies of the email
+// for event.
+func emailContent(event Event) (subject string, text string, htmlBody string) {
+	info := event.EventInfo()
+	pull := fmt.Sprintf("%s#%d", info.Repo.FullName, info.Pull.Num)
+
+	var summary string
+	var output string
+	switch e := event.(type) {
+	case ApplyResult:
+		summary = fmt.Sprintf("Apply %s", successWord(e.Success))
+		output = e.Output
+	case PlanResult:
+		summary = fmt.Sprintf("Plan %s", successWord(e.Success))
+		output = e.Output
+	case AutoplanStart:
+		summary = fmt.Sprintf("Autoplan started for %d project(s)", len(e.Projects))
+	case AutoplanFinish:
+		summary = fmt.Sprintf("Autoplan %s: %d of %d project(s) failed", successWord(e.Success), e.FailedProjects, e.Projects)
+	case LockResult:
+		summary = "Project unlocked"
+		if e.Locked {
+			summary = "Project locked"
+		}
+	case CommandFailure:
+		summary = fmt.Sprintf("%s failed", strings.Title(e.Command))
+		output = e.Error
+	default:
+		summary = event.EventType()
+	}
+	subject = fmt.Sprintf("[Atlantis] %s for %s", summary, pull)
+	if info.Dir != "" {
+		subject += fmt.Sprintf(" (%s, workspace %s)", info.Dir, info.Workspace)
+	}
+
+	var details [][2]string
+	if info.Project != "" {
+		details = append(details, [2]string{"Project", info.Project})
+	}
+	if info.Dir != "" {
+		details = append(details, [2]string{"Dir", info.Dir}, [2]string{"Workspace", info.Workspace})
+	}
+	var changes *models.PlanChanges
+	switch e := event.(type) {
+	case ApplyResult:
+		changes = e.Changes
+	case PlanResult:
+		changes = e.Changes
+	}
+	if changes != nil {
+		details = append(details, [2]string{"Changes", fmt.Sprintf("%d to add, %d to change, %d to destroy", changes.Add, changes.Change, changes.Destroy)})
+	}
+	details = append(details, [2]string{"User", info.User.Username}, [2]string{"Pull request", info.Pull.URL})
+	output, truncated := truncateOutput(output)
+
+	var t, h strings.Builder
+	fmt.Fprintf(&t, "%s for %s\n\n", summary, pull)
+	fmt.Fprintf(&h, "<p>%s for <a href=\"%s\">%s</a></p>\n<table>\n", html.EscapeString(summary), html.EscapeString(info.Pull.URL), html.EscapeString(pull))
+	for _, d := range details {
+		fmt.Fprintf(&t, "%s: %s\n", d[0], d[1])
+		fmt.Fprintf(&h, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\n", html.EscapeString(d[0]), html.EscapeString(d[1]))
+	}
+	h.WriteString("</table>\n")
+	if output != "" {
+		note := ""
+		if truncated {
+			note = " (truncated)"
+		}
+		fmt.Fprintf(&t, "\nOutput%s:\n%s\n", note, output)
+		fmt.Fprintf(&h, "<p>Output%s:</p>\n<pre>%s</pre>\n", note, html.EscapeString(output))
+	}
+	return subject, t.String(), h.String()
+}
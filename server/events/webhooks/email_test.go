@@ -0,0 +1,287 @@
+package webhooks_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/webhooks"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+// smtpMessage is a message received by smtpServer.
+type smtpMessage struct {
+	from string
+	to   []string
+	// auth is the decoded PLAIN auth response, ex. "\x00user\x00pass".
+	auth string
+	tls  bool
+	data string
+}
+
+// smtpServer is an in-process stand-in for an SMTP server. It implements
+// just enough of the protocol for net/smtp.
+type smtpServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	mutex     sync.Mutex
+	messages  []smtpMessage
+}
+
+// newSMTPServer starts an SMTP server. If tlsConfig is set, it supports
+// STARTTLS.
+func newSMTPServer(t *testing.T, tlsConfig *tls.Config) *smtpServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Ok(t, err)
+	s := &smtpServer{listener: l, tlsConfig: tlsConfig}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) received() []smtpMessage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.messages
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close() // nolint: errcheck
+	text := textproto.NewConn(conn)
+	var msg smtpMessage
+	text.PrintfLine("220 localhost ESMTP") // nolint: errcheck
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-localhost") // nolint: errcheck
+			if s.tlsConfig != nil && !msg.tls {
+				text.PrintfLine("250-STARTTLS") // nolint: errcheck
+			}
+			text.PrintfLine("250 AUTH PLAIN") // nolint: errcheck
+		case "STARTTLS":
+			text.PrintfLine("220 Ready to start TLS") // nolint: errcheck
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			msg.tls = true
+		case "AUTH":
+			parts := strings.Fields(line)
+			decoded, _ := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+			msg.auth = string(decoded)
+			text.PrintfLine("235 Authenticated") // nolint: errcheck
+		case "MAIL":
+			msg.from = strings.Trim(strings.SplitN(line, ":", 2)[1], "<>")
+			text.PrintfLine("250 OK") // nolint: errcheck
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.SplitN(line, ":", 2)[1], "<>"))
+			text.PrintfLine("250 OK") // nolint: errcheck
+		case "DATA":
+			text.PrintfLine("354 Send data") // nolint: errcheck
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			s.mutex.Lock()
+			s.messages = append(s.messages, msg)
+			s.mutex.Unlock()
+			text.PrintfLine("250 OK") // nolint: errcheck
+		case "QUIT":
+			text.PrintfLine("221 Bye") // nolint: errcheck
+			return
+		default:
+			text.PrintfLine("250 OK") // nolint: errcheck
+		}
+	}
+}
+
+var emailResult = webhooks.ApplyResult{
+	Workspace: "production",
+	Dir:       "prod",
+	Repo:      models.Repo{FullName: "runatlantis/atlantis"},
+	Pull:      models.PullRequest{Num: 1, URL: "https://github.com/runatlantis/atlantis/pull/1"},
+	User:      models.User{Username: "lkysow"},
+	Success:   false,
+	Output:    "Error: <resource> failed",
+}
+
+func emailHook(t *testing.T, port int, startTLS string) *webhooks.EmailWebhook {
+	hook, err := webhooks.NewEmail(
+		webhooks.Filter{Events: []string{webhooks.ApplyEvent}},
+		[]string{"alice@example.com", "bob@example.com"},
+		webhooks.SMTPConfig{
+			Host:     "127.0.0.1",
+			Port:     port,
+			Username: "user",
+			Password: "pass",
+			From:     "atlantis@example.com",
+			StartTLS: startTLS,
+		})
+	Ok(t, err)
+	return hook
+}
+
+// parseEmail returns the subject and the text and html parts of data.
+func parseEmail(t *testing.T, data string) (string, string, string) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	Ok(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	Ok(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	Ok(t, err)
+	Equals(t, "multipart/alternative", mediaType)
+
+	var parts []string
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err != nil {
+			break
+		}
+		// NextPart decodes quoted-printable.
+		b, err := ioutil.ReadAll(p)
+		Ok(t, err)
+		parts = append(parts, string(b))
+	}
+	Equals(t, 2, len(parts))
+	return subject, parts[0], parts[1]
+}
+
+func TestEmailWebhook_Send(t *testing.T) {
+	s := newSMTPServer(t, nil)
+	defer s.listener.Close() // nolint: errcheck
+	hook := emailHook(t, s.port(), webhooks.StartTLSOpportunistic)
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), emailResult))
+	Equals(t, 1, len(s.received()))
+	msg := s.received()[0]
+	Equals(t, "atlantis@example.com", msg.from)
+	Equals(t, []string{"alice@example.com", "bob@example.com"}, msg.to)
+	Equals(t, "\x00user\x00pass", msg.auth)
+	Equals(t, false, msg.tls)
+
+	subject, text, html := parseEmail(t, msg.data)
+	Equals(t, "[Atlantis] Apply failed for runatlantis/atlantis#1 (prod, workspace production)", subject)
+	Assert(t, strings.Contains(text, "Workspace: production\n"), "exp workspace in text, got %q", text)
+	Assert(t, strings.Contains(text, "Error: <resource> failed"), "exp output in text, got %q", text)
+	Assert(t, strings.Contains(html, `<a href="https://github.com/runatlantis/atlantis/pull/1">runatlantis/atlantis#1</a>`), "exp pull link in html, got %q", html)
+	Assert(t, strings.Contains(html, "<pre>Error: &lt;resource&gt; failed</pre>"), "exp escaped output in html, got %q", html)
+}
+
+func TestEmailWebhook_SendStartTLS(t *testing.T) {
+	// Borrow httptest's certificate for 127.0.0.1.
+	tlsServer := httptest.NewTLSServer(nil)
+	serverTLS := &tls.Config{Certificates: tlsServer.TLS.Certificates}
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsServer.Certificate())
+	tlsServer.Close()
+
+	s := newSMTPServer(t, serverTLS)
+	defer s.listener.Close() // nolint: errcheck
+	hook := emailHook(t, s.port(), "")
+	hook.TLSConfig = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), emailResult))
+	Equals(t, 1, len(s.received()))
+	Equals(t, true, s.received()[0].tls)
+}
+
+func TestEmailWebhook_SendStartTLSRequired(t *testing.T) {
+	s := newSMTPServer(t, nil)
+	defer s.listener.Close() // nolint: errcheck
+	hook := emailHook(t, s.port(), webhooks.StartTLSRequired)
+
+	err := hook.Send(logging.NewNoopLogger(), emailResult)
+	ErrEquals(t, "sending email through 127.0.0.1: server doesn't support STARTTLS", err)
+	Equals(t, 0, len(s.received()))
+}
+
+func TestEmailWebhook_SendNotMatched(t *testing.T) {
+	s := newSMTPServer(t, nil)
+	defer s.listener.Close() // nolint: errcheck
+	hook := emailHook(t, s.port(), webhooks.StartTLSDisabled)
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), webhooks.PlanResult{Workspace: "production"}))
+	Equals(t, 0, len(s.received()))
+}
+
+func TestEmailWebhook_SendConnectionError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Ok(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close() // nolint: errcheck
+	hook := emailHook(t, port, webhooks.StartTLSDisabled)
+
+	err = hook.Send(logging.NewNoopLogger(), emailResult)
+	ErrContains(t, "sending email through 127.0.0.1", err)
+}
+
+func TestNewEmail_Validation(t *testing.T) {
+	filter := webhooks.Filter{Events: []string{webhooks.ApplyEvent}}
+	valid := webhooks.SMTPConfig{Host: "smtp.example.com", From: "atlantis@example.com"}
+	to := []string{"alice@example.com"}
+
+	_, err := webhooks.NewEmail(filter, to, webhooks.SMTPConfig{From: "atlantis@example.com"})
+	ErrEquals(t, "must specify top-level \"smtp\" \"host\" and \"from\" if using a webhook of \"kind: email\"", err)
+
+	_, err = webhooks.NewEmail(filter, nil, valid)
+	ErrEquals(t, "must specify \"to\" if using a webhook of \"kind: email\"", err)
+
+	invalid := valid
+	invalid.StartTLS = "sometimes"
+	_, err = webhooks.NewEmail(filter, to, invalid)
+	ErrEquals(t, "\"smtp\" \"starttls: sometimes\" not supported. Supported values are required, opportunistic and disabled", err)
+
+	hook, err := webhooks.NewEmail(filter, to, valid)
+	Ok(t, err)
+	Equals(t, webhooks.DefaultSMTPPort, hook.SMTP.Port)
+	Equals(t, webhooks.StartTLSRequired, hook.SMTP.StartTLS)
+}
+
+func TestNewWebhooksManager_Email(t *testing.T) {
+	configs := []webhooks.Config{{
+		Event:          validEvent,
+		WorkspaceRegex: validRegex,
+		Kind:           webhooks.EmailKind,
+		To:             []string{"alice@example.com"},
+		SMTP:           webhooks.SMTPConfig{Host: "smtp.example.com", Port: 25, From: "atlantis@example.com"},
+	}}
+	m, err := webhooks.NewMultiWebhookSender(configs, nil)
+	Ok(t, err)
+	hook, ok := m.Webhooks[0].(*webhooks.EmailWebhook)
+	Assert(t, ok, "exp an email webhook")
+	Equals(t, []string{"alice@example.com"}, hook.To)
+	Equals(t, "smtp.example.com", hook.SMTP.Host)
+	Equals(t, 25, hook.SMTP.Port)
+}
@@ -0,0 +1,212 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+)
+
+// The types of events webhooks can be sent for.
+const (
+	ApplyEvent          = "apply"
+	PlanEvent           = "plan"
+	AutoplanStartEvent  = "autoplan_start"
+	AutoplanFinishEvent = "autoplan_finish"
+	LockEvent           = "lock"
+	UnlockEvent         = "unlock"
+	CommandFailureEvent = "command_failure"
+)
+
+// EventTypes are all the types of events.
+var EventTypes = []string{ApplyEvent, PlanEvent, AutoplanStartEvent, AutoplanFinishEvent, LockEvent, UnlockEvent, CommandFailureEvent}
+
+func isEventType(e string) bool {
+	for _, t := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is something webhooks can be sent for. Each type of event is its own
+// struct so it can carry its own data.
+type Event interface {
+	// EventType returns the type of the event, ex. ApplyEvent.
+	EventType() string
+	// EventInfo returns the information every event has.
+	EventInfo() EventInfo
+}
+
+// EventInfo is the information every event has.
+type EventInfo struct {
+	Repo models.Repo
+	Pull models.PullRequest
+	User models.User
+	// Workspace and Dir are empty for events that aren't for a single
+	// project, ex. AutoplanStartEvent.
+	Workspace string
+	Dir       string
+	// Project is the project's name from atlantis.yaml. It's empty if the
+	// project isn't named.
+	Project string
+}
+
+// ApplyResult is the result of a terraform apply.
+type ApplyResult struct {
+	Workspace string
+	// Dir is the project's dir relative to the repo root, ex. "." or "prod".
+	Dir string
+	// Project is the project's name from atlantis.yaml, if it has one.
+	Project string
+	Repo    models.Repo
+	Pull    models.PullRequest
+	User    models.User
+	Success bool
+	// Output is the output of the apply, or its error if it failed.
+	Output string
+	// Changes is how many resources the applied plan changes. It's nil if
+	// they weren't saved with the plan, ex. because Terraform's summary
+	// couldn't be found in the plan's output.
+	Changes *models.PlanChanges
+	// LockURL is the URL of the project's lock in the Atlantis UI. The page
+	// is gone once the lock is released, ex. when the pull request is merged.
+	LockURL    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// EventType implements Event.
+func (a ApplyResult) EventType() string { return ApplyEvent }
+
+// EventInfo implements Event.
+func (a ApplyResult) EventInfo() EventInfo {
+	return EventInfo{Repo: a.Repo, Pull: a.Pull, User: a.User, Workspace: a.Workspace, Dir: a.Dir, Project: a.Project}
+}
+
+// PlanResult is the result of a terraform plan.
+type PlanResult struct {
+	Workspace string
+	Dir       string
+	Project   string
+	Repo      models.Repo
+	Pull      models.PullRequest
+	User      models.User
+	Success   bool
+	// Output is the output of the plan, or its error if it failed.
+	Output string
+	// Changes is how many resources the plan will change. It's nil if the plan
+	// failed or Terraform's summary couldn't be found in the output.
+	Changes *models.PlanChanges
+	// LockURL is the URL of the project's lock in the Atlantis UI. It's empty
+	// if the plan failed since the lock is released.
+	LockURL    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// EventType implements Event.
+func (p PlanResult) EventType() string { return PlanEvent }
+
+// EventInfo implements Event.
+func (p PlanResult) EventInfo() EventInfo {
+	return EventInfo{Repo: p.Repo, Pull: p.Pull, User: p.User, Workspace: p.Workspace, Dir: p.Dir, Project: p.Project}
+}
+
+// AutoplanStart is sent when autoplan starts planning the projects modified
+// by a pull request.
+type AutoplanStart struct {
+	Repo models.Repo
+	Pull models.PullRequest
+	User models.User
+	// Projects are the projects that will be planned.
+	Projects []AutoplanProject
+}
+
+// AutoplanProject is a project planned by autoplan.
+type AutoplanProject struct {
+	Dir       string
+	Workspace string
+}
+
+// EventType implements Event.
+func (a AutoplanStart) EventType() string { return AutoplanStartEvent }
+
+// EventInfo implements Event.
+func (a AutoplanStart) EventInfo() EventInfo {
+	return EventInfo{Repo: a.Repo, Pull: a.Pull, User: a.User}
+}
+
+// AutoplanFinish is sent when autoplan has planned every project.
+type AutoplanFinish struct {
+	Repo models.Repo
+	Pull models.PullRequest
+	User models.User
+	// Success is true if every project was planned successfully.
+	Success bool
+	// Projects is how many projects were planned and FailedProjects is how
+	// many of them failed.
+	Projects       int
+	FailedProjects int
+	StartedAt      time.Time
+	FinishedAt     time.Time
+}
+
+// EventType implements Event.
+func (a AutoplanFinish) EventType() string { return AutoplanFinishEvent }
+
+// EventInfo implements Event.
+func (a AutoplanFinish) EventInfo() EventInfo {
+	return EventInfo{Repo: a.Repo, Pull: a.Pull, User: a.User}
+}
+
+// LockResult is sent when a project's lock is acquired or released.
+type LockResult struct {
+	Workspace string
+	Dir       string
+	Project   string
+	Repo      models.Repo
+	Pull      models.PullRequest
+	User      models.User
+	// Locked is true if the lock was acquired and false if it was released.
+	Locked bool
+	// LockURL is the URL of the lock in the Atlantis UI. It's empty if the
+	// lock was released.
+	LockURL string
+}
+
+// EventType implements Event.
+func (l LockResult) EventType() string {
+	if l.Locked {
+		return LockEvent
+	}
+	return UnlockEvent
+}
+
+// EventInfo implements Event.
+func (l LockResult) EventInfo() EventInfo {
+	return EventInfo{Repo: l.Repo, Pull: l.Pull, User: l.User, Workspace: l.Workspace, Dir: l.Dir, Project: l.Project}
+}
+
+// CommandFailure is sent when a command fails for a project, ex. because of a
+// terraform error or because the project is locked by another pull request.
+type CommandFailure struct {
+	Workspace string
+	Dir       string
+	Project   string
+	Repo      models.Repo
+	Pull      models.PullRequest
+	User      models.User
+	// Command is the command that failed, ex. "plan".
+	Command string
+	// Error is why it failed.
+	Error string
+}
+
+// EventType implements Event.
+func (c CommandFailure) EventType() string { return CommandFailureEvent }
+
+// EventInfo implements Event.
+func (c CommandFailure) EventInfo() EventInfo {
+	return EventInfo{Repo: c.Repo, Pull: c.Pull, User: c.User, Workspace: c.Workspace, Dir: c.Dir, Project: c.Project}
+}
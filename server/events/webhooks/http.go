@@ -0,0 +1,397 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// HTTPPayloadVersion is the version of the payloads. It's incremented
+	// when fields are removed or change meaning. Fields can be added without
+	// changing it.
+	HTTPPayloadVersion = 1
+	// HTTPEventHeader, HTTPDeliveryHeader and HTTPSignatureHeader are the
+	// headers sent with each request. The delivery id is the same for each
+	// retry of a request so receivers can ignore duplicates.
+	HTTPEventHeader     = "X-Atlantis-Event"
+	HTTPDeliveryHeader  = "X-Atlantis-Delivery"
+	HTTPSignatureHeader = "X-Atlantis-Signature"
+	// DefaultHTTPRetries is how many times a request is retried by default.
+	DefaultHTTPRetries = 3
+	// DefaultHTTPTimeout is how long each request can take by default.
+	DefaultHTTPTimeout = 10 * time.Second
+	// maxHTTPPending is how many requests to a webhook can be sending or
+	// waiting to retry at once. Events sent while it's reached are dropped.
+	maxHTTPPending = 100
+	// maxHTTPOutputLen is the most output that's sent. Longer output is
+	// truncated to its end since that's where terraform's summary is.
+	maxHTTPOutputLen = 4000
+)
+
+// HTTPPayload is the part of the JSON document POSTed by HTTPWebhook that's
+// the same for every event. Each event's payload embeds it, ex.
+// HTTPApplyPayload.
+type HTTPPayload struct {
+	Version int             `json:"version"`
+	Event   string          `json:"event"`
+	Repo    HTTPPayloadRepo `json:"repo"`
+	Pull    HTTPPayloadPull `json:"pull"`
+	User    string          `json:"user"`
+	// Workspace and Dir are omitted for events that aren't for a single
+	// project, ex. AutoplanStartEvent.
+	Workspace string `json:"workspace,omitempty"`
+	Dir       string `json:"dir,omitempty"`
+	// Project is the project's name from atlantis.yaml, if it has one.
+	Project string    `json:"project,omitempty"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// HTTPApplyPayload is the payload for ApplyEvent.
+type HTTPApplyPayload struct {
+	HTTPPayload
+	Success bool `json:"success"`
+	// Output is the end of the apply's output.
+	Output          string `json:"output"`
+	OutputTruncated bool   `json:"output_truncated"`
+	// Changes is omitted if they weren't saved with the plan.
+	Changes    *HTTPPayloadChanges `json:"changes,omitempty"`
+	LockURL    string              `json:"lock_url,omitempty"`
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+}
+
+// HTTPPlanPayload is the payload for PlanEvent.
+type HTTPPlanPayload struct {
+	HTTPPayload
+	Success bool `json:"success"`
+	// Output is the end of the plan's output.
+	Output          string `json:"output"`
+	OutputTruncated bool   `json:"output_truncated"`
+	// Changes is omitted if the plan failed or its summary couldn't be found.
+	Changes    *HTTPPayloadChanges `json:"changes,omitempty"`
+	LockURL    string              `json:"lock_url,omitempty"`
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+}
+
+// HTTPAutoplanStartPayload is the payload for AutoplanStartEvent.
+type HTTPAutoplanStartPayload struct {
+	HTTPPayload
+	Projects []HTTPPayloadProject `json:"projects"`
+}
+
+// HTTPAutoplanFinishPayload is the payload for AutoplanFinishEvent.
+type HTTPAutoplanFinishPayload struct {
+	HTTPPayload
+	Success        bool      `json:"success"`
+	Projects       int       `json:"projects"`
+	FailedProjects int       `json:"failed_projects"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
+
+// HTTPLockPayload is the payload for LockEvent and UnlockEvent.
+type HTTPLockPayload struct {
+	HTTPPayload
+	Locked  bool   `json:"locked"`
+	LockURL string `json:"lock_url,omitempty"`
+}
+
+// HTTPCommandFailurePayload is the payload for CommandFailureEvent.
+type HTTPCommandFailurePayload struct {
+	HTTPPayload
+	Command string `json:"command"`
+	Error   string `json:"error"`
+}
+
+// HTTPPayloadRepo is the repo in HTTPPayload.
+type HTTPPayloadRepo struct {
+	FullName string `json:"full_name"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	URL      string `json:"url"`
+}
+
+// HTTPPayloadPull is the pull request in HTTPPayload.
+type HTTPPayloadPull struct {
+	Num        int    `json:"num"`
+	URL        string `json:"url"`
+	Author     string `json:"author"`
+	Branch     string `json:"branch"`
+	HeadCommit string `json:"head_commit"`
+}
+
+// HTTPPayloadChanges is how many resources a plan will change.
+type HTTPPayloadChanges struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+}
+
+// newHTTPPayloadChanges returns changes in the payload, or nil if changes is
+// nil.
+func newHTTPPayloadChanges(changes *models.PlanChanges) *HTTPPayloadChanges {
+	if changes == nil {
+		return nil
+	}
+	return &HTTPPayloadChanges{
+		Add:     changes.Add,
+		Change:  changes.Change,
+		Destroy: changes.Destroy,
+	}
+}
+
+// HTTPPayloadProject is a project in HTTPAutoplanStartPayload.
+type HTTPPayloadProject struct {
+	Dir       string `json:"dir"`
+	Workspace string `json:"workspace"`
+}
+
+// HTTPWebhook POSTs a JSON document to a URL.
+type HTTPWebhook struct {
+	Client *http.Client
+	Filter Filter
+	URL    string
+	// Headers are added to each request, ex. for authentication.
+	Headers map[string]string
+	// Secret is used to sign each request's body with HMAC-SHA256. The
+	// signature is sent in the X-Atlantis-Signature header as
+	// "sha256=<hex signature>". If empty, requests aren't signed.
+	Secret []byte
+	// Retries is how many times a request is retried if it fails with a
+	// network error, a 429 or a 5xx.
+	Retries int
+	// RetryDelay is how long to wait before the first retry. It doubles on
+	// every retry.
+	RetryDelay time.Duration
+	// Async is true if Send returns without waiting for the request to be
+	// sent so a slow or failing receiver doesn't hold up commands. Errors are
+	// logged instead of returned. It's set by NewHTTP.
+	Async   bool
+	pending chan struct{}
+}
+
+// NewHTTP returns an HTTPWebhook. retries and timeout are the defaults if 0.
+// retries can be negative to disable retrying.
+func NewHTTP(filter Filter, url string, headers map[string]string, secret string, retries int, timeout time.Duration) *HTTPWebhook {
+	if retries == 0 {
+		retries = DefaultHTTPRetries
+	} else if retries < 0 {
+		retries = 0
+	}
+	if timeout == 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	return &HTTPWebhook{
+		Client:     &http.Client{Timeout: timeout},
+		Filter:     filter,
+		URL:        url,
+		Headers:    headers,
+		Secret:     []byte(secret),
+		Retries:    retries,
+		RetryDelay: time.Second,
+		Async:      true,
+		pending:    make(chan struct{}, maxHTTPPending),
+	}
+}
+
+// Send POSTs the webhook if the event matches the filter. If Async is true, it
+// only returns an error if the request couldn't be queued.
+func (h *HTTPWebhook) Send(log *logging.SimpleLogger, event Event) error {
+	if !h.Filter.Matches(event) {
+		return nil
+	}
+	body, err := json.Marshal(NewHTTPPayload(event))
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	deliveryID := uuid.New().String()
+	if !h.Async {
+		return h.deliver(log, body, event.EventType(), deliveryID)
+	}
+
+	select {
+	case h.pending <- struct{}{}:
+	default:
+		return fmt.Errorf("not sending webhook to %s since %d requests to it are already pending", h.URL, cap(h.pending))
+	}
+	go func() {
+		defer func() { <-h.pending }()
+		if err := h.deliver(log, body, event.EventType(), deliveryID); err != nil {
+			log.Warn("error sending webhook: %s", err)
+		}
+	}()
+	return nil
+}
+
+// deliver POSTs body, retrying if it fails in a way that retrying might fix.
+func (h *HTTPWebhook) deliver(log *logging.SimpleLogger, body []byte, eventType string, deliveryID string) error {
+	delay := h.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := h.post(body, eventType, deliveryID)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= h.Retries {
+			return errors.Wrapf(err, "sending webhook to %s", h.URL)
+		}
+		log.Warn("sending webhook to %s failed (%s), retrying in %s (retry %d/%d)", h.URL, err, delay, attempt+1, h.Retries)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// post makes one request. retry is true if the request failed in a way that
+// retrying might fix.
+func (h *HTTPWebhook) post(body []byte, eventType string, deliveryID string) (retry bool, err error) {
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Atlantis")
+	req.Header.Set(HTTPEventHeader, eventType)
+	req.Header.Set(HTTPDeliveryHeader, deliveryID)
+	if len(h.Secret) > 0 {
+		req.Header.Set(HTTPSignatureHeader, "sha256="+Sign(body, h.Secret))
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()            // nolint: errcheck
+	io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("got response %q", resp.Status)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of body. It's exported
+// so receivers written in Go can validate requests.
+func Sign(body []byte, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body) // nolint: errcheck
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewHTTPPayload returns the payload for event, ex. an HTTPApplyPayload for an
+// ApplyResult.
+func NewHTTPPayload(event Event) interface{} {
+	info := event.EventInfo()
+	common := HTTPPayload{
+		Version: HTTPPayloadVersion,
+		Event:   event.EventType(),
+		Repo: HTTPPayloadRepo{
+			FullName: info.Repo.FullName,
+			Owner:    info.Repo.Owner,
+			Name:     info.Repo.Name,
+			Host:     info.Repo.VCSHost.Hostname,
+			URL:      info.Repo.SanitizedCloneURL,
+		},
+		Pull: HTTPPayloadPull{
+			Num:        info.Pull.Num,
+			URL:        info.Pull.URL,
+			Author:     info.Pull.Author,
+			Branch:     info.Pull.Branch,
+			HeadCommit: info.Pull.HeadCommit,
+		},
+		User:      info.User.Username,
+		Workspace: info.Workspace,
+		Dir:       info.Dir,
+		Project:   info.Project,
+		SentAt:    time.Now(),
+	}
+
+	switch e := event.(type) {
+	case ApplyResult:
+		output, truncated := truncateOutput(e.Output)
+		return HTTPApplyPayload{
+			HTTPPayload:     common,
+			Success:         e.Success,
+			Output:          output,
+			OutputTruncated: truncated,
+			Changes:         newHTTPPayloadChanges(e.Changes),
+			LockURL:         e.LockURL,
+			StartedAt:       e.StartedAt,
+			FinishedAt:      e.FinishedAt,
+		}
+	case PlanResult:
+		output, truncated := truncateOutput(e.Output)
+		return HTTPPlanPayload{
+			HTTPPayload:     common,
+			Success:         e.Success,
+			Output:          output,
+			OutputTruncated: truncated,
+			Changes:         newHTTPPayloadChanges(e.Changes),
+			LockURL:         e.LockURL,
+			StartedAt:       e.StartedAt,
+			FinishedAt:      e.FinishedAt,
+		}
+	case AutoplanStart:
+		projects := []HTTPPayloadProject{}
+		for _, p := range e.Projects {
+			projects = append(projects, HTTPPayloadProject{Dir: p.Dir, Workspace: p.Workspace})
+		}
+		return HTTPAutoplanStartPayload{
+			HTTPPayload: common,
+			Projects:    projects,
+		}
+	case AutoplanFinish:
+		return HTTPAutoplanFinishPayload{
+			HTTPPayload:    common,
+			Success:        e.Success,
+			Projects:       e.Projects,
+			FailedProjects: e.FailedProjects,
+			StartedAt:      e.StartedAt,
+			FinishedAt:     e.FinishedAt,
+		}
+	case LockResult:
+		return HTTPLockPayload{
+			HTTPPayload: common,
+			Locked:      e.Locked,
+			LockURL:     e.LockURL,
+		}
+	case CommandFailure:
+		return HTTPCommandFailurePayload{
+			HTTPPayload: common,
+			Command:     e.Command,
+			Error:       e.Error,
+		}
+	}
+	return common
+}
+
+// truncateOutput returns the end of output if it's longer than
+// maxHTTPOutputLen and whether it was truncated.
+func truncateOutput(output string) (string, bool) {
+	if len(output) <= maxHTTPOutputLen {
+		return output, false
+	}
+	start := len(output) - maxHTTPOutputLen
+	// Don't start in the middle of a multi-byte character.
+	for start < len(output) && !utf8.RuneStart(output[start]) {
+		start++
+	}
+	return output[start:], true
+}
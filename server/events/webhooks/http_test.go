@@ -0,0 +1,297 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/webhooks"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+)
+
+var httpResult = webhooks.ApplyResult{
+	Workspace: "production",
+	Dir:       "prod",
+	Repo: models.Repo{
+		FullName:          "runatlantis/atlantis",
+		Owner:             "runatlantis",
+		Name:              "atlantis",
+		SanitizedCloneURL: "https://github.com/runatlantis/atlantis.git",
+		VCSHost:           models.VCSHost{Hostname: "github.com", Type: models.Github},
+	},
+	Pull: models.PullRequest{
+		Num:        1,
+		URL:        "https://github.com/runatlantis/atlantis/pull/1",
+		Author:     "author",
+		Branch:     "branch",
+		HeadCommit: "abc123",
+	},
+	User:       models.User{Username: "user"},
+	Success:    true,
+	Output:     "Apply complete!",
+	StartedAt:  time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+	FinishedAt: time.Date(2019, 1, 1, 0, 1, 0, 0, time.UTC),
+}
+
+// filter returns a filter for apply events in workspaces matching regex.
+func filter(regex string) webhooks.Filter {
+	return webhooks.Filter{Events: []string{webhooks.ApplyEvent}, WorkspaceRegex: regexp.MustCompile(regex)}
+}
+
+// recordedRequest is a request received by the test server.
+type recordedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// testServer returns a server that responds to each request with the next of
+// codes, or 200 once they're used up, and records the requests it received.
+func testServer(codes ...int) (*httptest.Server, func() []recordedRequest) {
+	var mutex sync.Mutex
+	var requests []recordedRequest
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests = append(requests, recordedRequest{header: r.Header, body: body})
+		if len(codes) > 0 {
+			w.WriteHeader(codes[0])
+			codes = codes[1:]
+		}
+	}))
+	return s, func() []recordedRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests
+	}
+}
+
+func TestHTTPWebhook_Send(t *testing.T) {
+	s, requests := testServer()
+	defer s.Close()
+	hook := webhooks.NewHTTP(filter(".*"), s.URL, map[string]string{"Authorization": "Bearer token"}, "secret", 0, 0)
+	hook.Async = false
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), httpResult))
+	Equals(t, 1, len(requests()))
+	req := requests()[0]
+	Equals(t, "application/json", req.header.Get("Content-Type"))
+	Equals(t, "Bearer token", req.header.Get("Authorization"))
+	Equals(t, "apply", req.header.Get(webhooks.HTTPEventHeader))
+	Assert(t, req.header.Get(webhooks.HTTPDeliveryHeader) != "", "exp delivery header to be set")
+	Equals(t, "sha256="+webhooks.Sign(req.body, []byte("secret")), req.header.Get(webhooks.HTTPSignatureHeader))
+
+	var payload webhooks.HTTPApplyPayload
+	Ok(t, json.Unmarshal(req.body, &payload))
+	Equals(t, webhooks.HTTPPayloadVersion, payload.Version)
+	Equals(t, "apply", payload.Event)
+	Equals(t, webhooks.HTTPPayloadRepo{
+		FullName: "runatlantis/atlantis",
+		Owner:    "runatlantis",
+		Name:     "atlantis",
+		Host:     "github.com",
+		URL:      "https://github.com/runatlantis/atlantis.git",
+	}, payload.Repo)
+	Equals(t, webhooks.HTTPPayloadPull{
+		Num:        1,
+		URL:        "https://github.com/runatlantis/atlantis/pull/1",
+		Author:     "author",
+		Branch:     "branch",
+		HeadCommit: "abc123",
+	}, payload.Pull)
+	Equals(t, "user", payload.User)
+	Equals(t, "production", payload.Workspace)
+	Equals(t, "prod", payload.Dir)
+	Equals(t, true, payload.Success)
+	Equals(t, "Apply complete!", payload.Output)
+	Equals(t, false, payload.OutputTruncated)
+	Equals(t, httpResult.StartedAt, payload.StartedAt)
+	Equals(t, httpResult.FinishedAt, payload.FinishedAt)
+	Assert(t, !payload.SentAt.IsZero(), "exp sent_at to be set")
+}
+
+func TestHTTPWebhook_SendNoSecret(t *testing.T) {
+	s, requests := testServer()
+	defer s.Close()
+	hook := webhooks.NewHTTP(filter(".*"), s.URL, nil, "", 0, 0)
+	hook.Async = false
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), httpResult))
+	Equals(t, 1, len(requests()))
+	Equals(t, "", requests()[0].header.Get(webhooks.HTTPSignatureHeader))
+}
+
+func TestHTTPWebhook_SendWorkspaceNotMatched(t *testing.T) {
+	s, requests := testServer()
+	defer s.Close()
+	hook := webhooks.NewHTTP(filter("staging"), s.URL, nil, "", 0, 0)
+	hook.Async = false
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), httpResult))
+	Equals(t, 0, len(requests()))
+}
+
+func TestHTTPWebhook_SendRetries(t *testing.T) {
+	s, requests := testServer(http.StatusInternalServerError, http.StatusTooManyRequests)
+	defer s.Close()
+	hook := webhooks.NewHTTP(filter(".*"), s.URL, nil, "", 0, 0)
+	hook.Async = false
+	hook.RetryDelay = time.Millisecond
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), httpResult))
+	Equals(t, 3, len(requests()))
+	// Each retry should have the same delivery id.
+	id := requests()[0].header.Get(webhooks.HTTPDeliveryHeader)
+	for _, req := range requests() {
+		Equals(t, id, req.header.Get(webhooks.HTTPDeliveryHeader))
+	}
+}
+
+func TestHTTPWebhook_SendRetriesExhausted(t *testing.T) {
+	s, requests := testServer(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	defer s.Close()
+	hook := webhooks.NewHTTP(filter(".*"), s.URL, nil, "", 2, 0)
+	hook.Async = false
+	hook.RetryDelay = time.Millisecond
+
+	err := hook.Send(logging.NewNoopLogger(), httpResult)
+	ErrContains(t, "502 Bad Gateway", err)
+	Equals(t, 3, len(requests()))
+}
+
+func TestHTTPWebhook_SendClientErrorNotRetried(t *testing.T) {
+	s, requests := testServer(http.StatusBadRequest)
+	defer s.Close()
+	hook := webhooks.NewHTTP(filter(".*"), s.URL, nil, "", 0, 0)
+	hook.Async = false
+	hook.RetryDelay = time.Millisecond
+
+	err := hook.Send(logging.NewNoopLogger(), httpResult)
+	ErrContains(t, "400 Bad Request", err)
+	Equals(t, 1, len(requests()))
+}
+
+func TestHTTPWebhook_SendTimeout(t *testing.T) {
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer s.Close()
+	defer close(done)
+	hook := webhooks.NewHTTP(filter(".*"), s.URL, nil, "", -1, 50*time.Millisecond)
+	hook.Async = false
+
+	err := hook.Send(logging.NewNoopLogger(), httpResult)
+	Assert(t, err != nil, "exp timeout error")
+}
+
+func TestHTTPWebhook_SendAsync(t *testing.T) {
+	received := make(chan struct{})
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-done
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer s.Close()
+	defer close(done)
+	hook := webhooks.NewHTTP(filter(".*"), s.URL, nil, "", 0, 0)
+
+	// Send shouldn't wait for the receiver to respond.
+	Ok(t, hook.Send(logging.NewNoopLogger(), httpResult))
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("exp request to be sent")
+	}
+}
+
+func TestNewHTTPPayload_TruncatesOutput(t *testing.T) {
+	result := httpResult
+	result.Output = strings.Repeat("a", 5000) + "Apply complete!"
+	payload := webhooks.NewHTTPPayload(result).(webhooks.HTTPApplyPayload)
+	Equals(t, true, payload.OutputTruncated)
+	Equals(t, 4000, len(payload.Output))
+	Assert(t, strings.HasSuffix(payload.Output, "Apply complete!"), "exp end of output to be kept")
+}
+
+func TestNewHTTPPayload_ApplyChanges(t *testing.T) {
+	payload := webhooks.NewHTTPPayload(httpResult).(webhooks.HTTPApplyPayload)
+	Assert(t, payload.Changes == nil, "exp no changes if they weren't saved with the plan")
+
+	result := httpResult
+	result.Changes = &models.PlanChanges{Add: 1, Change: 2, Destroy: 3}
+	payload = webhooks.NewHTTPPayload(result).(webhooks.HTTPApplyPayload)
+	Equals(t, &webhooks.HTTPPayloadChanges{Add: 1, Change: 2, Destroy: 3}, payload.Changes)
+}
+
+func TestHTTPWebhook_SendPlan(t *testing.T) {
+	s, requests := testServer()
+	defer s.Close()
+	hook := webhooks.NewHTTP(webhooks.Filter{Events: []string{webhooks.PlanEvent}}, s.URL, nil, "", 0, 0)
+	hook.Async = false
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), httpResult))
+	Equals(t, 0, len(requests()))
+
+	plan := webhooks.PlanResult{
+		Workspace: "production",
+		Dir:       "prod",
+		Repo:      httpResult.Repo,
+		Pull:      httpResult.Pull,
+		User:      httpResult.User,
+		Success:   true,
+		Output:    "Plan: 1 to add, 2 to change, 3 to destroy.",
+		Changes:   &models.PlanChanges{Add: 1, Change: 2, Destroy: 3},
+	}
+	Ok(t, hook.Send(logging.NewNoopLogger(), plan))
+	Equals(t, 1, len(requests()))
+	req := requests()[0]
+	Equals(t, "plan", req.header.Get(webhooks.HTTPEventHeader))
+
+	var payload webhooks.HTTPPlanPayload
+	Ok(t, json.Unmarshal(req.body, &payload))
+	Equals(t, "plan", payload.Event)
+	Equals(t, "production", payload.Workspace)
+	Equals(t, true, payload.Success)
+	Equals(t, &webhooks.HTTPPayloadChanges{Add: 1, Change: 2, Destroy: 3}, payload.Changes)
+}
+
+func TestNewHTTPPayload_AutoplanStart(t *testing.T) {
+	payload := webhooks.NewHTTPPayload(webhooks.AutoplanStart{
+		Repo:     httpResult.Repo,
+		Pull:     httpResult.Pull,
+		User:     httpResult.User,
+		Projects: []webhooks.AutoplanProject{{Dir: "prod", Workspace: "default"}},
+	})
+	body, err := json.Marshal(payload)
+	Ok(t, err)
+
+	// Workspace and dir should be omitted since the event isn't for one project.
+	var fields map[string]interface{}
+	Ok(t, json.Unmarshal(body, &fields))
+	_, ok := fields["workspace"]
+	Assert(t, !ok, "exp workspace to be omitted")
+	Equals(t, "autoplan_start", fields["event"])
+	Equals(t, []interface{}{map[string]interface{}{"dir": "prod", "workspace": "default"}}, fields["projects"])
+}
+
+func TestNewHTTPPayload_Lock(t *testing.T) {
+	payload := webhooks.NewHTTPPayload(webhooks.LockResult{
+		Workspace: "default",
+		Dir:       "prod",
+		Locked:    false,
+	})
+	lock, ok := payload.(webhooks.HTTPLockPayload)
+	Assert(t, ok, "exp lock payload")
+	Equals(t, "unlock", lock.Event)
+	Equals(t, false, lock.Locked)
+}
@@ -7,14 +7,14 @@ import (
 	"github.com/petergtz/pegomock"
 )
 
-func AnyWebhooksApplyResult() webhooks.ApplyResult {
-	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(webhooks.ApplyResult))(nil)).Elem()))
-	var nullValue webhooks.ApplyResult
+func AnyWebhooksEvent() webhooks.Event {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(webhooks.Event))(nil)).Elem()))
+	var nullValue webhooks.Event
 	return nullValue
 }
 
-func EqWebhooksApplyResult(value webhooks.ApplyResult) webhooks.ApplyResult {
+func EqWebhooksEvent(value webhooks.Event) webhooks.Event {
 	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
-	var nullValue webhooks.ApplyResult
+	var nullValue webhooks.Event
 	return nullValue
 }
@@ -58,8 +58,8 @@ func (mock *MockSlackClient) ChannelExists(channelName string) (bool, error) {
 	return ret0, ret1
 }
 
-func (mock *MockSlackClient) PostMessage(channel string, applyResult webhooks.ApplyResult) error {
-	params := []pegomock.Param{channel, applyResult}
+func (mock *MockSlackClient) PostMessage(channel string, event webhooks.Event) error {
+	params := []pegomock.Param{channel, event}
 	result := pegomock.GetGenericMockFrom(mock).Invoke("PostMessage", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
 	var ret0 error
 	if len(result) != 0 {
@@ -149,8 +149,8 @@ func (c *SlackClient_ChannelExists_OngoingVerification) GetAllCapturedArguments(
 	return
 }
 
-func (verifier *VerifierSlackClient) PostMessage(channel string, applyResult webhooks.ApplyResult) *SlackClient_PostMessage_OngoingVerification {
-	params := []pegomock.Param{channel, applyResult}
+func (verifier *VerifierSlackClient) PostMessage(channel string, event webhooks.Event) *SlackClient_PostMessage_OngoingVerification {
+	params := []pegomock.Param{channel, event}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "PostMessage", params)
 	return &SlackClient_PostMessage_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -160,21 +160,21 @@ type SlackClient_PostMessage_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *SlackClient_PostMessage_OngoingVerification) GetCapturedArguments() (string, webhooks.ApplyResult) {
-	channel, applyResult := c.GetAllCapturedArguments()
-	return channel[len(channel)-1], applyResult[len(applyResult)-1]
+func (c *SlackClient_PostMessage_OngoingVerification) GetCapturedArguments() (string, webhooks.Event) {
+	channel, event := c.GetAllCapturedArguments()
+	return channel[len(channel)-1], event[len(event)-1]
 }
 
-func (c *SlackClient_PostMessage_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []webhooks.ApplyResult) {
+func (c *SlackClient_PostMessage_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []webhooks.Event) {
 	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(params) > 0 {
 		_param0 = make([]string, len(params[0]))
 		for u, param := range params[0] {
 			_param0[u] = param.(string)
 		}
-		_param1 = make([]webhooks.ApplyResult, len(params[1]))
+		_param1 = make([]webhooks.Event, len(params[1]))
 		for u, param := range params[1] {
-			_param1[u] = param.(webhooks.ApplyResult)
+			_param1[u] = param.(webhooks.Event)
 		}
 	}
 	return
@@ -14,22 +14,25 @@
 package webhooks
 
 import (
-	"regexp"
-
 	"fmt"
+	"io/ioutil"
 
 	"github.com/cloudposse/atlantis/server/logging"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
 // SlackWebhook sends webhooks to Slack.
 type SlackWebhook struct {
-	Client         SlackClient
-	WorkspaceRegex *regexp.Regexp
-	Channel        string
+	Client  SlackClient
+	Filter  Filter
+	Channel string
+	// UserMapping maps VCS usernames to Slack member ids. If a failed apply's
+	// pull request author is in it, they're sent a direct message.
+	UserMapping map[string]string
 }
 
-func NewSlack(r *regexp.Regexp, channel string, client SlackClient) (*SlackWebhook, error) {
+func NewSlack(filter Filter, channel string, userMapping map[string]string, client SlackClient) (*SlackWebhook, error) {
 	if err := client.AuthTest(); err != nil {
 		return nil, fmt.Errorf("testing slack authentication: %s. Verify your slack-token is valid", err)
 	}
@@ -43,16 +46,41 @@ func NewSlack(r *regexp.Regexp, channel string, client SlackClient) (*SlackWebho
 	}
 
 	return &SlackWebhook{
-		Client:         client,
-		WorkspaceRegex: r,
-		Channel:        channel,
+		Client:      client,
+		Filter:      filter,
+		Channel:     channel,
+		UserMapping: userMapping,
 	}, nil
 }
 
-// Send sends the webhook to Slack if the workspace matches the regex.
-func (s *SlackWebhook) Send(log *logging.SimpleLogger, applyResult ApplyResult) error {
-	if !s.WorkspaceRegex.MatchString(applyResult.Workspace) {
+// Send sends the webhook to Slack if the event matches the filter.
+func (s *SlackWebhook) Send(log *logging.SimpleLogger, event Event) error {
+	if !s.Filter.Matches(event) {
 		return nil
 	}
-	return s.Client.PostMessage(s.Channel, applyResult)
+	if err := s.Client.PostMessage(s.Channel, event); err != nil {
+		return err
+	}
+	if apply, ok := event.(ApplyResult); ok && !apply.Success {
+		if member, ok := s.UserMapping[apply.Pull.Author]; ok {
+			if err := s.Client.PostMessage(member, event); err != nil {
+				return errors.Wrapf(err, "sending direct message to %s", apply.Pull.Author)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadSlackUserMapping reads the YAML file at path that maps VCS usernames to
+// Slack member ids, ex. "lkysow: U012AB3CD".
+func LoadSlackUserMapping(path string) (map[string]string, error) {
+	contents, err := ioutil.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading slack user mapping")
+	}
+	var mapping map[string]string
+	if err := yaml.UnmarshalStrict(contents, &mapping); err != nil {
+		return nil, errors.Wrapf(err, "parsing slack user mapping %s", path)
+	}
+	return mapping, nil
 }
@@ -15,7 +15,9 @@ package webhooks
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/nlopes/slack"
 )
 
@@ -31,7 +33,7 @@ type SlackClient interface {
 	AuthTest() error
 	TokenIsSet() bool
 	ChannelExists(channelName string) (bool, error)
-	PostMessage(channel string, applyResult ApplyResult) error
+	PostMessage(channel string, event Event) error
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_underlying_slack_client.go UnderlyingSlackClient
@@ -78,41 +80,112 @@ func (d *DefaultSlackClient) ChannelExists(channelName string) (bool, error) {
 	return false, nil
 }
 
-func (d *DefaultSlackClient) PostMessage(channel string, applyResult ApplyResult) error {
+func (d *DefaultSlackClient) PostMessage(channel string, event Event) error {
 	params := slack.NewPostMessageParameters()
-	params.Attachments = d.createAttachments(applyResult)
+	params.Attachments = d.createAttachments(event)
 	params.EscapeText = false
 	_, _, err := d.Slack.PostMessage(channel, "", params)
 	return err
 }
 
-func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.Attachment {
-	var colour string
-	var successWord string
-	if applyResult.Success {
-		colour = slackSuccessColour
-		successWord = "succeeded"
-	} else {
+// createAttachments returns the attachments of the message for event. Atlantis
+// doesn't have a page for each job, so messages link to the pull request,
+// where the command's output is commented. Plan and lock messages also link to
+// the project's lock, which exists while the message is relevant. Apply
+// messages don't since the lock is released once the pull request is merged.
+func (d *DefaultSlackClient) createAttachments(event Event) []slack.Attachment {
+	info := event.EventInfo()
+	pull := fmt.Sprintf("<%s|%s#%d>", info.Pull.URL, info.Repo.FullName, info.Pull.Num)
+	colour := slackSuccessColour
+	var text string
+	var lockURL string
+	var changes *models.PlanChanges
+	switch e := event.(type) {
+	case ApplyResult:
+		text = fmt.Sprintf("Apply %s for %s", successWord(e.Success), pull)
+		if !e.Success {
+			colour = slackFailureColour
+		}
+		changes = e.Changes
+	case PlanResult:
+		text = fmt.Sprintf("Plan %s for %s", successWord(e.Success), pull)
+		if !e.Success {
+			colour = slackFailureColour
+		}
+		lockURL = e.LockURL
+		changes = e.Changes
+	case AutoplanStart:
+		text = fmt.Sprintf("Autoplan started for %d project(s) in %s", len(e.Projects), pull)
+		colour = ""
+	case AutoplanFinish:
+		text = fmt.Sprintf("Autoplan %s for %s: %d of %d project(s) failed", successWord(e.Success), pull, e.FailedProjects, e.Projects)
+		if !e.Success {
+			colour = slackFailureColour
+		}
+	case LockResult:
+		verb := "unlocked"
+		if e.Locked {
+			verb = "locked"
+		}
+		text = fmt.Sprintf("Project %s by %s", verb, pull)
+		colour = ""
+		lockURL = e.LockURL
+	case CommandFailure:
+		text = fmt.Sprintf("%s failed for %s: %s", strings.Title(e.Command), pull, e.Error)
 		colour = slackFailureColour
-		successWord = "failed"
+	default:
+		text = fmt.Sprintf("%s for %s", event.EventType(), pull)
+		colour = ""
+	}
+	if lockURL != "" {
+		text += fmt.Sprintf(" (<%s|view in Atlantis>)", lockURL)
 	}
 
-	text := fmt.Sprintf("Apply %s for <%s|%s>", successWord, applyResult.Pull.URL, applyResult.Repo.FullName)
+	var fields []slack.AttachmentField
+	if info.Project != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Project",
+			Value: info.Project,
+			Short: true,
+		})
+	}
+	if info.Dir != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Dir",
+			Value: info.Dir,
+			Short: true,
+		})
+	}
+	if info.Workspace != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Workspace",
+			Value: info.Workspace,
+			Short: true,
+		})
+	}
+	if changes != nil {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Changes",
+			Value: fmt.Sprintf("%d to add, %d to change, %d to destroy", changes.Add, changes.Change, changes.Destroy),
+			Short: true,
+		})
+	}
+	fields = append(fields, slack.AttachmentField{
+		Title: "User",
+		Value: info.User.Username,
+		Short: true,
+	})
 	attachment := slack.Attachment{
-		Color: colour,
-		Text:  text,
-		Fields: []slack.AttachmentField{
-			{
-				Title: "Workspace",
-				Value: applyResult.Workspace,
-				Short: true,
-			},
-			{
-				Title: "User",
-				Value: applyResult.User.Username,
-				Short: true,
-			},
-		},
+		Color:  colour,
+		Text:   text,
+		Fields: fields,
 	}
 	return []slack.Attachment{attachment}
 }
+
+func successWord(success bool) string {
+	if success {
+		return "succeeded"
+	}
+	return "failed"
+}
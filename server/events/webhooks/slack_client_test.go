@@ -97,7 +97,7 @@ func TestPostMessage_Success(t *testing.T) {
 	expParams := slack.NewPostMessageParameters()
 	expParams.Attachments = []slack.Attachment{{
 		Color: "good",
-		Text:  "Apply succeeded for <url|runatlantis/atlantis>",
+		Text:  "Apply succeeded for <url|runatlantis/atlantis#1>",
 		Fields: []slack.AttachmentField{
 			{
 				Title: "Workspace",
@@ -122,7 +122,7 @@ func TestPostMessage_Success(t *testing.T) {
 	t.Log("When apply fails, function should succeed and indicate failure")
 	result.Success = false
 	expParams.Attachments[0].Color = "danger"
-	expParams.Attachments[0].Text = "Apply failed for <url|runatlantis/atlantis>"
+	expParams.Attachments[0].Text = "Apply failed for <url|runatlantis/atlantis#1>"
 
 	err = client.PostMessage(channel, result)
 	Ok(t, err)
@@ -136,7 +136,7 @@ func TestPostMessage_Error(t *testing.T) {
 	expParams := slack.NewPostMessageParameters()
 	expParams.Attachments = []slack.Attachment{{
 		Color: "good",
-		Text:  "Apply succeeded for <url|runatlantis/atlantis>",
+		Text:  "Apply succeeded for <url|runatlantis/atlantis#1>",
 		Fields: []slack.AttachmentField{
 			{
 				Title: "Workspace",
@@ -160,6 +160,64 @@ func TestPostMessage_Error(t *testing.T) {
 	Assert(t, err != nil, "expected error")
 }
 
+func TestPostMessage_PlanSummary(t *testing.T) {
+	t.Log("A plan's message should include its project, changes and links to the pull request and Atlantis")
+	setup(t)
+	plan := webhooks.PlanResult{
+		Workspace: "production",
+		Dir:       "prod",
+		Project:   "infra",
+		Repo:      result.Repo,
+		Pull:      result.Pull,
+		User:      result.User,
+		Success:   true,
+		Changes:   &models.PlanChanges{Add: 1, Change: 2, Destroy: 3},
+		LockURL:   "https://atlantis/lock?id=1",
+	}
+
+	expParams := slack.NewPostMessageParameters()
+	expParams.Attachments = []slack.Attachment{{
+		Color: "good",
+		Text:  "Plan succeeded for <url|runatlantis/atlantis#1> (<https://atlantis/lock?id=1|view in Atlantis>)",
+		Fields: []slack.AttachmentField{
+			{Title: "Project", Value: "infra", Short: true},
+			{Title: "Dir", Value: "prod", Short: true},
+			{Title: "Workspace", Value: "production", Short: true},
+			{Title: "Changes", Value: "1 to add, 2 to change, 3 to destroy", Short: true},
+			{Title: "User", Value: "lkysow", Short: true},
+		},
+	}}
+	expParams.EscapeText = false
+
+	Ok(t, client.PostMessage("somechannel", plan))
+	underlying.VerifyWasCalledOnce().PostMessage("somechannel", "", expParams)
+}
+
+func TestPostMessage_ApplySummary(t *testing.T) {
+	t.Log("An apply's message should include the plan's changes but not link to the lock since it can be released")
+	setup(t)
+	apply := result
+	apply.Dir = "prod"
+	apply.Changes = &models.PlanChanges{Add: 1, Change: 2, Destroy: 3}
+	apply.LockURL = "https://atlantis/lock?id=1"
+
+	expParams := slack.NewPostMessageParameters()
+	expParams.Attachments = []slack.Attachment{{
+		Color: "good",
+		Text:  "Apply succeeded for <url|runatlantis/atlantis#1>",
+		Fields: []slack.AttachmentField{
+			{Title: "Dir", Value: "prod", Short: true},
+			{Title: "Workspace", Value: "production", Short: true},
+			{Title: "Changes", Value: "1 to add, 2 to change, 3 to destroy", Short: true},
+			{Title: "User", Value: "lkysow", Short: true},
+		},
+	}}
+	expParams.EscapeText = false
+
+	Ok(t, client.PostMessage("somechannel", apply))
+	underlying.VerifyWasCalledOnce().PostMessage("somechannel", "", expParams)
+}
+
 func setup(t *testing.T) {
 	RegisterMockTestingT(t)
 	underlying = mocks.NewMockUnderlyingSlackClient()
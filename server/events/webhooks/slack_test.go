@@ -14,11 +14,16 @@
 package webhooks_test
 
 import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
 	"regexp"
 	"testing"
 
+	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/webhooks"
 	"github.com/cloudposse/atlantis/server/events/webhooks/mocks"
+	"github.com/cloudposse/atlantis/server/events/webhooks/mocks/matchers"
 	"github.com/cloudposse/atlantis/server/logging"
 	. "github.com/cloudposse/atlantis/testing"
 	. "github.com/petergtz/pegomock"
@@ -33,9 +38,9 @@ func TestSend_PostMessage(t *testing.T) {
 
 	channel := "somechannel"
 	hook := webhooks.SlackWebhook{
-		Client:         client,
-		WorkspaceRegex: regex,
-		Channel:        channel,
+		Client:  client,
+		Filter:  webhooks.Filter{Events: []string{webhooks.ApplyEvent}, WorkspaceRegex: regex},
+		Channel: channel,
 	}
 	result := webhooks.ApplyResult{
 		Workspace: "production",
@@ -55,9 +60,9 @@ func TestSend_NoopSuccess(t *testing.T) {
 
 	channel := "somechannel"
 	hook := webhooks.SlackWebhook{
-		Client:         client,
-		WorkspaceRegex: regex,
-		Channel:        channel,
+		Client:  client,
+		Filter:  webhooks.Filter{Events: []string{webhooks.ApplyEvent}, WorkspaceRegex: regex},
+		Channel: channel,
 	}
 	result := webhooks.ApplyResult{
 		Workspace: "production",
@@ -66,3 +71,86 @@ func TestSend_NoopSuccess(t *testing.T) {
 	Ok(t, err)
 	client.VerifyWasCalled(Never()).PostMessage(channel, result)
 }
+
+func TestSend_EventNotMatched(t *testing.T) {
+	t.Log("Sending a hook for an event it isn't configured for should succeed without posting")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+
+	channel := "somechannel"
+	hook := webhooks.SlackWebhook{
+		Client:  client,
+		Filter:  webhooks.Filter{Events: []string{webhooks.ApplyEvent}, WorkspaceRegex: regexp.MustCompile(".*")},
+		Channel: channel,
+	}
+	result := webhooks.PlanResult{
+		Workspace: "production",
+	}
+	err := hook.Send(logging.NewNoopLogger(), result)
+	Ok(t, err)
+	client.VerifyWasCalled(Never()).PostMessage(channel, result)
+}
+
+func TestSend_DirectMessageOnFailedApply(t *testing.T) {
+	t.Log("If a failed apply's author is in the user mapping, they should be sent a direct message")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	hook := webhooks.SlackWebhook{
+		Client:      client,
+		Filter:      webhooks.Filter{Events: []string{webhooks.ApplyEvent}, WorkspaceRegex: regexp.MustCompile(".*")},
+		Channel:     "somechannel",
+		UserMapping: map[string]string{"author": "U123"},
+	}
+	result := webhooks.ApplyResult{
+		Workspace: "production",
+		Pull:      models.PullRequest{Author: "author"},
+		Success:   true,
+	}
+
+	Ok(t, hook.Send(logging.NewNoopLogger(), result))
+	client.VerifyWasCalled(Never()).PostMessage("U123", result)
+
+	result.Success = false
+	Ok(t, hook.Send(logging.NewNoopLogger(), result))
+	client.VerifyWasCalledOnce().PostMessage("U123", result)
+
+	t.Log("Authors who aren't in the mapping shouldn't be sent a direct message")
+	result.Pull.Author = "someone-else"
+	Ok(t, hook.Send(logging.NewNoopLogger(), result))
+	channels, _ := client.VerifyWasCalled(Times(4)).PostMessage(AnyString(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	Equals(t, []string{"somechannel", "somechannel", "U123", "somechannel"}, channels)
+}
+
+func TestSend_ChannelErrorSkipsDirectMessage(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	hook := webhooks.SlackWebhook{
+		Client:      client,
+		Filter:      webhooks.Filter{Events: []string{webhooks.ApplyEvent}},
+		Channel:     "somechannel",
+		UserMapping: map[string]string{"author": "U123"},
+	}
+	result := webhooks.ApplyResult{Workspace: "production", Pull: models.PullRequest{Author: "author"}}
+	When(client.PostMessage("somechannel", result)).ThenReturn(errors.New("channel_not_found"))
+
+	ErrEquals(t, "channel_not_found", hook.Send(logging.NewNoopLogger(), result))
+	client.VerifyWasCalled(Never()).PostMessage("U123", result)
+}
+
+func TestLoadSlackUserMapping(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	path := filepath.Join(tmp, "users.yaml")
+	Ok(t, ioutil.WriteFile(path, []byte("lkysow: U123\nalice: U456\n"), 0600))
+
+	mapping, err := webhooks.LoadSlackUserMapping(path)
+	Ok(t, err)
+	Equals(t, map[string]string{"lkysow": "U123", "alice": "U456"}, mapping)
+
+	Ok(t, ioutil.WriteFile(path, []byte("- lkysow\n"), 0600))
+	_, err = webhooks.LoadSlackUserMapping(path)
+	ErrContains(t, "parsing slack user mapping", err)
+
+	_, err = webhooks.LoadSlackUserMapping(filepath.Join(tmp, "missing.yaml"))
+	ErrContains(t, "reading slack user mapping", err)
+}
@@ -15,32 +15,87 @@ package webhooks
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
+	"time"
 
 	"errors"
 
-	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/logging"
 )
 
 const SlackKind = "slack"
-const ApplyEvent = "apply"
+const HTTPKind = "http"
+const EmailKind = "email"
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_sender.go Sender
 
 // Sender sends webhooks.
 type Sender interface {
 	// Send sends the webhook (if the implementation thinks it should).
-	Send(log *logging.SimpleLogger, applyResult ApplyResult) error
+	Send(log *logging.SimpleLogger, event Event) error
 }
 
-// ApplyResult is the result of a terraform apply.
-type ApplyResult struct {
-	Workspace string
-	Repo      models.Repo
-	Pull      models.PullRequest
-	User      models.User
-	Success   bool
+// Filter decides which events a webhook is sent for.
+type Filter struct {
+	// Events are the types of events to send the webhook for, ex. ApplyEvent.
+	Events []string
+	// WorkspaceRegex must match the event's workspace. Events that aren't for
+	// a single project, ex. AutoplanStartEvent, always match.
+	WorkspaceRegex *regexp.Regexp
+	// RepoRegex must match the full name of the event's repo, ex.
+	// "runatlantis/atlantis". If nil, every repo matches.
+	RepoRegex *regexp.Regexp
+	// ProjectRegex must match the event's project name, or its dir if the
+	// project isn't named. Like WorkspaceRegex, events that aren't for a single
+	// project always match. If nil, every project matches.
+	ProjectRegex *regexp.Regexp
+	// DirRegex must match the event's project dir, ex. "envs/prod". Like
+	// WorkspaceRegex, events that aren't for a single project always match. If
+	// nil, every dir matches.
+	DirRegex *regexp.Regexp
+	// BranchRegex must match the pull request's head branch. If nil, every
+	// branch matches.
+	BranchRegex *regexp.Regexp
+}
+
+// Matches returns true if the webhook should be sent for event.
+func (f Filter) Matches(event Event) bool {
+	typeMatches := false
+	for _, e := range f.Events {
+		if e == event.EventType() {
+			typeMatches = true
+			break
+		}
+	}
+	if !typeMatches {
+		return false
+	}
+	info := event.EventInfo()
+	if f.RepoRegex != nil && !f.RepoRegex.MatchString(info.Repo.FullName) {
+		return false
+	}
+	if f.BranchRegex != nil && !f.BranchRegex.MatchString(info.Pull.Branch) {
+		return false
+	}
+	if info.Workspace == "" {
+		return true
+	}
+	if f.WorkspaceRegex != nil && !f.WorkspaceRegex.MatchString(info.Workspace) {
+		return false
+	}
+	project := info.Project
+	if project == "" {
+		project = info.Dir
+	}
+	if f.ProjectRegex != nil && !f.ProjectRegex.MatchString(project) {
+		return false
+	}
+	if f.DirRegex != nil && !f.DirRegex.MatchString(info.Dir) {
+		return false
+	}
+	return true
 }
 
 // MultiWebhookSender sends multiple webhooks for each one it's configured for.
@@ -49,10 +104,33 @@ type MultiWebhookSender struct {
 }
 
 type Config struct {
+	// Event and Events are the types of events to send the webhook for. Event
+	// is kept for configs written when only one event could be set.
 	Event          string
+	Events         []string
 	WorkspaceRegex string
-	Kind           string
-	Channel        string
+	// RepoRegex, ProjectRegex, DirRegex and BranchRegex are optional. See
+	// Filter.
+	RepoRegex    string
+	ProjectRegex string
+	DirRegex     string
+	BranchRegex  string
+	Kind         string
+	Channel      string
+	// UserMapping is the path to a file mapping VCS usernames to Slack member
+	// ids. If set, pull request authors are sent a direct message when their
+	// applies fail. It only applies to slack webhooks.
+	UserMapping string
+	// URL, Headers, Secret, Retries and Timeout only apply to http webhooks.
+	URL     string
+	Headers map[string]string
+	Secret  string
+	Retries int
+	Timeout time.Duration
+	// To and SMTP only apply to email webhooks. SMTP is the server-wide SMTP
+	// configuration.
+	To   []string
+	SMTP SMTPConfig
 }
 
 func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookSender, error) {
@@ -62,11 +140,41 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 		if err != nil {
 			return nil, err
 		}
-		if c.Kind == "" || c.Event == "" {
+		repoRegex, err := compileOptionalRegex(c.RepoRegex)
+		if err != nil {
+			return nil, err
+		}
+		projectRegex, err := compileOptionalRegex(c.ProjectRegex)
+		if err != nil {
+			return nil, err
+		}
+		dirRegex, err := compileOptionalRegex(c.DirRegex)
+		if err != nil {
+			return nil, err
+		}
+		branchRegex, err := compileOptionalRegex(c.BranchRegex)
+		if err != nil {
+			return nil, err
+		}
+		events := c.Events
+		if c.Event != "" {
+			events = append([]string{c.Event}, events...)
+		}
+		if c.Kind == "" || len(events) == 0 {
 			return nil, errors.New("must specify \"kind\" and \"event\" keys for webhooks")
 		}
-		if c.Event != ApplyEvent {
-			return nil, fmt.Errorf("\"event: %s\" not supported. Only \"event: %s\" is supported right now", c.Event, ApplyEvent)
+		for _, e := range events {
+			if !isEventType(e) {
+				return nil, fmt.Errorf("\"event: %s\" not supported. Supported events are %s", e, strings.Join(EventTypes, ", "))
+			}
+		}
+		filter := Filter{
+			Events:         events,
+			WorkspaceRegex: r,
+			RepoRegex:      repoRegex,
+			ProjectRegex:   projectRegex,
+			DirRegex:       dirRegex,
+			BranchRegex:    branchRegex,
 		}
 		switch c.Kind {
 		case SlackKind:
@@ -76,13 +184,32 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 			if c.Channel == "" {
 				return nil, errors.New("must specify \"channel\" if using a webhook of \"kind: slack\"")
 			}
-			slack, err := NewSlack(r, c.Channel, client)
+			var userMapping map[string]string
+			if c.UserMapping != "" {
+				userMapping, err = LoadSlackUserMapping(c.UserMapping)
+				if err != nil {
+					return nil, err
+				}
+			}
+			slack, err := NewSlack(filter, c.Channel, userMapping, client)
 			if err != nil {
 				return nil, err
 			}
 			webhooks = append(webhooks, slack)
+		case HTTPKind:
+			u, err := url.Parse(c.URL)
+			if c.URL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, errors.New("must specify an http or https \"url\" if using a webhook of \"kind: http\"")
+			}
+			webhooks = append(webhooks, NewHTTP(filter, c.URL, c.Headers, c.Secret, c.Retries, c.Timeout))
+		case EmailKind:
+			email, err := NewEmail(filter, c.To, c.SMTP)
+			if err != nil {
+				return nil, err
+			}
+			webhooks = append(webhooks, email)
 		default:
-			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\" is supported right now", c.Kind, SlackKind)
+			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\", \"kind: %s\" and \"kind: %s\" are supported right now", c.Kind, SlackKind, HTTPKind, EmailKind)
 		}
 	}
 
@@ -91,11 +218,19 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 	}, nil
 }
 
+// compileOptionalRegex compiles expr or returns nil if it's empty.
+func compileOptionalRegex(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
 // Send sends the webhook using its Webhooks.
-func (w *MultiWebhookSender) Send(log *logging.SimpleLogger, result ApplyResult) error {
+func (w *MultiWebhookSender) Send(log *logging.SimpleLogger, event Event) error {
 	for _, w := range w.Webhooks {
-		if err := w.Send(log, result); err != nil {
-			log.Warn("error sending slack webhook: %s", err)
+		if err := w.Send(log, event); err != nil {
+			log.Warn("error sending webhook: %s", err)
 		}
 	}
 	return nil
@@ -14,9 +14,13 @@
 package webhooks_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/webhooks"
 	"github.com/cloudposse/atlantis/server/events/webhooks/mocks"
 	"github.com/cloudposse/atlantis/server/logging"
@@ -78,7 +82,171 @@ func TestNewWebhooksManager_UnsupportedEvent(t *testing.T) {
 	configs[0].Event = unsupportedEvent
 	_, err := webhooks.NewMultiWebhookSender(configs, client)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"event: badevent\" not supported. Only \"event: apply\" is supported right now", err.Error())
+	Equals(t, "\"event: badevent\" not supported. Supported events are apply, plan, autoplan_start, autoplan_finish, lock, unlock, command_failure", err.Error())
+}
+
+func TestNewWebhooksManager_Events(t *testing.T) {
+	t.Log("When a config lists multiple events, the webhook is sent for each of them")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := []webhooks.Config{{
+		Event:          webhooks.ApplyEvent,
+		Events:         []string{webhooks.PlanEvent, webhooks.CommandFailureEvent},
+		WorkspaceRegex: validRegex,
+		Kind:           webhooks.HTTPKind,
+		URL:            "https://example.com/hook",
+	}}
+	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	Ok(t, err)
+	hook := m.Webhooks[0].(*webhooks.HTTPWebhook)
+	Equals(t, []string{"apply", "plan", "command_failure"}, hook.Filter.Events)
+
+	t.Log("An unsupported event in events should error")
+	configs[0].Events = []string{"badevent"}
+	_, err = webhooks.NewMultiWebhookSender(configs, client)
+	ErrContains(t, "\"event: badevent\" not supported", err)
+}
+
+func TestFilter_Matches(t *testing.T) {
+	filter := webhooks.Filter{
+		Events:         []string{webhooks.ApplyEvent, webhooks.AutoplanStartEvent},
+		WorkspaceRegex: regexp.MustCompile("^prod"),
+	}
+	cases := []struct {
+		description string
+		event       webhooks.Event
+		exp         bool
+	}{
+		{"matching event and workspace", webhooks.ApplyResult{Workspace: "production"}, true},
+		{"matching event but not workspace", webhooks.ApplyResult{Workspace: "staging"}, false},
+		{"matching workspace but not event", webhooks.PlanResult{Workspace: "production"}, false},
+		{"event without a workspace", webhooks.AutoplanStart{}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, filter.Matches(c.event))
+		})
+	}
+}
+
+func TestFilter_MatchesRepoAndProject(t *testing.T) {
+	filter := webhooks.Filter{
+		Events:         []string{webhooks.ApplyEvent, webhooks.AutoplanStartEvent},
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		RepoRegex:      regexp.MustCompile("^runatlantis/"),
+		ProjectRegex:   regexp.MustCompile("^(infra|prod)$"),
+	}
+	repo := models.Repo{FullName: "runatlantis/atlantis"}
+	cases := []struct {
+		description string
+		event       webhooks.Event
+		exp         bool
+	}{
+		{"matching repo and project name", webhooks.ApplyResult{Repo: repo, Workspace: "default", Dir: "a", Project: "infra"}, true},
+		{"matching repo and unnamed project's dir", webhooks.ApplyResult{Repo: repo, Workspace: "default", Dir: "prod"}, true},
+		{"matching repo but not project", webhooks.ApplyResult{Repo: repo, Workspace: "default", Dir: "prod", Project: "other"}, false},
+		{"matching project but not repo", webhooks.ApplyResult{Repo: models.Repo{FullName: "other/infra"}, Workspace: "default", Project: "infra"}, false},
+		{"event without a project checks only the repo", webhooks.AutoplanStart{Repo: repo}, true},
+		{"event without a project in another repo", webhooks.AutoplanStart{Repo: models.Repo{FullName: "other/infra"}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, filter.Matches(c.event))
+		})
+	}
+}
+
+func TestFilter_MatchesDirAndBranch(t *testing.T) {
+	filter := webhooks.Filter{
+		Events:      []string{webhooks.PlanEvent, webhooks.AutoplanFinishEvent},
+		DirRegex:    regexp.MustCompile("^envs/prod"),
+		BranchRegex: regexp.MustCompile("^release/"),
+	}
+	release := models.PullRequest{Branch: "release/1.0"}
+	cases := []struct {
+		description string
+		event       webhooks.Event
+		exp         bool
+	}{
+		{"matching dir and branch", webhooks.PlanResult{Pull: release, Workspace: "default", Dir: "envs/prod/vpc"}, true},
+		{"matching branch but not dir", webhooks.PlanResult{Pull: release, Workspace: "default", Dir: "envs/staging"}, false},
+		{"matching dir but not branch", webhooks.PlanResult{Pull: models.PullRequest{Branch: "feature"}, Workspace: "default", Dir: "envs/prod"}, false},
+		{"event without a dir checks only the branch", webhooks.AutoplanFinish{Pull: release}, true},
+		{"event without a dir on another branch", webhooks.AutoplanFinish{Pull: models.PullRequest{Branch: "feature"}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, filter.Matches(c.event))
+		})
+	}
+}
+
+func TestNewWebhooksManager_InvalidOptionalRegexes(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := validConfigs()
+	configs[0].RepoRegex = "("
+	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	ErrContains(t, "error parsing regexp", err)
+
+	configs = validConfigs()
+	configs[0].ProjectRegex = "("
+	_, err = webhooks.NewMultiWebhookSender(configs, client)
+	ErrContains(t, "error parsing regexp", err)
+
+	configs = validConfigs()
+	configs[0].DirRegex = "("
+	_, err = webhooks.NewMultiWebhookSender(configs, client)
+	ErrContains(t, "error parsing regexp", err)
+
+	configs = validConfigs()
+	configs[0].BranchRegex = "("
+	_, err = webhooks.NewMultiWebhookSender(configs, client)
+	ErrContains(t, "error parsing regexp", err)
+}
+
+func TestNewWebhooksManager_Filters(t *testing.T) {
+	t.Log("Each filter in a config should be set on its webhook")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := []webhooks.Config{{
+		Event:          validEvent,
+		WorkspaceRegex: validRegex,
+		RepoRegex:      "^org/",
+		ProjectRegex:   "^infra$",
+		DirRegex:       "^prod",
+		BranchRegex:    "^main$",
+		Kind:           webhooks.HTTPKind,
+		URL:            "https://example.com/hook",
+	}}
+	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	Ok(t, err)
+	filter := m.Webhooks[0].(*webhooks.HTTPWebhook).Filter
+	Equals(t, "^org/", filter.RepoRegex.String())
+	Equals(t, "^infra$", filter.ProjectRegex.String())
+	Equals(t, "^prod", filter.DirRegex.String())
+	Equals(t, "^main$", filter.BranchRegex.String())
+}
+
+func TestNewWebhooksManager_SlackUserMapping(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	When(client.TokenIsSet()).ThenReturn(true)
+	When(client.ChannelExists(validChannel)).ThenReturn(true, nil)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	path := filepath.Join(tmp, "users.yaml")
+	Ok(t, ioutil.WriteFile(path, []byte("lkysow: U123\n"), 0600))
+
+	configs := validConfigs()
+	configs[0].UserMapping = path
+	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	Ok(t, err)
+	Equals(t, map[string]string{"lkysow": "U123"}, m.Webhooks[0].(*webhooks.SlackWebhook).UserMapping)
+
+	configs[0].UserMapping = filepath.Join(tmp, "missing.yaml")
+	_, err = webhooks.NewMultiWebhookSender(configs, client)
+	ErrContains(t, "reading slack user mapping", err)
 }
 
 func TestNewWebhooksManager_NoKind(t *testing.T) {
@@ -103,7 +271,32 @@ func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
 	configs[0].Kind = unsupportedKind
 	_, err := webhooks.NewMultiWebhookSender(configs, client)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\" is supported right now", err.Error())
+	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\", \"kind: http\" and \"kind: email\" are supported right now", err.Error())
+}
+
+func TestNewWebhooksManager_HTTPNoURL(t *testing.T) {
+	t.Log("When an http webhook doesn't have a valid url, an error is returned")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	for _, u := range []string{"", "example.com", "ftp://example.com"} {
+		configs := []webhooks.Config{{Event: validEvent, WorkspaceRegex: validRegex, Kind: webhooks.HTTPKind, URL: u}}
+		_, err := webhooks.NewMultiWebhookSender(configs, client)
+		ErrEquals(t, "must specify an http or https \"url\" if using a webhook of \"kind: http\"", err)
+	}
+}
+
+func TestNewWebhooksManager_HTTPSuccess(t *testing.T) {
+	t.Log("When an http webhook is configured, it doesn't need a slack token")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	configs := []webhooks.Config{{Event: validEvent, WorkspaceRegex: validRegex, Kind: webhooks.HTTPKind, URL: "https://example.com/hook"}}
+	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	Ok(t, err)
+	Equals(t, 1, len(m.Webhooks))
+	hook, ok := m.Webhooks[0].(*webhooks.HTTPWebhook)
+	Assert(t, ok, "exp an http webhook")
+	Equals(t, webhooks.DefaultHTTPRetries, hook.Retries)
+	Equals(t, webhooks.DefaultHTTPTimeout, hook.Client.Timeout)
 }
 
 func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
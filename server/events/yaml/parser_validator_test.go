@@ -121,6 +121,19 @@ projects:
 			expErr: "version: must equal 2.",
 		},
 
+		// Apply requirements.
+		{
+			description: "approvals typo",
+			input: `
+version: 2
+projects:
+- dir: "."
+  apply_requirements:
+  - approvals: two
+`,
+			expErr: "projects: (0: (apply_requirements: \"approvals: two\" must be approvals: N where N is a whole number and the only key.).).",
+		},
+
 		// Projects key.
 		{
 			description: "empty projects list",
@@ -10,6 +10,7 @@ import (
 // Config is the representation for the whole config file at the top level.
 type Config struct {
 	Version   *int                `yaml:"version,omitempty"`
+	Automerge *bool               `yaml:"automerge,omitempty"`
 	Projects  []Project           `yaml:"projects,omitempty"`
 	Workflows map[string]Workflow `yaml:"workflows,omitempty"`
 }
@@ -42,8 +43,13 @@ func (c Config) ToValid() valid.Config {
 	for k, v := range c.Workflows {
 		validWorkflows[k] = v.ToValid()
 	}
+	automerge := false
+	if c.Automerge != nil {
+		automerge = *c.Automerge
+	}
 	return valid.Config{
 		Version:   *c.Version,
+		Automerge: automerge,
 		Projects:  validProjects,
 		Workflows: validWorkflows,
 	}
@@ -96,6 +96,7 @@ func TestConfig_UnmarshalYAML(t *testing.T) {
 			description: "should use values if set",
 			input: `
 version: 2
+automerge: true
 projects:
 - dir: mydir
   workspace: myworkspace
@@ -112,7 +113,8 @@ workflows:
     apply:
      steps: []`,
 			exp: raw.Config{
-				Version: Int(2),
+				Version:   Int(2),
+				Automerge: Bool(true),
 				Projects: []raw.Project{
 					{
 						Dir:              String("mydir"),
@@ -217,7 +219,8 @@ func TestConfig_ToValid(t *testing.T) {
 		{
 			description: "everything set",
 			input: raw.Config{
-				Version: Int(2),
+				Version:   Int(2),
+				Automerge: Bool(true),
 				Workflows: map[string]raw.Workflow{
 					"myworkflow": {
 						Apply: &raw.Stage{
@@ -243,7 +246,8 @@ func TestConfig_ToValid(t *testing.T) {
 				},
 			},
 			exp: valid.Config{
-				Version: 2,
+				Version:   2,
+				Automerge: true,
 				Workflows: map[string]valid.Workflow{
 					"myworkflow": {
 						Apply: &valid.Stage{
@@ -3,6 +3,8 @@ package raw
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cloudposse/atlantis/server/events/yaml/valid"
@@ -12,20 +14,81 @@ import (
 )
 
 const (
-	DefaultWorkspace           = "default"
-	ApprovedApplyRequirement   = "approved"
-	ApprovedDestroyRequirement = "approved"
+	DefaultWorkspace            = "default"
+	ApprovedApplyRequirement    = "approved"
+	ApprovedDestroyRequirement  = "approved"
+	MergeableApplyRequirement   = "mergeable"
+	MergeableDestroyRequirement = "mergeable"
+	// CodeOwnersApprovedApplyRequirement requires that one of the project's
+	// owners in the repo's CODEOWNERS file approved the pull request.
+	CodeOwnersApprovedApplyRequirement   = "codeowners_approved"
+	CodeOwnersApprovedDestroyRequirement = "codeowners_approved"
+	// ApprovalsRequirementKey is the key of the requirement that a pull
+	// request is approved by a number of reviewers, ex. approvals: 2.
+	ApprovalsRequirementKey = "approvals"
 )
 
+// Requirements are apply or destroy requirements. In YAML, each requirement
+// is either a string, ex. approved or mergeable, or a map for the number of
+// approvals required, ex. approvals: 2. Maps are stored as a string, ex.
+// "approvals: 2", so requirements can be passed around as strings. See
+// ParseApprovalsRequirement.
+type Requirements []string
+
+func (r *Requirements) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var elems []interface{}
+	if err := unmarshal(&elems); err != nil {
+		return err
+	}
+	// Leave nil rather than empty so an empty list looks the same as no list.
+	if elems == nil {
+		*r = nil
+		return nil
+	}
+	reqs := Requirements{}
+	for _, e := range elems {
+		switch v := e.(type) {
+		case string:
+			reqs = append(reqs, v)
+		case map[interface{}]interface{}:
+			// We validate that there's a single approvals key later.
+			var keys []string
+			for k, val := range v {
+				keys = append(keys, fmt.Sprintf("%v: %v", k, val))
+			}
+			sort.Strings(keys)
+			reqs = append(reqs, strings.Join(keys, ", "))
+		default:
+			reqs = append(reqs, fmt.Sprintf("%v", v))
+		}
+	}
+	*r = reqs
+	return nil
+}
+
+// ParseApprovalsRequirement returns the number of approvals required by req
+// if it's an approvals requirement, ex. "approvals: 2".
+func ParseApprovalsRequirement(req string) (int, bool) {
+	split := strings.SplitN(req, ":", 2)
+	if len(split) != 2 || strings.TrimSpace(split[0]) != ApprovalsRequirementKey {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(split[1]))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
 type Project struct {
-	Name                *string   `yaml:"name,omitempty"`
-	Dir                 *string   `yaml:"dir,omitempty"`
-	Workspace           *string   `yaml:"workspace,omitempty"`
-	Workflow            *string   `yaml:"workflow,omitempty"`
-	TerraformVersion    *string   `yaml:"terraform_version,omitempty"`
-	Autoplan            *Autoplan `yaml:"autoplan,omitempty"`
-	ApplyRequirements   []string  `yaml:"apply_requirements,omitempty"`
-	DestroyRequirements []string  `yaml:"destroy_requirements,omitempty"`
+	Name                *string      `yaml:"name,omitempty"`
+	Dir                 *string      `yaml:"dir,omitempty"`
+	Workspace           *string      `yaml:"workspace,omitempty"`
+	Workflow            *string      `yaml:"workflow,omitempty"`
+	TerraformVersion    *string      `yaml:"terraform_version,omitempty"`
+	Autoplan            *Autoplan    `yaml:"autoplan,omitempty"`
+	ApplyRequirements   Requirements `yaml:"apply_requirements,omitempty"`
+	DestroyRequirements Requirements `yaml:"destroy_requirements,omitempty"`
 }
 
 func (p Project) Validate() error {
@@ -35,21 +98,24 @@ func (p Project) Validate() error {
 		}
 		return nil
 	}
-	validApplyReq := func(value interface{}) error {
-		reqs := value.([]string)
-		for _, r := range reqs {
-			if r != ApprovedApplyRequirement {
-				return fmt.Errorf("%q not supported, only %s is supported", r, ApprovedApplyRequirement)
+	validReqs := func(value interface{}) error {
+		for _, r := range value.(Requirements) {
+			if r == ApprovedApplyRequirement || r == MergeableApplyRequirement || r == CodeOwnersApprovedApplyRequirement {
+				continue
 			}
-		}
-		return nil
-	}
-	validDestroyReq := func(value interface{}) error {
-		reqs := value.([]string)
-		for _, r := range reqs {
-			if r != ApprovedDestroyRequirement {
-				return fmt.Errorf("%q not supported, only %s is supported", r, ApprovedDestroyRequirement)
+			if n, ok := ParseApprovalsRequirement(r); ok {
+				if n < 1 {
+					return fmt.Errorf("%q must require at least 1 approval", r)
+				}
+				continue
+			}
+			// Catch typos in the number of approvals, ex. approvals: two, or
+			// extra keys, ex. {approvals: 2, mergeable: true}, with a clearer
+			// error than the generic one.
+			if strings.HasPrefix(r, ApprovalsRequirementKey+":") {
+				return fmt.Errorf("%q must be %s: N where N is a whole number and the only key", r, ApprovalsRequirementKey)
 			}
+			return fmt.Errorf("%q not supported, only %s, %s, %s and %s: N are supported", r, ApprovedApplyRequirement, MergeableApplyRequirement, CodeOwnersApprovedApplyRequirement, ApprovalsRequirementKey)
 		}
 		return nil
 	}
@@ -59,6 +125,13 @@ func (p Project) Validate() error {
 			return nil
 		}
 		_, err := version.NewVersion(*strPtr)
+		if err == nil {
+			return nil
+		}
+		// It can also be a constraint, ex. ~> 0.11.8.
+		if _, cErr := version.NewConstraint(*strPtr); cErr == nil {
+			return nil
+		}
 		return errors.Wrapf(err, "version %q could not be parsed", *strPtr)
 	}
 	validName := func(value interface{}) error {
@@ -73,8 +146,8 @@ func (p Project) Validate() error {
 	}
 	return validation.ValidateStruct(&p,
 		validation.Field(&p.Dir, validation.Required, validation.By(hasDotDot)),
-		validation.Field(&p.ApplyRequirements, validation.By(validApplyReq)),
-		validation.Field(&p.DestroyRequirements, validation.By(validDestroyReq)),
+		validation.Field(&p.ApplyRequirements, validation.By(validReqs)),
+		validation.Field(&p.DestroyRequirements, validation.By(validReqs)),
 		validation.Field(&p.TerraformVersion, validation.By(validTFVersion)),
 		validation.Field(&p.Name, validation.By(validName)),
 	)
@@ -96,7 +169,12 @@ func (p Project) ToValid() valid.Project {
 
 	v.Workflow = p.Workflow
 	if p.TerraformVersion != nil {
-		v.TerraformVersion, _ = version.NewVersion(*p.TerraformVersion)
+		var err error
+		v.TerraformVersion, err = version.NewVersion(*p.TerraformVersion)
+		if err != nil {
+			v.TerraformVersion = nil
+			v.TerraformVersionConstraints, _ = version.NewConstraint(*p.TerraformVersion)
+		}
 	}
 	if p.Autoplan == nil {
 		v.Autoplan = DefaultAutoPlan()
@@ -105,10 +183,10 @@ func (p Project) ToValid() valid.Project {
 	}
 
 	// There are no default apply requirements.
-	v.ApplyRequirements = p.ApplyRequirements
+	v.ApplyRequirements = []string(p.ApplyRequirements)
 
 	// There are no default destroy requirements.
-	v.DestroyRequirements = p.DestroyRequirements
+	v.DestroyRequirements = []string(p.DestroyRequirements)
 
 	v.Name = p.Name
 
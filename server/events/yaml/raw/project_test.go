@@ -56,6 +56,22 @@ apply_requirements:
 				ApplyRequirements: []string{"mergeable"},
 			},
 		},
+		{
+			description: "approvals requirement",
+			input: `
+dir: mydir
+apply_requirements:
+- approved
+- approvals: 2
+destroy_requirements:
+- approvals: 3
+- mergeable`,
+			exp: raw.Project{
+				Dir:                 String("mydir"),
+				ApplyRequirements:   []string{"approved", "approvals: 2"},
+				DestroyRequirements: []string{"approvals: 3", "mergeable"},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -101,7 +117,7 @@ func TestProject_Validate(t *testing.T) {
 				Dir:               String("."),
 				ApplyRequirements: []string{"unsupported"},
 			},
-			expErr: "apply_requirements: \"unsupported\" not supported, only approved is supported.",
+			expErr: "apply_requirements: \"unsupported\" not supported, only approved, mergeable, codeowners_approved and approvals: N are supported.",
 		},
 		{
 			description: "apply reqs with valid",
@@ -111,6 +127,54 @@ func TestProject_Validate(t *testing.T) {
 			},
 			expErr: "",
 		},
+		{
+			description: "apply reqs with mergeable and approvals",
+			input: raw.Project{
+				Dir:               String("."),
+				ApplyRequirements: []string{"mergeable", "approvals: 2"},
+			},
+			expErr: "",
+		},
+		{
+			description: "apply reqs with zero approvals",
+			input: raw.Project{
+				Dir:               String("."),
+				ApplyRequirements: []string{"approvals: 0"},
+			},
+			expErr: "apply_requirements: \"approvals: 0\" must require at least 1 approval.",
+		},
+		{
+			description: "apply reqs with unsupported map",
+			input: raw.Project{
+				Dir:               String("."),
+				ApplyRequirements: []string{"reviews: 2"},
+			},
+			expErr: "apply_requirements: \"reviews: 2\" not supported, only approved, mergeable, codeowners_approved and approvals: N are supported.",
+		},
+		{
+			description: "apply reqs with approvals that aren't a number",
+			input: raw.Project{
+				Dir:               String("."),
+				ApplyRequirements: []string{"approvals: two"},
+			},
+			expErr: "apply_requirements: \"approvals: two\" must be approvals: N where N is a whole number and the only key.",
+		},
+		{
+			description: "apply reqs with approvals and another key",
+			input: raw.Project{
+				Dir:               String("."),
+				ApplyRequirements: []string{"approvals: 2, mergeable: true"},
+			},
+			expErr: "apply_requirements: \"approvals: 2, mergeable: true\" must be approvals: N where N is a whole number and the only key.",
+		},
+		{
+			description: "destroy reqs with unsupported",
+			input: raw.Project{
+				Dir:                 String("."),
+				DestroyRequirements: []string{"unsupported"},
+			},
+			expErr: "destroy_requirements: \"unsupported\" not supported, only approved, mergeable, codeowners_approved and approvals: N are supported.",
+		},
 		{
 			description: "empty tf version string",
 			input: raw.Project{
@@ -135,6 +199,22 @@ func TestProject_Validate(t *testing.T) {
 			},
 			expErr: "",
 		},
+		{
+			description: "tf version constraint",
+			input: raw.Project{
+				Dir:              String("."),
+				TerraformVersion: String("~> 0.11.8"),
+			},
+			expErr: "",
+		},
+		{
+			description: "invalid tf version constraint",
+			input: raw.Project{
+				Dir:              String("."),
+				TerraformVersion: String("~> latest"),
+			},
+			expErr: "terraform_version: version \"~> latest\" could not be parsed: Malformed version: ~> latest.",
+		},
 		{
 			description: "empty string for project name",
 			input: raw.Project{
@@ -225,6 +305,22 @@ func TestProject_ToValid(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "tf version constraint",
+			input: raw.Project{
+				Dir:              String("."),
+				TerraformVersion: String("~> 0.11.8"),
+			},
+			exp: valid.Project{
+				Dir:                         ".",
+				Workspace:                   "default",
+				TerraformVersionConstraints: mustConstraint("~> 0.11.8"),
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*"},
+					Enabled:      true,
+				},
+			},
+		},
 		{
 			description: "dir with /",
 			input: raw.Project{
@@ -290,3 +386,32 @@ func TestProject_ToValid(t *testing.T) {
 		})
 	}
 }
+
+func TestParseApprovalsRequirement(t *testing.T) {
+	cases := []struct {
+		req   string
+		expN  int
+		expOk bool
+	}{
+		{"approvals: 2", 2, true},
+		{"approvals:3", 3, true},
+		{"approvals: two", 0, false},
+		{"approved", 0, false},
+		{"reviews: 2", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.req, func(t *testing.T) {
+			n, ok := raw.ParseApprovalsRequirement(c.req)
+			Equals(t, c.expN, n)
+			Equals(t, c.expOk, ok)
+		})
+	}
+}
+
+func mustConstraint(constraint string) version.Constraints {
+	c, err := version.NewConstraint(constraint)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
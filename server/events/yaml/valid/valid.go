@@ -8,7 +8,10 @@ import "github.com/hashicorp/go-version"
 type Config struct {
 	// Version is the version of the atlantis YAML file. Will always be equal
 	// to 2.
-	Version   int
+	Version int
+	// Automerge is true if pull requests should be merged once all their
+	// plans have been applied successfully.
+	Automerge bool
 	Projects  []Project
 	Workflows map[string]Workflow
 }
@@ -60,14 +63,17 @@ func (c Config) FindProjectByName(name string) *Project {
 }
 
 type Project struct {
-	Dir                 string
-	Workspace           string
-	Name                *string
-	Workflow            *string
-	TerraformVersion    *version.Version
-	Autoplan            Autoplan
-	ApplyRequirements   []string
-	DestroyRequirements []string
+	Dir              string
+	Workspace        string
+	Name             *string
+	Workflow         *string
+	TerraformVersion *version.Version
+	// TerraformVersionConstraints is set instead of TerraformVersion if
+	// terraform_version is a constraint, ex. ~> 0.11.8.
+	TerraformVersionConstraints version.Constraints
+	Autoplan                    Autoplan
+	ApplyRequirements           []string
+	DestroyRequirements         []string
 }
 
 // GetName returns the name of the project or an empty string if there is no
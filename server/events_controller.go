@@ -14,23 +14,33 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/cloudposse/atlantis/server/events"
 	"github.com/cloudposse/atlantis/server/events/models"
 	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/cloudposse/atlantis/server/events/vcs/azuredevops"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/cloudposse/atlantis/server/events/vcs/gitea"
 	"github.com/cloudposse/atlantis/server/logging"
 	"github.com/google/go-github/github"
+	"github.com/gorilla/mux"
 	"github.com/lkysow/go-gitlab"
 	"github.com/pkg/errors"
 )
 
 const githubHeader = "X-Github-Event"
+const githubRequestIDHeader = "X-Github-Delivery"
 const gitlabHeader = "X-Gitlab-Event"
+const gitlabRequestIDHeader = "X-Gitlab-Event-UUID"
 
 // bitbucketEventTypeHeader is the same in both cloud and server.
 const bitbucketEventTypeHeader = "X-Event-Key"
@@ -38,6 +48,16 @@ const bitbucketCloudRequestIDHeader = "X-Request-UUID"
 const bitbucketServerRequestIDHeader = "X-Request-ID"
 const bitbucketServerSignatureHeader = "X-Hub-Signature"
 
+// Gitea also sends X-GitHub-Event for compatibility so these headers must be
+// checked before the GitHub header.
+const giteaEventTypeHeader = "X-Gitea-Event"
+const giteaRequestIDHeader = "X-Gitea-Delivery"
+const giteaSignatureHeader = "X-Gitea-Signature"
+
+// azuredevopsHeader is sent by Azure DevOps service hooks. The event type is
+// only in the body.
+const azuredevopsHeader = "Request-Id"
+
 // EventsController handles all webhook requests which signify 'events' in the
 // VCS host, ex. GitHub.
 type EventsController struct {
@@ -58,6 +78,9 @@ type EventsController struct {
 	GitlabWebhookSecret  []byte
 	RepoWhitelistChecker *events.RepoWhitelistChecker
 	TeamWhitelistChecker *events.TeamWhitelistChecker
+	// PolicyApprovers are the teams whose members can approve plans that
+	// failed their policy checks. If empty, no one can.
+	PolicyApprovers []string
 	// SupportedVCSHosts is which VCS hosts Atlantis was configured upon
 	// startup to support.
 	SupportedVCSHosts []models.VCSHostType
@@ -67,11 +90,155 @@ type EventsController struct {
 	// UI that identifies this call as coming from Bitbucket. If empty, no
 	// request validation is done.
 	BitbucketWebhookSecret []byte
+	// GiteaWebhookSecret is the secret added to this webhook via the Gitea
+	// UI that identifies this call as coming from Gitea. If empty, no
+	// request validation is done.
+	GiteaWebhookSecret []byte
+	// AzureDevopsWebhookUser and AzureDevopsWebhookPassword are the basic
+	// auth credentials added to the service hook via the Azure DevOps UI that
+	// identify this call as coming from Azure DevOps. If the user is empty, no
+	// request validation is done.
+	AzureDevopsWebhookUser     []byte
+	AzureDevopsWebhookPassword []byte
+	// VCSResponseCache is invalidated for a pull request when we receive one
+	// of its pull request events. Can be nil if responses aren't cached.
+	VCSResponseCache *vcs.ResponseCache
+	// VCSHosts maps from the hostname of each additional VCS host to how its
+	// webhooks are handled. Its webhooks must be sent to /events/{hostname}.
+	VCSHosts map[string]VCSHostWebhooks
+	// Deliveries records the webhooks we receive so duplicate deliveries are
+	// ignored and deliveries can be replayed. Can be nil if deliveries aren't
+	// recorded.
+	Deliveries DeliveryStore
+	// hostname is the additional VCS host whose webhook is being handled. It's
+	// empty when handling webhooks from the default hosts.
+	hostname string
+}
+
+// VCSHostWebhooks is how webhooks from an additional VCS host are handled.
+type VCSHostWebhooks struct {
+	Type   models.VCSHostType
+	Parser events.EventParsing
+	// CommentParser is optional. If set, it's used instead of the default
+	// comment parser so the host's bot user can be addressed in comments.
+	CommentParser events.CommentParsing
+	// WebhookSecret is the secret used to validate the host's webhooks. If
+	// empty, no request validation is done.
+	WebhookSecret []byte
+}
+
+// PostHost handles POST webhook requests from an additional VCS host.
+func (e *EventsController) PostHost(w http.ResponseWriter, r *http.Request) {
+	hostname := mux.Vars(r)["hostname"]
+	host, ok := e.VCSHosts[hostname]
+	if !ok {
+		e.respond(w, logging.Debug, http.StatusNotFound, "Ignoring request since %q is not a configured VCS host", hostname)
+		return
+	}
+
+	// The webhook is handled like one from a default host but using the
+	// additional host's parser and secret.
+	hostController := *e
+	hostController.hostname = hostname
+	hostController.Parser = host.Parser
+	if host.CommentParser != nil {
+		hostController.CommentParser = host.CommentParser
+	}
+	hostController.SupportedVCSHosts = []models.VCSHostType{host.Type}
+	switch host.Type {
+	case models.Github:
+		hostController.GithubWebhookSecret = host.WebhookSecret
+	case models.Gitlab:
+		hostController.GitlabWebhookSecret = host.WebhookSecret
+	case models.BitbucketServer:
+		hostController.BitbucketWebhookSecret = host.WebhookSecret
+	case models.Gitea:
+		hostController.GiteaWebhookSecret = host.WebhookSecret
+	}
+	hostController.Post(w, r)
+}
+
+// deliveryKey is the request context key of the *webhookDelivery of a
+// webhook that's recorded.
+type deliveryKey struct{}
+
+// webhookDelivery is the state of recording a webhook.
+type webhookDelivery struct {
+	// body is the webhook's raw body.
+	body []byte
+	// recorded is the delivery that was recorded for the webhook. It's nil if
+	// it wasn't recorded.
+	recorded *Delivery
+}
+
+// statusRecorder is an http.ResponseWriter that records the response's status
+// code.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records code and writes it.
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// replayKey is the request context key set when a recorded delivery is being
+// replayed.
+type replayKey struct{}
+
+// deliveryCredentialHeaders are the headers that webhooks authenticate with.
+// They're not stored with recorded deliveries.
+var deliveryCredentialHeaders = []string{
+	"Authorization",
+	"X-Hub-Signature",
+	"X-Hub-Signature-256",
+	"X-Gitlab-Token",
+	giteaSignatureHeader,
 }
 
 // Post handles POST webhook requests.
 func (e *EventsController) Post(w http.ResponseWriter, r *http.Request) {
-	if r.Header.Get(githubHeader) != "" {
+	if e.Deliveries == nil {
+		e.post(w, r)
+		return
+	}
+
+	// The body is read here since the request validators consume it but it
+	// needs to be recorded after validation.
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s", err)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	delivery := &webhookDelivery{body: body}
+	r = r.WithContext(context.WithValue(r.Context(), deliveryKey{}, delivery))
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	e.post(recorder, r)
+
+	// If handling the delivery failed the host will retry it so the retry
+	// mustn't be ignored as a duplicate.
+	if delivery.recorded != nil && recorder.status >= http.StatusInternalServerError {
+		if err := e.Deliveries.Forget(*delivery.recorded); err != nil {
+			e.Logger.Warn("unable to forget failed delivery %s: %s", delivery.recorded.ID, err)
+		}
+	}
+}
+
+// post handles POST webhook requests after the body has been read for
+// recording.
+func (e *EventsController) post(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(giteaEventTypeHeader) != "" {
+		if !e.supportsHost(models.Gitea) {
+			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+			return
+		}
+		e.Logger.Debug("handling Gitea post")
+		e.handleGiteaPost(w, r)
+		return
+	} else if r.Header.Get(githubHeader) != "" {
 		if !e.supportsHost(models.Github) {
 			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitHub")
 			return
@@ -107,6 +274,14 @@ func (e *EventsController) Post(w http.ResponseWriter, r *http.Request) {
 			e.handleBitbucketServerPost(w, r)
 			return
 		}
+	} else if r.Header.Get(azuredevopsHeader) != "" {
+		if !e.supportsHost(models.AzureDevops) {
+			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Azure DevOps")
+			return
+		}
+		e.Logger.Debug("handling Azure DevOps post")
+		e.handleAzureDevopsPost(w, r)
+		return
 	}
 	e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request")
 }
@@ -119,8 +294,11 @@ func (e *EventsController) handleGithubPost(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	e.Logger.Debug("request valid")
+	if e.duplicateDelivery(w, r, models.Github, githubRequestIDHeader) {
+		return
+	}
 
-	githubReqID := "X-Github-Delivery=" + r.Header.Get("X-Github-Delivery")
+	githubReqID := githubRequestIDHeader + "=" + r.Header.Get(githubRequestIDHeader)
 	event, _ := github.ParseWebHook(github.WebHookType(r), payload)
 	switch event := event.(type) {
 	case *github.IssueCommentEvent:
@@ -143,6 +321,9 @@ func (e *EventsController) handleBitbucketCloudPost(w http.ResponseWriter, r *ht
 		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
 		return
 	}
+	if e.duplicateDelivery(w, r, models.BitbucketCloud, bitbucketCloudRequestIDHeader) {
+		return
+	}
 	switch eventType {
 	case bitbucketcloud.PullCreatedHeader, bitbucketcloud.PullUpdatedHeader, bitbucketcloud.PullFulfilledHeader, bitbucketcloud.PullRejectedHeader:
 		e.Logger.Debug("handling as pull request state changed event")
@@ -173,6 +354,9 @@ func (e *EventsController) handleBitbucketServerPost(w http.ResponseWriter, r *h
 			return
 		}
 	}
+	if e.duplicateDelivery(w, r, models.BitbucketServer, bitbucketServerRequestIDHeader) {
+		return
+	}
 	switch eventType {
 	case bitbucketserver.PullCreatedHeader, bitbucketserver.PullMergedHeader, bitbucketserver.PullDeclinedHeader:
 		e.Logger.Debug("handling as pull request state changed event")
@@ -187,6 +371,152 @@ func (e *EventsController) handleBitbucketServerPost(w http.ResponseWriter, r *h
 	}
 }
 
+func (e *EventsController) handleAzureDevopsPost(w http.ResponseWriter, r *http.Request) {
+	reqID := r.Header.Get(azuredevopsHeader)
+	if len(e.AzureDevopsWebhookUser) > 0 {
+		user, pass, ok := r.BasicAuth()
+		if err := azuredevops.ValidateBasicAuth(user, pass, ok, string(e.AzureDevopsWebhookUser), string(e.AzureDevopsWebhookPassword)); err != nil {
+			e.respond(w, logging.Warn, http.StatusUnauthorized, "request did not pass validation: %s", err)
+			return
+		}
+	}
+	defer r.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, azuredevopsHeader, reqID)
+		return
+	}
+	e.Logger.Debug("request valid")
+	if e.duplicateDelivery(w, r, models.AzureDevops, azuredevopsHeader) {
+		return
+	}
+
+	// Unlike the other hosts, Azure DevOps sends the event type in the body.
+	var event azuredevops.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing json: %s %s=%s", err, azuredevopsHeader, reqID)
+		return
+	}
+	eventType := ""
+	if event.EventType != nil {
+		eventType = *event.EventType
+	}
+	switch eventType {
+	case azuredevops.PullCreatedEvent, azuredevops.PullUpdatedEvent, azuredevops.PullMergedEvent:
+		e.Logger.Debug("handling as pull request state changed event")
+		e.HandleAzureDevopsPullRequestEvent(w, body, reqID)
+	case azuredevops.PullCommentCreatedEvent:
+		e.Logger.Debug("handling as comment created event")
+		e.HandleAzureDevopsCommentEvent(w, body, reqID)
+	default:
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, azuredevopsHeader, reqID)
+	}
+}
+
+// HandleAzureDevopsCommentEvent handles comment events from Azure DevOps
+// where Atlantis commands can come from. It's exported to make testing
+// easier.
+func (e *EventsController) HandleAzureDevopsCommentEvent(w http.ResponseWriter, body []byte, reqID string) {
+	pull, baseRepo, headRepo, user, comment, err := e.Parser.ParseAzureDevopsPullCommentEvent(body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, azuredevopsHeader, reqID)
+		return
+	}
+	e.handleCommentEvent(w, baseRepo, &headRepo, &pull, user, pull.Num, comment, models.AzureDevops)
+}
+
+// HandleAzureDevopsPullRequestEvent will delete any locks associated with
+// the pull request if the event is a pull request closed event. It's exported
+// to make testing easier.
+func (e *EventsController) HandleAzureDevopsPullRequestEvent(w http.ResponseWriter, body []byte, reqID string) {
+	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseAzureDevopsPullEvent(body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, azuredevopsHeader, reqID)
+		return
+	}
+	e.Logger.Info("identified event as type %q", pullEventType.String())
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+}
+
+func (e *EventsController) handleGiteaPost(w http.ResponseWriter, r *http.Request) {
+	eventType := r.Header.Get(giteaEventTypeHeader)
+	reqID := r.Header.Get(giteaRequestIDHeader)
+	sig := r.Header.Get(giteaSignatureHeader)
+	defer r.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	if len(e.GiteaWebhookSecret) > 0 {
+		if err := gitea.ValidateSignature(body, sig, e.GiteaWebhookSecret); err != nil {
+			e.respond(w, logging.Warn, http.StatusBadRequest, "request did not pass validation: %s", err)
+			return
+		}
+	}
+	e.Logger.Debug("request valid")
+	if e.duplicateDelivery(w, r, models.Gitea, giteaRequestIDHeader) {
+		return
+	}
+
+	switch eventType {
+	case gitea.PullRequestEventHeader:
+		var event gitea.PullRequestEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing json: %s %s=%s", err, giteaRequestIDHeader, reqID)
+			return
+		}
+		e.Logger.Debug("handling as pull request event")
+		e.HandleGiteaPullRequestEvent(w, &event, reqID)
+	case gitea.IssueCommentEventHeader:
+		var event gitea.IssueCommentEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing json: %s %s=%s", err, giteaRequestIDHeader, reqID)
+			return
+		}
+		e.Logger.Debug("handling as comment event")
+		e.HandleGiteaCommentEvent(w, &event, reqID)
+	default:
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, giteaRequestIDHeader, reqID)
+	}
+}
+
+// HandleGiteaCommentEvent handles comment events from Gitea where Atlantis
+// commands can come from. It's exported to make testing easier.
+func (e *EventsController) HandleGiteaCommentEvent(w http.ResponseWriter, event *gitea.IssueCommentEvent, reqID string) {
+	if event.Action == nil || *event.Action != gitea.CommentCreatedAction {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since action was not created %s=%s", giteaRequestIDHeader, reqID)
+		return
+	}
+	if !event.IsPull {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since it was not on a pull request %s=%s", giteaRequestIDHeader, reqID)
+		return
+	}
+
+	baseRepo, user, pullNum, err := e.Parser.ParseGiteaIssueCommentEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Failed parsing event: %v %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+
+	// We pass in nil for maybeHeadRepo because the head repo data isn't
+	// available in the Gitea issue comment event.
+	e.handleCommentEvent(w, baseRepo, nil, nil, user, pullNum, *event.Comment.Body, models.Gitea)
+}
+
+// HandleGiteaPullRequestEvent will delete any locks associated with the pull
+// request if the event is a pull request closed event. It's exported to make
+// testing easier.
+func (e *EventsController) HandleGiteaPullRequestEvent(w http.ResponseWriter, event *gitea.PullRequestEvent, reqID string) {
+	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGiteaPullEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	e.Logger.Info("identified event as type %q", pullEventType.String())
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+}
+
 // HandleGithubCommentEvent handles comment events from GitHub where Atlantis
 // commands can come from. It's exported to make testing easier.
 func (e *EventsController) HandleGithubCommentEvent(w http.ResponseWriter, event *github.IssueCommentEvent, githubReqID string) {
@@ -262,6 +592,10 @@ func (e *EventsController) HandleGithubPullRequestEvent(w http.ResponseWriter, p
 }
 
 func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User, eventType models.PullRequestEventType) {
+	if err := e.checkHostname(baseRepo); err != nil {
+		e.respond(w, logging.Warn, http.StatusBadRequest, "Ignoring request: %s", err)
+		return
+	}
 	if !e.RepoWhitelistChecker.IsWhitelisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
 		// If the repo isn't whitelisted and we receive an opened pull request
 		// event we comment back on the pull request that the repo isn't
@@ -274,6 +608,11 @@ func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRep
 		return
 	}
 
+	// The pull request has changed so anything we've cached about it is stale.
+	if e.VCSResponseCache != nil {
+		e.VCSResponseCache.InvalidatePull(baseRepo, pull.Num)
+	}
+
 	switch eventType {
 	case models.OpenedPullEvent, models.UpdatedPullEvent:
 		// If the pull request was opened or updated, we will try to autoplan.
@@ -314,6 +653,9 @@ func (e *EventsController) handleGitlabPost(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	e.Logger.Debug("request valid")
+	if e.duplicateDelivery(w, r, models.Gitlab, gitlabRequestIDHeader) {
+		return
+	}
 
 	switch event := event.(type) {
 	case gitlab.MergeCommentEvent:
@@ -340,7 +682,93 @@ func (e *EventsController) HandleGitlabCommentEvent(w http.ResponseWriter, event
 	e.handleCommentEvent(w, baseRepo, &headRepo, nil, user, event.MergeRequest.IID, event.ObjectAttributes.Note, models.Gitlab)
 }
 
+// duplicateDelivery records the validated webhook r and returns true if it's a
+// duplicate of a delivery from the same host received within the dedup
+// window, in which case it has already responded. If handling r then fails
+// with a 5xx, Post forgets the delivery so that the host's retry is handled. idHeader is the header with the host's delivery id.
+// Webhooks without a delivery id and replayed deliveries are never duplicates.
+func (e *EventsController) duplicateDelivery(w http.ResponseWriter, r *http.Request, hostType models.VCSHostType, idHeader string) bool {
+	id := r.Header.Get(idHeader)
+	delivery, ok := r.Context().Value(deliveryKey{}).(*webhookDelivery)
+	if e.Deliveries == nil || id == "" || !ok || r.Context().Value(replayKey{}) != nil {
+		return false
+	}
+	d := Delivery{
+		ID:         id,
+		HostType:   hostType.String(),
+		Hostname:   e.hostname,
+		ReceivedAt: time.Now(),
+		Header:     deliveryHeader(r.Header),
+		Body:       delivery.body,
+	}
+	recorded, err := e.Deliveries.Record(d)
+	if err != nil {
+		// Failing to record shouldn't stop the webhook from being handled.
+		e.Logger.Warn("unable to record delivery %s=%s: %s", idHeader, id, err)
+		return false
+	}
+	if !recorded {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring duplicate delivery %s=%s", idHeader, id)
+		return true
+	}
+	delivery.recorded = &d
+	return false
+}
+
+// deliveryHeader returns a copy of header without the headers webhooks
+// authenticate with.
+func deliveryHeader(header http.Header) http.Header {
+	stored := make(http.Header, len(header))
+	for k, v := range header {
+		stored[k] = v
+	}
+	for _, h := range deliveryCredentialHeaders {
+		stored.Del(h)
+	}
+	return stored
+}
+
+// withoutValidation returns a copy of e that doesn't validate webhooks. It's
+// used to replay recorded deliveries since they were validated when they were
+// received and don't have the credentials needed to validate them again.
+func (e *EventsController) withoutValidation() *EventsController {
+	unvalidated := *e
+	unvalidated.GithubWebhookSecret = nil
+	unvalidated.GitlabWebhookSecret = nil
+	unvalidated.BitbucketWebhookSecret = nil
+	unvalidated.GiteaWebhookSecret = nil
+	unvalidated.AzureDevopsWebhookUser = nil
+	unvalidated.AzureDevopsWebhookPassword = nil
+	unvalidated.VCSHosts = make(map[string]VCSHostWebhooks, len(e.VCSHosts))
+	for hostname, host := range e.VCSHosts {
+		host.WebhookSecret = nil
+		unvalidated.VCSHosts[hostname] = host
+	}
+	return &unvalidated
+}
+
+// checkHostname returns an error if baseRepo isn't on the VCS host the webhook
+// was sent for. Otherwise a webhook validated with one host's secret could
+// act on the repos of another host.
+func (e *EventsController) checkHostname(baseRepo models.Repo) error {
+	hostname := baseRepo.VCSHost.Hostname
+	if e.hostname != "" {
+		if hostname != e.hostname {
+			return fmt.Errorf("repo %s is on %s, not %s", baseRepo.FullName, hostname, e.hostname)
+		}
+		return nil
+	}
+	if _, ok := e.VCSHosts[hostname]; ok {
+		return fmt.Errorf("webhooks from %s must be sent to /events/%s", hostname, hostname)
+	}
+	return nil
+}
+
 func (e *EventsController) handleCommentEvent(w http.ResponseWriter, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, comment string, vcsHost models.VCSHostType) {
+	if err := e.checkHostname(baseRepo); err != nil {
+		e.respond(w, logging.Warn, http.StatusBadRequest, "Ignoring request: %s", err)
+		return
+	}
 	parseResult := e.CommentParser.Parse(comment, vcsHost)
 	if parseResult.Ignore {
 		truncated := comment
@@ -373,7 +801,7 @@ func (e *EventsController) handleCommentEvent(w http.ResponseWriter, baseRepo mo
 		return
 	}
 
-	// Check if the user who commented has the permissions to execute 'plan', 'apply' or destroy commands
+	// Check if the user who commented has the permissions to execute 'plan', 'apply', 'destroy' or 'approve_policies' commands
 	ok, err := e.checkUserPermissions(baseRepo, user, parseResult.Command)
 	if err != nil {
 		e.Logger.Err("unable to comment on pull request: %s", err)
@@ -446,9 +874,10 @@ func (e *EventsController) commentUserDoesNotHavePermissions(baseRepo models.Rep
 	}
 }
 
-// checkUserPermissions checks if the user has permissions to execute the command
+// checkUserPermissions checks if the user has permissions to execute the
+// command. Only members of PolicyApprovers can approve policies.
 func (e *EventsController) checkUserPermissions(repo models.Repo, user models.User, cmd *events.CommentCommand) (bool, error) {
-	if cmd.Name == events.ApplyCommand || cmd.Name == events.PlanCommand || cmd.Name == events.DestroyCommand {
+	if cmd.Name == events.ApplyCommand || cmd.Name == events.PlanCommand || cmd.Name == events.DestroyCommand || cmd.Name == events.ApprovePoliciesCommand {
 		teams, err := e.VCSClient.GetTeamNamesForUser(repo, user)
 		if err != nil {
 			return false, err
@@ -457,6 +886,21 @@ func (e *EventsController) checkUserPermissions(repo models.Repo, user models.Us
 		if !ok {
 			return false, nil
 		}
+		if cmd.Name == events.ApprovePoliciesCommand {
+			return e.isPolicyApprover(teams), nil
+		}
 	}
 	return true, nil
 }
+
+// isPolicyApprover returns true if any of teams is in PolicyApprovers.
+func (e *EventsController) isPolicyApprover(teams []string) bool {
+	for _, approver := range e.PolicyApprovers {
+		for _, t := range teams {
+			if strings.EqualFold(strings.TrimSpace(approver), strings.TrimSpace(t)) {
+				return true
+			}
+		}
+	}
+	return false
+}
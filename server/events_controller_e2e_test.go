@@ -273,7 +273,7 @@ func setupE2E(t *testing.T) (server.EventsController, *vcsmocks.MockClientProxy,
 		GitlabUser:  "gitlab-user",
 		GitlabToken: "gitlab-token",
 	}
-	terraformClient, err := terraform.NewClient(dataDir)
+	terraformClient, err := terraform.NewClient(dataDir, "")
 	Ok(t, err)
 	boltdb, err := boltdb.New(dataDir)
 	Ok(t, err)
@@ -329,7 +329,7 @@ func setupE2E(t *testing.T) (server.EventsController, *vcsmocks.MockClientProxy,
 			AllowRepoConfigFlag: "allow-repo-config",
 			AllowRepoConfig:     true,
 			RepoConfig:          "atlantis.yaml",
-			PendingPlanFinder:   &events.PendingPlanFinder{},
+			PendingPlanFinder:   &events.DefaultPendingPlanFinder{},
 			CommentBuilder:      commentParser,
 		},
 	}
@@ -367,7 +367,7 @@ func (m *mockLockURLGenerator) GenerateLockURL(lockID string) string {
 
 type mockWebhookSender struct{}
 
-func (w *mockWebhookSender) Send(log *logging.SimpleLogger, result webhooks.ApplyResult) error {
+func (w *mockWebhookSender) Send(log *logging.SimpleLogger, event webhooks.Event) error {
 	return nil
 }
 
@@ -30,16 +30,20 @@ import (
 	emocks "github.com/cloudposse/atlantis/server/events/mocks"
 	"github.com/cloudposse/atlantis/server/events/mocks/matchers"
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs"
 	vcsmocks "github.com/cloudposse/atlantis/server/events/vcs/mocks"
 	"github.com/cloudposse/atlantis/server/logging"
 	"github.com/cloudposse/atlantis/server/mocks"
 	. "github.com/cloudposse/atlantis/testing"
+	"github.com/gorilla/mux"
 	"github.com/lkysow/go-gitlab"
 	. "github.com/petergtz/pegomock"
 )
 
 const githubHeader = "X-Github-Event"
 const gitlabHeader = "X-Gitlab-Event"
+const giteaHeader = "X-Gitea-Event"
+const azuredevopsHeader = "Request-Id"
 
 var secret = []byte("secret")
 
@@ -290,6 +294,45 @@ func TestPost_GithubCommentSuccess(t *testing.T) {
 	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
 }
 
+func TestPost_GithubCommentApprovePolicies(t *testing.T) {
+	cases := []struct {
+		description string
+		approvers   []string
+		teams       []string
+		expAllowed  bool
+	}{
+		{"approver", []string{"security"}, []string{"dev", "Security"}, true},
+		{"not an approver", []string{"security"}, []string{"dev"}, false},
+		{"no approvers", nil, []string{"security"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			e, v, _, p, cr, _, vcsClient, cp := setup(t)
+			e.PolicyApprovers = c.approvers
+			req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+			req.Header.Set(githubHeader, "issue_comment")
+			event := `{"action": "created"}`
+			When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+			baseRepo := models.Repo{}
+			user := models.User{Username: "user"}
+			cmd := events.CommentCommand{Name: events.ApprovePoliciesCommand}
+			When(p.ParseGithubIssueCommentEvent(matchers.AnyPtrToGithubIssueCommentEvent())).ThenReturn(baseRepo, user, 1, nil)
+			When(cp.Parse("", models.Github)).ThenReturn(events.CommentParseResult{Command: &cmd})
+			When(vcsClient.GetTeamNamesForUser(baseRepo, user)).ThenReturn(c.teams, nil)
+			w := httptest.NewRecorder()
+			e.Post(w, req)
+
+			if c.expAllowed {
+				responseContains(t, w, http.StatusOK, "Processing...")
+				cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
+				return
+			}
+			responseContains(t, w, http.StatusForbidden, "User @user does not have permissions to execute 'approve_policies' command")
+			cr.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+		})
+	}
+}
+
 func TestPost_GithubPullRequestInvalid(t *testing.T) {
 	t.Log("when the event is a github pull request with invalid data we return a 400")
 	e, v, _, p, _, _, _, _ := setup(t)
@@ -509,6 +552,419 @@ func TestPost_PullOpenedOrUpdated(t *testing.T) {
 	}
 }
 
+func TestPost_PullEventInvalidatesResponseCache(t *testing.T) {
+	t.Log("when we receive a pull request event we invalidate the cached responses for the pull request")
+	e, v, _, p, _, _, _, _ := setup(t)
+	vcsClient := vcsmocks.NewMockClient()
+	cache := vcs.NewResponseCache(time.Hour, time.Hour, time.Hour)
+	cachingClient := &vcs.CachingClient{Client: vcsClient, Cache: cache}
+	e.VCSResponseCache = cache
+
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com", Type: models.Github}}
+	pull := models.PullRequest{Num: 1, HeadCommit: "sha", State: models.OpenPullState}
+	When(vcsClient.GetModifiedFiles(repo, pull)).ThenReturn([]string{"main.tf"}, nil)
+	_, err := cachingClient.GetModifiedFiles(repo, pull)
+	Ok(t, err)
+
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "pull_request")
+	When(v.Validate(req, secret)).ThenReturn([]byte(`{"action": "synchronize"}`), nil)
+	When(p.ParseGithubPullEvent(matchers.AnyPtrToGithubPullRequestEvent())).ThenReturn(pull, models.UpdatedPullEvent, repo, repo, models.User{}, nil)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+
+	_, err = cachingClient.GetModifiedFiles(repo, pull)
+	Ok(t, err)
+	vcsClient.VerifyWasCalled(Times(2)).GetModifiedFiles(repo, pull)
+}
+
+func TestPost_UnsupportedVCSGitea(t *testing.T) {
+	t.Log("when the request is for an unsupported vcs a 400 is returned")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = nil
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(giteaHeader, "pull_request")
+	// Gitea also sends the GitHub event header.
+	req.Header.Set(githubHeader, "pull_request")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+}
+
+func TestPost_InvalidGiteaSecret(t *testing.T) {
+	t.Log("when the gitea payload can't be validated a 400 is returned")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.GiteaWebhookSecret = secret
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "opened"}`))
+	req.Header.Set(giteaHeader, "pull_request")
+	req.Header.Set("X-Gitea-Signature", "0123abcd")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "request did not pass validation: payload signature check failed")
+}
+
+func TestPost_UnsupportedGiteaEvent(t *testing.T) {
+	t.Log("when the event type is an unsupported gitea event we ignore it")
+	e, _, _, _, _, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{}`))
+	req.Header.Set(giteaHeader, "push")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring unsupported event type push")
+}
+
+func TestPost_GiteaCommentNotCreated(t *testing.T) {
+	t.Log("when the event is a gitea comment but it's not a created event we ignore it")
+	e, _, _, _, _, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "deleted", "is_pull": true}`))
+	req.Header.Set(giteaHeader, "issue_comment")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring comment event since action was not created")
+}
+
+func TestPost_GiteaCommentNotOnPull(t *testing.T) {
+	t.Log("when the event is a gitea comment on an issue we ignore it")
+	e, _, _, _, _, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "created", "is_pull": false}`))
+	req.Header.Set(giteaHeader, "issue_comment")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring comment event since it was not on a pull request")
+}
+
+func TestPost_GiteaCommentSuccess(t *testing.T) {
+	t.Log("when the event is a gitea comment with a valid command we call the command handler")
+	e, _, _, p, cr, _, _, cp := setup(t)
+	event := `{"action": "created", "is_pull": true, "comment": {"body": "atlantis plan"}}`
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(event))
+	req.Header.Set(giteaHeader, "issue_comment")
+	baseRepo := models.Repo{}
+	user := models.User{}
+	cmd := events.CommentCommand{}
+	When(p.ParseGiteaIssueCommentEvent(matchers.AnyPtrToGiteaIssueCommentEvent())).ThenReturn(baseRepo, user, 1, nil)
+	When(cp.Parse("atlantis plan", models.Gitea)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+
+	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
+}
+
+func TestPost_GiteaPullRequestInvalid(t *testing.T) {
+	t.Log("when the event is a gitea pull request with invalid data we return a 400")
+	e, _, _, p, _, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "opened"}`))
+	req.Header.Set(giteaHeader, "pull_request")
+	When(p.ParseGiteaPullEvent(matchers.AnyPtrToGiteaPullRequestEvent())).ThenReturn(models.PullRequest{}, models.OpenedPullEvent, models.Repo{}, models.Repo{}, models.User{}, errors.New("err"))
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Error parsing pull data: err")
+}
+
+func TestPost_GiteaPullRequestOpened(t *testing.T) {
+	t.Log("when the event is a gitea pull request that was opened we autoplan")
+	e, _, _, p, cr, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "opened"}`))
+	req.Header.Set(giteaHeader, "pull_request")
+	repo := models.Repo{}
+	pull := models.PullRequest{State: models.OpenPullState}
+	When(p.ParseGiteaPullEvent(matchers.AnyPtrToGiteaPullRequestEvent())).ThenReturn(pull, models.OpenedPullEvent, repo, repo, models.User{}, nil)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+	cr.VerifyWasCalledOnce().RunAutoplanCommand(repo, repo, pull, models.User{})
+}
+
+func TestPost_DuplicateDelivery(t *testing.T) {
+	t.Log("when a delivery is received twice within the dedup window the second is ignored")
+	e, _, _, p, cr, _, _, cp := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	e.Deliveries = &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Hour, Retention: 2 * time.Hour}
+	event := `{"action": "created", "is_pull": true, "comment": {"body": "atlantis plan"}}`
+	baseRepo := models.Repo{}
+	user := models.User{}
+	cmd := events.CommentCommand{}
+	When(p.ParseGiteaIssueCommentEvent(matchers.AnyPtrToGiteaIssueCommentEvent())).ThenReturn(baseRepo, user, 1, nil)
+	When(cp.Parse("atlantis plan", models.Gitea)).ThenReturn(events.CommentParseResult{Command: &cmd})
+
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(event))
+	req.Header.Set(giteaHeader, "issue_comment")
+	req.Header.Set("X-Gitea-Delivery", "1")
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+
+	req, _ = http.NewRequest("GET", "", bytes.NewBufferString(event))
+	req.Header.Set(giteaHeader, "issue_comment")
+	req.Header.Set("X-Gitea-Delivery", "1")
+	w = httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring duplicate delivery X-Gitea-Delivery=1")
+
+	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
+	d, found, err := e.Deliveries.Get("1")
+	Ok(t, err)
+	Equals(t, true, found)
+	Equals(t, "Gitea", d.HostType)
+	Equals(t, event, string(d.Body))
+	// Credentials shouldn't be stored.
+	Equals(t, "1", d.Header.Get("X-Gitea-Delivery"))
+	Equals(t, "", d.Header.Get("Authorization"))
+}
+
+func TestPost_FailedDeliveryRetried(t *testing.T) {
+	t.Log("when handling a delivery fails with a 5xx the host's retry isn't ignored as a duplicate")
+	e, _, _, p, _, c, _, _ := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	e.Deliveries = &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Hour, Retention: 2 * time.Hour}
+	repo := models.Repo{}
+	pull := models.PullRequest{State: models.ClosedPullState}
+	When(p.ParseGiteaPullEvent(matchers.AnyPtrToGiteaPullRequestEvent())).ThenReturn(pull, models.ClosedPullEvent, repo, repo, models.User{}, nil)
+	When(c.CleanUpPull(repo, pull)).ThenReturn(errors.New("cleanup err")).ThenReturn(nil)
+
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "closed"}`))
+	req.Header.Set(giteaHeader, "pull_request")
+	req.Header.Set("X-Gitea-Delivery", "1")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusInternalServerError, "Error cleaning pull request: cleanup err")
+	_, found, err := e.Deliveries.Get("1")
+	Ok(t, err)
+	Equals(t, false, found)
+
+	req, _ = http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "closed"}`))
+	req.Header.Set(giteaHeader, "pull_request")
+	req.Header.Set("X-Gitea-Delivery", "1")
+	w = httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Pull request cleaned successfully")
+	_, found, err = e.Deliveries.Get("1")
+	Ok(t, err)
+	Equals(t, true, found)
+}
+
+func TestPost_InvalidDeliveryNotRecorded(t *testing.T) {
+	t.Log("when a delivery doesn't pass validation it isn't recorded")
+	e, _, _, _, _, _, _, _ := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	e.Deliveries = &server.FileDeliveryStore{DataDir: tmp, DedupWindow: time.Hour, Retention: 2 * time.Hour}
+	e.GiteaWebhookSecret = secret
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"action": "opened"}`))
+	req.Header.Set(giteaHeader, "pull_request")
+	req.Header.Set("X-Gitea-Delivery", "1")
+	req.Header.Set("X-Gitea-Signature", "0123abcd")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "request did not pass validation")
+
+	deliveries, err := e.Deliveries.List()
+	Ok(t, err)
+	Equals(t, 0, len(deliveries))
+}
+
+func TestPost_UnsupportedVCSAzureDevops(t *testing.T) {
+	t.Log("when the request is for an unsupported vcs a 400 is returned")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = nil
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(azuredevopsHeader, "reqID")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Ignoring request since not configured to support Azure DevOps")
+}
+
+func TestPost_AzureDevopsBasicAuth(t *testing.T) {
+	t.Log("when the azure devops basic auth credentials don't match a 401 is returned")
+	cases := []struct {
+		description string
+		setAuth     bool
+		user        string
+		pass        string
+		expErr      string
+	}{
+		{
+			"missing credentials",
+			false,
+			"",
+			"",
+			"request did not pass validation: missing basic auth credentials",
+		},
+		{
+			"wrong password",
+			true,
+			"user",
+			"wrong",
+			"request did not pass validation: basic auth credentials did not match",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			e, _, _, _, _, _, _, _ := setup(t)
+			e.AzureDevopsWebhookUser = []byte("user")
+			e.AzureDevopsWebhookPassword = []byte("pass")
+			req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"eventType": "git.pullrequest.created"}`))
+			req.Header.Set(azuredevopsHeader, "reqID")
+			if c.setAuth {
+				req.SetBasicAuth(c.user, c.pass)
+			}
+			w := httptest.NewRecorder()
+			e.Post(w, req)
+			responseContains(t, w, http.StatusUnauthorized, c.expErr)
+		})
+	}
+}
+
+func TestPost_UnsupportedAzureDevopsEvent(t *testing.T) {
+	t.Log("when the event type is an unsupported azure devops event we ignore it")
+	e, _, _, _, _, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"eventType": "git.push"}`))
+	req.Header.Set(azuredevopsHeader, "reqID")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring unsupported event type git.push")
+}
+
+func TestPost_AzureDevopsCommentSuccess(t *testing.T) {
+	t.Log("when the event is an azure devops comment with a valid command we call the command handler")
+	e, _, _, p, cr, _, _, cp := setup(t)
+	e.AzureDevopsWebhookUser = []byte("user")
+	e.AzureDevopsWebhookPassword = []byte("pass")
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"eventType": "ms.vss-code.git-pullrequest-comment-event"}`))
+	req.Header.Set(azuredevopsHeader, "reqID")
+	req.SetBasicAuth("user", "pass")
+	baseRepo := models.Repo{}
+	headRepo := models.Repo{}
+	pull := models.PullRequest{Num: 1}
+	user := models.User{}
+	cmd := events.CommentCommand{}
+	When(p.ParseAzureDevopsPullCommentEvent(matchers.AnySliceOfByte())).ThenReturn(pull, baseRepo, headRepo, user, "atlantis plan", nil)
+	When(cp.Parse("atlantis plan", models.AzureDevops)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+
+	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, &headRepo, &pull, user, 1, &cmd)
+}
+
+func TestPost_AzureDevopsPullRequestInvalid(t *testing.T) {
+	t.Log("when the event is an azure devops pull request with invalid data we return a 400")
+	e, _, _, p, _, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"eventType": "git.pullrequest.created"}`))
+	req.Header.Set(azuredevopsHeader, "reqID")
+	When(p.ParseAzureDevopsPullEvent(matchers.AnySliceOfByte())).ThenReturn(models.PullRequest{}, models.OpenedPullEvent, models.Repo{}, models.Repo{}, models.User{}, errors.New("err"))
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Error parsing pull data: err")
+}
+
+func TestPost_AzureDevopsPullRequestOpened(t *testing.T) {
+	t.Log("when the event is an azure devops pull request that was created we autoplan")
+	e, _, _, p, cr, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBufferString(`{"eventType": "git.pullrequest.created"}`))
+	req.Header.Set(azuredevopsHeader, "reqID")
+	repo := models.Repo{}
+	pull := models.PullRequest{State: models.OpenPullState}
+	When(p.ParseAzureDevopsPullEvent(matchers.AnySliceOfByte())).ThenReturn(pull, models.OpenedPullEvent, repo, repo, models.User{}, nil)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+	cr.VerifyWasCalledOnce().RunAutoplanCommand(repo, repo, pull, models.User{})
+}
+
+func TestPostHost_UnknownHost(t *testing.T) {
+	t.Log("when the hostname isn't a configured VCS host we return a 404")
+	e, _, _, _, _, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"hostname": "github.example.com"})
+	req.Header.Set(githubHeader, "issue_comment")
+	w := httptest.NewRecorder()
+	e.PostHost(w, req)
+	responseContains(t, w, http.StatusNotFound, "Ignoring request since \"github.example.com\" is not a configured VCS host")
+}
+
+func TestPostHost_UnsupportedVCS(t *testing.T) {
+	t.Log("when the request is for a different type than the host we return a 400")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.VCSHosts = map[string]server.VCSHostWebhooks{
+		"gitlab.example.com": {Type: models.Gitlab},
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"hostname": "gitlab.example.com"})
+	req.Header.Set(githubHeader, "issue_comment")
+	w := httptest.NewRecorder()
+	e.PostHost(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Ignoring request since not configured to support GitHub")
+}
+
+func TestPostHost_GithubCommentSuccess(t *testing.T) {
+	t.Log("a comment from an additional host should be validated with its secret and parsed with its parsers")
+	e, v, _, p, cr, _, _, cp := setup(t)
+	hostParser := emocks.NewMockEventParsing()
+	hostCommentParser := emocks.NewMockCommentParsing()
+	hostSecret := []byte("host-secret")
+	e.VCSHosts = map[string]server.VCSHostWebhooks{
+		"github.example.com": {Type: models.Github, Parser: hostParser, CommentParser: hostCommentParser, WebhookSecret: hostSecret},
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"hostname": "github.example.com"})
+	req.Header.Set(githubHeader, "issue_comment")
+	When(v.Validate(req, hostSecret)).ThenReturn([]byte(`{"action": "created"}`), nil)
+	baseRepo := models.Repo{VCSHost: models.VCSHost{Type: models.Github, Hostname: "github.example.com"}}
+	user := models.User{}
+	cmd := events.CommentCommand{}
+	When(hostParser.ParseGithubIssueCommentEvent(matchers.AnyPtrToGithubIssueCommentEvent())).ThenReturn(baseRepo, user, 1, nil)
+	When(hostCommentParser.Parse("", models.Github)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.PostHost(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+
+	p.VerifyWasCalled(Never()).ParseGithubIssueCommentEvent(matchers.AnyPtrToGithubIssueCommentEvent())
+	cp.VerifyWasCalled(Never()).Parse(AnyString(), matchers.AnyModelsVCSHostType())
+	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
+}
+
+func TestPostHost_RepoOnOtherHost(t *testing.T) {
+	t.Log("a webhook sent to an additional host's endpoint for a repo on another host should be ignored")
+	e, v, _, _, cr, _, _, _ := setup(t)
+	hostParser := emocks.NewMockEventParsing()
+	e.VCSHosts = map[string]server.VCSHostWebhooks{
+		"github.example.com": {Type: models.Github, Parser: hostParser},
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"hostname": "github.example.com"})
+	req.Header.Set(githubHeader, "issue_comment")
+	When(v.Validate(req, nil)).ThenReturn([]byte(`{"action": "created"}`), nil)
+	baseRepo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github, Hostname: "github.com"}}
+	When(hostParser.ParseGithubIssueCommentEvent(matchers.AnyPtrToGithubIssueCommentEvent())).ThenReturn(baseRepo, models.User{}, 1, nil)
+	w := httptest.NewRecorder()
+	e.PostHost(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Ignoring request: repo owner/repo is on github.com, not github.example.com")
+	cr.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
+func TestPost_RepoOnAdditionalHost(t *testing.T) {
+	t.Log("a webhook for an additional host's repo sent to the default endpoint should be ignored")
+	e, v, _, p, cr, _, _, _ := setup(t)
+	e.VCSHosts = map[string]server.VCSHostWebhooks{
+		"github.example.com": {Type: models.Github, Parser: emocks.NewMockEventParsing()},
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "issue_comment")
+	When(v.Validate(req, secret)).ThenReturn([]byte(`{"action": "created"}`), nil)
+	baseRepo := models.Repo{VCSHost: models.VCSHost{Type: models.Github, Hostname: "github.example.com"}}
+	When(p.ParseGithubIssueCommentEvent(matchers.AnyPtrToGithubIssueCommentEvent())).ThenReturn(baseRepo, models.User{}, 1, nil)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Ignoring request: webhooks from github.example.com must be sent to /events/github.example.com")
+	cr.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
 func setup(t *testing.T) (server.EventsController, *mocks.MockGithubRequestValidator, *mocks.MockGitlabRequestParserValidator, *emocks.MockEventParsing, *emocks.MockCommandRunner, *emocks.MockPullCleaner, *vcsmocks.MockClientProxy, *emocks.MockCommentParsing) {
 	RegisterMockTestingT(t)
 	v := mocks.NewMockGithubRequestValidator()
@@ -520,6 +976,8 @@ func setup(t *testing.T) (server.EventsController, *mocks.MockGithubRequestValid
 	vcsmock := vcsmocks.NewMockClientProxy()
 	repoWhitelistChecker, err := events.NewRepoWhitelistChecker("*")
 	Ok(t, err)
+	teamWhitelistChecker, err := events.NewTeamWhitelistChecker("*:*")
+	Ok(t, err)
 	e := server.EventsController{
 		TestingMode:                  true,
 		Logger:                       logging.NewNoopLogger(),
@@ -529,10 +987,11 @@ func setup(t *testing.T) (server.EventsController, *mocks.MockGithubRequestValid
 		CommandRunner:                cr,
 		PullCleaner:                  c,
 		GithubWebhookSecret:          secret,
-		SupportedVCSHosts:            []models.VCSHostType{models.Github, models.Gitlab},
+		SupportedVCSHosts:            []models.VCSHostType{models.Github, models.Gitlab, models.Gitea, models.AzureDevops},
 		GitlabWebhookSecret:          secret,
 		GitlabRequestParserValidator: gl,
 		RepoWhitelistChecker:         repoWhitelistChecker,
+		TeamWhitelistChecker:         teamWhitelistChecker,
 		VCSClient:                    vcsmock,
 	}
 	return e, v, gl, p, cr, c, vcsmock, cp
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cloudposse/atlantis/server/events"
+	"github.com/cloudposse/atlantis/server/logging"
+	"github.com/gorilla/mux"
+)
+
+// OutputsController serves the full output of commands whose comments were
+// truncated.
+type OutputsController struct {
+	Logger             *logging.SimpleLogger
+	CommentOutputStore events.CommentOutputStore
+}
+
+// GetOutput is the GET /outputs/{id} route. It responds with the output as
+// plain text. The route isn't authenticated, instead ids are random so
+// outputs can only be viewed by those who were given the link. We tell
+// caches and search engines not to keep the output for the same reason.
+func (o *OutputsController) GetOutput(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		o.respond(w, logging.Warn, http.StatusBadRequest, "No output id in request")
+		return
+	}
+	output, found, err := o.CommentOutputStore.Get(id)
+	if err != nil {
+		o.respond(w, logging.Error, http.StatusInternalServerError, "Failed getting output: %s", err)
+		return
+	}
+	if !found {
+		o.respond(w, logging.Info, http.StatusNotFound, "No output found at id %q", id)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	fmt.Fprint(w, output)
+}
+
+// respond is a helper function to respond and log the response. lvl is the log
+// level to log at, code is the HTTP response code.
+func (o *OutputsController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
+	response := fmt.Sprintf(format, args...)
+	o.Logger.Log(lvl, "%s", response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudposse/atlantis/server"
+	"github.com/cloudposse/atlantis/server/events/mocks"
+	"github.com/cloudposse/atlantis/server/logging"
+	. "github.com/cloudposse/atlantis/testing"
+	"github.com/gorilla/mux"
+	. "github.com/petergtz/pegomock"
+)
+
+func TestGetOutput_NoID(t *testing.T) {
+	t.Log("If there is no output ID in the request then we should get a 400")
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	oc := server.OutputsController{
+		Logger: logging.NewNoopLogger(),
+	}
+	oc.GetOutput(w, req)
+	responseContains(t, w, http.StatusBadRequest, "No output id in request")
+}
+
+func TestGetOutput_StoreErr(t *testing.T) {
+	t.Log("If there is an error retrieving the output, a 500 is returned")
+	RegisterMockTestingT(t)
+	store := mocks.NewMockCommentOutputStore()
+	When(store.Get("id")).ThenReturn("", false, errors.New("err"))
+	oc := server.OutputsController{
+		Logger:             logging.NewNoopLogger(),
+		CommentOutputStore: store,
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	oc.GetOutput(w, req)
+	responseContains(t, w, http.StatusInternalServerError, "Failed getting output: err")
+}
+
+func TestGetOutput_NotFound(t *testing.T) {
+	t.Log("If there is no output at that ID we get a 404")
+	RegisterMockTestingT(t)
+	store := mocks.NewMockCommentOutputStore()
+	When(store.Get("id")).ThenReturn("", false, nil)
+	oc := server.OutputsController{
+		Logger:             logging.NewNoopLogger(),
+		CommentOutputStore: store,
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	oc.GetOutput(w, req)
+	responseContains(t, w, http.StatusNotFound, "No output found at id \"id\"")
+}
+
+func TestGetOutput_Found(t *testing.T) {
+	t.Log("If the output is found it's returned as plain text")
+	RegisterMockTestingT(t)
+	store := mocks.NewMockCommentOutputStore()
+	When(store.Get("id")).ThenReturn("full output", true, nil)
+	oc := server.OutputsController{
+		Logger:             logging.NewNoopLogger(),
+		CommentOutputStore: store,
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	oc.GetOutput(w, req)
+	responseContains(t, w, http.StatusOK, "full output")
+	Equals(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	Equals(t, "no-store", w.Header().Get("Cache-Control"))
+	Equals(t, "noindex", w.Header().Get("X-Robots-Tag"))
+}
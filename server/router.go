@@ -19,6 +19,9 @@ type Router struct {
 	// LockViewRouteIDQueryParam is the query parameter needed to construct the
 	// lock view: underlying.Get(LockViewRouteName).URL(LockViewRouteIDQueryParam, "my id").
 	LockViewRouteIDQueryParam string
+	// OutputViewRouteName is the named route for the output view. Its path
+	// has an {id} variable.
+	OutputViewRouteName string
 	// AtlantisURL is the fully qualified URL (scheme included) that Atlantis is
 	// being served at, ex: https://example.com.
 	AtlantisURL string
@@ -29,3 +32,9 @@ func (r *Router) GenerateLockURL(lockID string) string {
 	path, _ := r.Underlying.Get(r.LockViewRouteName).URL(r.LockViewRouteIDQueryParam, url.QueryEscape(lockID))
 	return fmt.Sprintf("%s%s", r.AtlantisURL, path)
 }
+
+// GenerateOutputURL returns a fully qualified URL to view the output at id.
+func (r *Router) GenerateOutputURL(id string) string {
+	path, _ := r.Underlying.Get(r.OutputViewRouteName).URL("id", id)
+	return fmt.Sprintf("%s%s", r.AtlantisURL, path)
+}
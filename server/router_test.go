@@ -25,3 +25,18 @@ func TestRouter_GenerateLockURL(t *testing.T) {
 	}
 	Equals(t, "https://example.com/lock?queryparam=myid", router.GenerateLockURL("myid"))
 }
+
+func TestRouter_GenerateOutputURL(t *testing.T) {
+	routeName := "routename"
+	atlantisURL := "https://example.com"
+
+	underlyingRouter := mux.NewRouter()
+	underlyingRouter.HandleFunc("/outputs/{id}", func(_ http.ResponseWriter, _ *http.Request) {}).Methods("GET").Name(routeName)
+
+	router := &server.Router{
+		AtlantisURL:         atlantisURL,
+		OutputViewRouteName: routeName,
+		Underlying:          underlyingRouter,
+	}
+	Equals(t, "https://example.com/outputs/myid", router.GenerateOutputURL("myid"))
+}
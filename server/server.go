@@ -33,11 +33,15 @@ import (
 	"github.com/cloudposse/atlantis/server/events/locking"
 	"github.com/cloudposse/atlantis/server/events/locking/boltdb"
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/policy"
 	"github.com/cloudposse/atlantis/server/events/runtime"
 	"github.com/cloudposse/atlantis/server/events/terraform"
 	"github.com/cloudposse/atlantis/server/events/vcs"
+	"github.com/cloudposse/atlantis/server/events/vcs/azuredevops"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/cloudposse/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/cloudposse/atlantis/server/events/vcs/gitea"
+	"github.com/cloudposse/atlantis/server/events/vcs/retry"
 	"github.com/cloudposse/atlantis/server/events/webhooks"
 	"github.com/cloudposse/atlantis/server/events/yaml"
 	"github.com/cloudposse/atlantis/server/logging"
@@ -59,6 +63,9 @@ const (
 	// route. ex:
 	//   mux.Router.Get(LockViewRouteName).URL(LockViewRouteIDQueryParam, "my id")
 	LockViewRouteIDQueryParam = "id"
+	// OutputViewRouteName is the named route in mux.Router for the view of
+	// the full output of a truncated comment.
+	OutputViewRouteName = "output-detail"
 )
 
 // Server runs the Atlantis web server.
@@ -71,6 +78,9 @@ type Server struct {
 	Locker             locking.Locker
 	EventsController   *EventsController
 	LocksController    *LocksController
+	OutputsController  *OutputsController
+	AdminController    *AdminController
+	VCSStats           *retry.Stats
 	IndexTemplate      TemplateWriter
 	LockDetailTemplate TemplateWriter
 	SSLCertFile        string
@@ -81,35 +91,73 @@ type Server struct {
 // The mapstructure tags correspond to flags in cmd/server.go and are used when
 // the config is parsed from a YAML file.
 type UserConfig struct {
-	AllowForkPRs           bool   `mapstructure:"allow-fork-prs"`
-	AllowRepoConfig        bool   `mapstructure:"allow-repo-config"`
-	AtlantisURL            string `mapstructure:"atlantis-url"`
-	BitbucketBaseURL       string `mapstructure:"bitbucket-base-url"`
-	BitbucketToken         string `mapstructure:"bitbucket-token"`
-	BitbucketUser          string `mapstructure:"bitbucket-user"`
-	BitbucketWebhookSecret string `mapstructure:"bitbucket-webhook-secret"`
-	DataDir                string `mapstructure:"data-dir"`
-	GithubHostname         string `mapstructure:"gh-hostname"`
-	GithubTeamWhitelist    string `mapstructure:"gh-team-whitelist"`
-	GithubToken            string `mapstructure:"gh-token"`
-	GithubUser             string `mapstructure:"gh-user"`
-	GithubWebhookSecret    string `mapstructure:"gh-webhook-secret"`
-	GitlabHostname         string `mapstructure:"gitlab-hostname"`
-	GitlabToken            string `mapstructure:"gitlab-token"`
-	GitlabUser             string `mapstructure:"gitlab-user"`
-	GitlabWebhookSecret    string `mapstructure:"gitlab-webhook-secret"`
-	LogLevel               string `mapstructure:"log-level"`
-	Port                   int    `mapstructure:"port"`
-	RepoConfig             string `mapstructure:"repo-config"`
-	RepoWhitelist          string `mapstructure:"repo-whitelist"`
+	AdminToken                 string `mapstructure:"admin-token"`
+	AllowForkPRs               bool   `mapstructure:"allow-fork-prs"`
+	AllowRepoConfig            bool   `mapstructure:"allow-repo-config"`
+	AtlantisURL                string `mapstructure:"atlantis-url"`
+	Automerge                  bool   `mapstructure:"automerge"`
+	AzureDevopsHostname        string `mapstructure:"azuredevops-hostname"`
+	AzureDevopsToken           string `mapstructure:"azuredevops-token"`
+	AzureDevopsUser            string `mapstructure:"azuredevops-user"`
+	AzureDevopsWebhookPassword string `mapstructure:"azuredevops-webhook-password"`
+	AzureDevopsWebhookUser     string `mapstructure:"azuredevops-webhook-user"`
+	BitbucketBaseURL           string `mapstructure:"bitbucket-base-url"`
+	BitbucketToken             string `mapstructure:"bitbucket-token"`
+	BitbucketUser              string `mapstructure:"bitbucket-user"`
+	BitbucketWebhookSecret     string `mapstructure:"bitbucket-webhook-secret"`
+	CommentMode                string `mapstructure:"comment-mode"`
+	DataDir                    string `mapstructure:"data-dir"`
+	GiteaBaseURL               string `mapstructure:"gitea-base-url"`
+	GiteaToken                 string `mapstructure:"gitea-token"`
+	GiteaUser                  string `mapstructure:"gitea-user"`
+	GiteaWebhookSecret         string `mapstructure:"gitea-webhook-secret"`
+	GithubAppID                int    `mapstructure:"gh-app-id"`
+	GithubAppKeyFile           string `mapstructure:"gh-app-key-file"`
+	GithubChecks               bool   `mapstructure:"gh-checks"`
+	GithubHostname             string `mapstructure:"gh-hostname"`
+	GithubTeamWhitelist        string `mapstructure:"gh-team-whitelist"`
+	GithubToken                string `mapstructure:"gh-token"`
+	GithubUser                 string `mapstructure:"gh-user"`
+	GithubWebhookSecret        string `mapstructure:"gh-webhook-secret"`
+	GitlabHostname             string `mapstructure:"gitlab-hostname"`
+	GitlabToken                string `mapstructure:"gitlab-token"`
+	GitlabUser                 string `mapstructure:"gitlab-user"`
+	GitlabWebhookSecret        string `mapstructure:"gitlab-webhook-secret"`
+	LogLevel                   string `mapstructure:"log-level"`
+	// PolicyApprovers is a comma separated list of the teams whose members
+	// can approve plans that failed their policy checks.
+	PolicyApprovers string `mapstructure:"policy-approvers"`
+	// PolicySets are the policies plans are checked against. They're only
+	// set in the config file.
+	PolicySets    []PolicySetConfig `mapstructure:"policy-sets"`
+	Port          int               `mapstructure:"port"`
+	RepoConfig    string            `mapstructure:"repo-config"`
+	RepoWhitelist string            `mapstructure:"repo-whitelist"`
 	// RequireApproval is whether to require pull request approval before
 	// allowing terraform apply's to be run.
-	RequireApproval bool            `mapstructure:"require-approval"`
-	SlackToken      string          `mapstructure:"slack-token"`
-	SSLCertFile     string          `mapstructure:"ssl-cert-file"`
-	SSLKeyFile      string          `mapstructure:"ssl-key-file"`
-	WakeWord        string          `mapstructure:"wake-word"`
-	Webhooks        []WebhookConfig `mapstructure:"webhooks"`
+	RequireApproval bool   `mapstructure:"require-approval"`
+	SlackToken      string `mapstructure:"slack-token"`
+	// SMTP is how to send the emails of email webhooks. It's only set in the
+	// config file.
+	SMTP        SMTPConfig `mapstructure:"smtp"`
+	SSLCertFile string     `mapstructure:"ssl-cert-file"`
+	SSLKeyFile  string     `mapstructure:"ssl-key-file"`
+	// TFDownloadURL is the releases mirror that versions of terraform that
+	// aren't installed are downloaded from.
+	TFDownloadURL string `mapstructure:"tf-download-url"`
+	// VCSCacheModifiedFilesTTL, VCSCachePullTTL and VCSCacheTeamsTTL are
+	// durations, ex. 5m. 0 disables that cache.
+	VCSCacheModifiedFilesTTL string `mapstructure:"vcs-cache-modified-files-ttl"`
+	VCSCachePullTTL          string `mapstructure:"vcs-cache-pull-ttl"`
+	VCSCacheTeamsTTL         string `mapstructure:"vcs-cache-teams-ttl"`
+	// VCSDeliveryDedupWindow and VCSDeliveryRetention are durations, ex. 10m.
+	// A retention of 0 disables recording webhook deliveries.
+	VCSDeliveryDedupWindow string          `mapstructure:"vcs-delivery-dedup-window"`
+	VCSDeliveryRetention   string          `mapstructure:"vcs-delivery-retention"`
+	VCSHosts               []VCSHostConfig `mapstructure:"vcs-hosts"`
+	VCSMaxRetries          int             `mapstructure:"vcs-max-retries"`
+	WakeWord               string          `mapstructure:"wake-word"`
+	Webhooks               []WebhookConfig `mapstructure:"webhooks"`
 }
 
 // Config holds config for server that isn't passed in by the user.
@@ -123,30 +171,142 @@ type Config struct {
 type WebhookConfig struct {
 	// Event is the type of event we should send this webhook for, ex. apply.
 	Event string `mapstructure:"event"`
+	// Events are more types of events to send this webhook for, ex.
+	// [plan, command_failure].
+	Events []string `mapstructure:"events"`
 	// WorkspaceRegex is a regex that is used to match against the workspace
 	// that is being modified for this event. If the regex matches, we'll
 	// send the webhook, ex. "production.*".
 	WorkspaceRegex string `mapstructure:"workspace-regex"`
+	// RepoRegex is matched against the full name of the event's repo, ex.
+	// "runatlantis/atlantis". If empty, every repo matches.
+	RepoRegex string `mapstructure:"repo-regex"`
+	// ProjectRegex is matched against the name of the event's project, or its
+	// dir if it isn't named. If empty, every project matches.
+	ProjectRegex string `mapstructure:"project-regex"`
+	// DirRegex is matched against the event's project dir, ex. "envs/prod".
+	// If empty, every dir matches.
+	DirRegex string `mapstructure:"dir-regex"`
+	// BranchRegex is matched against the pull request's head branch. If
+	// empty, every branch matches.
+	BranchRegex string `mapstructure:"branch-regex"`
 	// Kind is the type of webhook we should send, ex. slack.
 	Kind string `mapstructure:"kind"`
 	// Channel is the channel to send this webhook to. It only applies to
 	// slack webhooks. Should be without '#'.
 	Channel string `mapstructure:"channel"`
+	// UserMapping is the path to a YAML file mapping VCS usernames to Slack
+	// member ids. Authors in it are sent a direct message when their applies
+	// fail. It only applies to slack webhooks.
+	UserMapping string `mapstructure:"user-mapping"`
+	// URL is the URL to POST to. It only applies to http webhooks.
+	URL string `mapstructure:"url"`
+	// Headers are added to each request. They only apply to http webhooks.
+	Headers map[string]string `mapstructure:"headers"`
+	// Secret is used to sign each request. It only applies to http webhooks.
+	Secret string `mapstructure:"secret"`
+	// Retries is how many times a failed request is retried. It only applies
+	// to http webhooks. If 0, the default is used. -1 disables retrying.
+	Retries int `mapstructure:"retries"`
+	// Timeout is how long each request can take, ex. 5s. It only applies to
+	// http webhooks.
+	Timeout string `mapstructure:"timeout"`
+	// To are the addresses to send emails to. It only applies to email
+	// webhooks.
+	To []string `mapstructure:"to"`
 }
 
+// PolicySetConfig is nested within UserConfig. It's used to configure the
+// policy sets plans are checked against.
+type PolicySetConfig struct {
+	// Name identifies the policy set in comments.
+	Name string `mapstructure:"name"`
+	// Engine evaluates the policies, either rego or exec.
+	Engine string `mapstructure:"engine"`
+	// Path is the .rego file or directory of .rego files. It only applies
+	// to rego policy sets.
+	Path string `mapstructure:"path"`
+	// Command is the shell command to run. It only applies to exec policy
+	// sets.
+	Command string `mapstructure:"command"`
+}
+
+// SMTPConfig is nested within UserConfig. It's the SMTP server used by email
+// webhooks.
+type SMTPConfig struct {
+	Host string `mapstructure:"host"`
+	// Port defaults to 587.
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// From is the address emails are sent from.
+	From string `mapstructure:"from"`
+	// StartTLS is one of required (the default), opportunistic or disabled.
+	StartTLS string `mapstructure:"starttls"`
+}
+
+// VCSHostConfig is nested within UserConfig. It's used to configure VCS hosts
+// in addition to the ones configured by flags, ex. a GitHub Enterprise
+// install alongside github.com.
+type VCSHostConfig struct {
+	// Type is the type of VCS host, one of VCSHostConfigTypes.
+	Type string `mapstructure:"type"`
+	// URL is the URL of the host, ex. https://github.example.com. For
+	// Bitbucket Server and Gitea it can include a path.
+	URL   string `mapstructure:"url"`
+	User  string `mapstructure:"user"`
+	Token string `mapstructure:"token"`
+	// WebhookSecret is used to validate the host's webhooks. If empty, no
+	// request validation is done.
+	WebhookSecret string `mapstructure:"webhook-secret"`
+}
+
+// VCSHostConfigTypes are the types of VCS host that can be added with
+// UserConfig.VCSHosts. Bitbucket Cloud and Azure DevOps aren't supported since
+// there's only one of each.
+var VCSHostConfigTypes = []string{"github", "gitlab", "bitbucket-server", "gitea"}
+
 // NewServer returns a new server. If there are issues starting the server or
 // its dependencies an error will be returned. This is like the main() function
 // for the server CLI command because it injects all the dependencies.
 func NewServer(userConfig UserConfig, config Config) (*Server, error) {
+	logger := logging.NewSimpleLogger("server", nil, false, logging.ToLogLevel(userConfig.LogLevel))
+	// All VCS API calls go through vcsHTTPClient so they're retried if
+	// they fail because of transient errors or rate limits.
+	vcsStats := &retry.Stats{}
+	vcsTransport := retry.NewTransport(http.DefaultTransport, userConfig.VCSMaxRetries, logger, vcsStats)
+	vcsHTTPClient := &http.Client{Transport: vcsTransport}
+	responseCache, err := newVCSResponseCache(userConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	var supportedVCSHosts []models.VCSHostType
 	var githubClient *vcs.GithubClient
 	var gitlabClient *vcs.GitlabClient
 	var bitbucketCloudClient *bitbucketcloud.Client
 	var bitbucketServerClient *bitbucketserver.Client
-	if userConfig.GithubUser != "" {
+	var giteaClient *gitea.Client
+	var azureDevopsClient *azuredevops.Client
+	var githubCredentials vcs.GithubCredentials
+	if userConfig.GithubUser != "" || userConfig.GithubAppID != 0 {
 		supportedVCSHosts = append(supportedVCSHosts, models.Github)
 		var err error
-		githubClient, err = vcs.NewGithubClient(userConfig.GithubHostname, userConfig.GithubUser, userConfig.GithubToken)
+		if userConfig.GithubAppID != 0 {
+			appCredentials, err := vcs.NewGithubAppCredentials(userConfig.GithubAppID, userConfig.GithubAppKeyFile, userConfig.GithubHostname)
+			if err != nil {
+				return nil, errors.Wrap(err, "setting up GitHub App credentials")
+			}
+			appCredentials.Transport = vcsTransport
+			githubCredentials = appCredentials
+		} else {
+			githubCredentials = &vcs.GithubUserCredentials{
+				User:      userConfig.GithubUser,
+				Token:     userConfig.GithubToken,
+				Transport: vcsTransport,
+			}
+		}
+		githubClient, err = vcs.NewGithubClient(userConfig.GithubHostname, githubCredentials)
 		if err != nil {
 			return nil, err
 		}
@@ -154,7 +314,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if userConfig.GitlabUser != "" {
 		supportedVCSHosts = append(supportedVCSHosts, models.Gitlab)
 		gitlabClient = &vcs.GitlabClient{
-			Client: gitlab.NewClient(nil, userConfig.GitlabToken),
+			Client: gitlab.NewClient(vcsHTTPClient, userConfig.GitlabToken),
 		}
 		// If not using gitlab.com we need to set the URL to the API.
 		if userConfig.GitlabHostname != "gitlab.com" {
@@ -176,15 +336,16 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		if userConfig.BitbucketBaseURL == bitbucketcloud.BaseURL {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketCloud)
 			bitbucketCloudClient = bitbucketcloud.NewClient(
-				http.DefaultClient,
+				vcsHTTPClient,
 				userConfig.BitbucketUser,
 				userConfig.BitbucketToken,
-				userConfig.AtlantisURL)
+				userConfig.AtlantisURL,
+				responseCache.TeamsTTL())
 		} else {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketServer)
 			var err error
 			bitbucketServerClient, err = bitbucketserver.NewClient(
-				http.DefaultClient,
+				vcsHTTPClient,
 				userConfig.BitbucketUser,
 				userConfig.BitbucketToken,
 				userConfig.BitbucketBaseURL,
@@ -194,14 +355,74 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			}
 		}
 	}
+	if userConfig.GiteaUser != "" {
+		supportedVCSHosts = append(supportedVCSHosts, models.Gitea)
+		var err error
+		giteaClient, err = gitea.NewClient(
+			vcsHTTPClient,
+			userConfig.GiteaUser,
+			userConfig.GiteaToken,
+			userConfig.GiteaBaseURL,
+			userConfig.AtlantisURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting up Gitea client")
+		}
+	}
+	if userConfig.AzureDevopsUser != "" {
+		supportedVCSHosts = append(supportedVCSHosts, models.AzureDevops)
+		// Check if they've also provided a scheme so we don't prepend it
+		// again.
+		baseURL := userConfig.AzureDevopsHostname
+		if !strings.Contains(baseURL, "://") {
+			baseURL = "https://" + baseURL
+		}
+		var err error
+		azureDevopsClient, err = azuredevops.NewClient(
+			vcsHTTPClient,
+			userConfig.AzureDevopsUser,
+			userConfig.AzureDevopsToken,
+			baseURL,
+			userConfig.AtlantisURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting up Azure DevOps client")
+		}
+	}
 
 	var webhooksConfig []webhooks.Config
 	for _, c := range userConfig.Webhooks {
+		var timeout time.Duration
+		if c.Timeout != "" {
+			var err error
+			timeout, err = time.ParseDuration(c.Timeout)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing webhook timeout %q", c.Timeout)
+			}
+		}
 		config := webhooks.Config{
 			Channel:        c.Channel,
 			Event:          c.Event,
+			Events:         c.Events,
 			Kind:           c.Kind,
 			WorkspaceRegex: c.WorkspaceRegex,
+			RepoRegex:      c.RepoRegex,
+			ProjectRegex:   c.ProjectRegex,
+			DirRegex:       c.DirRegex,
+			BranchRegex:    c.BranchRegex,
+			UserMapping:    c.UserMapping,
+			URL:            c.URL,
+			Headers:        c.Headers,
+			Secret:         c.Secret,
+			Retries:        c.Retries,
+			Timeout:        timeout,
+			To:             c.To,
+			SMTP: webhooks.SMTPConfig{
+				Host:     userConfig.SMTP.Host,
+				Port:     userConfig.SMTP.Port,
+				Username: userConfig.SMTP.Username,
+				Password: userConfig.SMTP.Password,
+				From:     userConfig.SMTP.From,
+				StartTLS: userConfig.SMTP.StartTLS,
+			},
 		}
 		webhooksConfig = append(webhooksConfig, config)
 	}
@@ -209,9 +430,57 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "initializing webhooks")
 	}
-	vcsClient := vcs.NewDefaultClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient)
+	var policyConfigs []policy.Config
+	for _, c := range userConfig.PolicySets {
+		policyConfigs = append(policyConfigs, policy.Config{
+			Name:    c.Name,
+			Engine:  c.Engine,
+			Path:    c.Path,
+			Command: c.Command,
+		})
+	}
+	// policyChecker is left nil if there aren't any policy sets so plans
+	// aren't checked.
+	var policyChecker policy.Checker
+	if len(policyConfigs) > 0 {
+		checker, err := policy.NewChecker(policyConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing policy sets")
+		}
+		policyChecker = checker
+	}
+	// Each client is wrapped so its responses are cached. Clients that
+	// aren't configured are left nil so the proxy knows they aren't
+	// configured.
+	var cachedClients [6]vcs.Client
+	for i, c := range []struct {
+		configured bool
+		client     vcs.Client
+	}{
+		{githubClient != nil, githubClient},
+		{gitlabClient != nil, gitlabClient},
+		{bitbucketCloudClient != nil, bitbucketCloudClient},
+		{bitbucketServerClient != nil, bitbucketServerClient},
+		{giteaClient != nil, giteaClient},
+		{azureDevopsClient != nil, azureDevopsClient},
+	} {
+		if c.configured {
+			cachedClients[i] = &vcs.CachingClient{Client: c.client, Cache: responseCache}
+		}
+	}
+	vcsClient := vcs.NewDefaultClientProxy(cachedClients[0], cachedClients[1], cachedClients[2], cachedClients[3], cachedClients[4], cachedClients[5])
+	var githubPullGetter events.GithubPullGetter = githubClient
+	if githubClient != nil {
+		githubPullGetter = &vcs.CachingGithubPullGetter{Client: githubClient, Cache: responseCache}
+	}
 	commitStatusUpdater := &events.DefaultCommitStatusUpdater{Client: vcsClient}
-	terraformClient, err := terraform.NewClient(userConfig.DataDir)
+	if userConfig.GithubChecks {
+		commitStatusUpdater.ChecksClient = githubClient
+		// Repo hostnames come from their clone URLs so they don't include a
+		// port.
+		commitStatusUpdater.ChecksHostname = strings.Split(userConfig.GithubHostname, ":")[0]
+	}
+	terraformClient, err := terraform.NewClient(userConfig.DataDir, userConfig.TFDownloadURL)
 	// The flag.Lookup call is to detect if we're running in a unit test. If we
 	// are, then we don't error out because we don't have/want terraform
 	// installed on our CI system where the unit tests run.
@@ -223,7 +492,11 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	lockingClient := locking.NewClient(boltdb)
+	lockingClient := &events.UnlockWebhookLocker{
+		Locker:   locking.NewClient(boltdb),
+		Webhooks: webhooksManager,
+		Logger:   logger,
+	}
 	workingDirLocker := events.NewDefaultWorkingDirLocker()
 	workingDir := &events.FileWorkspace{
 		DataDir: userConfig.DataDir,
@@ -236,41 +509,86 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		AtlantisURL:               userConfig.AtlantisURL,
 		LockViewRouteIDQueryParam: LockViewRouteIDQueryParam,
 		LockViewRouteName:         LockViewRouteName,
+		OutputViewRouteName:       OutputViewRouteName,
 		Underlying:                underlyingRouter,
 	}
+	commentOutputStore := &events.FileCommentOutputStore{
+		DataDir: userConfig.DataDir,
+	}
+	pullCommentStore := &events.FilePullCommentStore{
+		DataDir: userConfig.DataDir,
+	}
+	deliveryStore, err := newDeliveryStore(userConfig)
+	if err != nil {
+		return nil, err
+	}
 	pullClosedExecutor := &events.PullClosedExecutor{
-		VCSClient:  vcsClient,
-		Locker:     lockingClient,
-		WorkingDir: workingDir,
+		VCSClient:           vcsClient,
+		Locker:              lockingClient,
+		WorkingDir:          workingDir,
+		CommentOutputStore:  commentOutputStore,
+		PullCommentStore:    pullCommentStore,
+		CommitStatusUpdater: commitStatusUpdater,
 	}
-	logger := logging.NewSimpleLogger("server", nil, false, logging.ToLogLevel(userConfig.LogLevel))
 	eventParser := &events.EventParser{
 		GithubUser:         userConfig.GithubUser,
 		GithubToken:        userConfig.GithubToken,
+		GithubCredentials:  githubCredentials,
 		GitlabUser:         userConfig.GitlabUser,
 		GitlabToken:        userConfig.GitlabToken,
 		BitbucketUser:      userConfig.BitbucketUser,
 		BitbucketToken:     userConfig.BitbucketToken,
 		BitbucketServerURL: userConfig.BitbucketBaseURL,
+		GiteaUser:          userConfig.GiteaUser,
+		GiteaToken:         userConfig.GiteaToken,
+		AzureDevopsUser:    userConfig.AzureDevopsUser,
+		AzureDevopsToken:   userConfig.AzureDevopsToken,
 	}
 	commentParser := &events.CommentParser{
-		GithubUser:  userConfig.GithubUser,
-		GithubToken: userConfig.GithubToken,
-		GitlabUser:  userConfig.GitlabUser,
-		GitlabToken: userConfig.GitlabToken,
-		WakeWord:    userConfig.WakeWord,
+		GithubUser:      userConfig.GithubUser,
+		GithubToken:     userConfig.GithubToken,
+		GitlabUser:      userConfig.GitlabUser,
+		GitlabToken:     userConfig.GitlabToken,
+		GiteaUser:       userConfig.GiteaUser,
+		AzureDevopsUser: userConfig.AzureDevopsUser,
+		WakeWord:        userConfig.WakeWord,
+	}
+	// Each additional VCS host has its own client, pull getter, event and
+	// comment parsers, and its webhooks are sent to /events/{hostname}.
+	runnerVCSHosts := make(map[string]events.VCSHost)
+	webhookVCSHosts := make(map[string]VCSHostWebhooks)
+	for _, c := range userConfig.VCSHosts {
+		host, err := newVCSHost(c, *eventParser, *commentParser, vcsHTTPClient, userConfig.AtlantisURL, responseCache)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting up VCS host %s", c.URL)
+		}
+		vcsClient.AddHost(host.webhooks.Type, host.hostname, host.client)
+		runnerVCSHosts[host.hostname] = host.runner
+		webhookVCSHosts[host.hostname] = host.webhooks
 	}
 	defaultTfVersion := terraformClient.Version()
+	pendingPlanFinder := &events.DefaultPendingPlanFinder{}
 	commandRunner := &events.DefaultCommandRunner{
 		VCSClient:                vcsClient,
-		GithubPullGetter:         githubClient,
+		GithubPullGetter:         githubPullGetter,
 		GitlabMergeRequestGetter: gitlabClient,
+		GiteaPullGetter:          giteaClient,
 		CommitStatusUpdater:      commitStatusUpdater,
 		EventParser:              eventParser,
 		MarkdownRenderer:         markdownRenderer,
 		Logger:                   logger,
 		AllowForkPRs:             userConfig.AllowForkPRs,
 		AllowForkPRsFlag:         config.AllowForkPRsFlag,
+		CommentOutputStore:       commentOutputStore,
+		OutputURLGenerator:       router,
+		GlobalAutomerge:          userConfig.Automerge,
+		WorkingDir:               workingDir,
+		PendingPlanFinder:        pendingPlanFinder,
+		CommentMode:              events.CommentMode(userConfig.CommentMode),
+		PullCommentStore:         pullCommentStore,
+		VCSHosts:                 runnerVCSHosts,
+		Webhooks:                 webhooksManager,
+		PolicyChecksEnabled:      policyChecker != nil,
 		ProjectCommandBuilder: &events.DefaultProjectCommandBuilder{
 			ParserValidator:     &yaml.ParserValidator{},
 			ProjectFinder:       &events.DefaultProjectFinder{},
@@ -280,7 +598,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			AllowRepoConfig:     userConfig.AllowRepoConfig,
 			AllowRepoConfigFlag: config.AllowRepoConfigFlag,
 			RepoConfig:          userConfig.RepoConfig,
-			PendingPlanFinder:   &events.PendingPlanFinder{},
+			PendingPlanFinder:   pendingPlanFinder,
 			CommentBuilder:      commentParser,
 		},
 		ProjectCommandRunner: &events.DefaultProjectCommandRunner{
@@ -303,11 +621,21 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			RunStepRunner: &runtime.RunStepRunner{
 				DefaultTFVersion: defaultTfVersion,
 			},
+			ShowStepRunner: &runtime.ShowStepRunner{
+				TerraformExecutor: terraformClient,
+				DefaultTFVersion:  defaultTfVersion,
+			},
 			PullApprovedChecker:     vcsClient,
+			PullMergeableChecker:    vcsClient,
+			CodeOwnersChecker:       &events.DefaultCodeOwnersChecker{VCSClient: vcsClient},
 			WorkingDir:              workingDir,
 			Webhooks:                webhooksManager,
 			WorkingDirLocker:        workingDirLocker,
 			RequireApprovalOverride: userConfig.RequireApproval,
+			TerraformVersionResolver: &events.DefaultTerraformVersionResolver{
+				TerraformClient: terraformClient,
+			},
+			PolicyChecker: policyChecker,
 		},
 	}
 	repoWhitelist, err := events.NewRepoWhitelistChecker(userConfig.RepoWhitelist)
@@ -318,6 +646,12 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	var policyApprovers []string
+	for _, team := range strings.Split(userConfig.PolicyApprovers, ",") {
+		if team = strings.TrimSpace(team); team != "" {
+			policyApprovers = append(policyApprovers, team)
+		}
+	}
 	locksController := &LocksController{
 		AtlantisVersion:    config.AtlantisVersion,
 		Locker:             lockingClient,
@@ -327,6 +661,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WorkingDir:         workingDir,
 		WorkingDirLocker:   workingDirLocker,
 	}
+	outputsController := &OutputsController{
+		Logger:             logger,
+		CommentOutputStore: commentOutputStore,
+	}
 	eventsController := &EventsController{
 		CommandRunner:                commandRunner,
 		PullCleaner:                  pullClosedExecutor,
@@ -336,12 +674,25 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		GithubWebhookSecret:          []byte(userConfig.GithubWebhookSecret),
 		GithubRequestValidator:       &DefaultGithubRequestValidator{},
 		TeamWhitelistChecker:         githubTeamWhitelistChecker,
+		PolicyApprovers:              policyApprovers,
 		GitlabRequestParserValidator: &DefaultGitlabRequestParserValidator{},
 		GitlabWebhookSecret:          []byte(userConfig.GitlabWebhookSecret),
 		RepoWhitelistChecker:         repoWhitelist,
 		SupportedVCSHosts:            supportedVCSHosts,
 		VCSClient:                    vcsClient,
 		BitbucketWebhookSecret:       []byte(userConfig.BitbucketWebhookSecret),
+		GiteaWebhookSecret:           []byte(userConfig.GiteaWebhookSecret),
+		AzureDevopsWebhookUser:       []byte(userConfig.AzureDevopsWebhookUser),
+		AzureDevopsWebhookPassword:   []byte(userConfig.AzureDevopsWebhookPassword),
+		VCSResponseCache:             responseCache,
+		VCSHosts:                     webhookVCSHosts,
+		Deliveries:                   deliveryStore,
+	}
+	adminController := &AdminController{
+		Token:            userConfig.AdminToken,
+		Deliveries:       deliveryStore,
+		EventsController: eventsController,
+		Logger:           logger,
 	}
 	return &Server{
 		AtlantisVersion:    config.AtlantisVersion,
@@ -352,6 +703,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Locker:             lockingClient,
 		EventsController:   eventsController,
 		LocksController:    locksController,
+		OutputsController:  outputsController,
+		AdminController:    adminController,
+		VCSStats:           vcsStats,
 		IndexTemplate:      indexTemplate,
 		LockDetailTemplate: lockTemplate,
 		SSLKeyFile:         userConfig.SSLKeyFile,
@@ -359,17 +713,160 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	}, nil
 }
 
+// newVCSResponseCache returns the cache for VCS API responses configured by
+// userConfig.
+func newVCSResponseCache(userConfig UserConfig) (*vcs.ResponseCache, error) {
+	var ttls [3]time.Duration
+	for i, ttl := range []string{userConfig.VCSCacheTeamsTTL, userConfig.VCSCacheModifiedFilesTTL, userConfig.VCSCachePullTTL} {
+		if ttl == "" {
+			continue
+		}
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing VCS cache TTL %q", ttl)
+		}
+		ttls[i] = d
+	}
+	return vcs.NewResponseCache(ttls[0], ttls[1], ttls[2]), nil
+}
+
+// deliveryPruneInterval is how often webhook deliveries older than the
+// retention are deleted.
+const deliveryPruneInterval = time.Minute
+
+// newDeliveryStore returns the store for webhook deliveries configured by
+// userConfig. It returns nil if deliveries aren't recorded.
+func newDeliveryStore(userConfig UserConfig) (DeliveryStore, error) {
+	if userConfig.VCSDeliveryRetention == "" {
+		return nil, nil
+	}
+	retention, err := time.ParseDuration(userConfig.VCSDeliveryRetention)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing VCS delivery retention %q", userConfig.VCSDeliveryRetention)
+	}
+	if retention == 0 {
+		return nil, nil
+	}
+	var dedupWindow time.Duration
+	if userConfig.VCSDeliveryDedupWindow != "" {
+		dedupWindow, err = time.ParseDuration(userConfig.VCSDeliveryDedupWindow)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing VCS delivery dedup window %q", userConfig.VCSDeliveryDedupWindow)
+		}
+	}
+	return &FileDeliveryStore{
+		DataDir:       userConfig.DataDir,
+		DedupWindow:   dedupWindow,
+		Retention:     retention,
+		PruneInterval: deliveryPruneInterval,
+	}, nil
+}
+
+// vcsHost is an additional VCS host configured by UserConfig.VCSHosts.
+type vcsHost struct {
+	hostname string
+	client   vcs.Client
+	runner   events.VCSHost
+	webhooks VCSHostWebhooks
+}
+
+// newVCSHost sets up the additional VCS host configured by c. Its event and
+// comment parsers are copies of parser and commentParser that use the host's
+// credentials.
+func newVCSHost(c VCSHostConfig, parser events.EventParser, commentParser events.CommentParser, httpClient *http.Client, atlantisURL string, cache *vcs.ResponseCache) (vcsHost, error) {
+	hostURL, err := url.Parse(c.URL)
+	if err != nil {
+		return vcsHost{}, err
+	}
+	baseURL := strings.TrimSuffix(c.URL, "/")
+	host := vcsHost{
+		hostname: hostURL.Hostname(),
+		webhooks: VCSHostWebhooks{WebhookSecret: []byte(c.WebhookSecret)},
+	}
+
+	var client vcs.Client
+	switch c.Type {
+	case "github":
+		credentials := &vcs.GithubUserCredentials{
+			User:      c.User,
+			Token:     c.Token,
+			Transport: httpClient.Transport,
+		}
+		githubClient, err := vcs.NewGithubClient(hostURL.Host, credentials)
+		if err != nil {
+			return vcsHost{}, err
+		}
+		client = githubClient
+		host.runner.GithubPullGetter = &vcs.CachingGithubPullGetter{Client: githubClient, Cache: cache}
+		host.webhooks.Type = models.Github
+		parser.GithubUser = c.User
+		parser.GithubToken = c.Token
+		parser.GithubCredentials = credentials
+		commentParser.GithubUser = c.User
+		commentParser.GithubToken = c.Token
+	case "gitlab":
+		gitlabClient := &vcs.GitlabClient{
+			Client: gitlab.NewClient(httpClient, c.Token),
+		}
+		apiURL := fmt.Sprintf("%s://%s/api/v4/", hostURL.Scheme, hostURL.Host)
+		if err := gitlabClient.Client.SetBaseURL(apiURL); err != nil {
+			return vcsHost{}, errors.Wrapf(err, "setting GitLab API URL: %s", apiURL)
+		}
+		client = gitlabClient
+		host.runner.GitlabMergeRequestGetter = gitlabClient
+		host.webhooks.Type = models.Gitlab
+		parser.GitlabUser = c.User
+		parser.GitlabToken = c.Token
+		commentParser.GitlabUser = c.User
+		commentParser.GitlabToken = c.Token
+	case "bitbucket-server":
+		bitbucketClient, err := bitbucketserver.NewClient(httpClient, c.User, c.Token, baseURL, atlantisURL)
+		if err != nil {
+			return vcsHost{}, errors.Wrap(err, "setting up Bitbucket Server client")
+		}
+		client = bitbucketClient
+		host.webhooks.Type = models.BitbucketServer
+		parser.BitbucketUser = c.User
+		parser.BitbucketToken = c.Token
+		parser.BitbucketServerURL = baseURL
+	case "gitea":
+		giteaClient, err := gitea.NewClient(httpClient, c.User, c.Token, baseURL, atlantisURL)
+		if err != nil {
+			return vcsHost{}, errors.Wrap(err, "setting up Gitea client")
+		}
+		client = giteaClient
+		host.runner.GiteaPullGetter = giteaClient
+		host.webhooks.Type = models.Gitea
+		parser.GiteaUser = c.User
+		parser.GiteaToken = c.Token
+		commentParser.GiteaUser = c.User
+	default:
+		return vcsHost{}, fmt.Errorf("unsupported VCS host type %q, must be one of %s", c.Type, strings.Join(VCSHostConfigTypes, ", "))
+	}
+
+	host.client = &vcs.CachingClient{Client: client, Cache: cache}
+	host.runner.EventParser = &parser
+	host.webhooks.Parser = &parser
+	host.webhooks.CommentParser = &commentParser
+	return host, nil
+}
+
 // Start creates the routes and starts serving traffic.
 func (s *Server) Start() error {
 	s.Router.HandleFunc("/", s.Index).Methods("GET").MatcherFunc(func(r *http.Request, rm *mux.RouteMatch) bool {
 		return r.URL.Path == "/" || r.URL.Path == "/index.html"
 	})
 	s.Router.HandleFunc("/healthz", s.Healthz).Methods("GET")
+	s.Router.HandleFunc("/metrics/vcs", s.VCSMetrics).Methods("GET")
 	s.Router.PathPrefix("/static/").Handler(http.FileServer(&assetfs.AssetFS{Asset: static.Asset, AssetDir: static.AssetDir, AssetInfo: static.AssetInfo}))
 	s.Router.HandleFunc("/events", s.EventsController.Post).Methods("POST")
+	s.Router.HandleFunc("/events/{hostname}", s.EventsController.PostHost).Methods("POST")
 	s.Router.HandleFunc("/locks", s.LocksController.DeleteLock).Methods("DELETE").Queries("id", "{id:.*}")
 	s.Router.HandleFunc("/lock", s.LocksController.GetLock).Methods("GET").
 		Queries(LockViewRouteIDQueryParam, fmt.Sprintf("{%s}", LockViewRouteIDQueryParam)).Name(LockViewRouteName)
+	s.Router.HandleFunc("/outputs/{id}", s.OutputsController.GetOutput).Methods("GET").Name(OutputViewRouteName)
+	s.Router.HandleFunc("/admin/deliveries", s.AdminController.ListDeliveries).Methods("GET")
+	s.Router.HandleFunc("/admin/deliveries/{id}/replay", s.AdminController.ReplayDelivery).Methods("POST")
 	n := negroni.New(&negroni.Recovery{
 		Logger:     log.New(os.Stdout, "", log.LstdFlags),
 		PrintStack: false,
@@ -450,3 +947,20 @@ func (s *Server) Healthz(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(data) // nolint: errcheck
 }
+
+// VCSMetrics returns the counts of VCS API requests, retries and rate limited
+// responses since Atlantis started as JSON.
+func (s *Server) VCSMetrics(w http.ResponseWriter, _ *http.Request) {
+	var stats retry.Stats
+	if s.VCSStats != nil {
+		stats = s.VCSStats.Snapshot()
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error creating metrics json response: %s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data) // nolint: errcheck
+}
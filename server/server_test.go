@@ -26,6 +26,7 @@ import (
 	"github.com/cloudposse/atlantis/server"
 	"github.com/cloudposse/atlantis/server/events/locking/mocks"
 	"github.com/cloudposse/atlantis/server/events/models"
+	"github.com/cloudposse/atlantis/server/events/vcs/retry"
 	sMocks "github.com/cloudposse/atlantis/server/mocks"
 	. "github.com/cloudposse/atlantis/testing"
 	"github.com/gorilla/mux"
@@ -116,6 +117,25 @@ func TestHealthz(t *testing.T) {
 }`, string(body))
 }
 
+func TestVCSMetrics(t *testing.T) {
+	s := server.Server{
+		VCSStats: &retry.Stats{Requests: 3, Retries: 2, RateLimited: 1},
+	}
+	req, _ := http.NewRequest("GET", "/metrics/vcs", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.VCSMetrics(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	Equals(t, "application/json", w.Result().Header["Content-Type"][0])
+	Equals(t,
+		`{
+  "requests": 3,
+  "retries": 2,
+  "rate_limited": 1,
+  "failures": 0
+}`, string(body))
+}
+
 func responseContains(t *testing.T, r *httptest.ResponseRecorder, status int, bodySubstr string) {
 	t.Helper()
 	body, err := ioutil.ReadAll(r.Result().Body)